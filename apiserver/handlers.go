@@ -0,0 +1,990 @@
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/chaosscenario"
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/deployment/dockerdeploy"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type DeployerOutput struct {
+	Name         string                  `json:"name"`
+	IsDefault    bool                    `json:"is_default"`
+	Capabilities []deployment.Capability `json:"capabilities"`
+}
+
+type ClusterOutput struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	State    string       `json:"state"`
+	Purpose  string       `json:"purpose,omitempty"`
+	Expiry   *time.Time   `json:"expiry,omitempty"`
+	Deployer string       `json:"deployer"`
+	Nodes    []NodeOutput `json:"nodes"`
+}
+
+type NodeOutput struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	IPAddress     string `json:"ip_address"`
+	ResourceID    string `json:"resource_id"`
+	IsClusterNode bool   `json:"is_cluster_node"`
+}
+
+type AllocateRequest struct {
+	// Tag is a short definition such as simple:7.6.2, and Def is a full YAML
+	// cluster definition.  Exactly one of them must be specified.
+	Tag string `json:"tag,omitempty"`
+	Def string `json:"def,omitempty"`
+
+	Purpose       string `json:"purpose,omitempty"`
+	Expiry        string `json:"expiry,omitempty"`
+	Deployer      string `json:"deployer,omitempty"`
+	CloudProvider string `json:"cloud_provider,omitempty"`
+
+	// FromPool claims a ready cluster from the pool named by Tag, falling
+	// back to allocating a new cluster if the pool is empty.
+	FromPool bool `json:"from_pool,omitempty"`
+}
+
+type ReturnToPoolRequest struct {
+	IdleExpiry string `json:"idle_expiry,omitempty"`
+}
+
+type ConnectInfoOutput struct {
+	ConnStr        string `json:"connstr"`
+	ConnStrTls     string `json:"connstr_tls"`
+	ConnStrCb2     string `json:"connstr_cb2"`
+	Analytics      string `json:"analytics"`
+	AnalyticsTls   string `json:"analytics_tls"`
+	Mgmt           string `json:"mgmt"`
+	MgmtTls        string `json:"mgmt_tls"`
+	DataApiConnstr string `json:"data_api_connstr"`
+	DnsAName       string `json:"dns_a_name"`
+	DnsSRVName     string `json:"dns_srv_name"`
+	DnsServer      string `json:"dns_server"`
+}
+
+type BucketOutput struct {
+	Name string `json:"name"`
+}
+
+type CreateBucketRequest struct {
+	Name         string `json:"name"`
+	BucketType   string `json:"bucket_type,omitempty"`
+	RamQuotaMB   int    `json:"ram_quota_mb,omitempty"`
+	FlushEnabled bool   `json:"flush_enabled,omitempty"`
+	// NumReplicas defaults to 1 when unset.
+	NumReplicas *int `json:"num_replicas,omitempty"`
+
+	StorageBackend          string `json:"storage_backend,omitempty"`
+	EvictionPolicy          string `json:"eviction_policy,omitempty"`
+	DurabilityMinLevel      string `json:"durability_min_level,omitempty"`
+	MaxTTLSeconds           int    `json:"max_ttl_seconds,omitempty"`
+	CompressionMode         string `json:"compression_mode,omitempty"`
+	ConflictResolution      string `json:"conflict_resolution,omitempty"`
+	HistoryRetentionBytes   uint64 `json:"history_retention_bytes,omitempty"`
+	HistoryRetentionSeconds int    `json:"history_retention_seconds,omitempty"`
+	NumVBuckets             int    `json:"num_vbuckets,omitempty"`
+}
+
+type LoadSampleBucketRequest struct {
+	Name string `json:"name"`
+}
+
+type ScopeOutput struct {
+	Name        string   `json:"name"`
+	Collections []string `json:"collections"`
+}
+
+type UserOutput struct {
+	Username string   `json:"username"`
+	CanRead  bool     `json:"can_read"`
+	CanWrite bool     `json:"can_write"`
+	Roles    []string `json:"roles,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+	External bool     `json:"external,omitempty"`
+}
+
+type CreateUserRequest struct {
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	CanRead  bool     `json:"can_read,omitempty"`
+	CanWrite bool     `json:"can_write,omitempty"`
+	Roles    []string `json:"roles,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+	External bool     `json:"external,omitempty"`
+}
+
+type ChaosNodesRequest struct {
+	Nodes []string `json:"nodes"`
+}
+
+type BlockTrafficRequest struct {
+	Nodes []string `json:"nodes"`
+	// From is the type of traffic to block (nodes, clients, all) and defaults
+	// to nodes.
+	From       string `json:"from,omitempty"`
+	RejectWith string `json:"reject_with,omitempty"`
+}
+
+type PartitionTrafficRequest struct {
+	Nodes      []string `json:"nodes"`
+	RejectWith string   `json:"reject_with,omitempty"`
+}
+
+type PartitionGroupInput struct {
+	Name  string   `json:"name"`
+	Nodes []string `json:"nodes"`
+}
+
+type PartitionRuleInput struct {
+	From          string `json:"from"`
+	To            string `json:"to"`
+	Bidirectional bool   `json:"bidirectional,omitempty"`
+}
+
+type PartitionRequest struct {
+	Groups     []PartitionGroupInput `json:"groups"`
+	Rules      []PartitionRuleInput  `json:"rules"`
+	RejectWith string                `json:"reject_with,omitempty"`
+}
+
+type DegradeTrafficRequest struct {
+	Nodes []string `json:"nodes"`
+	// Traffic is the type of traffic to degrade (nodes, clients, all) and
+	// defaults to all.  Direction is ingress, egress or both, and defaults
+	// to both.
+	Traffic   string `json:"traffic,omitempty"`
+	Direction string `json:"direction,omitempty"`
+	// Latency and Jitter are durations such as 100ms.
+	Latency     string  `json:"latency,omitempty"`
+	Jitter      string  `json:"jitter,omitempty"`
+	LossPercent float64 `json:"loss_percent,omitempty"`
+	Rate        string  `json:"rate,omitempty"`
+}
+
+type NodeTrafficRulesOutput struct {
+	ID        string   `json:"id"`
+	IPAddress string   `json:"ip_address"`
+	Rules     []string `json:"rules"`
+}
+
+type RunScenarioRequest struct {
+	// Scenario is the YAML scenario to run, in the format used by `chaos run`.
+	Scenario string `json:"scenario"`
+}
+
+func clusterOutput(deployerName string, cluster deployment.ClusterInfo) *ClusterOutput {
+	out := &ClusterOutput{
+		ID:       cluster.GetID(),
+		Type:     string(cluster.GetType()),
+		State:    cluster.GetState(),
+		Purpose:  cluster.GetPurpose(),
+		Deployer: deployerName,
+		Nodes:    []NodeOutput{},
+	}
+
+	expiry := cluster.GetExpiry()
+	if !expiry.IsZero() {
+		out.Expiry = &expiry
+	}
+
+	for _, node := range cluster.GetNodes() {
+		out.Nodes = append(out.Nodes, NodeOutput{
+			ID:            node.GetID(),
+			Name:          node.GetName(),
+			IPAddress:     node.GetIPAddress(),
+			ResourceID:    node.GetResourceID(),
+			IsClusterNode: node.IsClusterNode(),
+		})
+	}
+
+	return out
+}
+
+func parseTrafficType(trafficTypeStr string, defaultType deployment.BlockNodeTrafficType) (deployment.BlockNodeTrafficType, error) {
+	switch trafficTypeStr {
+	case "":
+		return defaultType, nil
+	case "nodes":
+		return deployment.BlockNodeTrafficNodes, nil
+	case "clients":
+		return deployment.BlockNodeTrafficClients, nil
+	case "all":
+		return deployment.BlockNodeTrafficAll, nil
+	}
+	return "", badRequest(fmt.Errorf("unexpected traffic type `%s`", trafficTypeStr))
+}
+
+func parseOptionalDuration(name string, durationStr string) (time.Duration, error) {
+	if durationStr == "" {
+		return 0, nil
+	}
+
+	duration, err := time.ParseDuration(durationStr)
+	if err != nil {
+		return 0, badRequest(errors.Wrapf(err, "invalid %s", name))
+	}
+
+	return duration, nil
+}
+
+func (s *Server) handleListDeployers(r *http.Request) (any, error) {
+	out := []DeployerOutput{}
+	for deployerName, deployer := range s.deployers {
+		capabilities, err := deployer.Capabilities(r.Context())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get capabilities of %s deployer", deployerName)
+		}
+
+		out = append(out, DeployerOutput{
+			Name:         deployerName,
+			IsDefault:    deployerName == s.defaultDeployer,
+			Capabilities: capabilities,
+		})
+	}
+
+	slices.SortFunc(out, func(a, b DeployerOutput) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return out, nil
+}
+
+func (s *Server) handleListJobs(r *http.Request) (any, error) {
+	return s.jobs.List(), nil
+}
+
+func (s *Server) handleGetJob(r *http.Request) (any, error) {
+	job := s.jobs.Get(r.PathValue("job"))
+	if job == nil {
+		return nil, notFound(fmt.Errorf("failed to find job `%s`", r.PathValue("job")))
+	}
+
+	return job, nil
+}
+
+func (s *Server) handleListClusters(r *http.Request) (any, error) {
+	clusters := s.listClusters(r.Context())
+
+	slices.SortFunc(clusters, func(a, b *deployerCluster) int {
+		return strings.Compare(a.Cluster.GetID(), b.Cluster.GetID())
+	})
+
+	out := []*ClusterOutput{}
+	for _, cluster := range clusters {
+		out = append(out, clusterOutput(cluster.DeployerName, cluster.Cluster))
+	}
+
+	return out, nil
+}
+
+func (s *Server) handleGetCluster(r *http.Request) (any, error) {
+	cluster, err := s.identifyCluster(r.Context(), r.PathValue("cluster"))
+	if err != nil {
+		return nil, err
+	}
+
+	return clusterOutput(cluster.DeployerName, cluster.Cluster), nil
+}
+
+func (s *Server) handleAllocateCluster(r *http.Request) (jobFunc, error) {
+	req, err := decodeBody[AllocateRequest](r)
+	if err != nil {
+		return nil, err
+	}
+
+	var def *clusterdef.Cluster
+	if req.Tag != "" && req.Def != "" {
+		return nil, badRequest(errors.New("must specify only one of tag or def"))
+	} else if req.Tag != "" {
+		def, err = clusterdef.FromShortString(req.Tag)
+		if err != nil {
+			return nil, badRequest(errors.Wrap(err, "failed to parse definition short string"))
+		}
+	} else if req.Def != "" {
+		def, err = clusterdef.Parse([]byte(req.Def))
+		if err != nil {
+			return nil, badRequest(errors.Wrap(err, "failed to parse cluster definition"))
+		}
+	} else {
+		return nil, badRequest(errors.New("must specify one of tag or def"))
+	}
+
+	expiry, err := parseOptionalDuration("expiry", req.Expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Purpose != "" {
+		def.Purpose = req.Purpose
+	}
+	if expiry != 0 {
+		def.Expiry = expiry
+	} else if def.Expiry == 0 {
+		def.Expiry = s.defaultExpiry
+	}
+	if req.Deployer != "" {
+		def.Deployer = req.Deployer
+	}
+	if req.CloudProvider != "" {
+		def.Cloud.CloudProvider = req.CloudProvider
+	}
+
+	setup, err := deployment.NewClusterSetup(def)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	deployerName := def.Deployer
+	if deployerName == "" {
+		deployerName = s.defaultDeployer
+	}
+
+	deployer, err := s.getDeployer(deployerName)
+	if err != nil {
+		return nil, err
+	}
+
+	allocate := func(ctx context.Context) (any, error) {
+		s.logger.Info("deploying definition", zap.Any("def", def))
+
+		cluster, err := deployer.NewCluster(ctx, def)
+		if err != nil {
+			return nil, errors.Wrap(err, "cluster deployment failed")
+		}
+
+		err = setup.Apply(ctx, s.logger, deployer, cluster.GetID())
+		if err != nil {
+			return nil, err
+		}
+
+		return clusterOutput(deployerName, cluster), nil
+	}
+
+	if !req.FromPool {
+		return allocate, nil
+	}
+
+	if req.Tag == "" {
+		return nil, badRequest(errors.New("from_pool requires a tag naming the pool"))
+	}
+
+	dockerDeployer, ok := deployer.(*dockerdeploy.Deployer)
+	if !ok {
+		return nil, badRequest(errors.New("from_pool is only supported by the docker deployer"))
+	}
+
+	return func(ctx context.Context) (any, error) {
+		cluster, err := dockerDeployer.ClaimPoolCluster(ctx, req.Tag, def.Purpose, def.Expiry)
+		if err == nil {
+			s.refillPool(req.Tag)
+			return clusterOutput(deployerName, cluster), nil
+		} else if !errors.Is(err, dockerdeploy.ErrPoolEmpty) {
+			return nil, errors.Wrap(err, "failed to claim pool cluster")
+		}
+
+		s.logger.Warn("pool has no ready clusters, allocating a new cluster instead",
+			zap.String("pool", req.Tag))
+		s.refillPool(req.Tag)
+
+		return allocate(ctx)
+	}, nil
+}
+
+func (s *Server) handleReturnToPool(r *http.Request) (jobFunc, error) {
+	req, err := decodeBody[ReturnToPoolRequest](r)
+	if err != nil {
+		return nil, err
+	}
+
+	idleExpiry, err := parseOptionalDuration("idle_expiry", req.IdleExpiry)
+	if err != nil {
+		return nil, err
+	}
+	if idleExpiry == 0 {
+		idleExpiry = dockerdeploy.DefaultPoolIdleExpiry
+	}
+
+	cluster, err := s.identifyCluster(r.Context(), r.PathValue("cluster"))
+	if err != nil {
+		return nil, err
+	}
+
+	dockerDeployer, ok := cluster.Deployer.(*dockerdeploy.Deployer)
+	if !ok {
+		return nil, deployment.NewNotSupportedError("only docker clusters can be returned to a pool")
+	}
+
+	return func(ctx context.Context) (any, error) {
+		return nil, dockerDeployer.ReturnPoolCluster(ctx, cluster.Cluster.GetID(), idleExpiry)
+	}, nil
+}
+
+func (s *Server) handleRemoveCluster(r *http.Request) (jobFunc, error) {
+	cluster, err := s.identifyCluster(r.Context(), r.PathValue("cluster"))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) (any, error) {
+		return nil, cluster.Deployer.RemoveCluster(ctx, cluster.Cluster.GetID())
+	}, nil
+}
+
+func (s *Server) handleStopCluster(r *http.Request) (jobFunc, error) {
+	cluster, err := s.identifyCluster(r.Context(), r.PathValue("cluster"))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) (any, error) {
+		return nil, cluster.Deployer.StopCluster(ctx, cluster.Cluster.GetID())
+	}, nil
+}
+
+func (s *Server) handleStartCluster(r *http.Request) (jobFunc, error) {
+	cluster, err := s.identifyCluster(r.Context(), r.PathValue("cluster"))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) (any, error) {
+		return nil, cluster.Deployer.StartCluster(ctx, cluster.Cluster.GetID())
+	}, nil
+}
+
+func (s *Server) handleGetConnectInfo(r *http.Request) (any, error) {
+	cluster, err := s.identifyCluster(r.Context(), r.PathValue("cluster"))
+	if err != nil {
+		return nil, err
+	}
+
+	connectInfo, err := cluster.Deployer.GetConnectInfo(r.Context(), cluster.Cluster.GetID())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get connect info")
+	}
+
+	return &ConnectInfoOutput{
+		ConnStr:        connectInfo.ConnStr,
+		ConnStrTls:     connectInfo.ConnStrTls,
+		ConnStrCb2:     connectInfo.ConnStrCb2,
+		Analytics:      connectInfo.Analytics,
+		AnalyticsTls:   connectInfo.AnalyticsTls,
+		Mgmt:           connectInfo.Mgmt,
+		MgmtTls:        connectInfo.MgmtTls,
+		DataApiConnstr: connectInfo.DataApiConnstr,
+		DnsAName:       connectInfo.DnsAName,
+		DnsSRVName:     connectInfo.DnsSRVName,
+		DnsServer:      connectInfo.DnsServer,
+	}, nil
+}
+
+func (s *Server) handleListBuckets(r *http.Request) (any, error) {
+	cluster, err := s.identifyCluster(r.Context(), r.PathValue("cluster"))
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := cluster.Deployer.ListBuckets(r.Context(), cluster.Cluster.GetID())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list buckets")
+	}
+
+	out := []BucketOutput{}
+	for _, bucket := range buckets {
+		out = append(out, BucketOutput{Name: bucket.Name})
+	}
+
+	return out, nil
+}
+
+func (s *Server) handleCreateBucket(r *http.Request) (any, error) {
+	req, err := decodeBody[CreateBucketRequest](r)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name == "" {
+		return nil, badRequest(errors.New("a bucket name must be specified"))
+	}
+
+	numReplicas := 1
+	if req.NumReplicas != nil {
+		numReplicas = *req.NumReplicas
+	}
+
+	opts, err := deployment.NewCreateBucketOptions(req.Name, &clusterdef.Settings{
+		BucketType:               req.BucketType,
+		RamQuotaMB:               req.RamQuotaMB,
+		FlushEnabled:             req.FlushEnabled,
+		NumReplicas:              numReplicas,
+		StorageBackend:           req.StorageBackend,
+		EvictionPolicy:           req.EvictionPolicy,
+		DurabilityMinLevel:       req.DurabilityMinLevel,
+		MaxTTL:                   time.Duration(req.MaxTTLSeconds) * time.Second,
+		CompressionMode:          req.CompressionMode,
+		ConflictResolution:       req.ConflictResolution,
+		HistoryRetentionBytes:    req.HistoryRetentionBytes,
+		HistoryRetentionDuration: time.Duration(req.HistoryRetentionSeconds) * time.Second,
+		NumVBuckets:              req.NumVBuckets,
+	})
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	cluster, err := s.identifyCluster(r.Context(), r.PathValue("cluster"))
+	if err != nil {
+		return nil, err
+	}
+
+	err = cluster.Deployer.CreateBucket(r.Context(), cluster.Cluster.GetID(), opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create bucket")
+	}
+
+	return nil, nil
+}
+
+func (s *Server) handleDeleteBucket(r *http.Request) (any, error) {
+	cluster, err := s.identifyCluster(r.Context(), r.PathValue("cluster"))
+	if err != nil {
+		return nil, err
+	}
+
+	err = cluster.Deployer.DeleteBucket(r.Context(), cluster.Cluster.GetID(), r.PathValue("bucket"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to delete bucket")
+	}
+
+	return nil, nil
+}
+
+func (s *Server) handleLoadSampleBucket(r *http.Request) (jobFunc, error) {
+	req, err := decodeBody[LoadSampleBucketRequest](r)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name == "" {
+		return nil, badRequest(errors.New("a sample bucket name must be specified"))
+	}
+
+	cluster, err := s.identifyCluster(r.Context(), r.PathValue("cluster"))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) (any, error) {
+		return nil, cluster.Deployer.LoadSampleBucket(ctx, cluster.Cluster.GetID(), req.Name)
+	}, nil
+}
+
+func (s *Server) handleListCollections(r *http.Request) (any, error) {
+	cluster, err := s.identifyCluster(r.Context(), r.PathValue("cluster"))
+	if err != nil {
+		return nil, err
+	}
+
+	scopes, err := cluster.Deployer.ListCollections(r.Context(), cluster.Cluster.GetID(), r.PathValue("bucket"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list collections")
+	}
+
+	out := []ScopeOutput{}
+	for _, scope := range scopes {
+		scopeOut := ScopeOutput{
+			Name:        scope.Name,
+			Collections: []string{},
+		}
+		for _, collection := range scope.Collections {
+			scopeOut.Collections = append(scopeOut.Collections, collection.Name)
+		}
+		out = append(out, scopeOut)
+	}
+
+	return out, nil
+}
+
+func (s *Server) handleListUsers(r *http.Request) (any, error) {
+	cluster, err := s.identifyCluster(r.Context(), r.PathValue("cluster"))
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := cluster.Deployer.ListUsers(r.Context(), cluster.Cluster.GetID())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list users")
+	}
+
+	out := []UserOutput{}
+	for _, user := range users {
+		out = append(out, UserOutput{
+			Username: user.Username,
+			CanRead:  user.CanRead,
+			CanWrite: user.CanWrite,
+			Roles:    user.Roles,
+			Groups:   user.Groups,
+			External: user.External,
+		})
+	}
+
+	return out, nil
+}
+
+func (s *Server) handleCreateUser(r *http.Request) (any, error) {
+	req, err := decodeBody[CreateUserRequest](r)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.External {
+		if req.Username == "" || req.Password != "" {
+			return nil, badRequest(errors.New("a username and no password must be specified for external users"))
+		}
+	} else if req.Username == "" || req.Password == "" {
+		return nil, badRequest(errors.New("a username and password must be specified"))
+	}
+
+	_, err = deployment.ParseRoles(req.Roles)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	cluster, err := s.identifyCluster(r.Context(), r.PathValue("cluster"))
+	if err != nil {
+		return nil, err
+	}
+
+	err = cluster.Deployer.CreateUser(r.Context(), cluster.Cluster.GetID(), &deployment.CreateUserOptions{
+		Username: req.Username,
+		Password: req.Password,
+		CanRead:  req.CanRead,
+		CanWrite: req.CanWrite,
+		Roles:    req.Roles,
+		Groups:   req.Groups,
+		External: req.External,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create user")
+	}
+
+	return nil, nil
+}
+
+func (s *Server) handleDeleteUser(r *http.Request) (any, error) {
+	cluster, err := s.identifyCluster(r.Context(), r.PathValue("cluster"))
+	if err != nil {
+		return nil, err
+	}
+
+	err = cluster.Deployer.DeleteUser(r.Context(), cluster.Cluster.GetID(), r.PathValue("user"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to delete user")
+	}
+
+	return nil, nil
+}
+
+// identifyChaosNodes identifies the cluster of a chaos request along with the
+// ids of the nodes it targets.
+func (s *Server) identifyChaosNodes(r *http.Request, nodeIdents []string) (*deployerCluster, []string, error) {
+	cluster, err := s.identifyCluster(r.Context(), r.PathValue("cluster"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodeIDs, err := identifyNodeIDs(cluster.Cluster, nodeIdents)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cluster, nodeIDs, nil
+}
+
+func (s *Server) handleBlockTraffic(r *http.Request) (any, error) {
+	req, err := decodeBody[BlockTrafficRequest](r)
+	if err != nil {
+		return nil, err
+	}
+
+	trafficType, err := parseTrafficType(req.From, deployment.BlockNodeTrafficNodes)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, nodeIDs, err := s.identifyChaosNodes(r, req.Nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cluster.Deployer.BlockNodeTraffic(r.Context(), cluster.Cluster.GetID(), nodeIDs, trafficType, req.RejectWith)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to block node traffic")
+	}
+
+	return nil, nil
+}
+
+func (s *Server) handleAllowTraffic(r *http.Request) (any, error) {
+	req, err := decodeBody[ChaosNodesRequest](r)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, nodeIDs, err := s.identifyChaosNodes(r, req.Nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cluster.Deployer.AllowNodeTraffic(r.Context(), cluster.Cluster.GetID(), nodeIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to allow node traffic")
+	}
+
+	return nil, nil
+}
+
+func (s *Server) handlePartitionTraffic(r *http.Request) (any, error) {
+	req, err := decodeBody[PartitionTrafficRequest](r)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, nodeIDs, err := s.identifyChaosNodes(r, req.Nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cluster.Deployer.PartitionNodeTraffic(r.Context(), cluster.Cluster.GetID(), nodeIDs, req.RejectWith)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to partition node traffic")
+	}
+
+	return nil, nil
+}
+
+func (s *Server) handlePartition(r *http.Request) (any, error) {
+	req, err := decodeBody[PartitionRequest](r)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.identifyCluster(r.Context(), r.PathValue("cluster"))
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &deployment.PartitionSpec{}
+	for _, group := range req.Groups {
+		nodeIDs, err := identifyNodeIDs(cluster.Cluster, group.Nodes)
+		if err != nil {
+			return nil, err
+		}
+
+		spec.Groups = append(spec.Groups, deployment.PartitionGroup{
+			Name:    group.Name,
+			NodeIDs: nodeIDs,
+		})
+	}
+	for _, rule := range req.Rules {
+		spec.Rules = append(spec.Rules, deployment.PartitionRule{
+			From:          rule.From,
+			To:            rule.To,
+			Bidirectional: rule.Bidirectional,
+		})
+	}
+
+	err = spec.Validate()
+	if err != nil {
+		return nil, badRequest(errors.Wrap(err, "invalid partition"))
+	}
+
+	err = cluster.Deployer.PartitionNodeGroups(r.Context(), cluster.Cluster.GetID(), spec, req.RejectWith)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to partition node traffic")
+	}
+
+	return nil, nil
+}
+
+func (s *Server) handleDegradeTraffic(r *http.Request) (any, error) {
+	req, err := decodeBody[DegradeTrafficRequest](r)
+	if err != nil {
+		return nil, err
+	}
+
+	trafficType, err := parseTrafficType(req.Traffic, deployment.BlockNodeTrafficAll)
+	if err != nil {
+		return nil, err
+	}
+
+	var direction deployment.DegradeNodeTrafficDirection
+	switch req.Direction {
+	case "", "both":
+		direction = deployment.DegradeNodeTrafficBoth
+	case "ingress":
+		direction = deployment.DegradeNodeTrafficIngress
+	case "egress":
+		direction = deployment.DegradeNodeTrafficEgress
+	default:
+		return nil, badRequest(fmt.Errorf("unexpected traffic direction `%s`", req.Direction))
+	}
+
+	latency, err := parseOptionalDuration("latency", req.Latency)
+	if err != nil {
+		return nil, err
+	}
+
+	jitter, err := parseOptionalDuration("jitter", req.Jitter)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, nodeIDs, err := s.identifyChaosNodes(r, req.Nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cluster.Deployer.DegradeNodeTraffic(r.Context(), cluster.Cluster.GetID(), nodeIDs, &deployment.DegradeNodeTrafficOptions{
+		TrafficType: trafficType,
+		Direction:   direction,
+		Latency:     latency,
+		Jitter:      jitter,
+		LossPercent: req.LossPercent,
+		Rate:        req.Rate,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to degrade node traffic")
+	}
+
+	return nil, nil
+}
+
+func (s *Server) handleListTrafficRules(r *http.Request) (any, error) {
+	cluster, err := s.identifyCluster(r.Context(), r.PathValue("cluster"))
+	if err != nil {
+		return nil, err
+	}
+
+	nodeRules, err := cluster.Deployer.ListNodeTrafficRules(r.Context(), cluster.Cluster.GetID())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list node traffic rules")
+	}
+
+	out := []NodeTrafficRulesOutput{}
+	for _, node := range nodeRules {
+		rules := node.Rules
+		if rules == nil {
+			rules = []string{}
+		}
+
+		out = append(out, NodeTrafficRulesOutput{
+			ID:        node.NodeID,
+			IPAddress: node.IPAddress,
+			Rules:     rules,
+		})
+	}
+
+	return out, nil
+}
+
+func (s *Server) handlePauseNode(r *http.Request) (any, error) {
+	req, err := decodeBody[ChaosNodesRequest](r)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, nodeIDs, err := s.identifyChaosNodes(r, req.Nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cluster.Deployer.PauseNode(r.Context(), cluster.Cluster.GetID(), nodeIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pause node")
+	}
+
+	return nil, nil
+}
+
+func (s *Server) handleUnpauseNode(r *http.Request) (any, error) {
+	req, err := decodeBody[ChaosNodesRequest](r)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, nodeIDs, err := s.identifyChaosNodes(r, req.Nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cluster.Deployer.UnpauseNode(r.Context(), cluster.Cluster.GetID(), nodeIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unpause node")
+	}
+
+	return nil, nil
+}
+
+func (s *Server) handleKillCouchbase(r *http.Request) (any, error) {
+	req, err := decodeBody[ChaosNodesRequest](r)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, nodeIDs, err := s.identifyChaosNodes(r, req.Nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cluster.Deployer.KillCouchbase(r.Context(), cluster.Cluster.GetID(), nodeIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to kill couchbase")
+	}
+
+	return nil, nil
+}
+
+func (s *Server) handleRunScenario(r *http.Request) (jobFunc, error) {
+	req, err := decodeBody[RunScenarioRequest](r)
+	if err != nil {
+		return nil, err
+	}
+
+	scenario, err := chaosscenario.Parse([]byte(req.Scenario))
+	if err != nil {
+		return nil, badRequest(errors.Wrap(err, "failed to parse scenario"))
+	}
+
+	cluster, err := s.identifyCluster(r.Context(), r.PathValue("cluster"))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) (any, error) {
+		runner := &chaosscenario.Runner{
+			Logger:    s.logger,
+			Deployer:  cluster.Deployer,
+			ClusterID: cluster.Cluster.GetID(),
+			Nodes:     cluster.Cluster.GetNodes(),
+		}
+
+		timeline, err := runner.Run(ctx, scenario)
+		if err != nil {
+			return nil, err
+		}
+
+		return timeline, nil
+	}, nil
+}
@@ -0,0 +1,161 @@
+package apiserver
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type JobState string
+
+const (
+	JobStateRunning   JobState = "running"
+	JobStateSucceeded JobState = "succeeded"
+	JobStateFailed    JobState = "failed"
+)
+
+type Job struct {
+	ID         string     `json:"id"`
+	Operation  string     `json:"operation"`
+	State      JobState   `json:"state"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	Result     any        `json:"result,omitempty"`
+}
+
+type jobFunc func(ctx context.Context) (any, error)
+
+// JobManager runs long-running operations in the background and tracks their
+// state so that clients can poll for the result.
+type JobManager struct {
+	Logger *zap.Logger
+
+	// Retention is how long finished jobs are kept before they are forgotten.
+	// Defaults to 24 hours.
+	Retention time.Duration
+
+	lock sync.Mutex
+	jobs map[string]*Job
+	wg   sync.WaitGroup
+}
+
+func (m *JobManager) retention() time.Duration {
+	if m.Retention == 0 {
+		return 24 * time.Hour
+	}
+	return m.Retention
+}
+
+// pruneLocked removes finished jobs that are past their retention.
+func (m *JobManager) pruneLocked() {
+	for jobID, job := range m.jobs {
+		if job.FinishedAt != nil && time.Since(*job.FinishedAt) > m.retention() {
+			delete(m.jobs, jobID)
+		}
+	}
+}
+
+// Start runs fn in the background as a new job.  The job runs with ctx
+// rather than the context of the request which started it, so that it is
+// not cancelled when that request completes.
+func (m *JobManager) Start(ctx context.Context, operation string, fn jobFunc) *Job {
+	job := &Job{
+		ID:        uuid.NewString(),
+		Operation: operation,
+		State:     JobStateRunning,
+		CreatedAt: time.Now(),
+	}
+
+	m.lock.Lock()
+	if m.jobs == nil {
+		m.jobs = make(map[string]*Job)
+	}
+	m.pruneLocked()
+	m.jobs[job.ID] = job
+	jobCopy := *job
+	m.lock.Unlock()
+
+	m.Logger.Info("starting job",
+		zap.String("id", job.ID),
+		zap.String("operation", operation))
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		result, err := fn(ctx)
+
+		m.lock.Lock()
+		finishedAt := time.Now()
+		job.FinishedAt = &finishedAt
+		if err != nil {
+			job.State = JobStateFailed
+			job.Error = err.Error()
+		} else {
+			job.State = JobStateSucceeded
+			job.Result = result
+		}
+		m.lock.Unlock()
+
+		if err != nil {
+			m.Logger.Warn("job failed",
+				zap.String("id", job.ID),
+				zap.String("operation", operation),
+				zap.Error(err))
+		} else {
+			m.Logger.Info("job succeeded",
+				zap.String("id", job.ID),
+				zap.String("operation", operation))
+		}
+	}()
+
+	return &jobCopy
+}
+
+// Get returns a copy of the job, or nil if it does not exist.
+func (m *JobManager) Get(jobID string) *Job {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	job := m.jobs[jobID]
+	if job == nil {
+		return nil
+	}
+
+	jobCopy := *job
+	return &jobCopy
+}
+
+// List returns a copy of every job, oldest first.
+func (m *JobManager) List() []*Job {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.pruneLocked()
+
+	jobs := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobCopy := *job
+		jobs = append(jobs, &jobCopy)
+	}
+
+	slices.SortFunc(jobs, func(a, b *Job) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return jobs
+}
+
+// Wait blocks until every running job has finished.
+func (m *JobManager) Wait() {
+	m.wg.Wait()
+}
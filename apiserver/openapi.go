@@ -0,0 +1,200 @@
+package apiserver
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+var pathParamRegexp = regexp.MustCompile(`\{([a-zA-Z0-9_]+)\}`)
+
+// schemaBuilder generates JSON schemas for the request and response types
+// of the API, registering each named struct as a reusable component.
+type schemaBuilder struct {
+	components map[string]any
+}
+
+func (b *schemaBuilder) schemaFor(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return map[string]any{}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": b.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+
+		ref := map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+		if _, ok := b.components[t.Name()]; !ok {
+			// register the name before recursing, for self-referencing types
+			b.components[t.Name()] = map[string]any{}
+			b.components[t.Name()] = b.structSchema(t)
+		}
+		return ref
+	}
+
+	return map[string]any{}
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+
+	for fieldIdx := 0; fieldIdx < t.NumField(); fieldIdx++ {
+		field := t.Field(fieldIdx)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		omitEmpty := false
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+
+			tagName, tagOpts, _ := strings.Cut(tag, ",")
+			if tagName != "" {
+				name = tagName
+			}
+			omitEmpty = strings.Contains(tagOpts, "omitempty")
+		}
+
+		properties[name] = b.schemaFor(field.Type)
+		if !omitEmpty && field.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{
+		"application/json": map[string]any{
+			"schema": schema,
+		},
+	}
+}
+
+// OpenAPI generates the OpenAPI document describing the routes of the API.
+func OpenAPI() map[string]any {
+	b := &schemaBuilder{
+		components: make(map[string]any),
+	}
+
+	errorResponse := map[string]any{
+		"description": "The request failed",
+		"content":     jsonContent(b.schemaFor(reflect.TypeOf(ErrorOutput{}))),
+	}
+	jobSchema := b.schemaFor(reflect.TypeOf(Job{}))
+
+	paths := make(map[string]any)
+	for _, route := range routes {
+		pathItem, _ := paths[route.Path].(map[string]any)
+		if pathItem == nil {
+			pathItem = make(map[string]any)
+			paths[route.Path] = pathItem
+		}
+
+		operation := map[string]any{
+			"operationId": route.OperationID,
+			"summary":     route.Summary,
+			"tags":        []string{route.Tag},
+		}
+
+		var parameters []any
+		for _, match := range pathParamRegexp.FindAllStringSubmatch(route.Path, -1) {
+			parameters = append(parameters, map[string]any{
+				"name":     match[1],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]any{"type": "string"},
+			})
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+
+		if route.Request != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content":  jsonContent(b.schemaFor(reflect.TypeOf(route.Request))),
+			}
+		}
+
+		responses := map[string]any{
+			"default": errorResponse,
+		}
+		if route.Async {
+			description := "The job which was started to perform the operation"
+			if route.Response != nil {
+				// register the result type so that clients can decode it
+				resultType := reflect.TypeOf(route.Response)
+				b.schemaFor(resultType)
+				description += ", whose result is a " + resultType.Name()
+			}
+
+			responses["202"] = map[string]any{
+				"description": description,
+				"content":     jsonContent(jobSchema),
+			}
+		} else if route.Response != nil {
+			responses["200"] = map[string]any{
+				"description": "The operation succeeded",
+				"content":     jsonContent(b.schemaFor(reflect.TypeOf(route.Response))),
+			}
+		} else {
+			responses["204"] = map[string]any{
+				"description": "The operation succeeded",
+			}
+		}
+		operation["responses"] = responses
+
+		pathItem[strings.ToLower(route.Method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "cbdinocluster",
+			"version": "1",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": b.components,
+		},
+	}
+}
@@ -0,0 +1,81 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaFor(t *testing.T) {
+	type Inner struct {
+		Value float64 `json:"value"`
+	}
+	type Outer struct {
+		Name     string            `json:"name"`
+		Optional string            `json:"optional,omitempty"`
+		Time     *time.Time        `json:"time"`
+		Items    []Inner           `json:"items"`
+		Labels   map[string]string `json:"labels"`
+		Ignored  string            `json:"-"`
+		hidden   string
+	}
+
+	b := &schemaBuilder{components: make(map[string]any)}
+	schema := b.schemaFor(reflect.TypeOf(&Outer{}))
+	require.Equal(t, map[string]any{"$ref": "#/components/schemas/Outer"}, schema)
+
+	require.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":     map[string]any{"type": "string"},
+			"optional": map[string]any{"type": "string"},
+			"time":     map[string]any{"type": "string", "format": "date-time"},
+			"items": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"$ref": "#/components/schemas/Inner"},
+			},
+			"labels": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string"},
+			},
+		},
+		"required": []string{"name", "items", "labels"},
+	}, b.components["Outer"])
+	require.Contains(t, b.components, "Inner")
+}
+
+func TestOpenAPI(t *testing.T) {
+	doc := OpenAPI()
+
+	// the document must be serializable for clients to consume it
+	_, err := json.Marshal(doc)
+	require.NoError(t, err)
+
+	paths := doc["paths"].(map[string]any)
+	operationIDs := make(map[string]bool)
+	for _, route := range routes {
+		pathItem := paths[route.Path].(map[string]any)
+		require.Contains(t, pathItem, map[string]string{
+			"GET": "get", "POST": "post", "DELETE": "delete",
+		}[route.Method])
+
+		require.False(t, operationIDs[route.OperationID], "duplicate operation id %s", route.OperationID)
+		operationIDs[route.OperationID] = true
+
+		if route.Async {
+			require.Nil(t, route.Handler)
+			require.NotNil(t, route.AsyncHandler)
+		} else {
+			require.NotNil(t, route.Handler)
+			require.Nil(t, route.AsyncHandler)
+		}
+	}
+
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	require.Contains(t, schemas, "Job")
+	require.Contains(t, schemas, "Timeline")
+	require.Contains(t, schemas, "AllocateRequest")
+}
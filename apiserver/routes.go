@@ -0,0 +1,208 @@
+package apiserver
+
+import (
+	"net/http"
+
+	"github.com/couchbaselabs/cbdinocluster/chaosscenario"
+)
+
+type route struct {
+	Method      string
+	Path        string
+	OperationID string
+	Tag         string
+	Summary     string
+
+	// Request and Response are zero values of the request and response body
+	// types, and are used to generate the OpenAPI document.  For async routes
+	// Response is the type of the result of the job.
+	Request  any
+	Response any
+
+	// Async routes validate the request and return the work to perform as a
+	// job, rather than performing it during the request.
+	Async        bool
+	Handler      func(s *Server, r *http.Request) (any, error)
+	AsyncHandler func(s *Server, r *http.Request) (jobFunc, error)
+}
+
+var routes = []route{
+	{
+		Method: "GET", Path: "/v1/deployers", OperationID: "listDeployers", Tag: "deployers",
+		Summary:  "Lists the configured deployers and their capabilities",
+		Response: []DeployerOutput{},
+		Handler:  (*Server).handleListDeployers,
+	},
+	{
+		Method: "GET", Path: "/v1/jobs", OperationID: "listJobs", Tag: "jobs",
+		Summary:  "Lists the recent async jobs",
+		Response: []Job{},
+		Handler:  (*Server).handleListJobs,
+	},
+	{
+		Method: "GET", Path: "/v1/jobs/{job}", OperationID: "getJob", Tag: "jobs",
+		Summary:  "Gets the state of an async job",
+		Response: Job{},
+		Handler:  (*Server).handleGetJob,
+	},
+	{
+		Method: "GET", Path: "/v1/clusters", OperationID: "listClusters", Tag: "clusters",
+		Summary:  "Lists all clusters",
+		Response: []ClusterOutput{},
+		Handler:  (*Server).handleListClusters,
+	},
+	{
+		Method: "POST", Path: "/v1/clusters", OperationID: "allocateCluster", Tag: "clusters",
+		Summary:      "Allocates a cluster",
+		Request:      AllocateRequest{},
+		Response:     ClusterOutput{},
+		Async:        true,
+		AsyncHandler: (*Server).handleAllocateCluster,
+	},
+	{
+		Method: "GET", Path: "/v1/clusters/{cluster}", OperationID: "getCluster", Tag: "clusters",
+		Summary:  "Gets a cluster",
+		Response: ClusterOutput{},
+		Handler:  (*Server).handleGetCluster,
+	},
+	{
+		Method: "DELETE", Path: "/v1/clusters/{cluster}", OperationID: "removeCluster", Tag: "clusters",
+		Summary:      "Removes a cluster",
+		Async:        true,
+		AsyncHandler: (*Server).handleRemoveCluster,
+	},
+	{
+		Method: "POST", Path: "/v1/clusters/{cluster}/stop", OperationID: "stopCluster", Tag: "clusters",
+		Summary:      "Stops a cluster without removing its data",
+		Async:        true,
+		AsyncHandler: (*Server).handleStopCluster,
+	},
+	{
+		Method: "POST", Path: "/v1/clusters/{cluster}/start", OperationID: "startCluster", Tag: "clusters",
+		Summary:      "Starts a previously stopped cluster",
+		Async:        true,
+		AsyncHandler: (*Server).handleStartCluster,
+	},
+	{
+		Method: "POST", Path: "/v1/clusters/{cluster}/return-to-pool", OperationID: "returnClusterToPool", Tag: "clusters",
+		Summary:      "Resets a cluster claimed from a pool and returns it to the pool",
+		Request:      ReturnToPoolRequest{},
+		Async:        true,
+		AsyncHandler: (*Server).handleReturnToPool,
+	},
+	{
+		Method: "GET", Path: "/v1/clusters/{cluster}/connect-info", OperationID: "getConnectInfo", Tag: "clusters",
+		Summary:  "Gets the connection strings and endpoints of a cluster",
+		Response: ConnectInfoOutput{},
+		Handler:  (*Server).handleGetConnectInfo,
+	},
+	{
+		Method: "GET", Path: "/v1/clusters/{cluster}/buckets", OperationID: "listBuckets", Tag: "buckets",
+		Summary:  "Lists the buckets of a cluster",
+		Response: []BucketOutput{},
+		Handler:  (*Server).handleListBuckets,
+	},
+	{
+		Method: "POST", Path: "/v1/clusters/{cluster}/buckets", OperationID: "createBucket", Tag: "buckets",
+		Summary: "Creates a bucket",
+		Request: CreateBucketRequest{},
+		Handler: (*Server).handleCreateBucket,
+	},
+	{
+		Method: "DELETE", Path: "/v1/clusters/{cluster}/buckets/{bucket}", OperationID: "deleteBucket", Tag: "buckets",
+		Summary: "Deletes a bucket",
+		Handler: (*Server).handleDeleteBucket,
+	},
+	{
+		Method: "POST", Path: "/v1/clusters/{cluster}/sample-buckets", OperationID: "loadSampleBucket", Tag: "buckets",
+		Summary:      "Loads a sample bucket",
+		Request:      LoadSampleBucketRequest{},
+		Async:        true,
+		AsyncHandler: (*Server).handleLoadSampleBucket,
+	},
+	{
+		Method: "GET", Path: "/v1/clusters/{cluster}/buckets/{bucket}/scopes", OperationID: "listCollections", Tag: "buckets",
+		Summary:  "Lists the scopes and collections of a bucket",
+		Response: []ScopeOutput{},
+		Handler:  (*Server).handleListCollections,
+	},
+	{
+		Method: "GET", Path: "/v1/clusters/{cluster}/users", OperationID: "listUsers", Tag: "users",
+		Summary:  "Lists the users of a cluster",
+		Response: []UserOutput{},
+		Handler:  (*Server).handleListUsers,
+	},
+	{
+		Method: "POST", Path: "/v1/clusters/{cluster}/users", OperationID: "createUser", Tag: "users",
+		Summary: "Creates a user",
+		Request: CreateUserRequest{},
+		Handler: (*Server).handleCreateUser,
+	},
+	{
+		Method: "DELETE", Path: "/v1/clusters/{cluster}/users/{user}", OperationID: "deleteUser", Tag: "users",
+		Summary: "Deletes a user",
+		Handler: (*Server).handleDeleteUser,
+	},
+	{
+		Method: "POST", Path: "/v1/clusters/{cluster}/chaos/block-traffic", OperationID: "blockTraffic", Tag: "chaos",
+		Summary: "Blocks a type of traffic to nodes",
+		Request: BlockTrafficRequest{},
+		Handler: (*Server).handleBlockTraffic,
+	},
+	{
+		Method: "POST", Path: "/v1/clusters/{cluster}/chaos/allow-traffic", OperationID: "allowTraffic", Tag: "chaos",
+		Summary: "Allows all traffic to nodes",
+		Request: ChaosNodesRequest{},
+		Handler: (*Server).handleAllowTraffic,
+	},
+	{
+		Method: "POST", Path: "/v1/clusters/{cluster}/chaos/partition-traffic", OperationID: "partitionTraffic", Tag: "chaos",
+		Summary: "Blocks traffic between nodes and the rest of the cluster",
+		Request: PartitionTrafficRequest{},
+		Handler: (*Server).handlePartitionTraffic,
+	},
+	{
+		Method: "POST", Path: "/v1/clusters/{cluster}/chaos/partition", OperationID: "partitionNodeGroups", Tag: "chaos",
+		Summary: "Partitions the traffic between groups of nodes",
+		Request: PartitionRequest{},
+		Handler: (*Server).handlePartition,
+	},
+	{
+		Method: "POST", Path: "/v1/clusters/{cluster}/chaos/degrade", OperationID: "degradeTraffic", Tag: "chaos",
+		Summary: "Adds latency, packet loss or rate limiting to the traffic of nodes",
+		Request: DegradeTrafficRequest{},
+		Handler: (*Server).handleDegradeTraffic,
+	},
+	{
+		Method: "GET", Path: "/v1/clusters/{cluster}/chaos/traffic-rules", OperationID: "listTrafficRules", Tag: "chaos",
+		Summary:  "Lists the traffic rules currently applied to each node",
+		Response: []NodeTrafficRulesOutput{},
+		Handler:  (*Server).handleListTrafficRules,
+	},
+	{
+		Method: "POST", Path: "/v1/clusters/{cluster}/chaos/pause-node", OperationID: "pauseNode", Tag: "chaos",
+		Summary: "Pauses nodes",
+		Request: ChaosNodesRequest{},
+		Handler: (*Server).handlePauseNode,
+	},
+	{
+		Method: "POST", Path: "/v1/clusters/{cluster}/chaos/unpause-node", OperationID: "unpauseNode", Tag: "chaos",
+		Summary: "Unpauses nodes",
+		Request: ChaosNodesRequest{},
+		Handler: (*Server).handleUnpauseNode,
+	},
+	{
+		Method: "POST", Path: "/v1/clusters/{cluster}/chaos/kill-couchbase", OperationID: "killCouchbase", Tag: "chaos",
+		Summary: "Kills the couchbase server process on nodes",
+		Request: ChaosNodesRequest{},
+		Handler: (*Server).handleKillCouchbase,
+	},
+	{
+		Method: "POST", Path: "/v1/clusters/{cluster}/chaos/run", OperationID: "runChaosScenario", Tag: "chaos",
+		Summary:      "Runs a scenario of timed chaos steps",
+		Request:      RunScenarioRequest{},
+		Response:     chaosscenario.Timeline{},
+		Async:        true,
+		AsyncHandler: (*Server).handleRunScenario,
+	},
+}
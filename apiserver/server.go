@@ -0,0 +1,310 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/deployment/dockerdeploy"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
+)
+
+type ServerOptions struct {
+	Logger          *zap.Logger
+	Deployers       map[string]deployment.Deployer
+	DefaultDeployer string
+	DefaultExpiry   time.Duration
+
+	// PoolManagers are the pools maintained alongside the server, which are
+	// refilled as soon as a cluster is claimed from them.
+	PoolManagers []*dockerdeploy.PoolManager
+}
+
+// Server exposes the operations of a set of deployers over HTTP.  The
+// deployers are created once and shared by every request.
+type Server struct {
+	logger          *zap.Logger
+	deployers       map[string]deployment.Deployer
+	defaultDeployer string
+	defaultExpiry   time.Duration
+	poolManagers    map[string]*dockerdeploy.PoolManager
+	jobs            *JobManager
+
+	jobsCtx    context.Context
+	cancelJobs context.CancelFunc
+}
+
+func NewServer(opts *ServerOptions) (*Server, error) {
+	if len(opts.Deployers) == 0 {
+		return nil, errors.New("at least one deployer is required")
+	}
+
+	poolManagers := make(map[string]*dockerdeploy.PoolManager, len(opts.PoolManagers))
+	for _, poolMgr := range opts.PoolManagers {
+		poolManagers[poolMgr.Pool()] = poolMgr
+	}
+
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+
+	return &Server{
+		logger:          opts.Logger,
+		deployers:       opts.Deployers,
+		defaultDeployer: opts.DefaultDeployer,
+		defaultExpiry:   opts.DefaultExpiry,
+		poolManagers:    poolManagers,
+		jobs: &JobManager{
+			Logger: opts.Logger,
+		},
+		jobsCtx:    jobsCtx,
+		cancelJobs: cancelJobs,
+	}, nil
+}
+
+func (s *Server) refillPool(pool string) {
+	poolMgr := s.poolManagers[pool]
+	if poolMgr != nil {
+		poolMgr.Refill()
+	}
+}
+
+// Close cancels any jobs which are still running and waits for them to stop.
+func (s *Server) Close() {
+	s.cancelJobs()
+	s.jobs.Wait()
+}
+
+type ErrorOutput struct {
+	Error string `json:"error"`
+}
+
+// apiError is an error with a specific HTTP status.
+type apiError struct {
+	status int
+	err    error
+}
+
+func (e *apiError) Error() string {
+	return e.err.Error()
+}
+
+func (e *apiError) Unwrap() error {
+	return e.err
+}
+
+func badRequest(err error) error {
+	return &apiError{status: http.StatusBadRequest, err: err}
+}
+
+func notFound(err error) error {
+	return &apiError{status: http.StatusNotFound, err: err}
+}
+
+func errorStatus(err error) int {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr.status
+	}
+	if errors.Is(err, deployment.ErrNotSupported) {
+		return http.StatusNotImplemented
+	}
+	if errors.Is(err, deployment.ErrBucketAlreadyExists) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (s *Server) writeJson(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(value)
+	if err != nil {
+		s.logger.Debug("failed to write response", zap.Error(err))
+	}
+}
+
+func (s *Server) writeError(w http.ResponseWriter, err error) {
+	s.writeJson(w, errorStatus(err), &ErrorOutput{Error: err.Error()})
+}
+
+// decodeBody parses the JSON body of a request, rejecting unknown fields so
+// that typos in client requests are not silently ignored.
+func decodeBody[T any](r *http.Request) (*T, error) {
+	var body T
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(&body)
+	if err != nil {
+		return nil, badRequest(errors.Wrap(err, "invalid request body"))
+	}
+
+	return &body, nil
+}
+
+// Handler returns the HTTP handler serving the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	for _, route := range routes {
+		mux.HandleFunc(route.Method+" "+route.Path, func(w http.ResponseWriter, r *http.Request) {
+			s.logger.Debug("handling request",
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path))
+
+			if route.Async {
+				run, err := route.AsyncHandler(s, r)
+				if err != nil {
+					s.writeError(w, err)
+					return
+				}
+
+				job := s.jobs.Start(s.jobsCtx, route.OperationID, run)
+				s.writeJson(w, http.StatusAccepted, job)
+				return
+			}
+
+			result, err := route.Handler(s, r)
+			if err != nil {
+				s.writeError(w, err)
+				return
+			}
+
+			if result == nil {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			s.writeJson(w, http.StatusOK, result)
+		})
+	}
+
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		s.writeJson(w, http.StatusOK, OpenAPI())
+	})
+
+	return mux
+}
+
+func (s *Server) getDeployer(deployerName string) (deployment.Deployer, error) {
+	if deployerName == "" {
+		deployerName = s.defaultDeployer
+	}
+
+	deployer := s.deployers[deployerName]
+	if deployer == nil {
+		return nil, badRequest(fmt.Errorf("unknown deployer `%s`, available deployers are %v",
+			deployerName, maps.Keys(s.deployers)))
+	}
+
+	return deployer, nil
+}
+
+type deployerCluster struct {
+	DeployerName string
+	Deployer     deployment.Deployer
+	Cluster      deployment.ClusterInfo
+}
+
+func (s *Server) listClusters(ctx context.Context) []*deployerCluster {
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	var clusters []*deployerCluster
+
+	for deployerName, deployer := range s.deployers {
+		wg.Add(1)
+		go func(deployerName string, deployer deployment.Deployer) {
+			defer wg.Done()
+
+			deployerClusters, err := deployer.ListClusters(ctx)
+			if err != nil {
+				s.logger.Warn("failed to list clusters",
+					zap.String("deployer", deployerName),
+					zap.Error(err))
+				return
+			}
+
+			lock.Lock()
+			for _, cluster := range deployerClusters {
+				clusters = append(clusters, &deployerCluster{
+					DeployerName: deployerName,
+					Deployer:     deployer,
+					Cluster:      cluster,
+				})
+			}
+			lock.Unlock()
+		}(deployerName, deployer)
+	}
+	wg.Wait()
+
+	return clusters
+}
+
+// identifyCluster finds a cluster by its id or a unique prefix of it.
+func (s *Server) identifyCluster(ctx context.Context, userInput string) (*deployerCluster, error) {
+	var matches []*deployerCluster
+	for _, cluster := range s.listClusters(ctx) {
+		if cluster.Cluster.GetID() == userInput {
+			return cluster, nil
+		}
+		if strings.HasPrefix(cluster.Cluster.GetID(), userInput) {
+			matches = append(matches, cluster)
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil, notFound(fmt.Errorf("failed to identify cluster `%s`", userInput))
+	}
+	if len(matches) > 1 {
+		return nil, badRequest(fmt.Errorf("cluster identifier `%s` is ambiguous", userInput))
+	}
+
+	return matches[0], nil
+}
+
+// identifyNode finds a node by its id, resource id or ip address, falling back
+// to a prefix of its id or resource id, matching the CLI.
+func identifyNode(cluster deployment.ClusterInfo, userInput string) (deployment.ClusterNodeInfo, error) {
+	if userInput == "" {
+		return nil, badRequest(errors.New("node identifiers must not be empty"))
+	}
+
+	nodes := cluster.GetNodes()
+
+	matchers := []func(node deployment.ClusterNodeInfo) bool{
+		func(node deployment.ClusterNodeInfo) bool { return node.GetID() == userInput },
+		func(node deployment.ClusterNodeInfo) bool { return node.GetResourceID() == userInput },
+		func(node deployment.ClusterNodeInfo) bool { return node.GetIPAddress() == userInput },
+		func(node deployment.ClusterNodeInfo) bool { return strings.HasPrefix(node.GetID(), userInput) },
+		func(node deployment.ClusterNodeInfo) bool { return strings.HasPrefix(node.GetResourceID(), userInput) },
+	}
+	for _, matcher := range matchers {
+		for _, node := range nodes {
+			if matcher(node) {
+				return node, nil
+			}
+		}
+	}
+
+	return nil, badRequest(fmt.Errorf("failed to identify node `%s`", userInput))
+}
+
+func identifyNodeIDs(cluster deployment.ClusterInfo, userInputs []string) ([]string, error) {
+	var nodeIDs []string
+	for _, userInput := range userInputs {
+		node, err := identifyNode(cluster, userInput)
+		if err != nil {
+			return nil, err
+		}
+
+		nodeIDs = append(nodeIDs, node.GetID())
+	}
+	return nodeIDs, nil
+}
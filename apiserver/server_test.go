@@ -0,0 +1,200 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type testNode struct {
+	id string
+	ip string
+}
+
+func (n *testNode) GetID() string         { return n.id }
+func (n *testNode) IsClusterNode() bool   { return true }
+func (n *testNode) GetResourceID() string { return n.id }
+func (n *testNode) GetName() string       { return n.id }
+func (n *testNode) GetIPAddress() string  { return n.ip }
+
+type testCluster struct {
+	id    string
+	nodes []deployment.ClusterNodeInfo
+}
+
+func (c *testCluster) GetID() string                          { return c.id }
+func (c *testCluster) GetType() deployment.ClusterType        { return deployment.ClusterTypeServer }
+func (c *testCluster) GetPurpose() string                     { return "" }
+func (c *testCluster) GetExpiry() time.Time                   { return time.Time{} }
+func (c *testCluster) GetState() string                       { return "ready" }
+func (c *testCluster) GetNodes() []deployment.ClusterNodeInfo { return c.nodes }
+
+// testDeployer implements the deployer methods used by the tests, any other
+// deployer method panics since the embedded interface is nil.
+type testDeployer struct {
+	deployment.Deployer
+
+	lock     sync.Mutex
+	clusters []deployment.ClusterInfo
+	paused   []string
+}
+
+func (d *testDeployer) ListClusters(ctx context.Context) ([]deployment.ClusterInfo, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.clusters, nil
+}
+
+func (d *testDeployer) NewCluster(ctx context.Context, def *clusterdef.Cluster) (deployment.ClusterInfo, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	cluster := &testCluster{id: "new-cluster"}
+	d.clusters = append(d.clusters, cluster)
+	return cluster, nil
+}
+
+func (d *testDeployer) PauseNode(ctx context.Context, clusterID string, nodeIDs []string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.paused = append(d.paused, nodeIDs...)
+	return nil
+}
+
+func (d *testDeployer) ListUsers(ctx context.Context, clusterID string) ([]deployment.UserInfo, error) {
+	return nil, deployment.NewNotSupportedError("testdeploy does not support users")
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, *testDeployer) {
+	deployer := &testDeployer{
+		clusters: []deployment.ClusterInfo{
+			&testCluster{
+				id: "abc123",
+				nodes: []deployment.ClusterNodeInfo{
+					&testNode{id: "node-a", ip: "10.0.0.1"},
+					&testNode{id: "node-b", ip: "10.0.0.2"},
+				},
+			},
+		},
+	}
+
+	server, err := NewServer(&ServerOptions{
+		Logger:          zap.NewNop(),
+		Deployers:       map[string]deployment.Deployer{"test": deployer},
+		DefaultDeployer: "test",
+	})
+	require.NoError(t, err)
+
+	httpServer := httptest.NewServer(server.Handler())
+	t.Cleanup(func() {
+		httpServer.Close()
+		server.Close()
+	})
+
+	return httpServer, deployer
+}
+
+func doRequest(t *testing.T, method, url string, body string, out any) int {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	if out != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+
+	return resp.StatusCode
+}
+
+func TestServerClusters(t *testing.T) {
+	httpServer, _ := newTestServer(t)
+
+	var clusters []ClusterOutput
+	status := doRequest(t, "GET", httpServer.URL+"/v1/clusters", "", &clusters)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, clusters, 1)
+	require.Equal(t, "abc123", clusters[0].ID)
+	require.Equal(t, "test", clusters[0].Deployer)
+	require.Len(t, clusters[0].Nodes, 2)
+
+	var cluster ClusterOutput
+	status = doRequest(t, "GET", httpServer.URL+"/v1/clusters/abc", "", &cluster)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "abc123", cluster.ID)
+
+	var errOut ErrorOutput
+	status = doRequest(t, "GET", httpServer.URL+"/v1/clusters/xyz", "", &errOut)
+	require.Equal(t, http.StatusNotFound, status)
+	require.Contains(t, errOut.Error, "xyz")
+}
+
+func TestServerErrors(t *testing.T) {
+	httpServer, _ := newTestServer(t)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"not supported", "GET", "/v1/clusters/abc123/users", "", http.StatusNotImplemented},
+		{"unknown field", "POST", "/v1/clusters/abc123/chaos/pause-node", `{"nodez":["node-a"]}`, http.StatusBadRequest},
+		{"unknown node", "POST", "/v1/clusters/abc123/chaos/pause-node", `{"nodes":["node-z"]}`, http.StatusBadRequest},
+		{"bad traffic type", "POST", "/v1/clusters/abc123/chaos/block-traffic", `{"nodes":["node-a"],"from":"x"}`, http.StatusBadRequest},
+		{"missing def", "POST", "/v1/clusters", `{}`, http.StatusBadRequest},
+		{"invalid index", "POST", "/v1/clusters", `{"def":"nodes: [{count: 1, version: 7.6.2}]\nbuckets: {default: {indexes: [{name: idx}]}}"}`, http.StatusBadRequest},
+		{"pool on non-docker deployer", "POST", "/v1/clusters", `{"tag":"single:7.6.2","from_pool":true}`, http.StatusBadRequest},
+		{"return non-docker cluster", "POST", "/v1/clusters/abc123/return-to-pool", `{}`, http.StatusNotImplemented},
+		{"unknown job", "GET", "/v1/jobs/missing", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errOut ErrorOutput
+			status := doRequest(t, tt.method, httpServer.URL+tt.path, tt.body, &errOut)
+			require.Equal(t, tt.wantStatus, status)
+			require.NotEmpty(t, errOut.Error)
+		})
+	}
+}
+
+func TestServerChaos(t *testing.T) {
+	httpServer, deployer := newTestServer(t)
+
+	status := doRequest(t, "POST", httpServer.URL+"/v1/clusters/abc123/chaos/pause-node",
+		`{"nodes":["node-b","10.0.0.1"]}`, nil)
+	require.Equal(t, http.StatusNoContent, status)
+	require.Equal(t, []string{"node-b", "node-a"}, deployer.paused)
+}
+
+func TestServerAllocateJob(t *testing.T) {
+	httpServer, _ := newTestServer(t)
+
+	var job Job
+	status := doRequest(t, "POST", httpServer.URL+"/v1/clusters", `{"tag":"single:7.6.2"}`, &job)
+	require.Equal(t, http.StatusAccepted, status)
+	require.Equal(t, "allocateCluster", job.Operation)
+
+	require.Eventually(t, func() bool {
+		status := doRequest(t, "GET", httpServer.URL+"/v1/jobs/"+job.ID, "", &job)
+		require.Equal(t, http.StatusOK, status)
+		return job.State != JobStateRunning
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.Equal(t, JobStateSucceeded, job.State)
+	require.Equal(t, "new-cluster", job.Result.(map[string]any)["id"])
+}
@@ -132,6 +132,10 @@ type Config_Capella struct {
 type Config_DNS struct {
 	Enabled  StringBool `yaml:"enabled"`
 	Hostname string     `yaml:"hostname"`
+
+	// Provider is either aws, which uses a Route53 hosted zone, or local,
+	// which runs a dns server on the docker network.  Defaults to aws.
+	Provider string `yaml:"provider"`
 }
 
 // EnvConfigPath is the environment variable that, when set, overrides the
@@ -179,6 +183,18 @@ func ConfigPath() (string, error) {
 	return DefaultConfigPath()
 }
 
+// SnapshotsPath returns the directory that cluster snapshots are stored in.
+// It sits alongside the config file so that independent configs also keep
+// independent snapshots.
+func SnapshotsPath() (string, error) {
+	configPath, err := ConfigPath()
+	if err != nil {
+		return "", err
+	}
+
+	return configPath + "-snapshots", nil
+}
+
 func Upgrade(config *Config) *Config {
 	if config.Version < 2 {
 		config._DefaultCloud = "aws"
@@ -0,0 +1,369 @@
+package chaosscenario
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type TimelineEventType string
+
+const (
+	TimelineEventApplied   TimelineEventType = "applied"
+	TimelineEventReverted  TimelineEventType = "reverted"
+	TimelineEventReapplied TimelineEventType = "reapplied"
+	TimelineEventFailed    TimelineEventType = "failed"
+)
+
+type TimelineEvent struct {
+	Time      time.Time         `json:"time"`
+	ElapsedMs int64             `json:"elapsed_ms"`
+	Step      int               `json:"step"`
+	Name      string            `json:"name"`
+	Action    Action            `json:"action"`
+	Event     TimelineEventType `json:"event"`
+	Nodes     []string          `json:"nodes,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+type Timeline struct {
+	ClusterID string           `json:"cluster_id"`
+	Seed      int64            `json:"seed"`
+	StartTime time.Time        `json:"start_time"`
+	EndTime   time.Time        `json:"end_time"`
+	Events    []*TimelineEvent `json:"events"`
+}
+
+type Runner struct {
+	Logger    *zap.Logger
+	Deployer  deployment.Deployer
+	ClusterID string
+	Nodes     []deployment.ClusterNodeInfo
+}
+
+// pendingRevert is an action that has been applied to the cluster and must
+// be undone, either once its duration passes or when the scenario ends.
+type pendingRevert struct {
+	StepIdx int
+	Step    *Step
+	NodeIDs []string
+	// DueAt is zero for actions which last until the end of the scenario.
+	DueAt time.Time
+}
+
+type runState struct {
+	Runner   *Runner
+	Timeline *Timeline
+	Reverts  []*pendingRevert
+}
+
+func (s *runState) record(stepIdx int, step *Step, event TimelineEventType, nodeIDs []string, err error) {
+	now := time.Now()
+	timelineEvent := &TimelineEvent{
+		Time:      now,
+		ElapsedMs: now.Sub(s.Timeline.StartTime).Milliseconds(),
+		Step:      stepIdx + 1,
+		Name:      step.DisplayName(),
+		Action:    step.Action,
+		Event:     event,
+		Nodes:     nodeIDs,
+	}
+	if err != nil {
+		timelineEvent.Error = err.Error()
+	}
+
+	s.Timeline.Events = append(s.Timeline.Events, timelineEvent)
+}
+
+// nextDueRevert returns the index of the timed revert that is due soonest.
+func (s *runState) nextDueRevert() int {
+	nextIdx := -1
+	for revertIdx, revert := range s.Reverts {
+		if revert.DueAt.IsZero() {
+			continue
+		}
+		if nextIdx < 0 || revert.DueAt.Before(s.Reverts[nextIdx].DueAt) {
+			nextIdx = revertIdx
+		}
+	}
+	return nextIdx
+}
+
+// runRevert reverts an action, applying the still active actions which it
+// overlaps again unless the scenario is being restored.
+func (s *runState) runRevert(ctx context.Context, revertIdx int, reapply bool) error {
+	revert := s.Reverts[revertIdx]
+	s.Reverts = slices.Delete(s.Reverts, revertIdx, revertIdx+1)
+
+	err := s.Runner.revertStep(ctx, revert.Step, revert.NodeIDs)
+	if err != nil {
+		s.record(revert.StepIdx, revert.Step, TimelineEventFailed, revert.NodeIDs, err)
+		return errors.Wrapf(err, "failed to revert step %d (%s)", revert.StepIdx+1, revert.Step.DisplayName())
+	}
+
+	s.record(revert.StepIdx, revert.Step, TimelineEventReverted, revert.NodeIDs, nil)
+
+	if !reapply {
+		return nil
+	}
+	return s.reapplyActive(ctx, revert)
+}
+
+// reapplyActive applies the still active steps again on the nodes of a step
+// which was just reverted.  Reverting clears all the chaos of its kind from
+// the nodes, rather than just that of the reverted step, so any other steps
+// affecting those nodes are lost otherwise.  Steps are reapplied in the order
+// they were originally applied, so later steps still take precedence.
+func (s *runState) reapplyActive(ctx context.Context, reverted *pendingRevert) error {
+	for _, active := range s.Reverts {
+		if revertKind(active.Step) != revertKind(reverted.Step) {
+			continue
+		}
+
+		overlapNodeIDs := overlappingNodes(active.NodeIDs, reverted.NodeIDs)
+		if len(overlapNodeIDs) == 0 {
+			continue
+		}
+
+		// traffic rules replace those already on a node, so are reapplied
+		// for the whole step as partitions depend on all of its nodes, but
+		// only the nodes which were unpaused are paused again.
+		nodeIDs := active.NodeIDs
+		if active.Step.Action == ActionPauseNode {
+			nodeIDs = overlapNodeIDs
+		}
+
+		err := s.Runner.applyStep(ctx, active.Step, nodeIDs)
+		if err != nil {
+			s.record(active.StepIdx, active.Step, TimelineEventFailed, nodeIDs, err)
+			return errors.Wrapf(err, "failed to reapply step %d (%s)", active.StepIdx+1, active.Step.DisplayName())
+		}
+
+		s.record(active.StepIdx, active.Step, TimelineEventReapplied, nodeIDs, nil)
+	}
+
+	return nil
+}
+
+// overlappingNodes returns the nodes of a which are also in b.
+func overlappingNodes(a, b []string) []string {
+	var overlap []string
+	for _, nodeID := range a {
+		if slices.Contains(b, nodeID) {
+			overlap = append(overlap, nodeID)
+		}
+	}
+	return overlap
+}
+
+// waitUntil waits until the specified time, running any reverts which fall
+// due in the meantime.
+func (s *runState) waitUntil(ctx context.Context, until time.Time) error {
+	for {
+		waitUntil := until
+		revertIdx := s.nextDueRevert()
+		if revertIdx >= 0 && s.Reverts[revertIdx].DueAt.Before(waitUntil) {
+			waitUntil = s.Reverts[revertIdx].DueAt
+		} else {
+			revertIdx = -1
+		}
+
+		select {
+		case <-time.After(time.Until(waitUntil)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		if revertIdx < 0 {
+			return nil
+		}
+
+		err := s.runRevert(ctx, revertIdx, true)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Run executes the scenario against the cluster.  Whatever happens, including
+// the context being cancelled, every action which was applied is reverted
+// before Run returns.  The timeline is returned even when an error occurs.
+func (r *Runner) Run(ctx context.Context, scenario *Scenario) (*Timeline, error) {
+	seed := scenario.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	state := &runState{
+		Runner: r,
+		Timeline: &Timeline{
+			ClusterID: r.ClusterID,
+			Seed:      seed,
+			StartTime: time.Now(),
+		},
+	}
+
+	runErr := r.runSteps(ctx, state, scenario, rng)
+
+	// restore the cluster regardless of how the steps finished, without the
+	// callers context since it may be what caused us to stop.  Actions are
+	// reverted in the reverse of the order they were applied.
+	restoreCtx := context.WithoutCancel(ctx)
+	for len(state.Reverts) > 0 {
+		err := state.runRevert(restoreCtx, len(state.Reverts)-1, false)
+		if err != nil {
+			r.Logger.Warn("failed to restore cluster", zap.Error(err))
+			if runErr == nil {
+				runErr = err
+			}
+		}
+	}
+
+	state.Timeline.EndTime = time.Now()
+	return state.Timeline, runErr
+}
+
+func (r *Runner) runSteps(ctx context.Context, state *runState, scenario *Scenario, rng *rand.Rand) error {
+	for stepIdx, step := range scenario.Steps {
+		err := state.waitUntil(ctx, state.Timeline.StartTime.Add(step.At))
+		if err != nil {
+			return err
+		}
+
+		candidates, foundOrchestrator, err := InspectNodes(ctx, r.Logger, r.Nodes, step.Nodes.needsInspection())
+		if err != nil {
+			state.record(stepIdx, step, TimelineEventFailed, nil, err)
+			return errors.Wrapf(err, "failed to inspect nodes for step %d (%s)", stepIdx+1, step.DisplayName())
+		}
+
+		nodes, err := step.Nodes.Select(r.Logger, candidates, foundOrchestrator, rng)
+		if err != nil {
+			state.record(stepIdx, step, TimelineEventFailed, nil, err)
+			return errors.Wrapf(err, "failed to select nodes for step %d (%s)", stepIdx+1, step.DisplayName())
+		}
+
+		var nodeIDs []string
+		for _, node := range nodes {
+			nodeIDs = append(nodeIDs, node.ID)
+		}
+
+		r.Logger.Info("running chaos step",
+			zap.Int("step", stepIdx+1),
+			zap.String("name", step.DisplayName()),
+			zap.Strings("nodes", nodeIDs))
+
+		err = r.applyStep(ctx, step, nodeIDs)
+		if err != nil {
+			state.record(stepIdx, step, TimelineEventFailed, nodeIDs, err)
+			return errors.Wrapf(err, "failed to run step %d (%s)", stepIdx+1, step.DisplayName())
+		}
+
+		state.record(stepIdx, step, TimelineEventApplied, nodeIDs, nil)
+
+		if stepNeedsRevert(step) {
+			revert := &pendingRevert{
+				StepIdx: stepIdx,
+				Step:    step,
+				NodeIDs: nodeIDs,
+			}
+			if step.For > 0 {
+				revert.DueAt = time.Now().Add(step.For)
+			}
+
+			state.Reverts = append(state.Reverts, revert)
+		}
+	}
+
+	// wait for the timed actions to finish before the remaining actions are
+	// reverted as part of the restore.
+	for {
+		revertIdx := state.nextDueRevert()
+		if revertIdx < 0 {
+			break
+		}
+
+		err := state.waitUntil(ctx, state.Reverts[revertIdx].DueAt.Add(time.Millisecond))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func stepNeedsRevert(step *Step) bool {
+	return revertKind(step) != ""
+}
+
+// revertKind groups the actions which are reverted together, since a revert
+// clears everything of its kind from the nodes.
+func revertKind(step *Step) string {
+	switch step.Action {
+	case ActionBlockTraffic, ActionPartitionTraffic, ActionDegradeTraffic:
+		return "traffic"
+	case ActionPauseNode:
+		return "pause"
+	}
+	return ""
+}
+
+func (r *Runner) applyStep(ctx context.Context, step *Step, nodeIDs []string) error {
+	switch step.Action {
+	case ActionBlockTraffic:
+		trafficType := deployment.BlockNodeTrafficType(step.From)
+		if trafficType == "" {
+			trafficType = deployment.BlockNodeTrafficNodes
+		}
+
+		return r.Deployer.BlockNodeTraffic(ctx, r.ClusterID, nodeIDs, trafficType, step.RejectWith)
+	case ActionPartitionTraffic:
+		return r.Deployer.PartitionNodeTraffic(ctx, r.ClusterID, nodeIDs, step.RejectWith)
+	case ActionDegradeTraffic:
+		trafficType := deployment.BlockNodeTrafficType(step.Traffic)
+		if trafficType == "" {
+			trafficType = deployment.BlockNodeTrafficAll
+		}
+
+		direction := deployment.DegradeNodeTrafficDirection(step.Direction)
+		if direction == "" {
+			direction = deployment.DegradeNodeTrafficBoth
+		}
+
+		return r.Deployer.DegradeNodeTraffic(ctx, r.ClusterID, nodeIDs, &deployment.DegradeNodeTrafficOptions{
+			TrafficType: trafficType,
+			Direction:   direction,
+			Latency:     step.Latency,
+			Jitter:      step.Jitter,
+			LossPercent: step.Loss,
+			Rate:        step.Rate,
+		})
+	case ActionAllowTraffic:
+		return r.Deployer.AllowNodeTraffic(ctx, r.ClusterID, nodeIDs)
+	case ActionPauseNode:
+		return r.Deployer.PauseNode(ctx, r.ClusterID, nodeIDs)
+	case ActionUnpauseNode:
+		return r.Deployer.UnpauseNode(ctx, r.ClusterID, nodeIDs)
+	case ActionKillCouchbase:
+		return r.Deployer.KillCouchbase(ctx, r.ClusterID, nodeIDs)
+	}
+
+	return fmt.Errorf("unsupported action `%s`", step.Action)
+}
+
+func (r *Runner) revertStep(ctx context.Context, step *Step, nodeIDs []string) error {
+	switch step.Action {
+	case ActionBlockTraffic, ActionPartitionTraffic, ActionDegradeTraffic:
+		return r.Deployer.AllowNodeTraffic(ctx, r.ClusterID, nodeIDs)
+	case ActionPauseNode:
+		return r.Deployer.UnpauseNode(ctx, r.ClusterID, nodeIDs)
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package chaosscenario
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type testNode struct {
+	id string
+}
+
+func (n *testNode) GetID() string         { return n.id }
+func (n *testNode) IsClusterNode() bool   { return true }
+func (n *testNode) GetResourceID() string { return n.id }
+func (n *testNode) GetName() string       { return n.id }
+func (n *testNode) GetIPAddress() string  { return "" }
+
+// testDeployer records the chaos calls made against it, any other deployer
+// method panics since the embedded interface is nil.
+type testDeployer struct {
+	deployment.Deployer
+
+	lock  sync.Mutex
+	calls []string
+}
+
+func (d *testDeployer) record(call string, nodeIDs []string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.calls = append(d.calls, fmt.Sprintf("%s %v", call, nodeIDs))
+	return nil
+}
+
+func (d *testDeployer) BlockNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string, trafficType deployment.BlockNodeTrafficType, rejectType string) error {
+	return d.record("block", nodeIDs)
+}
+
+func (d *testDeployer) AllowNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string) error {
+	return d.record("allow", nodeIDs)
+}
+
+func (d *testDeployer) PartitionNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string, rejectType string) error {
+	return d.record("partition", nodeIDs)
+}
+
+func (d *testDeployer) DegradeNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string, opts *deployment.DegradeNodeTrafficOptions) error {
+	return d.record("degrade", nodeIDs)
+}
+
+func (d *testDeployer) PauseNode(ctx context.Context, clusterID string, nodeIDs []string) error {
+	return d.record("pause", nodeIDs)
+}
+
+func (d *testDeployer) UnpauseNode(ctx context.Context, clusterID string, nodeIDs []string) error {
+	return d.record("unpause", nodeIDs)
+}
+
+func (d *testDeployer) KillCouchbase(ctx context.Context, clusterID string, nodeIDs []string) error {
+	return d.record("kill", nodeIDs)
+}
+
+func newTestRunner() (*Runner, *testDeployer) {
+	deployer := &testDeployer{}
+	return &Runner{
+		Logger:    zap.NewNop(),
+		Deployer:  deployer,
+		ClusterID: "cluster",
+		Nodes: []deployment.ClusterNodeInfo{
+			&testNode{id: "a"},
+			&testNode{id: "b"},
+		},
+	}, deployer
+}
+
+func TestRunnerRevertsActions(t *testing.T) {
+	runner, deployer := newTestRunner()
+
+	timeline, err := runner.Run(context.Background(), &Scenario{
+		Steps: []*Step{
+			{Action: ActionBlockTraffic, Nodes: NodeSelector{IDs: []string{"a"}}, For: 20 * time.Millisecond},
+			{Action: ActionPauseNode, Nodes: NodeSelector{IDs: []string{"b"}}},
+			{At: 50 * time.Millisecond, Action: ActionKillCouchbase, Nodes: NodeSelector{Pick: NodePickAll}},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"block [a]",
+		"pause [b]",
+		"allow [a]",
+		"kill [a b]",
+		"unpause [b]",
+	}, deployer.calls)
+
+	var events []TimelineEventType
+	for _, event := range timeline.Events {
+		events = append(events, event.Event)
+	}
+	require.Equal(t, []TimelineEventType{
+		TimelineEventApplied,
+		TimelineEventApplied,
+		TimelineEventReverted,
+		TimelineEventApplied,
+		TimelineEventReverted,
+	}, events)
+	require.GreaterOrEqual(t, timeline.Events[3].ElapsedMs, int64(50))
+}
+
+func TestRunnerRestoresOnCancel(t *testing.T) {
+	runner, deployer := newTestRunner()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := runner.Run(ctx, &Scenario{
+		Steps: []*Step{
+			{Action: ActionBlockTraffic, Nodes: NodeSelector{IDs: []string{"a"}}, For: time.Hour},
+			{Action: ActionPauseNode, Nodes: NodeSelector{IDs: []string{"b"}}},
+		},
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.Equal(t, []string{
+		"block [a]",
+		"pause [b]",
+		"unpause [b]",
+		"allow [a]",
+	}, deployer.calls)
+}
+
+func TestRunnerReappliesOverlappingActions(t *testing.T) {
+	runner, deployer := newTestRunner()
+
+	timeline, err := runner.Run(context.Background(), &Scenario{
+		Steps: []*Step{
+			{Action: ActionDegradeTraffic, Nodes: NodeSelector{Pick: NodePickAll}, Latency: time.Millisecond},
+			{Action: ActionBlockTraffic, Nodes: NodeSelector{IDs: []string{"a"}}, For: 20 * time.Millisecond},
+			{Action: ActionPauseNode, Nodes: NodeSelector{Pick: NodePickAll}},
+			{Action: ActionPauseNode, Nodes: NodeSelector{IDs: []string{"b"}}, For: 20 * time.Millisecond},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"degrade [a b]",
+		"block [a]",
+		"pause [a b]",
+		"pause [b]",
+		// the block is reverted, which also clears the degrade from a
+		"allow [a]",
+		"degrade [a b]",
+		// the second pause is reverted, but b is still paused by the first
+		"unpause [b]",
+		"pause [b]",
+		// reverts while restoring the cluster clear everything anyway
+		"unpause [a b]",
+		"allow [a b]",
+	}, deployer.calls)
+
+	var events []TimelineEventType
+	for _, event := range timeline.Events {
+		events = append(events, event.Event)
+	}
+	require.Equal(t, []TimelineEventType{
+		TimelineEventApplied,
+		TimelineEventApplied,
+		TimelineEventApplied,
+		TimelineEventApplied,
+		TimelineEventReverted,
+		TimelineEventReapplied,
+		TimelineEventReverted,
+		TimelineEventReapplied,
+		TimelineEventReverted,
+		TimelineEventReverted,
+	}, events)
+}
@@ -0,0 +1,129 @@
+package chaosscenario
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+type Action string
+
+const (
+	ActionBlockTraffic     Action = "block-traffic"
+	ActionPartitionTraffic Action = "partition-traffic"
+	ActionDegradeTraffic   Action = "degrade-traffic"
+	ActionAllowTraffic     Action = "allow-traffic"
+	ActionPauseNode        Action = "pause-node"
+	ActionUnpauseNode      Action = "unpause-node"
+	ActionKillCouchbase    Action = "kill-couchbase"
+)
+
+type Scenario struct {
+	// Seed seeds the random selection of nodes, allowing a scenario to be
+	// repeated exactly.  When unset, a random seed is used.
+	Seed int64 `yaml:"seed,omitempty"`
+
+	Steps []*Step `yaml:"steps"`
+}
+
+type Step struct {
+	Name string `yaml:"name,omitempty"`
+
+	// At is the time after the start of the scenario at which this step runs.
+	// Steps always run in the order they are listed, so a step whose time has
+	// already passed runs immediately after the step before it.
+	At time.Duration `yaml:"at,omitempty"`
+
+	Action Action       `yaml:"action"`
+	Nodes  NodeSelector `yaml:"nodes,omitempty"`
+
+	// For is how long the action lasts before it is reverted.  When unset, the
+	// action lasts until the end of the scenario.
+	For time.Duration `yaml:"for,omitempty"`
+
+	// From and RejectWith apply to block-traffic and partition-traffic and
+	// match the flags of the equivalent chaos commands.
+	From       string `yaml:"from,omitempty"`
+	RejectWith string `yaml:"reject-with,omitempty"`
+
+	// These apply to degrade-traffic and match the flags of `chaos degrade`.
+	Latency   time.Duration `yaml:"latency,omitempty"`
+	Jitter    time.Duration `yaml:"jitter,omitempty"`
+	Loss      float64       `yaml:"loss,omitempty"`
+	Rate      string        `yaml:"rate,omitempty"`
+	Traffic   string        `yaml:"traffic,omitempty"`
+	Direction string        `yaml:"direction,omitempty"`
+}
+
+// DisplayName returns the name of the step for logging.
+func (s *Step) DisplayName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return string(s.Action)
+}
+
+func (s *Step) validate() error {
+	switch s.Action {
+	case ActionBlockTraffic:
+		switch s.From {
+		case "", "nodes", "clients", "all":
+		default:
+			return fmt.Errorf("invalid traffic type `%s`", s.From)
+		}
+	case ActionDegradeTraffic:
+		switch s.Traffic {
+		case "", "nodes", "clients", "all":
+		default:
+			return fmt.Errorf("invalid traffic type `%s`", s.Traffic)
+		}
+		switch s.Direction {
+		case "", "ingress", "egress", "both":
+		default:
+			return fmt.Errorf("invalid traffic direction `%s`", s.Direction)
+		}
+	case ActionPartitionTraffic, ActionPauseNode:
+	case ActionAllowTraffic, ActionUnpauseNode, ActionKillCouchbase:
+		if s.For != 0 {
+			return fmt.Errorf("%s cannot be given a duration", s.Action)
+		}
+	case "":
+		return errors.New("an action must be specified")
+	default:
+		return fmt.Errorf("unsupported action `%s`", s.Action)
+	}
+
+	if s.At < 0 || s.For < 0 {
+		return errors.New("step times must not be negative")
+	}
+
+	err := s.Nodes.validate()
+	if err != nil {
+		return errors.Wrap(err, "invalid node selector")
+	}
+
+	return nil
+}
+
+func Parse(data []byte) (*Scenario, error) {
+	var scenario *Scenario
+	err := yaml.Unmarshal(data, &scenario)
+	if err != nil {
+		return nil, errors.Wrap(err, "yaml parsing failed")
+	}
+
+	if scenario == nil || len(scenario.Steps) == 0 {
+		return nil, errors.New("scenario has no steps")
+	}
+
+	for stepIdx, step := range scenario.Steps {
+		err := step.validate()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid step %d (%s)", stepIdx+1, step.DisplayName())
+		}
+	}
+
+	return scenario, nil
+}
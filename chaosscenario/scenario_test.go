@@ -0,0 +1,57 @@
+package chaosscenario
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	scenario, err := Parse([]byte(`
+seed: 42
+steps:
+  - at: 10s
+    action: partition-traffic
+    nodes:
+      role: orchestrator
+    for: 30s
+  - name: kill-data
+    action: kill-couchbase
+    nodes:
+      service: kv
+      pick: random
+`))
+	require.NoError(t, err)
+	require.Equal(t, int64(42), scenario.Seed)
+	require.Len(t, scenario.Steps, 2)
+	require.Equal(t, 10*time.Second, scenario.Steps[0].At)
+	require.Equal(t, 30*time.Second, scenario.Steps[0].For)
+	require.Equal(t, NodeRoleOrchestrator, scenario.Steps[0].Nodes.Role)
+	require.Equal(t, "partition-traffic", scenario.Steps[0].DisplayName())
+	require.Equal(t, "kill-data", scenario.Steps[1].DisplayName())
+	require.Equal(t, NodePickRandom, scenario.Steps[1].Nodes.Pick)
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{name: "no steps", yaml: `steps: []`},
+		{name: "missing action", yaml: "steps:\n  - at: 1s"},
+		{name: "unknown action", yaml: "steps:\n  - action: explode"},
+		{name: "kill with duration", yaml: "steps:\n  - action: kill-couchbase\n    for: 10s"},
+		{name: "invalid traffic type", yaml: "steps:\n  - action: block-traffic\n    from: everyone"},
+		{name: "invalid direction", yaml: "steps:\n  - action: degrade-traffic\n    direction: sideways"},
+		{name: "invalid role", yaml: "steps:\n  - action: pause-node\n    nodes:\n      role: leader"},
+		{name: "ids with filters", yaml: "steps:\n  - action: pause-node\n    nodes:\n      ids: [a]\n      service: kv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml))
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package chaosscenario
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/utils/clustercontrol"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type NodeRole string
+
+const (
+	NodeRoleAny             NodeRole = ""
+	NodeRoleOrchestrator    NodeRole = "orchestrator"
+	NodeRoleNonOrchestrator NodeRole = "non-orchestrator"
+)
+
+type NodePick string
+
+const (
+	NodePickFirst  NodePick = "first"
+	NodePickRandom NodePick = "random"
+	NodePickAll    NodePick = "all"
+)
+
+// NodeSelector identifies the cluster nodes a step applies to.  Only
+// cluster nodes are ever selected, never utility nodes like load balancers.
+type NodeSelector struct {
+	// IDs selects specific nodes by their id or ip address, and cannot be
+	// combined with the other options.
+	IDs []string `yaml:"ids,omitempty"`
+
+	Role    NodeRole `yaml:"role,omitempty"`
+	Service string   `yaml:"service,omitempty"`
+
+	// Pick chooses between the nodes which match, in order of their id.
+	// Defaults to picking the first matching node.
+	Pick NodePick `yaml:"pick,omitempty"`
+
+	// Count is the number of nodes to pick, and defaults to 1.  It is not
+	// used when picking all nodes.
+	Count int `yaml:"count,omitempty"`
+}
+
+func (s *NodeSelector) validate() error {
+	if len(s.IDs) > 0 && (s.Role != NodeRoleAny || s.Service != "" || s.Pick != "" || s.Count != 0) {
+		return errors.New("ids cannot be combined with other options")
+	}
+
+	switch s.Role {
+	case NodeRoleAny, NodeRoleOrchestrator, NodeRoleNonOrchestrator:
+	default:
+		return fmt.Errorf("invalid role `%s`", s.Role)
+	}
+
+	switch s.Pick {
+	case "", NodePickFirst, NodePickRandom, NodePickAll:
+	default:
+		return fmt.Errorf("invalid pick `%s`", s.Pick)
+	}
+
+	if s.Count < 0 {
+		return errors.New("count must not be negative")
+	}
+
+	return nil
+}
+
+func (s *NodeSelector) needsInspection() bool {
+	return s.Role != NodeRoleAny || s.Service != ""
+}
+
+// selectorService converts the service of a selector into its cluster
+// definition form, accepting the operator names such as `data` as well.
+func selectorService(service string) clusterdef.Service {
+	caoService, err := clusterdef.CaoServiceToService(service)
+	if err == nil {
+		return caoService
+	}
+	return clusterdef.Service(service)
+}
+
+type CandidateNode struct {
+	ID             string
+	IPAddress      string
+	Services       []clusterdef.Service
+	IsOrchestrator bool
+}
+
+// InspectNodes returns the cluster nodes of a cluster, sorted by id.  When
+// inspect is set, each node is queried for its services and the orchestrator
+// is identified.  The returned bool indicates whether an orchestrator was
+// identified.
+func InspectNodes(
+	ctx context.Context,
+	logger *zap.Logger,
+	nodes []deployment.ClusterNodeInfo,
+	inspect bool,
+) ([]*CandidateNode, bool, error) {
+	var candidates []*CandidateNode
+	for _, node := range nodes {
+		if !node.IsClusterNode() {
+			continue
+		}
+
+		candidates = append(candidates, &CandidateNode{
+			ID:        node.GetID(),
+			IPAddress: node.GetIPAddress(),
+		})
+	}
+
+	if len(candidates) == 0 {
+		return nil, false, errors.New("no cluster nodes found")
+	}
+
+	// sort for deterministic selection
+	slices.SortFunc(candidates, func(a, b *CandidateNode) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	if !inspect {
+		return candidates, false, nil
+	}
+
+	// query ns_server to find the orchestrator, from whichever node answers
+	// first since some of the nodes may be down due to earlier steps.
+	var terseInfo *clustercontrol.TerseClusterInfo
+	var terseErr error
+	for _, candidate := range candidates {
+		ctrl := &clustercontrol.Controller{
+			Logger:   logger,
+			Endpoint: fmt.Sprintf("http://%s:8091", candidate.IPAddress),
+		}
+
+		terseInfo, terseErr = ctrl.GetTerseClusterInfo(ctx)
+		if terseErr == nil {
+			break
+		}
+
+		logger.Debug("failed to get terse cluster info from node, trying next node",
+			zap.String("node", candidate.ID),
+			zap.Error(terseErr))
+	}
+	if terseErr != nil {
+		return nil, false, errors.Wrap(terseErr, "failed to get terse cluster info from any node")
+	}
+
+	foundOrchestrator := false
+	for _, candidate := range candidates {
+		nodeCtrl := &clustercontrol.Controller{
+			Logger:   logger,
+			Endpoint: fmt.Sprintf("http://%s:8091", candidate.IPAddress),
+		}
+
+		localInfo, err := nodeCtrl.GetLocalInfo(ctx)
+		if err != nil {
+			logger.Warn("failed to get local info for node, skipping",
+				zap.String("node", candidate.ID),
+				zap.Error(err))
+			continue
+		}
+
+		services, err := clusterdef.NsServicesToServices(localInfo.Services)
+		if err != nil {
+			logger.Warn("failed to parse services of node, skipping",
+				zap.String("node", candidate.ID),
+				zap.Error(err))
+			continue
+		}
+
+		candidate.Services = services
+		if localInfo.OTPNode == terseInfo.Orchestrator {
+			candidate.IsOrchestrator = true
+			foundOrchestrator = true
+		}
+	}
+
+	return candidates, foundOrchestrator, nil
+}
+
+// Select picks the nodes matching the selector from a list of candidates
+// returned by InspectNodes.
+func (s *NodeSelector) Select(
+	logger *zap.Logger,
+	candidates []*CandidateNode,
+	foundOrchestrator bool,
+	rng *rand.Rand,
+) ([]*CandidateNode, error) {
+	if len(s.IDs) > 0 {
+		var selected []*CandidateNode
+		for _, nodeID := range s.IDs {
+			nodeIdx := slices.IndexFunc(candidates, func(node *CandidateNode) bool {
+				return node.ID == nodeID || node.IPAddress == nodeID
+			})
+			if nodeIdx < 0 {
+				return nil, fmt.Errorf("failed to find node `%s`", nodeID)
+			}
+
+			selected = append(selected, candidates[nodeIdx])
+		}
+		return selected, nil
+	}
+
+	var matching []*CandidateNode
+	for _, node := range candidates {
+		switch s.Role {
+		case NodeRoleOrchestrator:
+			if !node.IsOrchestrator {
+				continue
+			}
+		case NodeRoleNonOrchestrator:
+			// if the orchestrator is unknown, we select from all nodes
+			if foundOrchestrator && node.IsOrchestrator {
+				continue
+			}
+		}
+
+		if s.Service != "" && !slices.Contains(node.Services, selectorService(s.Service)) {
+			continue
+		}
+
+		matching = append(matching, node)
+	}
+
+	if s.Role == NodeRoleOrchestrator && !foundOrchestrator {
+		return nil, errors.New("could not identify orchestrator node")
+	}
+	if s.Role == NodeRoleNonOrchestrator && !foundOrchestrator {
+		logger.Warn("could not identify orchestrator node, selecting from all nodes")
+	}
+
+	if len(matching) == 0 {
+		return nil, errors.New("no nodes matched the selector")
+	}
+
+	if s.Pick == NodePickAll {
+		return matching, nil
+	}
+
+	count := s.Count
+	if count == 0 {
+		count = 1
+	}
+	if count > len(matching) {
+		return nil, fmt.Errorf("selector requires %d nodes but only %d matched", count, len(matching))
+	}
+
+	if s.Pick == NodePickRandom {
+		matching = slices.Clone(matching)
+		rng.Shuffle(len(matching), func(i, j int) {
+			matching[i], matching[j] = matching[j], matching[i]
+		})
+	}
+
+	return matching[:count], nil
+}
@@ -0,0 +1,73 @@
+package chaosscenario
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNodeSelectorSelect(t *testing.T) {
+	candidates := []*CandidateNode{
+		{ID: "a", IPAddress: "10.0.0.2", Services: []clusterdef.Service{clusterdef.KvService}, IsOrchestrator: true},
+		{ID: "b", IPAddress: "10.0.0.3", Services: []clusterdef.Service{clusterdef.KvService}},
+		{ID: "c", IPAddress: "10.0.0.4", Services: []clusterdef.Service{clusterdef.QueryService}},
+	}
+
+	nodeIDs := func(nodes []*CandidateNode) []string {
+		var out []string
+		for _, node := range nodes {
+			out = append(out, node.ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name              string
+		selector          NodeSelector
+		foundOrchestrator bool
+		want              []string
+		wantErr           bool
+	}{
+		{name: "default picks first", selector: NodeSelector{}, want: []string{"a"}},
+		{name: "ids and addresses", selector: NodeSelector{IDs: []string{"c", "10.0.0.3"}}, want: []string{"c", "b"}},
+		{name: "unknown id", selector: NodeSelector{IDs: []string{"z"}}, wantErr: true},
+		{name: "orchestrator", selector: NodeSelector{Role: NodeRoleOrchestrator}, foundOrchestrator: true, want: []string{"a"}},
+		{name: "unknown orchestrator", selector: NodeSelector{Role: NodeRoleOrchestrator}, wantErr: true},
+		{name: "non-orchestrator", selector: NodeSelector{Role: NodeRoleNonOrchestrator}, foundOrchestrator: true, want: []string{"b"}},
+		{name: "non-orchestrator falls back to all", selector: NodeSelector{Role: NodeRoleNonOrchestrator}, want: []string{"a"}},
+		{name: "service", selector: NodeSelector{Service: "n1ql", Pick: NodePickAll}, want: []string{"c"}},
+		{name: "operator service name", selector: NodeSelector{Service: "data", Pick: NodePickAll}, want: []string{"a", "b"}},
+		{name: "count", selector: NodeSelector{Count: 2}, want: []string{"a", "b"}},
+		{name: "count too large", selector: NodeSelector{Count: 4}, wantErr: true},
+		{name: "no matches", selector: NodeSelector{Service: "fts"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.selector.Select(zap.NewNop(), candidates, tt.foundOrchestrator, nil)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, nodeIDs(got))
+		})
+	}
+}
+
+func TestNodeSelectorSelectRandomIsSeeded(t *testing.T) {
+	candidates := []*CandidateNode{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+	selector := NodeSelector{Pick: NodePickRandom, Count: 2}
+
+	first, err := selector.Select(zap.NewNop(), candidates, false, rand.New(rand.NewSource(7)))
+	require.NoError(t, err)
+	second, err := selector.Select(zap.NewNop(), candidates, false, rand.New(rand.NewSource(7)))
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+
+	// the candidates themselves must not be reordered
+	require.Equal(t, "a", candidates[0].ID)
+}
@@ -11,6 +11,8 @@ type Cluster struct {
 	Columnar   bool              `yaml:"columnar,omitempty"`
 	NodeGroups []*NodeGroup      `yaml:"nodes,omitempty"`
 	Buckets    map[string]Bucket `yaml:"buckets,omitempty"`
+	Groups     []Group           `yaml:"groups,omitempty"`
+	Users      []User            `yaml:"users,omitempty"`
 
 	Docker DockerCluster `yaml:"docker,omitempty"`
 	Cao    CaoCluster    `yaml:"cao,omitempty"`
@@ -18,8 +20,35 @@ type Cluster struct {
 }
 
 type Bucket struct {
-	Settings Settings `yaml:"settings,omitempty"`
-	Scopes   Scopes   `yaml:",inline"`
+	Settings      Settings      `yaml:"settings,omitempty"`
+	Indexes       []Index       `yaml:"indexes,omitempty"`
+	SearchIndexes []SearchIndex `yaml:"search-indexes,omitempty"`
+	Scopes        Scopes        `yaml:",inline"`
+}
+
+// Index is a GSI index, created once the scopes and collections of its
+// bucket exist.  The scope and collection default to _default.
+type Index struct {
+	Name       string `yaml:"name"`
+	Scope      string `yaml:"scope,omitempty"`
+	Collection string `yaml:"collection,omitempty"`
+
+	Primary bool     `yaml:"primary,omitempty"`
+	Keys    []string `yaml:"keys,omitempty"`
+	Where   string   `yaml:"where,omitempty"`
+
+	NumReplicas   int      `yaml:"num-replicas,omitempty"`
+	PartitionBy   []string `yaml:"partition-by,omitempty"`
+	NumPartitions int      `yaml:"num-partitions,omitempty"`
+	Deferred      bool     `yaml:"deferred,omitempty"`
+}
+
+// SearchIndex is a search index of a bucket.  Definition is the JSON index
+// definition of the search service, the name and source of which are set
+// from the index.
+type SearchIndex struct {
+	Name       string `yaml:"name"`
+	Definition string `yaml:"definition"`
 }
 
 type Settings struct {
@@ -27,10 +56,41 @@ type Settings struct {
 	RamQuotaMB   int    `yaml:"ram-quota-mb,omitempty"`
 	FlushEnabled bool   `yaml:"flush-enabled,omitempty"`
 	NumReplicas  int    `yaml:"num-replicas,omitempty"`
+
+	StorageBackend     string        `yaml:"storage-backend,omitempty"`
+	EvictionPolicy     string        `yaml:"eviction-policy,omitempty"`
+	DurabilityMinLevel string        `yaml:"durability-min-level,omitempty"`
+	MaxTTL             time.Duration `yaml:"max-ttl,omitempty"`
+	CompressionMode    string        `yaml:"compression-mode,omitempty"`
+	ConflictResolution string        `yaml:"conflict-resolution,omitempty"`
+
+	HistoryRetentionBytes    uint64        `yaml:"history-retention-bytes,omitempty"`
+	HistoryRetentionDuration time.Duration `yaml:"history-retention-duration,omitempty"`
+
+	NumVBuckets int `yaml:"num-vbuckets,omitempty"`
 }
 
 type Scopes map[string]Collections
 
+type Group struct {
+	Name         string   `yaml:"name"`
+	Description  string   `yaml:"description,omitempty"`
+	Roles        []string `yaml:"roles,omitempty"`
+	LdapGroupRef string   `yaml:"ldap-group-ref,omitempty"`
+}
+
+// User is created once the cluster and its buckets have been created, so its
+// roles may refer to any of the buckets of the cluster.
+type User struct {
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password,omitempty"`
+	Roles    []string `yaml:"roles,omitempty"`
+	Groups   []string `yaml:"groups,omitempty"`
+
+	// External users are authenticated by LDAP and so have no password.
+	External bool `yaml:"external,omitempty"`
+}
+
 type Collections []string
 
 type DockerCluster struct {
@@ -50,10 +110,57 @@ type DockerCluster struct {
 	UseDinoCerts        bool              `yaml:"use-dino-certs,omitempty"`
 	EnableJwt           bool              `yaml:"jwt,omitempty"`
 
+	// LoadBalancer configures the active load balancer, and is ignored
+	// unless active-load-balancer is enabled.
+	LoadBalancer LoadBalancerSettings `yaml:"load-balancer-settings,omitempty"`
+
+	// DinoCerts configures the certificates generated when use-dino-certs
+	// is enabled.
+	DinoCerts DinoCertSettings `yaml:"dino-cert-settings,omitempty"`
+
+	// Persistent stores each node's data in a named docker volume so that
+	// the cluster can be stopped and started without losing data.
+	Persistent bool `yaml:"persistent,omitempty"`
+
 	// load-balancer is deprecated in favor of the specific load balancer settings
 	_EnableLoadBalancer bool `yaml:"load-balancer,omitempty"`
 }
 
+// DinoCertSettings control the private keys of generated certificates.
+// Load balancers always use an unencrypted key, since they cannot be given
+// the passphrase.
+type DinoCertSettings struct {
+	// KeyType is one of rsa-2048, rsa-4096, ecdsa-p256, ecdsa-p384 or
+	// ed25519, and defaults to rsa-4096.
+	KeyType string `yaml:"key-type,omitempty"`
+
+	// KeyFormat is one of pkcs1, sec1 or pkcs8, and defaults to the
+	// traditional format of the key type.
+	KeyFormat string `yaml:"key-format,omitempty"`
+
+	// Passphrase encrypts the node keys as encrypted PKCS#8.
+	Passphrase string `yaml:"passphrase,omitempty"`
+}
+
+// LoadBalancerSettings are the tunables of the active load balancer.  Any
+// unset value keeps its default, which broadly matches the defaults of an AWS
+// Network Load Balancer.
+type LoadBalancerSettings struct {
+	ConnectTimeout time.Duration `yaml:"connect-timeout,omitempty"`
+	ClientTimeout  time.Duration `yaml:"client-timeout,omitempty"`
+	ServerTimeout  time.Duration `yaml:"server-timeout,omitempty"`
+	Retries        int           `yaml:"retries,omitempty"`
+
+	// Balance is the haproxy balance algorithm, such as roundrobin or
+	// leastconn.  The management ports always use source balancing so that
+	// sessions stick to a node.
+	Balance string `yaml:"balance,omitempty"`
+
+	HealthCheckInterval time.Duration `yaml:"health-check-interval,omitempty"`
+	HealthCheckFall     int           `yaml:"health-check-fall,omitempty"`
+	HealthCheckRise     int           `yaml:"health-check-rise,omitempty"`
+}
+
 type AnalyticsSettings struct {
 	BlobStorage AnalyticsBlobStorageSettings `yaml:"blob-storage,omitempty"`
 }
@@ -79,6 +186,27 @@ type CaoCluster struct {
 	GatewayOtlpEndpoint string `yaml:"gateway-otlp-endpoint,omitempty"`
 
 	Ingress string `yaml:"ingress,omitempty"`
+
+	// Service memory quotas in MB, unset quotas keep the operator defaults.
+	KvMemoryMB       int `yaml:"kv-memory,omitempty"`
+	IndexMemoryMB    int `yaml:"index-memory,omitempty"`
+	FtsMemoryMB      int `yaml:"fts-memory,omitempty"`
+	CbasMemoryMB     int `yaml:"cbas-memory,omitempty"`
+	EventingMemoryMB int `yaml:"eventing-memory,omitempty"`
+
+	// UseDinoCerts enables TLS with certificates generated from the dino
+	// CA, which are installed as secrets in the cluster namespace.
+	UseDinoCerts bool             `yaml:"use-dino-certs,omitempty"`
+	DinoCerts    DinoCertSettings `yaml:"dino-cert-settings,omitempty"`
+
+	// StorageClass is the storage class of node volumes, and defaults to
+	// the default storage class of the kubernetes cluster.
+	StorageClass string `yaml:"storage-class,omitempty"`
+
+	// ServerGroupZones maps the server groups of node groups to zones.  The
+	// operator names server groups after their zone, so server groups
+	// without a mapping are assumed to already be a zone name.
+	ServerGroupZones map[string]string `yaml:"server-group-zones,omitempty"`
 }
 
 type CloudCluster struct {
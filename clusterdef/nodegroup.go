@@ -15,12 +15,45 @@ type NodeGroup struct {
 	Services []Service `yaml:"services,omitempty"`
 
 	Docker DockerNodeGroup `yaml:"docker,omitempty"`
+	Cao    CaoNodeGroup    `yaml:"cao,omitempty"`
 	Cloud  CloudNodeGroup  `yaml:"cloud,omitempty"`
 }
 
 type DockerNodeGroup struct {
 	Image   string            `yaml:"image,omitempty"`
 	EnvVars map[string]string `yaml:"env,omitempty"`
+
+	// Cpus limits the number of CPUs each node may use, fractional
+	// values such as 1.5 are permitted.
+	Cpus float64 `yaml:"cpus,omitempty"`
+
+	// Memory limits the memory each node may use, using docker-style
+	// sizes such as 512m or 4g.
+	Memory string `yaml:"memory,omitempty"`
+
+	// MemorySwap limits the total memory plus swap each node may use,
+	// or -1 to allow unlimited swap.  Requires Memory to be specified.
+	MemorySwap string `yaml:"memory-swap,omitempty"`
+
+	// PidsLimit limits the number of processes each node may run.
+	PidsLimit int64 `yaml:"pids-limit,omitempty"`
+}
+
+type CaoNodeGroup struct {
+	// Resource requests and limits of each node pod, using kubernetes
+	// quantities such as 500m or 4Gi.
+	CpuRequest    string `yaml:"cpu-request,omitempty"`
+	CpuLimit      string `yaml:"cpu-limit,omitempty"`
+	MemoryRequest string `yaml:"memory-request,omitempty"`
+	MemoryLimit   string `yaml:"memory-limit,omitempty"`
+
+	// VolumeSize gives each node a persistent volume of this size, such as
+	// 10Gi, instead of using ephemeral storage.
+	VolumeSize string `yaml:"volume-size,omitempty"`
+
+	// StorageClass overrides the storage class of the cluster for the
+	// volumes of this group.
+	StorageClass string `yaml:"storage-class,omitempty"`
 }
 
 type CloudNodeGroup struct {
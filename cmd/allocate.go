@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/couchbaselabs/cbdinocluster/clusterdef"
 	"github.com/couchbaselabs/cbdinocluster/deployment"
 	"github.com/couchbaselabs/cbdinocluster/deployment/clouddeploy"
+	"github.com/couchbaselabs/cbdinocluster/deployment/dockerdeploy"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
@@ -14,7 +16,7 @@ var allocateCmd = &cobra.Command{
 	Use:     "allocate [flags] <definition-tag | --def | --def-file>",
 	Aliases: []string{"alloc", "create"},
 	Short:   "Allocates a cluster",
-	Example: "allocate simple:7.0.0\nallocate single:7.2.0",
+	Example: "allocate simple:7.0.0\nallocate single:7.2.0\nallocate --from-pool simple:7.6.2",
 	Run: func(cmd *cobra.Command, args []string) {
 		helper := CmdHelper{}
 		logger := helper.GetLogger()
@@ -29,6 +31,7 @@ var allocateCmd = &cobra.Command{
 		expiryIsSet := cmd.Flags().Changed("expiry")
 		deployerName, _ := cmd.Flags().GetString("deployer")
 		cloudProvider, _ := cmd.Flags().GetString("cloud-provider")
+		fromPool, _ := cmd.Flags().GetBool("from-pool")
 
 		var def *clusterdef.Cluster
 
@@ -37,6 +40,10 @@ var allocateCmd = &cobra.Command{
 			simpleDefStr = args[0]
 		}
 
+		if fromPool && (defStr != "" || defFile != "" || simpleDefStr == "") {
+			logger.Fatal("--from-pool requires a definition-tag naming the pool")
+		}
+
 		def, err := helper.FetchClusterDef(simpleDefStr, defStr, defFile)
 		if err != nil {
 			logger.Fatal("failed to get definition", zap.Error(err))
@@ -57,21 +64,11 @@ var allocateCmd = &cobra.Command{
 			def.Cloud.CloudProvider = cloudProvider
 		}
 
-		// Validate and assemble the bucket options up-front so that an invalid
-		// bucket type fails fast, before the cluster is allocated.
-		bucketOpts := make(map[string]*deployment.CreateBucketOptions, len(def.Buckets))
-		for bucketName, bucketDef := range def.Buckets {
-			opts, err := newCreateBucketOptions(
-				bucketName,
-				bucketDef.Settings.BucketType,
-				bucketDef.Settings.RamQuotaMB,
-				bucketDef.Settings.FlushEnabled,
-				bucketDef.Settings.NumReplicas,
-			)
-			if err != nil {
-				logger.Fatal("invalid bucket type", zap.String("bucket", bucketName), zap.Error(err))
-			}
-			bucketOpts[bucketName] = opts
+		// the setup is validated up-front so that an invalid definition fails
+		// fast, before the cluster is allocated.
+		setup, err := deployment.NewClusterSetup(def)
+		if err != nil {
+			logger.Fatal("invalid cluster definition", zap.Error(err))
 		}
 
 		logger.Info("deploying definition", zap.Any("def", def))
@@ -80,6 +77,22 @@ var allocateCmd = &cobra.Command{
 			return
 		}
 
+		if fromPool {
+			deployer := helper.GetDockerDeployer(ctx)
+
+			cluster, err := deployer.ClaimPoolCluster(ctx, simpleDefStr, def.Purpose, def.Expiry)
+			if err == nil {
+				fmt.Printf("%s\n", cluster.GetID())
+				return
+			} else if !errors.Is(err, dockerdeploy.ErrPoolEmpty) {
+				logger.Fatal("failed to claim pool cluster", zap.Error(err))
+			}
+
+			logger.Warn("pool has no ready clusters, allocating a new cluster instead",
+				zap.String("pool", simpleDefStr))
+			def.Deployer = "docker"
+		}
+
 		var deployer deployment.Deployer
 		if def.Deployer == "" {
 			deployer = helper.GetDefaultDeployer(ctx)
@@ -92,34 +105,9 @@ var allocateCmd = &cobra.Command{
 			logger.Fatal("cluster deployment failed", zap.Error(err))
 		}
 
-		if len(def.Buckets) > 0 {
-			for bucketName, bucketDef := range def.Buckets {
-				err = deployer.CreateBucket(ctx, cluster.GetID(), bucketOpts[bucketName])
-				if err != nil {
-					logger.Fatal("failed to create bucket", zap.String("bucket", bucketName), zap.Error(err))
-				}
-				logger.Info("bucket created", zap.String("bucket", bucketName))
-
-				for scopeName, collections := range bucketDef.Scopes {
-					if scopeName == "" {
-						continue
-					}
-					if err := deployer.CreateScope(ctx, cluster.GetID(), bucketName, scopeName); err != nil {
-						logger.Fatal("failed to create scope", zap.String("bucket", bucketName), zap.String("scope", scopeName), zap.Error(err))
-					}
-					logger.Info("scope created", zap.String("bucket", bucketName), zap.String("scope", scopeName))
-
-					for _, collName := range collections {
-						if collName == "" {
-							continue
-						}
-						if err := deployer.CreateCollection(ctx, cluster.GetID(), bucketName, scopeName, collName); err != nil {
-							logger.Fatal("failed to create collection", zap.String("bucket", bucketName), zap.String("scope", scopeName), zap.String("collection", collName), zap.Error(err))
-						}
-						logger.Info("collection created", zap.String("bucket", bucketName), zap.String("scope", scopeName), zap.String("collection", collName))
-					}
-				}
-			}
+		err = setup.Apply(ctx, logger, deployer, cluster.GetID())
+		if err != nil {
+			logger.Fatal("failed to set up cluster", zap.Error(err))
 		}
 
 		switch cluster := cluster.(type) {
@@ -138,6 +126,11 @@ var allocateCmd = &cobra.Command{
 			logger.Info("cluster deployed",
 				zap.String("mgmt", connectInfo.Mgmt),
 				zap.String("connstr", connectInfo.ConnStr))
+
+			if connectInfo.DnsServer != "" {
+				logger.Info("cluster dns is served by a local dns server",
+					zap.String("dns-server", connectInfo.DnsServer))
+			}
 		}
 
 		fmt.Printf("%s\n", cluster.GetID())
@@ -154,4 +147,5 @@ func init() {
 	allocateCmd.Flags().Duration("expiry", 0, "The time to keep this cluster allocated for")
 	allocateCmd.Flags().String("deployer", "", "The name of the deployer to use")
 	allocateCmd.Flags().String("cloud-provider", "", "The cloud provider to use for this cluster")
+	allocateCmd.Flags().Bool("from-pool", false, "Claims a ready cluster from the pool for the definition-tag if one is available")
 }
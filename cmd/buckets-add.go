@@ -3,6 +3,7 @@ package cmd
 import (
 	"errors"
 
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
 	"github.com/couchbaselabs/cbdinocluster/deployment"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -20,14 +21,9 @@ var bucketsAddCmd = &cobra.Command{
 		clusterID := args[0]
 		bucketName := args[1]
 
-		ramQuotaMB, _ := cmd.Flags().GetInt("ram-quota-mb")
-		flushEnabled, _ := cmd.Flags().GetBool("flush-enabled")
-		numReplicas, _ := cmd.Flags().GetInt("num-replicas")
-		bucketTypeStr, _ := cmd.Flags().GetString("bucket-type")
-
-		createOpts, err := newCreateBucketOptions(bucketName, bucketTypeStr, ramQuotaMB, flushEnabled, numReplicas)
+		createOpts, err := deployment.NewCreateBucketOptions(bucketName, bucketSettingsFromFlags(cmd))
 		if err != nil {
-			logger.Fatal("invalid bucket type", zap.Error(err))
+			logger.Fatal("invalid bucket settings", zap.Error(err))
 		}
 
 		_, deployer, cluster := helper.IdentifyCluster(ctx, clusterID)
@@ -42,29 +38,47 @@ var bucketsAddCmd = &cobra.Command{
 	},
 }
 
-// newCreateBucketOptions parses the user-supplied bucket type and assembles the
-// CreateBucketOptions shared by the `buckets add` command and the YAML-driven
-// allocate path. It returns an error for an unrecognized bucket type.
-func newCreateBucketOptions(name, bucketTypeStr string, ramQuotaMB int, flushEnabled bool, numReplicas int) (*deployment.CreateBucketOptions, error) {
-	bucketType, err := deployment.ParseBucketType(bucketTypeStr)
-	if err != nil {
-		return nil, err
-	}
+// bucketSettingsFromFlags reads the bucket settings of `buckets add` in the
+// same form as the settings of a bucket in a cluster definition, so both
+// paths share the validation of deployment.NewCreateBucketOptions.
+func bucketSettingsFromFlags(cmd *cobra.Command) *clusterdef.Settings {
+	flags := cmd.Flags()
+	settings := &clusterdef.Settings{}
+	settings.BucketType, _ = flags.GetString("bucket-type")
+	settings.RamQuotaMB, _ = flags.GetInt("ram-quota-mb")
+	settings.FlushEnabled, _ = flags.GetBool("flush-enabled")
+	settings.NumReplicas, _ = flags.GetInt("num-replicas")
+	settings.StorageBackend, _ = flags.GetString("storage-backend")
+	settings.EvictionPolicy, _ = flags.GetString("eviction-policy")
+	settings.DurabilityMinLevel, _ = flags.GetString("durability-min-level")
+	settings.MaxTTL, _ = flags.GetDuration("max-ttl")
+	settings.CompressionMode, _ = flags.GetString("compression-mode")
+	settings.ConflictResolution, _ = flags.GetString("conflict-resolution")
+	settings.HistoryRetentionBytes, _ = flags.GetUint64("history-retention-bytes")
+	settings.HistoryRetentionDuration, _ = flags.GetDuration("history-retention-duration")
+	settings.NumVBuckets, _ = flags.GetInt("num-vbuckets")
+	return settings
+}
 
-	return &deployment.CreateBucketOptions{
-		Name:         name,
-		BucketType:   bucketType,
-		RamQuotaMB:   ramQuotaMB,
-		FlushEnabled: flushEnabled,
-		NumReplicas:  numReplicas,
-	}, nil
+// addBucketSettingsFlags registers the flags read by bucketSettingsFromFlags.
+func addBucketSettingsFlags(cmd *cobra.Command) {
+	cmd.Flags().String("bucket-type", "couchbase", "The type of bucket to create: couchbase (default) or ephemeral. memcached is a legacy type that Couchbase Server 8.0+ rejects and is only creatable on older clusters.")
+	cmd.Flags().Int("ram-quota-mb", 0, "The amount of RAM to provide for the bucket.")
+	cmd.Flags().Bool("flush-enabled", false, "Whether flush is enabled on the bucket.")
+	cmd.Flags().Int("num-replicas", 1, "The number of replicas for the bucket.")
+	cmd.Flags().String("storage-backend", "", "The storage backend of the bucket: couchstore or magma.")
+	cmd.Flags().String("eviction-policy", "", "The eviction policy of the bucket: value-only or full for couchbase buckets, no-eviction or nru for ephemeral buckets.")
+	cmd.Flags().String("durability-min-level", "", "The minimum durability level: none, majority, majority-and-persist-active or persist-to-majority.")
+	cmd.Flags().Duration("max-ttl", 0, "The maximum time-to-live of documents in the bucket.")
+	cmd.Flags().String("compression-mode", "", "The compression mode of the bucket: off, passive or active.")
+	cmd.Flags().String("conflict-resolution", "", "The XDCR conflict resolution of the bucket: seqno, lww or custom.")
+	cmd.Flags().Uint64("history-retention-bytes", 0, "The amount of change history to retain for CDC, requires magma.")
+	cmd.Flags().Duration("history-retention-duration", 0, "How long to retain change history for CDC, requires magma.")
+	cmd.Flags().Int("num-vbuckets", 0, "The number of vbuckets of the bucket (128 or 1024), requires magma.")
 }
 
 func init() {
 	bucketsCmd.AddCommand(bucketsAddCmd)
 
-	bucketsAddCmd.Flags().String("bucket-type", "couchbase", "The type of bucket to create: couchbase (default) or ephemeral. memcached is a legacy type that Couchbase Server 8.0+ rejects and is only creatable on older clusters.")
-	bucketsAddCmd.Flags().Int("ram-quota-mb", 0, "The amount of RAM to provide for the bucket.")
-	bucketsAddCmd.Flags().Bool("flush-enabled", false, "Whether flush is enabled on the bucket.")
-	bucketsAddCmd.Flags().Int("num-replicas", 1, "The number of replicas for the bucket.")
+	addBucketSettingsFlags(bucketsAddCmd)
 }
@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"testing"
+	"time"
 
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
 	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 )
 
@@ -16,11 +19,11 @@ func TestBucketsAddBucketTypeFlag(t *testing.T) {
 	require.Equal(t, "couchbase", flag.DefValue)
 }
 
-// TestNewCreateBucketOptionsWiring asserts that the parsed bucket type and the
-// remaining bucket settings actually land in the CreateBucketOptions handed to
-// the deployer. This is the glue between the CLI flags and the deployer that
-// the flag-default test alone does not cover.
-func TestNewCreateBucketOptionsWiring(t *testing.T) {
+// TestBucketSettingsFromFlagsWiring asserts that the flags of `buckets add`
+// actually land in the CreateBucketOptions handed to the deployer. This is
+// the glue between the CLI flags and the deployer that the flag-default test
+// alone does not cover.
+func TestBucketSettingsFromFlagsWiring(t *testing.T) {
 	tests := []struct {
 		name           string
 		bucketTypeStr  string
@@ -34,23 +37,28 @@ func TestNewCreateBucketOptionsWiring(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			opts, err := newCreateBucketOptions("mybucket", tt.bucketTypeStr, 256, true, 2)
+			cmd := &cobra.Command{}
+			addBucketSettingsFlags(cmd)
+			cmd.Flags().Set("bucket-type", tt.bucketTypeStr)
+			cmd.Flags().Set("ram-quota-mb", "256")
+			cmd.Flags().Set("flush-enabled", "true")
+			cmd.Flags().Set("num-replicas", "2")
+			cmd.Flags().Set("max-ttl", "1h")
+			cmd.Flags().Set("durability-min-level", "majority")
+
+			opts, err := deployment.NewCreateBucketOptions("mybucket", bucketSettingsFromFlags(cmd))
 			require.NoError(t, err)
 			require.Equal(t, "mybucket", opts.Name)
 			require.Equal(t, tt.wantBucketType, opts.BucketType)
 			require.Equal(t, 256, opts.RamQuotaMB)
 			require.True(t, opts.FlushEnabled)
 			require.Equal(t, 2, opts.NumReplicas)
+			require.Equal(t, time.Hour, opts.MaxTTL)
+			require.Equal(t, deployment.DurabilityLevelMajority, opts.DurabilityMinLevel)
 		})
 	}
 }
 
-func TestNewCreateBucketOptionsInvalidType(t *testing.T) {
-	opts, err := newCreateBucketOptions("mybucket", "nonsense", 0, false, 0)
-	require.Error(t, err)
-	require.Nil(t, opts)
-}
-
 // TestBucketsAddFlagDefaultIsValid ties the flag default to the parser: the
 // default string must be a bucket type the option-builder accepts, so the
 // zero-config path can never fail validation in the command's Run.
@@ -58,7 +66,7 @@ func TestBucketsAddFlagDefaultIsValid(t *testing.T) {
 	flag := bucketsAddCmd.Flags().Lookup("bucket-type")
 	require.NotNil(t, flag)
 
-	opts, err := newCreateBucketOptions("b", flag.DefValue, 0, false, 0)
+	opts, err := deployment.NewCreateBucketOptions("b", &clusterdef.Settings{BucketType: flag.DefValue})
 	require.NoError(t, err)
 	require.Equal(t, deployment.BucketTypeCouchbase, opts.BucketType)
 }
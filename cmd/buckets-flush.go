@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var bucketsFlushCmd = &cobra.Command{
+	Use:   "flush <cluster-id> <bucket-name>",
+	Short: "Removes all the documents of a bucket",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		clusterID := args[0]
+		bucketName := args[1]
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, clusterID)
+
+		err := deployer.FlushBucket(ctx, cluster.GetID(), bucketName)
+		if err != nil {
+			logger.Fatal("failed to flush bucket", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	bucketsCmd.AddCommand(bucketsFlushCmd)
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+type BucketsInspectOutput struct {
+	Name                    string   `json:"name"`
+	BucketType              string   `json:"bucket_type"`
+	RamQuotaMB              int      `json:"ram_quota_mb"`
+	FlushEnabled            bool     `json:"flush_enabled"`
+	NumReplicas             int      `json:"num_replicas"`
+	StorageBackend          string   `json:"storage_backend,omitempty"`
+	EvictionPolicy          string   `json:"eviction_policy,omitempty"`
+	DurabilityMinLevel      string   `json:"durability_min_level,omitempty"`
+	MaxTTLSeconds           int      `json:"max_ttl_seconds"`
+	CompressionMode         string   `json:"compression_mode,omitempty"`
+	ConflictResolution      string   `json:"conflict_resolution,omitempty"`
+	HistoryRetentionBytes   uint64   `json:"history_retention_bytes,omitempty"`
+	HistoryRetentionSeconds int      `json:"history_retention_seconds,omitempty"`
+	NumVBuckets             int      `json:"num_vbuckets,omitempty"`
+	ItemCount               uint64   `json:"item_count"`
+	MemUsedBytes            uint64   `json:"mem_used_bytes"`
+	DiskUsedBytes           uint64   `json:"disk_used_bytes"`
+	ResidentRatio           *float64 `json:"resident_ratio,omitempty"`
+}
+
+var bucketsInspectCmd = &cobra.Command{
+	Use:   "inspect <cluster-id> <bucket-name>",
+	Short: "Shows the settings and usage of a bucket",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		outputJson, _ := cmd.Flags().GetBool("json")
+
+		clusterID := args[0]
+		bucketName := args[1]
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, clusterID)
+
+		bucket, err := deployer.GetBucket(ctx, cluster.GetID(), bucketName)
+		if err != nil {
+			logger.Fatal("failed to get bucket", zap.Error(err))
+		}
+
+		settings := bucket.Settings
+		out := BucketsInspectOutput{
+			Name:                    settings.Name,
+			BucketType:              string(settings.BucketType),
+			RamQuotaMB:              settings.RamQuotaMB,
+			FlushEnabled:            settings.FlushEnabled,
+			NumReplicas:             settings.NumReplicas,
+			StorageBackend:          string(settings.StorageBackend),
+			EvictionPolicy:          string(settings.EvictionPolicy),
+			DurabilityMinLevel:      string(settings.DurabilityMinLevel),
+			MaxTTLSeconds:           int(settings.MaxTTL.Seconds()),
+			CompressionMode:         string(settings.CompressionMode),
+			ConflictResolution:      string(settings.ConflictResolution),
+			HistoryRetentionBytes:   settings.HistoryRetentionBytes,
+			HistoryRetentionSeconds: int(settings.HistoryRetentionDuration.Seconds()),
+			NumVBuckets:             settings.NumVBuckets,
+			ItemCount:               bucket.ItemCount,
+			MemUsedBytes:            bucket.MemUsedBytes,
+			DiskUsedBytes:           bucket.DiskUsedBytes,
+			ResidentRatio:           bucket.ResidentRatio,
+		}
+
+		if !outputJson {
+			fmt.Printf("Bucket: %s\n", out.Name)
+			fmt.Printf("  Type: %s\n", out.BucketType)
+			fmt.Printf("  RAM Quota: %d MB\n", out.RamQuotaMB)
+			fmt.Printf("  Replicas: %d\n", out.NumReplicas)
+			fmt.Printf("  Flush Enabled: %t\n", out.FlushEnabled)
+			if out.StorageBackend != "" {
+				fmt.Printf("  Storage Backend: %s\n", out.StorageBackend)
+			}
+			if out.EvictionPolicy != "" {
+				fmt.Printf("  Eviction Policy: %s\n", out.EvictionPolicy)
+			}
+			if out.DurabilityMinLevel != "" {
+				fmt.Printf("  Durability Min Level: %s\n", out.DurabilityMinLevel)
+			}
+			fmt.Printf("  Max TTL: %s\n", settings.MaxTTL)
+			if out.CompressionMode != "" {
+				fmt.Printf("  Compression Mode: %s\n", out.CompressionMode)
+			}
+			if out.ConflictResolution != "" {
+				fmt.Printf("  Conflict Resolution: %s\n", out.ConflictResolution)
+			}
+			if out.HistoryRetentionBytes > 0 || out.HistoryRetentionSeconds > 0 {
+				fmt.Printf("  History Retention: %d bytes, %s\n",
+					out.HistoryRetentionBytes,
+					settings.HistoryRetentionDuration)
+			}
+			if out.NumVBuckets > 0 {
+				fmt.Printf("  VBuckets: %d\n", out.NumVBuckets)
+			}
+			fmt.Printf("  Items: %d\n", out.ItemCount)
+			fmt.Printf("  Memory Used: %d bytes\n", out.MemUsedBytes)
+			fmt.Printf("  Disk Used: %d bytes\n", out.DiskUsedBytes)
+			if out.ResidentRatio != nil {
+				fmt.Printf("  Resident Ratio: %.1f%%\n", *out.ResidentRatio)
+			}
+		} else {
+			helper.OutputJson(out)
+		}
+	},
+}
+
+func init() {
+	bucketsCmd.AddCommand(bucketsInspectCmd)
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var bucketsUpdateCmd = &cobra.Command{
+	Use:   "update <cluster-id> <bucket-name>",
+	Short: "Updates the settings of a bucket",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		clusterID := args[0]
+		bucketName := args[1]
+
+		updateOpts, err := updateBucketOptionsFromFlags(cmd)
+		if err != nil {
+			logger.Fatal("invalid bucket settings", zap.Error(err))
+		}
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, clusterID)
+
+		err = deployer.UpdateBucket(ctx, cluster.GetID(), bucketName, updateOpts)
+		if err != nil {
+			logger.Fatal("failed to update bucket", zap.Error(err))
+		}
+	},
+}
+
+// updateBucketOptionsFromFlags only includes the settings whose flags were
+// passed, so that zero replicas or no max TTL can still be requested.
+func updateBucketOptionsFromFlags(cmd *cobra.Command) (*deployment.UpdateBucketOptions, error) {
+	flags := cmd.Flags()
+	opts := &deployment.UpdateBucketOptions{}
+
+	if flags.Changed("ram-quota-mb") {
+		ramQuotaMB, _ := flags.GetInt("ram-quota-mb")
+		opts.RamQuotaMB = &ramQuotaMB
+	}
+	if flags.Changed("num-replicas") {
+		numReplicas, _ := flags.GetInt("num-replicas")
+		opts.NumReplicas = &numReplicas
+	}
+	if flags.Changed("max-ttl") {
+		maxTTL, _ := flags.GetDuration("max-ttl")
+		opts.MaxTTL = &maxTTL
+	}
+
+	durabilityLevelStr, _ := flags.GetString("durability-min-level")
+	durabilityLevel, err := deployment.ParseDurabilityLevel(durabilityLevelStr)
+	if err != nil {
+		return nil, err
+	}
+	opts.DurabilityMinLevel = durabilityLevel
+
+	return opts, nil
+}
+
+// addUpdateBucketFlags registers the flags read by updateBucketOptionsFromFlags.
+func addUpdateBucketFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("ram-quota-mb", 0, "The amount of RAM to provide for the bucket.")
+	cmd.Flags().Int("num-replicas", 0, "The number of replicas for the bucket.")
+	cmd.Flags().Duration("max-ttl", 0, "The maximum time-to-live of documents in the bucket, 0 to disable.")
+	cmd.Flags().String("durability-min-level", "", "The minimum durability level: none, majority, majority-and-persist-active or persist-to-majority.")
+}
+
+func init() {
+	bucketsCmd.AddCommand(bucketsUpdateCmd)
+
+	addUpdateBucketFlags(bucketsUpdateCmd)
+}
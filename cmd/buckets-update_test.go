@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateBucketOptionsFromFlags(t *testing.T) {
+	cmd := &cobra.Command{}
+	addUpdateBucketFlags(cmd)
+	cmd.Flags().Set("num-replicas", "0")
+	cmd.Flags().Set("durability-min-level", "majority")
+
+	opts, err := updateBucketOptionsFromFlags(cmd)
+	require.NoError(t, err)
+
+	// only flags which were passed are included, even when they are zero
+	require.Nil(t, opts.RamQuotaMB)
+	require.Nil(t, opts.MaxTTL)
+	require.NotNil(t, opts.NumReplicas)
+	require.Equal(t, 0, *opts.NumReplicas)
+	require.Equal(t, deployment.DurabilityLevelMajority, opts.DurabilityMinLevel)
+}
+
+func TestUpdateBucketOptionsFromFlagsInvalidDurability(t *testing.T) {
+	cmd := &cobra.Command{}
+	addUpdateBucketFlags(cmd)
+	cmd.Flags().Set("durability-min-level", "all")
+
+	_, err := updateBucketOptionsFromFlags(cmd)
+	require.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
+)
+
+type CapabilitiesOutput []CapabilitiesOutput_Item
+
+type CapabilitiesOutput_Item struct {
+	Deployer     string   `json:"deployer"`
+	Capabilities []string `json:"capabilities"`
+}
+
+var capabilitiesCmd = &cobra.Command{
+	Use:   "capabilities [flags] [deployer-name]",
+	Short: "Lists the operations each deployer supports",
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		outputJson, _ := cmd.Flags().GetBool("json")
+
+		deployers := make(map[string]deployment.Deployer)
+		if len(args) >= 1 {
+			deployers[args[0]] = helper.GetDeployerByName(ctx, args[0])
+		} else {
+			deployers = helper.GetAllDeployers(ctx)
+		}
+
+		deployerNames := maps.Keys(deployers)
+		sort.Strings(deployerNames)
+
+		deployerCaps := make(map[string][]deployment.Capability)
+		for _, deployerName := range deployerNames {
+			caps, err := deployers[deployerName].Capabilities(ctx)
+			if err != nil {
+				logger.Fatal("failed to get deployer capabilities",
+					zap.String("deployer", deployerName),
+					zap.Error(err))
+			}
+
+			deployerCaps[deployerName] = caps
+		}
+
+		if !outputJson {
+			fmt.Printf("%-24s", "Capability")
+			for _, deployerName := range deployerNames {
+				fmt.Printf(" %-8s", deployerName)
+			}
+			fmt.Printf("\n")
+
+			for _, capability := range deployment.AllCapabilities {
+				fmt.Printf("%-24s", capability)
+				for _, deployerName := range deployerNames {
+					supportedStr := "-"
+					if slices.Contains(deployerCaps[deployerName], capability) {
+						supportedStr = "yes"
+					}
+					fmt.Printf(" %-8s", supportedStr)
+				}
+				fmt.Printf("\n")
+			}
+		} else {
+			out := CapabilitiesOutput{}
+			for _, deployerName := range deployerNames {
+				capStrs := []string{}
+				for _, capability := range deployerCaps[deployerName] {
+					capStrs = append(capStrs, string(capability))
+				}
+
+				out = append(out, CapabilitiesOutput_Item{
+					Deployer:     deployerName,
+					Capabilities: capStrs,
+				})
+			}
+			helper.OutputJson(out)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(capabilitiesCmd)
+}
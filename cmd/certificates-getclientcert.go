@@ -24,6 +24,9 @@ var certificatesGetClientCertCmd = &cobra.Command{
 
 		username := args[0]
 		expiresInStr, _ := cmd.Flags().GetString("expires-in")
+		keyType, _ := cmd.Flags().GetString("key-type")
+		keyFormat, _ := cmd.Flags().GetString("key-format")
+		passphrase, _ := cmd.Flags().GetString("passphrase")
 		outputJson, _ := cmd.Flags().GetBool("json")
 
 		rootCa, err := dinocerts.GetRootCertAuthority()
@@ -43,7 +46,11 @@ var certificatesGetClientCertCmd = &cobra.Command{
 			expiresIn = expiresInDate
 		}
 
-		cert, key, err := rootCa.MakeClientCertificate(username, expiresIn)
+		cert, key, err := rootCa.MakeClientCertificateWithKey(username, expiresIn, dinocerts.KeySpec{
+			Type:       dinocerts.KeyType(keyType),
+			Format:     dinocerts.KeyFormat(keyFormat),
+			Passphrase: passphrase,
+		})
 		if err != nil {
 			logger.Fatal("failed to generate client certificate", zap.Error(err))
 		}
@@ -62,5 +69,8 @@ var certificatesGetClientCertCmd = &cobra.Command{
 func init() {
 	certificatesCmd.AddCommand(certificatesGetClientCertCmd)
 	certificatesGetClientCertCmd.Flags().String("expires-in", "", "How long before the token expires (e.g. 24h, 30m, 10s, -1h) or '' for the default fixed period")
+	certificatesGetClientCertCmd.Flags().String("key-type", "", "The type of key to generate (rsa-2048, rsa-4096, ecdsa-p256, ecdsa-p384, ed25519), defaults to rsa-4096")
+	certificatesGetClientCertCmd.Flags().String("key-format", "", "The format of the private key (pkcs1, sec1, pkcs8), defaults to the traditional format of the key type")
+	certificatesGetClientCertCmd.Flags().String("passphrase", "", "Encrypts the private key as an encrypted PKCS#8 key")
 
 }
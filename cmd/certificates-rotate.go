@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment/dockerdeploy"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var certificatesRotateCmd = &cobra.Command{
+	Use:   "rotate <cluster-id>",
+	Short: "Rotates the certificates of a cluster using dino-certs",
+	Long: `Rotates the certificates of a cluster using dino-certs.
+
+New certificates are issued to every node and load balancer of the cluster.
+With --new-ca, the intermediate CA of the cluster is also replaced, and the
+previous CA remains trusted for the --overlap period.  It is removed by the
+first cleanup or rotation after that.  The validity of the new certificates
+can be set to a specific time (RFC3339) or a duration relative to now
+(e.g. -1h, 720h), which allows testing of expired certificates.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		newCa, _ := cmd.Flags().GetBool("new-ca")
+		notBeforeStr, _ := cmd.Flags().GetString("not-before")
+		notAfterStr, _ := cmd.Flags().GetString("not-after")
+		overlap, _ := cmd.Flags().GetDuration("overlap")
+
+		now := time.Now()
+
+		notBefore, err := parseCertTime(notBeforeStr, now)
+		if err != nil {
+			logger.Fatal("failed to parse not-before", zap.Error(err))
+		}
+
+		notAfter, err := parseCertTime(notAfterStr, now)
+		if err != nil {
+			logger.Fatal("failed to parse not-after", zap.Error(err))
+		}
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+		dockerDeployer, ok := deployer.(*dockerdeploy.Deployer)
+		if !ok {
+			logger.Fatal("certificate rotation is only supported for docker clusters")
+		}
+
+		err = dockerDeployer.RotateCertificates(ctx, cluster.GetID(), &dockerdeploy.RotateCertificatesOptions{
+			NewCA:     newCa,
+			NotBefore: notBefore,
+			NotAfter:  notAfter,
+			Overlap:   overlap,
+		})
+		if err != nil {
+			logger.Fatal("failed to rotate certificates", zap.Error(err))
+		}
+	},
+}
+
+// parseCertTime parses a certificate validity time, which is either an
+// RFC3339 time or a duration relative to now.  An empty string is the zero
+// time, which means the default validity.
+func parseCertTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	parsedTime, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return parsedTime, nil
+	}
+
+	offset, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("`%s` is neither an RFC3339 time nor a duration", value)
+	}
+
+	return now.Add(offset), nil
+}
+
+func init() {
+	certificatesCmd.AddCommand(certificatesRotateCmd)
+	certificatesRotateCmd.Flags().Bool("new-ca", false, "Also replace the intermediate CA of the cluster")
+	certificatesRotateCmd.Flags().String("not-before", "", "When the new certificates become valid, as an RFC3339 time or a duration from now")
+	certificatesRotateCmd.Flags().String("not-after", "", "When the new certificates expire, as an RFC3339 time or a duration from now")
+	certificatesRotateCmd.Flags().Duration("overlap", 24*time.Hour, "How long the previous CA remains trusted when using --new-ca")
+}
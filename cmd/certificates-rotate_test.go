@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCertTime(t *testing.T) {
+	now := time.Date(2025, 06, 01, 12, 00, 00, 00, time.UTC)
+
+	testCases := []struct {
+		name     string
+		value    string
+		expected time.Time
+		isError  bool
+	}{
+		{"empty", "", time.Time{}, false},
+		{"rfc3339", "2026-01-02T03:04:05Z", time.Date(2026, 01, 02, 03, 04, 05, 00, time.UTC), false},
+		{"future duration", "720h", now.Add(720 * time.Hour), false},
+		{"past duration", "-1h", now.Add(-time.Hour), false},
+		{"invalid", "tomorrow", time.Time{}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := parseCertTime(tc.value, now)
+			if tc.isError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.True(t, tc.expected.Equal(parsed), "expected %s, got %s", tc.expected, parsed)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var chaosDegradeCmd = &cobra.Command{
+	Use:   "degrade <cluster-id> [<node-id-or-ip> ...]",
+	Short: "Adds latency, packet loss or rate limiting to node traffic",
+	Long: `Adds latency, packet loss or rate limiting to node traffic.
+
+The degradation replaces any previously applied to the nodes, and is removed
+by the allow-traffic command.`,
+	Example: "chaos degrade {{CLUSTER_ID}} --latency 200ms --jitter 50ms\nchaos degrade {{CLUSTER_ID}} {{NODE_ID}} --loss 5% --rate 1mbit --traffic clients --direction egress",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		clusterID := args[0]
+		nodeIdents := args[1:]
+		latency, _ := cmd.Flags().GetDuration("latency")
+		jitter, _ := cmd.Flags().GetDuration("jitter")
+		lossStr, _ := cmd.Flags().GetString("loss")
+		rate, _ := cmd.Flags().GetString("rate")
+		trafficTypeStr, _ := cmd.Flags().GetString("traffic")
+		directionStr, _ := cmd.Flags().GetString("direction")
+
+		var lossPercent float64
+		if lossStr != "" {
+			parsedLoss, err := strconv.ParseFloat(strings.TrimSuffix(lossStr, "%"), 64)
+			if err != nil {
+				logger.Fatal("failed to parse loss percentage", zap.Error(err))
+			}
+			lossPercent = parsedLoss
+		}
+
+		var trafficType deployment.BlockNodeTrafficType
+		switch trafficTypeStr {
+		case "nodes":
+			trafficType = deployment.BlockNodeTrafficNodes
+		case "clients":
+			trafficType = deployment.BlockNodeTrafficClients
+		case "all":
+			trafficType = deployment.BlockNodeTrafficAll
+		default:
+			logger.Fatal("unexpected traffic type",
+				zap.String("type", trafficTypeStr))
+		}
+
+		var direction deployment.DegradeNodeTrafficDirection
+		switch directionStr {
+		case "ingress":
+			direction = deployment.DegradeNodeTrafficIngress
+		case "egress":
+			direction = deployment.DegradeNodeTrafficEgress
+		case "both":
+			direction = deployment.DegradeNodeTrafficBoth
+		default:
+			logger.Fatal("unexpected traffic direction",
+				zap.String("direction", directionStr))
+		}
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, clusterID)
+
+		var nodeIds []string
+		for _, nodeIdent := range nodeIdents {
+			node := helper.IdentifyNode(ctx, cluster, nodeIdent)
+			nodeIds = append(nodeIds, node.GetID())
+		}
+
+		err := deployer.DegradeNodeTraffic(ctx, cluster.GetID(), nodeIds, &deployment.DegradeNodeTrafficOptions{
+			TrafficType: trafficType,
+			Direction:   direction,
+			Latency:     latency,
+			Jitter:      jitter,
+			LossPercent: lossPercent,
+			Rate:        rate,
+		})
+		if err != nil {
+			logger.Fatal("failed to degrade node traffic", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	chaosCmd.AddCommand(chaosDegradeCmd)
+
+	chaosDegradeCmd.Flags().Duration("latency", 0, "The latency to add to each packet")
+	chaosDegradeCmd.Flags().Duration("jitter", 0, "The random variation to apply to the added latency")
+	chaosDegradeCmd.Flags().String("loss", "", "The percentage of packets to drop, such as 5%")
+	chaosDegradeCmd.Flags().String("rate", "", "The bandwidth to limit traffic to, such as 1mbit")
+	chaosDegradeCmd.Flags().String("traffic", "all", "Specifies the type of traffic to degrade (nodes, clients, all)")
+	chaosDegradeCmd.Flags().String("direction", "both", "Specifies the direction of traffic to degrade (ingress, egress, both)")
+}
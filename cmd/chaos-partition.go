@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// parsePartitionSpec parses the partition syntax of `chaos partition`.  The
+// node groups of the returned spec contain the node identifiers as written,
+// which still need to be resolved to node ids.
+func parsePartitionSpec(specStr string, groupDefs []string) (*deployment.PartitionSpec, error) {
+	spec := &deployment.PartitionSpec{}
+
+	namedGroups := make(map[string]bool)
+	for _, groupDef := range groupDefs {
+		name, nodesStr, ok := strings.Cut(groupDef, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid group `%s`, expected name=node,node", groupDef)
+		}
+
+		spec.Groups = append(spec.Groups, deployment.PartitionGroup{
+			Name:    name,
+			NodeIDs: splitPartitionNodes(nodesStr),
+		})
+		namedGroups[name] = true
+	}
+
+	parseGroup := func(groupStr string) (string, error) {
+		groupStr = strings.TrimSpace(groupStr)
+		groupStr = strings.TrimSuffix(strings.TrimPrefix(groupStr, "{"), "}")
+		if namedGroups[groupStr] {
+			return groupStr, nil
+		}
+
+		nodeIdents := splitPartitionNodes(groupStr)
+		if len(nodeIdents) == 0 {
+			return "", errors.New("empty group in partition")
+		}
+
+		// unnamed groups are named after their nodes, so that repeating the
+		// same group refers to the same group.
+		slices.Sort(nodeIdents)
+		name := "{" + strings.Join(nodeIdents, ",") + "}"
+		if !slices.ContainsFunc(spec.Groups, func(group deployment.PartitionGroup) bool {
+			return group.Name == name
+		}) {
+			spec.Groups = append(spec.Groups, deployment.PartitionGroup{
+				Name:    name,
+				NodeIDs: nodeIdents,
+			})
+		}
+
+		return name, nil
+	}
+
+	for _, ruleStr := range strings.Split(specStr, ";") {
+		ruleStr = strings.TrimSpace(ruleStr)
+		if ruleStr == "" {
+			continue
+		}
+
+		if fromStr, toStr, isOneWay := strings.Cut(ruleStr, ">"); isOneWay {
+			fromGroup, err := parseGroup(fromStr)
+			if err != nil {
+				return nil, err
+			}
+
+			toGroup, err := parseGroup(toStr)
+			if err != nil {
+				return nil, err
+			}
+
+			spec.Rules = append(spec.Rules, deployment.PartitionRule{
+				From: fromGroup,
+				To:   toGroup,
+			})
+			continue
+		}
+
+		groupStrs := strings.Split(ruleStr, "|")
+		if len(groupStrs) < 2 {
+			return nil, fmt.Errorf("invalid partition rule `%s`, expected groups separated by | or >", ruleStr)
+		}
+
+		var groups []string
+		for _, groupStr := range groupStrs {
+			group, err := parseGroup(groupStr)
+			if err != nil {
+				return nil, err
+			}
+
+			groups = append(groups, group)
+		}
+
+		for groupIdx, group := range groups {
+			for _, otherGroup := range groups[groupIdx+1:] {
+				spec.Rules = append(spec.Rules, deployment.PartitionRule{
+					From:          group,
+					To:            otherGroup,
+					Bidirectional: true,
+				})
+			}
+		}
+	}
+
+	err := spec.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+func splitPartitionNodes(nodesStr string) []string {
+	var nodes []string
+	for _, node := range strings.Split(nodesStr, ",") {
+		node = strings.TrimSpace(node)
+		if node != "" {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+var chaosPartitionCmd = &cobra.Command{
+	Use:   "partition <cluster-id> <partition>",
+	Short: "Partitions the traffic between groups of nodes",
+	Long: `Partitions the traffic between groups of nodes.
+
+Groups are either lists of node ids or addresses separated by commas, or the
+name of a group declared with --group.  Groups separated by | are partitioned
+from each other in both directions, while A>B prevents the nodes of A from
+reaching the nodes of B, but still allows B to reach A.  Several rules can be
+combined by separating them with a semicolon.  Nodes which are not part of
+any group are unaffected.  Use allow-traffic to remove the partition.`,
+	Example: "chaos partition {{CLUSTER_ID}} \"{node1,node2}|{node3}\"\n" +
+		"chaos partition {{CLUSTER_ID}} --group east=node1,node2 --group west=node3 \"west>east\"",
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		groupDefs, _ := cmd.Flags().GetStringArray("group")
+		blockTypeStr, _ := cmd.Flags().GetString("reject-with")
+
+		spec, err := parsePartitionSpec(args[1], groupDefs)
+		if err != nil {
+			logger.Fatal("failed to parse partition", zap.Error(err))
+		}
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+		for groupIdx, group := range spec.Groups {
+			var nodeIds []string
+			for _, nodeIdent := range group.NodeIDs {
+				node := helper.IdentifyNode(ctx, cluster, nodeIdent)
+				nodeIds = append(nodeIds, node.GetID())
+			}
+			spec.Groups[groupIdx].NodeIDs = nodeIds
+		}
+
+		err = deployer.PartitionNodeGroups(ctx, cluster.GetID(), spec, blockTypeStr)
+		if err != nil {
+			logger.Fatal("failed to partition node traffic", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	chaosCmd.AddCommand(chaosPartitionCmd)
+
+	chaosPartitionCmd.Flags().StringArray("group", nil, "Declares a named group of nodes as name=node,node")
+	chaosPartitionCmd.Flags().String("reject-with", "", "Specifies the reject-with type to use from iptables or empty for DROP")
+}
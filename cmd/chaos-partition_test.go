@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePartitionSpec(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      string
+		groupDefs []string
+		want      *deployment.PartitionSpec
+		wantErr   bool
+	}{
+		{
+			name: "two groups",
+			spec: "{b,a} | {c}",
+			want: &deployment.PartitionSpec{
+				Groups: []deployment.PartitionGroup{
+					{Name: "{a,b}", NodeIDs: []string{"a", "b"}},
+					{Name: "{c}", NodeIDs: []string{"c"}},
+				},
+				Rules: []deployment.PartitionRule{
+					{From: "{a,b}", To: "{c}", Bidirectional: true},
+				},
+			},
+		},
+		{
+			name: "three groups",
+			spec: "a|b|c",
+			want: &deployment.PartitionSpec{
+				Groups: []deployment.PartitionGroup{
+					{Name: "{a}", NodeIDs: []string{"a"}},
+					{Name: "{b}", NodeIDs: []string{"b"}},
+					{Name: "{c}", NodeIDs: []string{"c"}},
+				},
+				Rules: []deployment.PartitionRule{
+					{From: "{a}", To: "{b}", Bidirectional: true},
+					{From: "{a}", To: "{c}", Bidirectional: true},
+					{From: "{b}", To: "{c}", Bidirectional: true},
+				},
+			},
+		},
+		{
+			name:      "named one-way",
+			spec:      "west>east; west|c",
+			groupDefs: []string{"east=a,b", "west=d"},
+			want: &deployment.PartitionSpec{
+				Groups: []deployment.PartitionGroup{
+					{Name: "east", NodeIDs: []string{"a", "b"}},
+					{Name: "west", NodeIDs: []string{"d"}},
+					{Name: "{c}", NodeIDs: []string{"c"}},
+				},
+				Rules: []deployment.PartitionRule{
+					{From: "west", To: "east"},
+					{From: "west", To: "{c}", Bidirectional: true},
+				},
+			},
+		},
+		{name: "single group", spec: "a,b", wantErr: true},
+		{name: "empty group", spec: "a|", wantErr: true},
+		{name: "overlapping groups", spec: "a,b|b,c", wantErr: true},
+		{name: "invalid group def", spec: "x|y", groupDefs: []string{"x"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePartitionSpec(tt.spec, tt.groupDefs)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
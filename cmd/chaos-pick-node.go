@@ -2,9 +2,8 @@ package cmd
 
 import (
 	"fmt"
-	"slices"
 
-	"github.com/couchbaselabs/cbdinocluster/utils/clustercontrol"
+	"github.com/couchbaselabs/cbdinocluster/chaosscenario"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
@@ -23,111 +22,26 @@ var chaosPickNodeCmd = &cobra.Command{
 
 		_, _, cluster := helper.IdentifyCluster(ctx, clusterId)
 
-		// collect only cluster nodes (exclude utility nodes like load balancers)
-		type candidateNode struct {
-			ID        string
-			IPAddress string
+		// this uses the same node selection as chaos scenarios, so that the
+		// nodes picked here match those a scenario would pick.
+		selector := &chaosscenario.NodeSelector{
+			Role: chaosscenario.NodeRoleNonOrchestrator,
 		}
-
-		var candidates []candidateNode
-		for _, node := range cluster.GetNodes() {
-			if !node.IsClusterNode() {
-				continue
-			}
-			candidates = append(candidates, candidateNode{
-				ID:        node.GetID(),
-				IPAddress: node.GetIPAddress(),
-			})
-		}
-
-		if len(candidates) == 0 {
-			logger.Fatal("no cluster nodes found")
-		}
-
-		// sort for deterministic selection
-		slices.SortFunc(candidates, func(a, b candidateNode) int {
-			if a.ID < b.ID {
-				return -1
-			}
-			if a.ID > b.ID {
-				return 1
-			}
-			return 0
-		})
-
-		// query ns_server to find the orchestrator
-		firstNodeIP := candidates[0].IPAddress
-		ctrl := &clustercontrol.Controller{
-			Logger:   logger,
-			Endpoint: fmt.Sprintf("http://%s:8091", firstNodeIP),
+		if selectOrchestrator {
+			selector.Role = chaosscenario.NodeRoleOrchestrator
 		}
 
-		terseInfo, err := ctrl.GetTerseClusterInfo(ctx)
+		candidates, foundOrchestrator, err := chaosscenario.InspectNodes(ctx, logger, cluster.GetNodes(), true)
 		if err != nil {
-			logger.Fatal("failed to get terse cluster info", zap.Error(err))
+			logger.Fatal("failed to inspect cluster nodes", zap.Error(err))
 		}
 
-		orchestratorOTP := terseInfo.Orchestrator
-
-		// resolve orchestrator OTP to a node IP by querying each node
-		var orchestratorIP string
-		for _, c := range candidates {
-			nodeCtrl := &clustercontrol.Controller{
-				Logger:   logger,
-				Endpoint: fmt.Sprintf("http://%s:8091", c.IPAddress),
-			}
-
-			localInfo, err := nodeCtrl.GetLocalInfo(ctx)
-			if err != nil {
-				logger.Warn("failed to get local info for node, skipping",
-					zap.String("node", c.ID),
-					zap.Error(err))
-				continue
-			}
-
-			if localInfo.OTPNode == orchestratorOTP {
-				orchestratorIP = c.IPAddress
-				break
-			}
-		}
-
-		if selectOrchestrator {
-			// select the orchestrator specifically
-			if orchestratorIP == "" {
-				logger.Fatal("could not identify orchestrator node")
-			}
-
-			var orchestrator *candidateNode
-			for _, c := range candidates {
-				if c.IPAddress == orchestratorIP {
-					foundOrchestrator := c
-					orchestrator = &foundOrchestrator
-					break
-				}
-			}
-
-			candidates = []candidateNode{*orchestrator}
-		} else {
-			// exclude the orchestrator (default behavior)
-			if orchestratorIP != "" {
-				var filtered []candidateNode
-				for _, c := range candidates {
-					if c.IPAddress != orchestratorIP {
-						filtered = append(filtered, c)
-					}
-				}
-				candidates = filtered
-			} else {
-				logger.Warn("could not identify orchestrator node, selecting from all nodes")
-			}
-
-			if len(candidates) == 0 {
-				logger.Fatal("no eligible nodes after excluding orchestrator")
-			}
+		selected, err := selector.Select(logger, candidates, foundOrchestrator, nil)
+		if err != nil {
+			logger.Fatal("failed to pick node", zap.Error(err))
 		}
 
-		selected := candidates[0]
-		fmt.Println(selected.ID)
+		fmt.Println(selected[0].ID)
 	},
 }
 
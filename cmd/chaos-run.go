@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/couchbaselabs/cbdinocluster/chaosscenario"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var chaosRunCmd = &cobra.Command{
+	Use:   "run <cluster-id> --scenario <file>",
+	Short: "Runs a scenario of timed chaos steps against a cluster",
+	Long: `Runs a scenario of timed chaos steps against a cluster.
+
+Every action applied by the scenario is reverted when it finishes, including
+when it fails or is interrupted.  A JSON timeline of the steps is written to
+the timeline file, or to stdout if no file is specified.`,
+	Example: "chaos run {{CLUSTER_ID}} --scenario examples/chaos-scenario.yaml --timeline timeline.json",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		scenarioPath, _ := cmd.Flags().GetString("scenario")
+		timelinePath, _ := cmd.Flags().GetString("timeline")
+
+		if scenarioPath == "" {
+			logger.Fatal("a scenario file must be specified")
+		}
+
+		scenarioBytes, err := os.ReadFile(scenarioPath)
+		if err != nil {
+			logger.Fatal("failed to read scenario file", zap.Error(err))
+		}
+
+		scenario, err := chaosscenario.Parse(scenarioBytes)
+		if err != nil {
+			logger.Fatal("failed to parse scenario", zap.Error(err))
+		}
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+		runCtx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		runner := &chaosscenario.Runner{
+			Logger:    logger,
+			Deployer:  deployer,
+			ClusterID: cluster.GetID(),
+			Nodes:     cluster.GetNodes(),
+		}
+
+		timeline, runErr := runner.Run(runCtx, scenario)
+
+		if timelinePath != "" {
+			timelineBytes, err := json.MarshalIndent(timeline, "", "  ")
+			if err != nil {
+				logger.Fatal("failed to marshal timeline", zap.Error(err))
+			}
+
+			err = os.WriteFile(timelinePath, timelineBytes, 0644)
+			if err != nil {
+				logger.Fatal("failed to write timeline", zap.Error(err))
+			}
+		} else {
+			helper.OutputJson(timeline)
+		}
+
+		if runErr != nil {
+			logger.Fatal("failed to run scenario", zap.Error(runErr))
+		}
+	},
+}
+
+func init() {
+	chaosCmd.AddCommand(chaosRunCmd)
+
+	chaosRunCmd.Flags().String("scenario", "", "The path to the scenario file to run")
+	chaosRunCmd.Flags().String("timeline", "", "The path to write the JSON timeline to, defaults to stdout")
+}
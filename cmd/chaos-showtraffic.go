@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+type ChaosShowTrafficOutput []ChaosShowTrafficOutput_Node
+
+type ChaosShowTrafficOutput_Node struct {
+	ID        string   `json:"id"`
+	IPAddress string   `json:"ip_address"`
+	Rules     []string `json:"rules"`
+}
+
+var chaosShowTrafficCmd = &cobra.Command{
+	Use:   "show-traffic <cluster-id>",
+	Short: "Shows the traffic rules currently applied to each node",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		outputJson, _ := cmd.Flags().GetBool("json")
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+		nodeRules, err := deployer.ListNodeTrafficRules(ctx, cluster.GetID())
+		if err != nil {
+			logger.Fatal("failed to list node traffic rules", zap.Error(err))
+		}
+
+		if !outputJson {
+			fmt.Printf("Traffic Rules:\n")
+			for _, node := range nodeRules {
+				fmt.Printf("  %s [IP: %s]\n", node.NodeID, node.IPAddress)
+				if len(node.Rules) == 0 {
+					fmt.Printf("    (none)\n")
+				}
+				for _, rule := range node.Rules {
+					fmt.Printf("    %s\n", rule)
+				}
+			}
+		} else {
+			out := ChaosShowTrafficOutput{}
+			for _, node := range nodeRules {
+				rules := node.Rules
+				if rules == nil {
+					rules = []string{}
+				}
+
+				out = append(out, ChaosShowTrafficOutput_Node{
+					ID:        node.NodeID,
+					IPAddress: node.IPAddress,
+					Rules:     rules,
+				})
+			}
+			helper.OutputJson(out)
+		}
+	},
+}
+
+func init() {
+	chaosCmd.AddCommand(chaosShowTrafficCmd)
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"os/user"
+	"path/filepath"
 	"strings"
 	"sync"
 
@@ -119,9 +120,27 @@ func (h *CmdHelper) getDockerDeployer(ctx context.Context) (*dockerdeploy.Deploy
 	dockerHost := config.Docker.Host
 	dockerNetwork := config.Docker.Network
 
+	dockerCli, err := client.NewClientWithOpts(
+		client.WithHost(dockerHost),
+		client.WithAPIVersionNegotiation(),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to docker")
+	}
+
 	var dnsProvider dockerdeploy.DnsProvider
 	if config.DNS.Enabled.Value() {
-		if config.AWS.Enabled.ValueOr(false) {
+		if config.DNS.Provider == "local" {
+			dnsProvider, err = dockerdeploy.NewLocalDnsProvider(&dockerdeploy.LocalDnsProviderOptions{
+				Logger:      logger,
+				DockerCli:   dockerCli,
+				NetworkName: dockerNetwork,
+				Hostname:    config.DNS.Hostname,
+			})
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to initialize local dns provider")
+			}
+		} else if config.AWS.Enabled.ValueOr(false) {
 			awsCreds := h.GetAWSCredentials(ctx)
 
 			dnsProvider = &dockerdeploy.AwsDnsProvider{
@@ -135,14 +154,6 @@ func (h *CmdHelper) getDockerDeployer(ctx context.Context) (*dockerdeploy.Deploy
 		}
 	}
 
-	dockerCli, err := client.NewClientWithOpts(
-		client.WithHost(dockerHost),
-		client.WithAPIVersionNegotiation(),
-	)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to connect to docker")
-	}
-
 	deployer, err := dockerdeploy.NewDeployer(&dockerdeploy.DeployerOptions{
 		Logger:       logger,
 		DockerCli:    dockerCli,
@@ -604,6 +615,21 @@ func (h *CmdHelper) IdentifyNode(
 	return nil
 }
 
+func (h *CmdHelper) GetSnapshotPath(name string) string {
+	logger := h.GetLogger()
+
+	if name == "" || name == "." || name == ".." || filepath.Base(name) != name {
+		logger.Fatal("invalid snapshot name", zap.String("name", name))
+	}
+
+	snapshotsPath, err := cbdcconfig.SnapshotsPath()
+	if err != nil {
+		logger.Fatal("failed to get snapshots path", zap.Error(err))
+	}
+
+	return filepath.Join(snapshotsPath, name)
+}
+
 func (h *CmdHelper) OutputJson(value interface{}) {
 	out, _ := json.Marshal(value)
 	fmt.Printf("%s\n", out)
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"strings"
@@ -128,11 +129,24 @@ var connstrCmd = &cobra.Command{
 		}
 
 		if waitVisible {
+			// records served by a local dns server are not visible through the
+			// host resolver, so we query that server directly instead.
+			resolver := net.DefaultResolver
+			if connectInfo.DnsServer != "" {
+				resolver = &net.Resolver{
+					PreferGo: true,
+					Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+						var dialer net.Dialer
+						return dialer.DialContext(ctx, network, net.JoinHostPort(connectInfo.DnsServer, "53"))
+					},
+				}
+			}
+
 			for {
 				var err error
 				if connectInfo.DnsSRVName != "" {
 					var addrs []*net.SRV
-					_, addrs, err = net.LookupSRV("couchbases", "tcp", connectInfo.DnsSRVName)
+					_, addrs, err = resolver.LookupSRV(ctx, "couchbases", "tcp", connectInfo.DnsSRVName)
 					if err == nil && len(addrs) == 0 {
 						err = errors.New("no srv entries for record")
 					}
@@ -143,7 +157,7 @@ var connstrCmd = &cobra.Command{
 					}
 				} else if connectInfo.DnsAName != "" {
 					var ips []net.IP
-					ips, err = net.LookupIP(connectInfo.DnsAName)
+					ips, err = resolver.LookupIP(ctx, "ip", connectInfo.DnsAName)
 					if err == nil && len(ips) == 0 {
 						err = errors.New("no ip addresses for hostname")
 					}
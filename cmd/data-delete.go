@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/couchbaselabs/cbdinocluster/utils/dataloader"
+	"github.com/spf13/cobra"
+)
+
+var dataDeleteCmd = &cobra.Command{
+	Use:   "delete <cluster-id> <bucket-name>",
+	Short: "Deletes the documents of a previous load",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runDataCommand(cmd, args, dataloader.OperationDelete)
+	},
+}
+
+func init() {
+	dataCmd.AddCommand(dataDeleteCmd)
+
+	addDataFlags(dataDeleteCmd)
+	dataDeleteCmd.Flags().String("durability", "", "The durability level of deletes (majority, majority-and-persist-active, persist-to-majority)")
+}
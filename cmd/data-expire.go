@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/couchbaselabs/cbdinocluster/utils/dataloader"
+	"github.com/spf13/cobra"
+)
+
+var dataExpireCmd = &cobra.Command{
+	Use:   "expire <cluster-id> <bucket-name>",
+	Short: "Sets the expiry of the documents of a previous load",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runDataCommand(cmd, args, dataloader.OperationTouch)
+	},
+}
+
+func init() {
+	dataCmd.AddCommand(dataExpireCmd)
+
+	addDataFlags(dataExpireCmd)
+	dataExpireCmd.Flags().Duration("expiry", 0, "The expiry to set on the documents")
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/couchbaselabs/cbdinocluster/utils/dataloader"
+	"github.com/spf13/cobra"
+)
+
+var dataLoadCmd = &cobra.Command{
+	Use:   "load <cluster-id> <bucket-name>",
+	Short: "Loads synthetic documents into a bucket",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runDataCommand(cmd, args, dataloader.OperationUpsert)
+	},
+}
+
+func init() {
+	dataCmd.AddCommand(dataLoadCmd)
+
+	addDataFlags(dataLoadCmd)
+	addDocumentFlags(dataLoadCmd)
+}
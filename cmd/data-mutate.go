@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/couchbaselabs/cbdinocluster/utils/dataloader"
+	"github.com/spf13/cobra"
+)
+
+var dataMutateCmd = &cobra.Command{
+	Use:   "mutate <cluster-id> <bucket-name>",
+	Short: "Rewrites the documents of a previous load with new content",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runDataCommand(cmd, args, dataloader.OperationUpsert)
+	},
+}
+
+func init() {
+	dataCmd.AddCommand(dataMutateCmd)
+
+	addDataFlags(dataMutateCmd)
+	addDocumentFlags(dataMutateCmd)
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/deployment/commondeploy"
+	"github.com/couchbaselabs/cbdinocluster/utils/dataloader"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var dataCmd = &cobra.Command{
+	Use:   "data",
+	Short: "Provides the ability to load and change synthetic documents",
+	Run:   nil,
+}
+
+type DataOutput struct {
+	Operation      string           `json:"operation"`
+	Succeeded      int64            `json:"succeeded"`
+	Failed         int64            `json:"failed"`
+	ElapsedSeconds float64          `json:"elapsed_seconds"`
+	OpsPerSec      float64          `json:"ops_per_sec"`
+	Errors         map[string]int64 `json:"errors,omitempty"`
+}
+
+// addDataFlags registers the flags shared by the data commands.
+func addDataFlags(cmd *cobra.Command) {
+	cmd.Flags().String("scope", "", "The scope of the documents")
+	cmd.Flags().String("collection", "", "The collection of the documents")
+	cmd.Flags().String("count", "1000", "The number of documents, such as 1000 or 1e6")
+	cmd.Flags().Int("start", 0, "The index of the first document")
+	cmd.Flags().String("key-pattern", dataloader.DefaultKeyPattern, "The fmt pattern which formats the index of a document into its key")
+	cmd.Flags().Int("workers", 16, "The number of concurrent workers")
+	cmd.Flags().Duration("duration", 0, "Cycles through the documents until the duration has passed, rather than once")
+	cmd.Flags().Duration("report-interval", 5*time.Second, "How often to report progress")
+	cmd.Flags().String("username", "", "the database user to connect as (cloud clusters need this)")
+	cmd.Flags().String("password", "", "the password of the database user (cloud clusters need this)")
+}
+
+// addDocumentFlags registers the flags of the commands which write documents.
+func addDocumentFlags(cmd *cobra.Command) {
+	cmd.Flags().String("doc-size", "1KB", "The size of the documents, or a range of sizes such as 512B-4KB")
+	cmd.Flags().String("template", "", "The path to a text/template of the JSON documents, with .Key, .Index, .RandInt n, .RandString n and .Pick a b c")
+	cmd.Flags().String("durability", "", "The durability level of writes (majority, majority-and-persist-active, persist-to-majority)")
+	cmd.Flags().Duration("expiry", 0, "The expiry of the documents")
+}
+
+// generatorFromFlags builds the generator of the documents of a command.
+// Commands which do not write documents have no size or template flags.
+func generatorFromFlags(cmd *cobra.Command) (*dataloader.Generator, error) {
+	flags := cmd.Flags()
+
+	genOpts := &dataloader.GeneratorOptions{}
+	genOpts.KeyPattern, _ = flags.GetString("key-pattern")
+
+	if flags.Lookup("doc-size") != nil {
+		docSize, _ := flags.GetString("doc-size")
+		var err error
+		genOpts.MinSize, genOpts.MaxSize, err = dataloader.ParseSizeRange(docSize)
+		if err != nil {
+			return nil, err
+		}
+
+		templatePath, _ := flags.GetString("template")
+		if templatePath != "" {
+			templateBytes, err := os.ReadFile(templatePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read template: %w", err)
+			}
+			genOpts.Template = string(templateBytes)
+		}
+	}
+
+	return dataloader.NewGenerator(genOpts)
+}
+
+// dataOptionsFromFlags builds the options of a data command.  Flags which a
+// command does not register are left unset.
+func dataOptionsFromFlags(cmd *cobra.Command, op dataloader.Operation) (*dataloader.Options, deployment.DurabilityLevel, error) {
+	flags := cmd.Flags()
+
+	countStr, _ := flags.GetString("count")
+	count, err := dataloader.ParseCount(countStr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	generator, err := generatorFromFlags(cmd)
+	if err != nil {
+		return nil, "", err
+	}
+
+	durabilityStr, _ := flags.GetString("durability")
+	durabilityLevel, err := deployment.ParseDurabilityLevel(durabilityStr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	startIndex, _ := flags.GetInt("start")
+	workers, _ := flags.GetInt("workers")
+	duration, _ := flags.GetDuration("duration")
+	expiry, _ := flags.GetDuration("expiry")
+	reportInterval, _ := flags.GetDuration("report-interval")
+
+	if op == dataloader.OperationTouch && expiry <= 0 {
+		return nil, "", fmt.Errorf("an expiry is required")
+	}
+
+	return &dataloader.Options{
+		Operation:      op,
+		Generator:      generator,
+		StartIndex:     startIndex,
+		Count:          count,
+		Workers:        workers,
+		Duration:       duration,
+		Expiry:         expiry,
+		ReportInterval: reportInterval,
+	}, durabilityLevel, nil
+}
+
+// runDataCommand runs the operation of a data command against the bucket
+// named by its arguments.
+func runDataCommand(cmd *cobra.Command, args []string, op dataloader.Operation) {
+	helper := CmdHelper{}
+	logger := helper.GetLogger()
+	ctx := helper.GetContext()
+
+	outputJson, _ := cmd.Flags().GetBool("json")
+	scopeName, _ := cmd.Flags().GetString("scope")
+	collectionName, _ := cmd.Flags().GetString("collection")
+	username, _ := cmd.Flags().GetString("username")
+	password, _ := cmd.Flags().GetString("password")
+
+	bucketName := args[1]
+
+	opts, durabilityLevel, err := dataOptionsFromFlags(cmd, op)
+	if err != nil {
+		logger.Fatal("invalid options", zap.Error(err))
+	}
+
+	opts.OnProgress = func(progress *dataloader.Progress) {
+		logger.Info("progress",
+			zap.Duration("elapsed", progress.Elapsed.Round(time.Second)),
+			zap.Int64("succeeded", progress.Succeeded),
+			zap.Int64("failed", progress.Failed),
+			zap.String("ops-per-sec", fmt.Sprintf("%.0f", progress.OpsPerSec)))
+	}
+
+	_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+	agent, err := deployer.OpenBucketAgent(ctx, cluster.GetID(), bucketName, &deployment.OpenBucketAgentOptions{
+		Username: username,
+		Password: password,
+	})
+	if err != nil {
+		logger.Fatal("failed to connect to bucket", zap.Error(err))
+	}
+	defer agent.Close()
+
+	result, err := dataloader.Run(ctx, commondeploy.DocumentStore{
+		Agent:           agent,
+		ScopeName:       scopeName,
+		CollectionName:  collectionName,
+		DurabilityLevel: durabilityLevel,
+	}, opts)
+	if err != nil {
+		logger.Fatal("failed to run operation", zap.Error(err))
+	}
+
+	if !outputJson {
+		fmt.Printf("Succeeded: %d\n", result.Succeeded)
+		fmt.Printf("Failed: %d\n", result.Failed)
+		fmt.Printf("Elapsed: %s\n", result.Elapsed.Round(time.Millisecond))
+		fmt.Printf("Throughput: %.0f ops/s\n", result.OpsPerSec())
+		if len(result.Errors) > 0 {
+			fmt.Printf("Errors:\n")
+			for errClass, count := range result.Errors {
+				fmt.Printf("  %s: %d (%s)\n", errClass, count, result.FirstErrors[errClass])
+			}
+		}
+	} else {
+		helper.OutputJson(DataOutput{
+			Operation:      cmd.Name(),
+			Succeeded:      result.Succeeded,
+			Failed:         result.Failed,
+			ElapsedSeconds: result.Elapsed.Seconds(),
+			OpsPerSec:      result.OpsPerSec(),
+			Errors:         result.Errors,
+		})
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(dataCmd)
+}
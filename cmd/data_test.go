@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/utils/dataloader"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataOptionsFromFlags(t *testing.T) {
+	cmd := &cobra.Command{}
+	addDataFlags(cmd)
+	addDocumentFlags(cmd)
+	cmd.Flags().Set("count", "1e6")
+	cmd.Flags().Set("start", "100")
+	cmd.Flags().Set("doc-size", "512B-2KB")
+	cmd.Flags().Set("durability", "majority")
+	cmd.Flags().Set("expiry", "1h")
+
+	opts, durabilityLevel, err := dataOptionsFromFlags(cmd, dataloader.OperationUpsert)
+	require.NoError(t, err)
+	require.Equal(t, 1000000, opts.Count)
+	require.Equal(t, 100, opts.StartIndex)
+	require.Equal(t, 16, opts.Workers)
+	require.Equal(t, time.Hour, opts.Expiry)
+	require.Equal(t, "doc-100", opts.Generator.Key(100))
+	require.Equal(t, deployment.DurabilityLevelMajority, durabilityLevel)
+}
+
+func TestDataOptionsFromFlagsInvalid(t *testing.T) {
+	cmd := &cobra.Command{}
+	addDataFlags(cmd)
+	addDocumentFlags(cmd)
+	cmd.Flags().Set("doc-size", "huge")
+
+	_, _, err := dataOptionsFromFlags(cmd, dataloader.OperationUpsert)
+	require.Error(t, err)
+
+	// expiring documents requires an expiry
+	cmd = &cobra.Command{}
+	addDataFlags(cmd)
+	cmd.Flags().Duration("expiry", 0, "")
+
+	_, _, err = dataOptionsFromFlags(cmd, dataloader.OperationTouch)
+	require.Error(t, err)
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var indexesAddCmd = &cobra.Command{
+	Use:   "add <cluster-id> <bucket-name> <index-name>",
+	Short: "Creates a GSI index, or a search index when a search definition is given",
+	Example: "indexes add <cluster-id> travel-sample idx_city --scope inventory --collection hotel --key city\n" +
+		"indexes add <cluster-id> travel-sample idx_primary --primary --deferred\n" +
+		"indexes add <cluster-id> travel-sample fts_hotels --search-def-file hotels.json",
+	Args: cobra.MinimumNArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		bucketName := args[1]
+		indexName := args[2]
+
+		scopeName, _ := cmd.Flags().GetString("scope")
+		collectionName, _ := cmd.Flags().GetString("collection")
+		primary, _ := cmd.Flags().GetBool("primary")
+		keys, _ := cmd.Flags().GetStringArray("key")
+		where, _ := cmd.Flags().GetString("where")
+		numReplicas, _ := cmd.Flags().GetInt("num-replicas")
+		partitionBy, _ := cmd.Flags().GetStringArray("partition-by")
+		numPartitions, _ := cmd.Flags().GetInt("num-partitions")
+		deferred, _ := cmd.Flags().GetBool("deferred")
+		searchDef, _ := cmd.Flags().GetString("search-def")
+		searchDefFile, _ := cmd.Flags().GetString("search-def-file")
+		wait, _ := cmd.Flags().GetBool("wait")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		if searchDefFile != "" {
+			searchDefBytes, err := os.ReadFile(searchDefFile)
+			if err != nil {
+				logger.Fatal("failed to read search definition", zap.Error(err))
+			}
+			searchDef = string(searchDefBytes)
+		}
+
+		var indexOpts *deployment.CreateIndexOptions
+		var searchIndexOpts *deployment.CreateSearchIndexOptions
+		if searchDef != "" {
+			opts, err := deployment.NewCreateSearchIndexOptions(bucketName, &clusterdef.SearchIndex{
+				Name:       indexName,
+				Definition: searchDef,
+			})
+			if err != nil {
+				logger.Fatal("invalid search index", zap.Error(err))
+			}
+			searchIndexOpts = opts
+		} else {
+			opts, err := deployment.NewCreateIndexOptions(bucketName, &clusterdef.Index{
+				Name:          indexName,
+				Scope:         scopeName,
+				Collection:    collectionName,
+				Primary:       primary,
+				Keys:          keys,
+				Where:         where,
+				NumReplicas:   numReplicas,
+				PartitionBy:   partitionBy,
+				NumPartitions: numPartitions,
+				Deferred:      deferred,
+			})
+			if err != nil {
+				logger.Fatal("invalid index", zap.Error(err))
+			}
+			indexOpts = opts
+		}
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+		if searchIndexOpts != nil {
+			err := deployer.CreateSearchIndex(ctx, cluster.GetID(), searchIndexOpts)
+			if err != nil {
+				logger.Fatal("failed to create search index", zap.Error(err))
+			}
+		} else {
+			err := deployer.CreateIndex(ctx, cluster.GetID(), indexOpts)
+			if err != nil {
+				logger.Fatal("failed to create index", zap.Error(err))
+			}
+		}
+
+		if wait {
+			err := deployment.WaitForIndexes(ctx, logger, deployer, cluster.GetID(), timeout)
+			if err != nil {
+				logger.Fatal("failed to wait for indexes", zap.Error(err))
+			}
+		}
+	},
+}
+
+func init() {
+	indexesCmd.AddCommand(indexesAddCmd)
+
+	indexesAddCmd.Flags().String("scope", "", "The scope of the index")
+	indexesAddCmd.Flags().String("collection", "", "The collection of the index")
+	indexesAddCmd.Flags().Bool("primary", false, "Creates a primary index")
+	indexesAddCmd.Flags().StringArray("key", nil, "An index key expression, may be repeated")
+	indexesAddCmd.Flags().String("where", "", "The condition of a partial index")
+	indexesAddCmd.Flags().Int("num-replicas", 0, "The number of replicas of the index")
+	indexesAddCmd.Flags().StringArray("partition-by", nil, "An expression to hash partition the index by, may be repeated")
+	indexesAddCmd.Flags().Int("num-partitions", 0, "The number of partitions of a partitioned index")
+	indexesAddCmd.Flags().Bool("deferred", false, "Defers building the index until it is built explicitly")
+	indexesAddCmd.Flags().String("search-def", "", "The JSON definition of a search index to create")
+	indexesAddCmd.Flags().String("search-def-file", "", "The path to a file containing the JSON definition of a search index")
+	indexesAddCmd.Flags().Bool("wait", false, "Waits for the index to come online")
+	indexesAddCmd.Flags().Duration("timeout", deployment.DefaultIndexWaitTimeout, "How long to wait for the index to come online")
+}
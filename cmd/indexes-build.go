@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var indexesBuildCmd = &cobra.Command{
+	Use:   "build <cluster-id> <bucket-name> [index-name...]",
+	Short: "Builds deferred indexes, or all the deferred indexes of a collection when none are named",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		bucketName := args[1]
+		indexNames := args[2:]
+
+		scopeName, _ := cmd.Flags().GetString("scope")
+		collectionName, _ := cmd.Flags().GetString("collection")
+		wait, _ := cmd.Flags().GetBool("wait")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		keyspace := deployment.Keyspace{
+			Bucket:     bucketName,
+			Scope:      scopeName,
+			Collection: collectionName,
+		}
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+		if len(indexNames) == 0 {
+			indexes, err := deployer.ListIndexes(ctx, cluster.GetID())
+			if err != nil {
+				logger.Fatal("failed to list indexes", zap.Error(err))
+			}
+
+			for _, index := range indexes {
+				if index.Keyspace.String() == keyspace.String() && index.State == deployment.IndexStateDeferred {
+					indexNames = append(indexNames, index.Name)
+				}
+			}
+
+			if len(indexNames) == 0 {
+				logger.Info("no deferred indexes to build", zap.String("keyspace", keyspace.String()))
+				return
+			}
+		}
+
+		err := deployer.BuildIndexes(ctx, cluster.GetID(), keyspace, indexNames)
+		if err != nil {
+			logger.Fatal("failed to build indexes", zap.Error(err))
+		}
+
+		if wait {
+			err := deployment.WaitForIndexes(ctx, logger, deployer, cluster.GetID(), timeout)
+			if err != nil {
+				logger.Fatal("failed to wait for indexes", zap.Error(err))
+			}
+		}
+	},
+}
+
+func init() {
+	indexesCmd.AddCommand(indexesBuildCmd)
+
+	indexesBuildCmd.Flags().String("scope", "", "The scope of the indexes")
+	indexesBuildCmd.Flags().String("collection", "", "The collection of the indexes")
+	indexesBuildCmd.Flags().Bool("wait", false, "Waits for the indexes to come online")
+	indexesBuildCmd.Flags().Duration("timeout", deployment.DefaultIndexWaitTimeout, "How long to wait for the indexes to come online")
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var indexesDropCmd = &cobra.Command{
+	Use:     "drop <cluster-id> <bucket-name> <index-name>",
+	Aliases: []string{"remove", "rm"},
+	Short:   "Drops a GSI or search index",
+	Args:    cobra.MinimumNArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		bucketName := args[1]
+		indexName := args[2]
+
+		scopeName, _ := cmd.Flags().GetString("scope")
+		collectionName, _ := cmd.Flags().GetString("collection")
+		search, _ := cmd.Flags().GetBool("search")
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+		if search {
+			err := deployer.DropSearchIndex(ctx, cluster.GetID(), indexName)
+			if err != nil {
+				logger.Fatal("failed to drop search index", zap.Error(err))
+			}
+			return
+		}
+
+		err := deployer.DropIndex(ctx, cluster.GetID(), deployment.Keyspace{
+			Bucket:     bucketName,
+			Scope:      scopeName,
+			Collection: collectionName,
+		}, indexName)
+		if err != nil {
+			logger.Fatal("failed to drop index", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	indexesCmd.AddCommand(indexesDropCmd)
+
+	indexesDropCmd.Flags().String("scope", "", "The scope of the index")
+	indexesDropCmd.Flags().String("collection", "", "The collection of the index")
+	indexesDropCmd.Flags().Bool("search", false, "Drops a search index rather than a GSI index")
+}
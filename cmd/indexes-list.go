@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+type IndexesListOutput struct {
+	Indexes       []IndexesListOutput_Index       `json:"indexes"`
+	SearchIndexes []IndexesListOutput_SearchIndex `json:"search_indexes"`
+}
+
+type IndexesListOutput_Index struct {
+	Name       string   `json:"name"`
+	Bucket     string   `json:"bucket"`
+	Scope      string   `json:"scope"`
+	Collection string   `json:"collection"`
+	IsPrimary  bool     `json:"is_primary"`
+	Keys       []string `json:"keys,omitempty"`
+	Condition  string   `json:"condition,omitempty"`
+	Partition  string   `json:"partition,omitempty"`
+	State      string   `json:"state"`
+}
+
+type IndexesListOutput_SearchIndex struct {
+	Name   string `json:"name"`
+	Bucket string `json:"bucket"`
+	Ready  bool   `json:"ready"`
+}
+
+var indexesListCmd = &cobra.Command{
+	Use:     "list <cluster-id>",
+	Aliases: []string{"ls"},
+	Short:   "Lists the GSI and search indexes of a cluster",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		outputJson, _ := cmd.Flags().GetBool("json")
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+		indexes, err := deployer.ListIndexes(ctx, cluster.GetID())
+		if err != nil {
+			logger.Fatal("failed to list indexes", zap.Error(err))
+		}
+
+		searchIndexes, err := deployer.ListSearchIndexes(ctx, cluster.GetID())
+		if err != nil && !errors.Is(err, deployment.ErrNotSupported) {
+			logger.Fatal("failed to list search indexes", zap.Error(err))
+		}
+
+		if !outputJson {
+			fmt.Printf("Indexes:\n")
+			for _, index := range indexes {
+				fmt.Printf("  %s on %s [%s]\n", index.Name, index.Keyspace, index.State)
+				if index.IsPrimary {
+					fmt.Printf("    primary\n")
+				}
+				if len(index.Keys) > 0 {
+					fmt.Printf("    keys: %s\n", strings.Join(index.Keys, ", "))
+				}
+				if index.Condition != "" {
+					fmt.Printf("    where: %s\n", index.Condition)
+				}
+				if index.Partition != "" {
+					fmt.Printf("    partition: %s\n", index.Partition)
+				}
+			}
+
+			fmt.Printf("Search Indexes:\n")
+			for _, index := range searchIndexes {
+				state := "building"
+				if index.Ready {
+					state = "ready"
+				}
+				fmt.Printf("  %s on %s [%s]\n", index.Name, index.SourceName, state)
+			}
+		} else {
+			out := IndexesListOutput{
+				Indexes:       []IndexesListOutput_Index{},
+				SearchIndexes: []IndexesListOutput_SearchIndex{},
+			}
+			for _, index := range indexes {
+				out.Indexes = append(out.Indexes, IndexesListOutput_Index{
+					Name:       index.Name,
+					Bucket:     index.Keyspace.Bucket,
+					Scope:      index.Keyspace.ScopeName(),
+					Collection: index.Keyspace.CollectionName(),
+					IsPrimary:  index.IsPrimary,
+					Keys:       index.Keys,
+					Condition:  index.Condition,
+					Partition:  index.Partition,
+					State:      index.State,
+				})
+			}
+			for _, index := range searchIndexes {
+				out.SearchIndexes = append(out.SearchIndexes, IndexesListOutput_SearchIndex{
+					Name:   index.Name,
+					Bucket: index.SourceName,
+					Ready:  index.Ready,
+				})
+			}
+			helper.OutputJson(out)
+		}
+	},
+}
+
+func init() {
+	indexesCmd.AddCommand(indexesListCmd)
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var indexesWaitCmd = &cobra.Command{
+	Use:   "wait <cluster-id>",
+	Short: "Waits until all the indexes of a cluster which are not deferred are online",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+		err := deployment.WaitForIndexes(ctx, logger, deployer, cluster.GetID(), timeout)
+		if err != nil {
+			logger.Fatal("failed to wait for indexes", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	indexesCmd.AddCommand(indexesWaitCmd)
+
+	indexesWaitCmd.Flags().Duration("timeout", deployment.DefaultIndexWaitTimeout, "How long to wait for the indexes to come online")
+}
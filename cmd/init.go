@@ -1493,14 +1493,17 @@ var initCmd = &cobra.Command{
 
 		printDnsConfig := func() {
 			fmt.Printf("  Enabled: %t\n", curConfig.DNS.Enabled.Value())
+			fmt.Printf("  Provider: %s\n", curConfig.DNS.Provider)
 			fmt.Printf("  Hostname: %s\n", curConfig.DNS.Hostname)
 		}
 		{
 			flagDisableDns, _ := cmd.Flags().GetBool("disable-dns")
 			flagDnsHostname, _ := cmd.Flags().GetString("dns-hostname")
+			flagDnsProvider, _ := cmd.Flags().GetString("dns-provider")
 
 			dnsEnabled := curConfig.DNS.Enabled.ValueOr(true)
 			dnsHostname := curConfig.DNS.Hostname
+			dnsProvider := curConfig.DNS.Provider
 
 			for {
 				if flagDisableDns {
@@ -1516,6 +1519,60 @@ var initCmd = &cobra.Command{
 					break
 				}
 
+				if dnsProvider == "" {
+					if curConfig.AWS.Enabled.ValueOr(false) {
+						dnsProvider = "aws"
+					} else {
+						dnsProvider = "local"
+					}
+				}
+
+				if flagDnsProvider != "" {
+					fmt.Printf("DNS provider specified via flags:\n  %s\n", flagDnsProvider)
+					dnsProvider = flagDnsProvider
+				} else {
+					dnsProvider = readString(
+						"Which DNS provider should we use (aws or local)?",
+						dnsProvider, false)
+				}
+
+				if dnsProvider == "local" {
+					if !curConfig.Docker.Enabled.Value() {
+						fmt.Printf("Docker must be configured to use local DNS.\n")
+						dnsEnabled = false
+						break
+					}
+
+					if flagDnsHostname != "" {
+						fmt.Printf("DNS hostname specified via flags:\n  %s\n", flagDnsHostname)
+						dnsHostname = flagDnsHostname
+					} else {
+						if dnsHostname == "" {
+							dnsHostname = "dinocluster.test"
+						}
+
+						dnsHostname = readString(
+							"What DNS hostname should we use?",
+							dnsHostname, false)
+					}
+					if dnsHostname == "" {
+						fmt.Printf("The DNS hostname is required.\n")
+						dnsEnabled = false
+						continue
+					}
+
+					fmt.Printf("Records will be served by a DNS server container on the docker network.\n")
+					break
+				} else if dnsProvider != "aws" {
+					fmt.Printf("Unknown DNS provider: %s\n", dnsProvider)
+					dnsProvider = ""
+					if flagDnsProvider != "" {
+						dnsEnabled = false
+						break
+					}
+					continue
+				}
+
 				if !curConfig.AWS.Enabled.ValueOr(false) {
 					fmt.Printf("AWS must be configured to use DNS.\n")
 					dnsEnabled = false
@@ -1580,6 +1637,7 @@ var initCmd = &cobra.Command{
 
 			curConfig.DNS.Enabled.Set(dnsEnabled)
 			curConfig.DNS.Hostname = dnsHostname
+			curConfig.DNS.Provider = dnsProvider
 			saveConfig()
 		}
 
@@ -1693,5 +1751,6 @@ func init() {
 	initCmd.Flags().String("gcp-project-id", "", "The GCP project id to use")
 	initCmd.Flags().Bool("disable-dns", false, "Disable DNS")
 	initCmd.Flags().String("dns-hostname", "", "DNS hostname prefix to use")
+	initCmd.Flags().String("dns-provider", "", "DNS provider to use (aws or local)")
 	initCmd.Flags().String("upload-server-logs-host-name", "", "Upload server logs host name")
 }
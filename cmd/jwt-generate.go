@@ -1,91 +1,146 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
-	"github.com/couchbaselabs/cbdinocluster/utils/dinocerts"
+	"github.com/couchbaselabs/cbdinocluster/utils/dinooidc"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
-
-	jwt "github.com/golang-jwt/jwt/v5"
 )
 
-type customJwtClaims struct {
-	Roles []string `json:"roles"`
-	jwt.RegisteredClaims
-}
-
 var jwtGenerateCmd = &cobra.Command{
 	Use:   "generate <username>",
 	Short: "Fetches a JWT token for a specific set of roles",
-	Args:  cobra.MinimumNArgs(1),
+	Long: `Fetches a JWT token for a specific set of roles.
+
+Tokens are signed by the active key of the issuer, which is the dino issuer
+unless another is specified.  Roles can be given explicitly with --role,
+otherwise they are determined by --can-read and --can-write.`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		helper := CmdHelper{}
 		logger := helper.GetLogger()
+		ctx := helper.GetContext()
 
 		username := args[0]
 		canRead, _ := cmd.Flags().GetBool("can-read")
 		canWrite, _ := cmd.Flags().GetBool("can-write")
 		expiresInStr, _ := cmd.Flags().GetString("expires-in")
+		issuerName, _ := cmd.Flags().GetString("issuer")
+		roles, _ := cmd.Flags().GetStringArray("role")
+		groups, _ := cmd.Flags().GetStringArray("group")
+		audiences, _ := cmd.Flags().GetStringArray("audience")
+		notBeforeSkew, _ := cmd.Flags().GetDuration("not-before-skew")
+		issuedAtSkew, _ := cmd.Flags().GetDuration("issued-at-skew")
+		claimSpecs, _ := cmd.Flags().GetStringArray("claim")
+		keyGeneration, _ := cmd.Flags().GetInt("key-generation")
 
-		var expiresInClaim *jwt.NumericDate
+		var expiresIn time.Duration
 		if expiresInStr == "none" {
-			// leave the expiresInClaim as nil
+			// leave expiresIn at 0, which omits the expiry claim
 		} else {
-			expiresIn, err := time.ParseDuration(expiresInStr)
+			parsedExpiresIn, err := time.ParseDuration(expiresInStr)
 			if err != nil {
 				logger.Fatal("failed to parse expires-in duration", zap.Error(err))
 			}
 
-			expiresInClaim = jwt.NewNumericDate(time.Now().Add(expiresIn))
+			expiresIn = parsedExpiresIn
 		}
 
-		var roles []string
-		if canWrite {
-			roles = append(roles, "admin")
-		} else if canRead {
-			roles = append(roles,
-				"ro_admin",
-				"analytics_reader",
-				"data_reader[*]",
-				"views_reader[*]",
-				"query_select[*]",
-				"fts_searcher[*]")
+		if !cmd.Flags().Changed("role") {
+			if canWrite {
+				roles = append(roles, "admin")
+			} else if canRead {
+				roles = append(roles,
+					"ro_admin",
+					"analytics_reader",
+					"data_reader[*]",
+					"views_reader[*]",
+					"query_select[*]",
+					"fts_searcher[*]")
+			}
 		}
 
-		rootCa, err := dinocerts.GetRootCertAuthority()
-		if err != nil {
-			logger.Fatal("failed to get dino certificate authority", zap.Error(err))
+		claims := make(map[string]any)
+		for _, claimSpec := range claimSpecs {
+			name, value, err := parseJwtClaim(claimSpec)
+			if err != nil {
+				logger.Fatal("failed to parse claim", zap.Error(err))
+			}
+
+			claims[name] = value
 		}
 
-		_, privKey, err := rootCa.GetRS256SigningKeys()
+		// the default issuer can be used without docker, since its keys are
+		// only changed by rotating them through docker.
+		issuer := dinooidc.DefaultIssuer()
+		deployer, err := helper.getDockerDeployer(ctx)
 		if err != nil {
-			logger.Fatal("failed to get jwt signing keys", zap.Error(err))
+			logger.Fatal("failed to get docker deployer", zap.Error(err))
+		}
+		if deployer != nil {
+			issuer, err = deployer.OidcIssuer().GetIssuer(ctx, issuerName)
+			if err != nil {
+				logger.Fatal("failed to get jwt issuer", zap.Error(err))
+			}
+		} else if issuerName != dinooidc.DefaultIssuerName {
+			logger.Fatal("docker must be configured to use issuers other than the default")
+		}
+
+		tokenOpts := &dinooidc.TokenOptions{
+			Subject:       username,
+			Roles:         roles,
+			Groups:        groups,
+			Audiences:     audiences,
+			ExpiresIn:     expiresIn,
+			NotBeforeSkew: notBeforeSkew,
+			IssuedAtSkew:  issuedAtSkew,
+			Claims:        claims,
+		}
+		if keyGeneration >= 0 {
+			tokenOpts.KeyGeneration = &keyGeneration
 		}
 
-		token := jwt.NewWithClaims(jwt.SigningMethodRS256, &customJwtClaims{
-			Roles: roles,
-			RegisteredClaims: jwt.RegisteredClaims{
-				Issuer:    "dino",
-				Subject:   username,
-				Audience:  []string{"client"},
-				ExpiresAt: expiresInClaim,
-			},
-		})
-
-		signedToken, err := token.SignedString(privKey)
+		signedToken, err := dinooidc.IssueToken(issuer, tokenOpts)
 		if err != nil {
-			logger.Fatal("failed to sign token", zap.Error(err))
+			logger.Fatal("failed to generate token", zap.Error(err))
 		}
 
 		fmt.Printf("%s\n", signedToken)
 	},
 }
 
+// parseJwtClaim parses a name=value claim, where the value is decoded as json
+// if possible so that numbers, booleans and arrays can be specified.
+func parseJwtClaim(spec string) (string, any, error) {
+	name, valueStr, ok := strings.Cut(spec, "=")
+	if !ok || name == "" {
+		return "", nil, fmt.Errorf("invalid claim `%s`, expected name=value", spec)
+	}
+
+	var value any
+	err := json.Unmarshal([]byte(valueStr), &value)
+	if err != nil {
+		return name, valueStr, nil
+	}
+
+	return name, value, nil
+}
+
 func init() {
 	jwtCmd.AddCommand(jwtGenerateCmd)
 	jwtGenerateCmd.Flags().Bool("can-read", true, "Whether the token can read data")
 	jwtGenerateCmd.Flags().Bool("can-write", true, "Whether the token can write data")
 	jwtGenerateCmd.Flags().String("expires-in", "8766h", "How long before the token expires (e.g. 24h, 30m, 10s, -1h) or 'none' for no expiration claim")
+	jwtGenerateCmd.Flags().String("issuer", dinooidc.DefaultIssuerName, "The issuer which signs the token")
+	jwtGenerateCmd.Flags().StringArray("role", nil, "A role to include in the token, overriding --can-read and --can-write")
+	jwtGenerateCmd.Flags().StringArray("group", nil, "A group to include in the token")
+	jwtGenerateCmd.Flags().StringArray("audience", nil, "An audience of the token, defaults to the audiences of the issuer")
+	jwtGenerateCmd.Flags().Duration("not-before-skew", 0, "Offset of the nbf claim from now (e.g. 1h for a token which is not yet valid)")
+	jwtGenerateCmd.Flags().Duration("issued-at-skew", 0, "Offset of the iat claim from now")
+	jwtGenerateCmd.Flags().StringArray("claim", nil, "An additional claim in the form name=value, where value may be json")
+	jwtGenerateCmd.Flags().Int("key-generation", -1, "Sign with a specific key generation of the issuer rather than its active key")
 }
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJwtClaim(t *testing.T) {
+	testCases := []struct {
+		spec    string
+		name    string
+		value   any
+		isError bool
+	}{
+		{"tenant=acme", "tenant", "acme", false},
+		{"level=3", "level", float64(3), false},
+		{"admin=true", "admin", true, false},
+		{`scopes=["a","b"]`, "scopes", []any{"a", "b"}, false},
+		{"url=http://a=b", "url", "http://a=b", false},
+		{"empty=", "empty", "", false},
+		{"noequals", "", nil, true},
+		{"=value", "", nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			name, value, err := parseJwtClaim(tc.spec)
+			if tc.isError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.name, name)
+			require.Equal(t, tc.value, value)
+		})
+	}
+}
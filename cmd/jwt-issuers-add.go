@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var jwtIssuersAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Adds a JWT issuer",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		audiences, _ := cmd.Flags().GetStringArray("audience")
+
+		deployer := helper.GetDockerDeployer(ctx)
+
+		_, err := deployer.OidcIssuer().AddIssuer(ctx, args[0], audiences)
+		if err != nil {
+			logger.Fatal("failed to add jwt issuer", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	jwtIssuersCmd.AddCommand(jwtIssuersAddCmd)
+	jwtIssuersAddCmd.Flags().StringArray("audience", nil, "An audience clusters accept from the issuer, defaults to client")
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/couchbaselabs/cbdinocluster/utils/dinooidc"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+type JwtIssuersListOutput []JwtIssuersListOutput_Item
+
+type JwtIssuersListOutput_Item struct {
+	Name      string   `json:"name"`
+	Audiences []string `json:"audiences"`
+	KeyIDs    []string `json:"key_ids"`
+	ActiveKey string   `json:"active_key"`
+}
+
+var jwtIssuersListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "Lists the JWT issuers and their keys",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		outputJson, _ := cmd.Flags().GetBool("json")
+
+		deployer := helper.GetDockerDeployer(ctx)
+
+		issuers, err := deployer.OidcIssuer().ListIssuers(ctx)
+		if err != nil {
+			logger.Fatal("failed to list jwt issuers", zap.Error(err))
+		}
+
+		out := JwtIssuersListOutput{}
+		for _, issuer := range issuers {
+			var keyIDs []string
+			for _, generation := range issuer.KeyGenerations {
+				keyIDs = append(keyIDs, dinooidc.KeyID(issuer.Name, generation))
+			}
+
+			out = append(out, JwtIssuersListOutput_Item{
+				Name:      issuer.Name,
+				Audiences: issuer.Audiences,
+				KeyIDs:    keyIDs,
+				ActiveKey: dinooidc.KeyID(issuer.Name, issuer.ActiveKeyGeneration),
+			})
+		}
+
+		if !outputJson {
+			fmt.Printf("Issuers:\n")
+			for _, item := range out {
+				fmt.Printf("  %s [Audiences: %s, Active Key: %s, Keys: %s]\n",
+					item.Name,
+					strings.Join(item.Audiences, ", "),
+					item.ActiveKey,
+					strings.Join(item.KeyIDs, ", "))
+			}
+		} else {
+			helper.OutputJson(out)
+		}
+	},
+}
+
+func init() {
+	jwtIssuersCmd.AddCommand(jwtIssuersListCmd)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/couchbaselabs/cbdinocluster/utils/dinooidc"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var jwtIssuersRotateKeyCmd = &cobra.Command{
+	Use:   "rotate-key <name>",
+	Short: "Makes a new signing key active for a JWT issuer",
+	Long: `Makes a new signing key active for a JWT issuer.
+
+The previous keys remain published in the JWKS of the issuer so tokens signed
+with them are still accepted, unless --drop-old is specified.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		dropOld, _ := cmd.Flags().GetBool("drop-old")
+
+		deployer := helper.GetDockerDeployer(ctx)
+
+		issuer, err := deployer.OidcIssuer().RotateIssuerKey(ctx, args[0], dropOld)
+		if err != nil {
+			logger.Fatal("failed to rotate jwt issuer key", zap.Error(err))
+		}
+
+		fmt.Printf("%s\n", dinooidc.KeyID(issuer.Name, issuer.ActiveKeyGeneration))
+	},
+}
+
+func init() {
+	jwtIssuersCmd.AddCommand(jwtIssuersRotateKeyCmd)
+	jwtIssuersRotateKeyCmd.Flags().Bool("drop-old", false, "Stop publishing the previous keys of the issuer")
+}
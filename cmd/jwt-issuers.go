@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var jwtIssuersCmd = &cobra.Command{
+	Use:   "issuers",
+	Short: "Manages the local JWT issuers which jwt-enabled clusters trust",
+	Long: `Manages the local JWT issuers which jwt-enabled clusters trust.
+
+The issuers publish their keys from JWKS endpoints on the docker network,
+which clusters allocated with jwt enabled are configured against.  Rotating
+the key of an issuer updates its JWKS without needing to reconfigure clusters,
+but clusters need to be set up again with 'jwt setup' to trust new issuers.`,
+	Run: nil,
+}
+
+func init() {
+	jwtCmd.AddCommand(jwtIssuersCmd)
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/couchbaselabs/cbdinocluster/deployment/dockerdeploy"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var jwtSetupCmd = &cobra.Command{
+	Use:   "setup <cluster-id>",
+	Short: "Configures a cluster to trust the current JWT issuers",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+		dockerDeployer, ok := deployer.(*dockerdeploy.Deployer)
+		if !ok {
+			logger.Fatal("jwt setup is only supported for docker clusters")
+		}
+
+		err := dockerDeployer.SetupJwt(ctx, cluster.GetID())
+		if err != nil {
+			logger.Fatal("failed to setup jwt authentication", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	jwtCmd.AddCommand(jwtSetupCmd)
+}
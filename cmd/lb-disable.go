@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"github.com/couchbaselabs/cbdinocluster/deployment/dockerdeploy"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var lbDisableCmd = &cobra.Command{
+	Use:   "disable <cluster-id> <node-id-or-ip>",
+	Short: "Disables a node, putting it into maintenance so it receives no traffic",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		deployer, cluster := getLbDeployer(ctx, &helper, args[0])
+		node := helper.IdentifyNode(ctx, cluster, args[1])
+
+		err := deployer.SetLoadBalancerServerState(ctx, cluster.GetID(), node.GetID(), dockerdeploy.LoadBalancerServerStateMaint)
+		if err != nil {
+			logger.Fatal("failed to disable node", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	lbCmd.AddCommand(lbDisableCmd)
+}
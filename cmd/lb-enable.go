@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"github.com/couchbaselabs/cbdinocluster/deployment/dockerdeploy"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var lbEnableCmd = &cobra.Command{
+	Use:   "enable <cluster-id> <node-id-or-ip>",
+	Short: "Re-enables a node which was drained or disabled",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		deployer, cluster := getLbDeployer(ctx, &helper, args[0])
+		node := helper.IdentifyNode(ctx, cluster, args[1])
+
+		err := deployer.SetLoadBalancerServerState(ctx, cluster.GetID(), node.GetID(), dockerdeploy.LoadBalancerServerStateReady)
+		if err != nil {
+			logger.Fatal("failed to enable node", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	lbCmd.AddCommand(lbEnableCmd)
+}
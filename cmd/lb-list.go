@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+type LbListOutput []LbListOutput_Item
+
+type LbListOutput_Item struct {
+	Backend     string `json:"backend"`
+	NodeID      string `json:"node_id"`
+	Address     string `json:"address"`
+	Status      string `json:"status"`
+	CheckStatus string `json:"check_status"`
+	Weight      int    `json:"weight"`
+	Sessions    int    `json:"sessions"`
+}
+
+var lbListCmd = &cobra.Command{
+	Use:     "list <cluster-id>",
+	Aliases: []string{"ls"},
+	Short:   "Lists the state of each node behind the load balancer",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		outputJson, _ := cmd.Flags().GetBool("json")
+
+		deployer, cluster := getLbDeployer(ctx, &helper, args[0])
+
+		servers, err := deployer.ListLoadBalancerServers(ctx, cluster.GetID())
+		if err != nil {
+			logger.Fatal("failed to list load balancer servers", zap.Error(err))
+		}
+
+		if !outputJson {
+			fmt.Printf("Load Balancer Servers:\n")
+			lastBackend := ""
+			for _, server := range servers {
+				if server.Backend != lastBackend {
+					fmt.Printf("  %s\n", server.Backend)
+					lastBackend = server.Backend
+				}
+
+				fmt.Printf("    %s [Address: %s, Status: %s, Check: %s, Weight: %d, Sessions: %d]\n",
+					server.NodeID,
+					server.Address,
+					server.Status,
+					server.CheckStatus,
+					server.Weight,
+					server.Sessions)
+			}
+		} else {
+			out := LbListOutput{}
+			for _, server := range servers {
+				out = append(out, LbListOutput_Item{
+					Backend:     server.Backend,
+					NodeID:      server.NodeID,
+					Address:     server.Address,
+					Status:      server.Status,
+					CheckStatus: server.CheckStatus,
+					Weight:      server.Weight,
+					Sessions:    server.Sessions,
+				})
+			}
+			helper.OutputJson(out)
+		}
+	},
+}
+
+func init() {
+	lbCmd.AddCommand(lbListCmd)
+}
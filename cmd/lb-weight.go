@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var lbWeightCmd = &cobra.Command{
+	Use:   "weight <cluster-id> <node-id-or-ip> <weight>",
+	Short: "Sets the share of new connections a node receives, from 0 to 256",
+	Long: `Sets the share of new connections a node receives, from 0 to 256.
+
+Nodes have a weight of 1 by default, so giving a node a weight of 0 stops it
+receiving new connections, and giving the other nodes a higher weight starves
+it of traffic without taking it out of rotation.`,
+	Example: "lb weight a1b2c3 10.0.0.2 0",
+	Args:    cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		weight, err := strconv.Atoi(args[2])
+		if err != nil {
+			logger.Fatal("invalid weight", zap.Error(err))
+		}
+
+		deployer, cluster := getLbDeployer(ctx, &helper, args[0])
+		node := helper.IdentifyNode(ctx, cluster, args[1])
+
+		err = deployer.SetLoadBalancerServerWeight(ctx, cluster.GetID(), node.GetID(), weight)
+		if err != nil {
+			logger.Fatal("failed to set node weight", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	lbCmd.AddCommand(lbWeightCmd)
+}
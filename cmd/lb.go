@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/deployment/dockerdeploy"
+	"github.com/spf13/cobra"
+)
+
+var lbCmd = &cobra.Command{
+	Use:   "lb",
+	Short: "Provides the ability to control the active load balancer of a cluster",
+	Long: `Provides the ability to control the active load balancer of a cluster.
+
+These commands change the state of nodes behind the haproxy load balancer of
+a docker cluster allocated with active-load-balancer enabled.  The changes are
+made at runtime and are reset when the load balancer config is rewritten, such
+as when nodes are added or removed.`,
+	Run: nil,
+}
+
+// getLbDeployer identifies a cluster and the docker deployer which owns it,
+// since only docker clusters have an active load balancer.
+func getLbDeployer(ctx context.Context, helper *CmdHelper, clusterIdent string) (*dockerdeploy.Deployer, deployment.ClusterInfo) {
+	logger := helper.GetLogger()
+
+	_, deployer, cluster := helper.IdentifyCluster(ctx, clusterIdent)
+
+	dockerDeployer, ok := deployer.(*dockerdeploy.Deployer)
+	if !ok {
+		logger.Fatal("load balancer control is only supported for docker clusters")
+	}
+
+	return dockerDeployer, cluster
+}
+
+func init() {
+	rootCmd.AddCommand(lbCmd)
+}
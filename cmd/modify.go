@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/couchbaselabs/cbdinocluster/deployment"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
@@ -26,6 +27,11 @@ var modifyCmd = &cobra.Command{
 			logger.Fatal("failed to get definition", zap.Error(err))
 		}
 
+		indexes, err := deployment.NewDefinedIndexes(def.Buckets)
+		if err != nil {
+			logger.Fatal("invalid indexes", zap.Error(err))
+		}
+
 		logger.Info("updating definition", zap.Any("def", def))
 
 		deployerName, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
@@ -38,6 +44,13 @@ var modifyCmd = &cobra.Command{
 		if err != nil {
 			logger.Fatal("failed to update cluster", zap.Error(err))
 		}
+
+		// only indexes which are missing are created, so that a definition
+		// can be reapplied to a cluster
+		err = indexes.CreateAndWait(ctx, logger, deployer, cluster.GetID())
+		if err != nil {
+			logger.Fatal("failed to set up indexes", zap.Error(err))
+		}
 	},
 }
 
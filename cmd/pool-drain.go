@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment/dockerdeploy"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var poolDrainCmd = &cobra.Command{
+	Use:   "drain <definition-tag>",
+	Short: "Removes the unclaimed clusters of a pool",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		deployer := helper.GetDockerDeployer(ctx)
+
+		// clusters are claimed before being removed, so that we never remove a
+		// cluster which someone else has just claimed.
+		for {
+			cluster, err := deployer.ClaimPoolCluster(ctx, args[0], "draining pool", 0)
+			if err != nil {
+				if errors.Is(err, dockerdeploy.ErrPoolEmpty) {
+					break
+				}
+				logger.Fatal("failed to claim pool cluster", zap.Error(err))
+			}
+
+			logger.Info("removing pool cluster", zap.String("cluster", cluster.GetID()))
+
+			err = deployer.RemoveCluster(ctx, cluster.GetID())
+			if err != nil {
+				logger.Fatal("failed to remove pool cluster", zap.Error(err))
+			}
+		}
+	},
+}
+
+func init() {
+	poolCmd.AddCommand(poolDrainCmd)
+}
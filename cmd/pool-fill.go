@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"github.com/couchbaselabs/cbdinocluster/deployment/dockerdeploy"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var poolFillCmd = &cobra.Command{
+	Use:     "fill <definition-tag>",
+	Short:   "Fills a pool with ready clusters",
+	Example: "pool fill simple:7.6.2 --size 3",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		size, _ := cmd.Flags().GetInt("size")
+		idleExpiry, _ := cmd.Flags().GetDuration("idle-expiry")
+
+		deployer := helper.GetDockerDeployer(ctx)
+
+		poolMgr, err := dockerdeploy.NewPoolManager(&dockerdeploy.PoolManagerOptions{
+			Logger:     helper.GetInternalLogger(),
+			Deployer:   deployer,
+			Pool:       args[0],
+			Size:       size,
+			IdleExpiry: idleExpiry,
+		})
+		if err != nil {
+			logger.Fatal("failed to create pool manager", zap.Error(err))
+		}
+
+		err = poolMgr.Fill(ctx)
+		if err != nil {
+			logger.Fatal("failed to fill pool", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	poolCmd.AddCommand(poolFillCmd)
+
+	poolFillCmd.Flags().Int("size", 1, "The number of ready clusters to keep in the pool")
+	poolFillCmd.Flags().Duration("idle-expiry", dockerdeploy.DefaultPoolIdleExpiry, "The time to keep unclaimed clusters for")
+}
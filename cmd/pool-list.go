@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+type PoolListOutput []PoolListOutput_Item
+
+type PoolListOutput_Item struct {
+	ClusterID string     `json:"cluster_id"`
+	Pool      string     `json:"pool"`
+	Claimed   bool       `json:"claimed"`
+	State     string     `json:"state"`
+	Expiry    *time.Time `json:"expiry,omitempty"`
+}
+
+var poolListCmd = &cobra.Command{
+	Use:     "list [definition-tag]",
+	Aliases: []string{"ls"},
+	Short:   "Lists the clusters of all pools, or of a specific pool",
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		outputJson, _ := cmd.Flags().GetBool("json")
+
+		pool := ""
+		if len(args) >= 1 {
+			pool = args[0]
+		}
+
+		deployer := helper.GetDockerDeployer(ctx)
+
+		clusters, err := deployer.ListPoolClusters(ctx, pool)
+		if err != nil {
+			logger.Fatal("failed to list pool clusters", zap.Error(err))
+		}
+
+		if !outputJson {
+			fmt.Printf("Pool Clusters:\n")
+			for _, cluster := range clusters {
+				claimedStr := "idle"
+				if cluster.Claimed {
+					claimedStr = "claimed"
+				}
+
+				fmt.Printf("  %s [Pool: %s, Status: %s, State: %s]\n",
+					cluster.ClusterID,
+					cluster.Pool,
+					claimedStr,
+					cluster.State)
+			}
+		} else {
+			out := PoolListOutput{}
+			for _, cluster := range clusters {
+				item := PoolListOutput_Item{
+					ClusterID: cluster.ClusterID,
+					Pool:      cluster.Pool,
+					Claimed:   cluster.Claimed,
+					State:     cluster.State,
+				}
+				if !cluster.Expiry.IsZero() {
+					item.Expiry = &cluster.Expiry
+				}
+				out = append(out, item)
+			}
+			helper.OutputJson(out)
+		}
+	},
+}
+
+func init() {
+	poolCmd.AddCommand(poolListCmd)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment/dockerdeploy"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var poolMaintainCmd = &cobra.Command{
+	Use:   "maintain <definition-tag>",
+	Short: "Keeps a pool filled with ready clusters until interrupted",
+	Long: `Keeps a pool filled with ready clusters until interrupted.
+
+The pool is checked at each interval, and any clusters which were claimed
+are replaced.  Unclaimed clusters have their expiry extended while the pool
+is maintained, so they expire once nothing is maintaining the pool.`,
+	Example: "pool maintain simple:7.6.2 --size 3",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		size, _ := cmd.Flags().GetInt("size")
+		idleExpiry, _ := cmd.Flags().GetDuration("idle-expiry")
+		interval, _ := cmd.Flags().GetDuration("interval")
+
+		deployer := helper.GetDockerDeployer(ctx)
+
+		poolMgr, err := dockerdeploy.NewPoolManager(&dockerdeploy.PoolManagerOptions{
+			Logger:     helper.GetInternalLogger(),
+			Deployer:   deployer,
+			Pool:       args[0],
+			Size:       size,
+			IdleExpiry: idleExpiry,
+		})
+		if err != nil {
+			logger.Fatal("failed to create pool manager", zap.Error(err))
+		}
+
+		runCtx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		logger.Info("maintaining pool",
+			zap.String("pool", args[0]),
+			zap.Int("size", size))
+
+		poolMgr.Run(runCtx, interval)
+	},
+}
+
+func init() {
+	poolCmd.AddCommand(poolMaintainCmd)
+
+	poolMaintainCmd.Flags().Int("size", 1, "The number of ready clusters to keep in the pool")
+	poolMaintainCmd.Flags().Duration("idle-expiry", dockerdeploy.DefaultPoolIdleExpiry, "The time to keep unclaimed clusters for")
+	poolMaintainCmd.Flags().Duration("interval", 30*time.Second, "The interval at which to check the pool")
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/couchbaselabs/cbdinocluster/deployment/dockerdeploy"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var poolReturnCmd = &cobra.Command{
+	Use:   "return <cluster-id>",
+	Short: "Resets a cluster claimed from a pool and returns it to the pool",
+	Long: `Resets a cluster claimed from a pool and returns it to the pool.
+
+The buckets and users of the cluster are removed, and any chaos applied to
+its nodes is reverted, before it is made available to be claimed again.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		idleExpiry, _ := cmd.Flags().GetDuration("idle-expiry")
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+		dockerDeployer, ok := deployer.(*dockerdeploy.Deployer)
+		if !ok {
+			logger.Fatal("pools are only supported for docker clusters")
+		}
+
+		err := dockerDeployer.ReturnPoolCluster(ctx, cluster.GetID(), idleExpiry)
+		if err != nil {
+			logger.Fatal("failed to return cluster to pool", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	poolCmd.AddCommand(poolReturnCmd)
+
+	poolReturnCmd.Flags().Duration("idle-expiry", dockerdeploy.DefaultPoolIdleExpiry, "The time to keep the cluster for if it is not claimed again")
+}
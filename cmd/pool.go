@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var poolCmd = &cobra.Command{
+	Use:   "pool",
+	Short: "Provides the ability to keep pools of ready docker clusters",
+	Long: `Provides the ability to keep pools of ready docker clusters.
+
+A pool holds clusters allocated from a short definition such as simple:7.6.2,
+which is also the name of the pool.  Clusters are claimed from a pool with
+allocate --from-pool, and can be reset and returned to their pool with
+pool return rather than being removed.`,
+	Run: nil,
+}
+
+func init() {
+	rootCmd.AddCommand(poolCmd)
+}
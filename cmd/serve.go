@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/apiserver"
+	"github.com/couchbaselabs/cbdinocluster/deployment/dockerdeploy"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serves the deployer operations as a REST API",
+	Long: `Serves the deployer operations as a REST API.
+
+The configuration is loaded and the deployers are connected once when the
+server starts, rather than for every operation.  Long-running operations such
+as allocating or removing clusters return a job which can be polled at
+/v1/jobs/{job}.  The OpenAPI document describing the API is served at
+/openapi.json, or can be printed with --print-openapi.
+
+Pools of ready docker clusters can be maintained by the server with --pool,
+and claimed by allocating with from_pool set.`,
+	Example: "serve --listen localhost:8080\nserve --pool simple:7.6.2=3",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		listenAddr, _ := cmd.Flags().GetString("listen")
+		printOpenApi, _ := cmd.Flags().GetBool("print-openapi")
+		poolSpecs, _ := cmd.Flags().GetStringArray("pool")
+		poolInterval, _ := cmd.Flags().GetDuration("pool-interval")
+
+		if printOpenApi {
+			helper.OutputJson(apiserver.OpenAPI())
+			return
+		}
+
+		config := helper.GetConfig(ctx)
+		deployers := helper.GetAllDeployers(ctx)
+
+		var poolMgrs []*dockerdeploy.PoolManager
+		for _, poolSpec := range poolSpecs {
+			pool, size, err := parseServePoolSpec(poolSpec)
+			if err != nil {
+				logger.Fatal("invalid pool", zap.Error(err))
+			}
+
+			poolMgr, err := dockerdeploy.NewPoolManager(&dockerdeploy.PoolManagerOptions{
+				Logger:   helper.GetInternalLogger(),
+				Deployer: helper.GetDockerDeployer(ctx),
+				Pool:     pool,
+				Size:     size,
+			})
+			if err != nil {
+				logger.Fatal("failed to create pool manager", zap.Error(err))
+			}
+
+			poolMgrs = append(poolMgrs, poolMgr)
+		}
+
+		server, err := apiserver.NewServer(&apiserver.ServerOptions{
+			Logger:          helper.GetInternalLogger(),
+			Deployers:       deployers,
+			DefaultDeployer: config.DefaultDeployer,
+			DefaultExpiry:   config.DefaultExpiry,
+			PoolManagers:    poolMgrs,
+		})
+		if err != nil {
+			logger.Fatal("failed to create server", zap.Error(err))
+		}
+
+		httpServer := &http.Server{
+			Addr:    listenAddr,
+			Handler: server.Handler(),
+		}
+
+		sigCtx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		for _, poolMgr := range poolMgrs {
+			go poolMgr.Run(sigCtx, poolInterval)
+		}
+
+		go func() {
+			<-sigCtx.Done()
+
+			logger.Info("shutting down server")
+
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			err := httpServer.Shutdown(shutdownCtx)
+			if err != nil {
+				logger.Warn("failed to shut down server cleanly", zap.Error(err))
+			}
+		}()
+
+		logger.Info("serving api", zap.String("listen", listenAddr))
+
+		err = httpServer.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal("failed to serve api", zap.Error(err))
+		}
+
+		// jobs which are still running are cancelled rather than left behind
+		server.Close()
+	},
+}
+
+// parseServePoolSpec parses a pool given as <definition-tag>=<size>.
+func parseServePoolSpec(spec string) (string, int, error) {
+	pool, sizeStr, ok := strings.Cut(spec, "=")
+	if !ok || pool == "" {
+		return "", 0, errors.Errorf("pool `%s` must be of the form <definition-tag>=<size>", spec)
+	}
+
+	size, err := strconv.Atoi(sizeStr)
+	if err != nil || size < 0 {
+		return "", 0, errors.Errorf("pool `%s` has an invalid size", spec)
+	}
+
+	return pool, size, nil
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().String("listen", "localhost:8080", "The address to listen on")
+	serveCmd.Flags().Bool("print-openapi", false, "Prints the OpenAPI document of the API and exits")
+	serveCmd.Flags().StringArray("pool", nil, "A pool of docker clusters to maintain, as <definition-tag>=<size>")
+	serveCmd.Flags().Duration("pool-interval", 30*time.Second, "The interval at which to check the pools")
+}
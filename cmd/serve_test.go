@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServePoolSpec(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		wantPool string
+		wantSize int
+		wantErr  bool
+	}{
+		{name: "valid", spec: "simple:7.6.2=3", wantPool: "simple:7.6.2", wantSize: 3},
+		{name: "empty pool", spec: "single:7.2.0=0", wantPool: "single:7.2.0", wantSize: 0},
+		{name: "missing size", spec: "simple:7.6.2", wantErr: true},
+		{name: "missing tag", spec: "=3", wantErr: true},
+		{name: "invalid size", spec: "simple:7.6.2=x", wantErr: true},
+		{name: "negative size", spec: "simple:7.6.2=-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool, size, err := parseServePoolSpec(tt.spec)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.wantPool, pool)
+			require.Equal(t, tt.wantSize, size)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"github.com/couchbaselabs/cbdinocluster/deployment/dockerdeploy"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var snapshotCreateCmd = &cobra.Command{
+	Use:   "create [flags] <cluster-id> <name>",
+	Short: "Creates a snapshot of a cluster",
+	Long: `Creates a snapshot of a cluster.
+
+The cluster is briefly quiesced while the data of each node is exported, and
+is brought back online once the snapshot has been written.`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		snapshotPath := helper.GetSnapshotPath(args[1])
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+		dockerDeployer, ok := deployer.(*dockerdeploy.Deployer)
+		if !ok {
+			logger.Fatal("snapshots are only supported for docker deployments")
+		}
+
+		for _, node := range cluster.GetNodes() {
+			if !node.IsClusterNode() {
+				logger.Warn("utility nodes such as load balancers are not included in snapshots")
+				break
+			}
+		}
+
+		_, err := dockerDeployer.CreateSnapshot(ctx, cluster.GetID(), snapshotPath)
+		if err != nil {
+			logger.Fatal("failed to create snapshot", zap.Error(err))
+		}
+
+		logger.Info("snapshot created",
+			zap.String("name", args[1]),
+			zap.String("path", snapshotPath))
+	},
+}
+
+func init() {
+	snapshotCmd.AddCommand(snapshotCreateCmd)
+}
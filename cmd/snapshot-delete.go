@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"github.com/couchbaselabs/cbdinocluster/deployment/dockerdeploy"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var snapshotDeleteCmd = &cobra.Command{
+	Use:     "delete [flags] <name>",
+	Aliases: []string{"rm"},
+	Short:   "Deletes a snapshot",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+
+		snapshotPath := helper.GetSnapshotPath(args[0])
+
+		err := dockerdeploy.DeleteSnapshot(snapshotPath)
+		if err != nil {
+			logger.Fatal("failed to delete snapshot", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	snapshotCmd.AddCommand(snapshotDeleteCmd)
+}
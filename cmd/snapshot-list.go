@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/cbdcconfig"
+	"github.com/couchbaselabs/cbdinocluster/deployment/dockerdeploy"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+type SnapshotListOutput []SnapshotListOutput_Item
+
+type SnapshotListOutput_Item struct {
+	Name            string    `json:"name"`
+	CreatedAt       time.Time `json:"created_at"`
+	SourceClusterID string    `json:"source_cluster_id"`
+	NumNodes        int       `json:"num_nodes"`
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "Lists all snapshots",
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+
+		outputJson, _ := cmd.Flags().GetBool("json")
+
+		snapshotsPath, err := cbdcconfig.SnapshotsPath()
+		if err != nil {
+			logger.Fatal("failed to get snapshots path", zap.Error(err))
+		}
+
+		snapshots, err := dockerdeploy.ListSnapshots(snapshotsPath)
+		if err != nil {
+			logger.Fatal("failed to list snapshots", zap.Error(err))
+		}
+
+		if !outputJson {
+			fmt.Printf("Snapshots:\n")
+			for _, snapshot := range snapshots {
+				fmt.Printf("  %-30s %s [Source: %s, Nodes: %d]\n",
+					snapshot.Name,
+					snapshot.CreatedAt.Format(time.RFC3339),
+					snapshot.SourceClusterID,
+					len(snapshot.Nodes))
+			}
+		} else {
+			out := SnapshotListOutput{}
+			for _, snapshot := range snapshots {
+				out = append(out, SnapshotListOutput_Item{
+					Name:            snapshot.Name,
+					CreatedAt:       snapshot.CreatedAt,
+					SourceClusterID: snapshot.SourceClusterID,
+					NumNodes:        len(snapshot.Nodes),
+				})
+			}
+			helper.OutputJson(out)
+		}
+	},
+}
+
+func init() {
+	snapshotCmd.AddCommand(snapshotListCmd)
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment/dockerdeploy"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore [flags] <name>",
+	Short: "Restores a snapshot as a new cluster",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		purpose, _ := cmd.Flags().GetString("purpose")
+		expiry, _ := cmd.Flags().GetDuration("expiry")
+
+		snapshotPath := helper.GetSnapshotPath(args[0])
+
+		dockerDeployer := helper.GetDockerDeployer(ctx)
+
+		cluster, err := dockerDeployer.RestoreSnapshot(ctx, snapshotPath, &dockerdeploy.RestoreSnapshotOptions{
+			Purpose: purpose,
+			Expiry:  expiry,
+		})
+		if err != nil {
+			logger.Fatal("failed to restore snapshot", zap.Error(err))
+		}
+
+		connectInfo, _ := dockerDeployer.GetConnectInfo(ctx, cluster.GetID())
+		if connectInfo != nil {
+			logger.Info("cluster restored",
+				zap.String("mgmt", connectInfo.Mgmt),
+				zap.String("connstr", connectInfo.ConnStr))
+		}
+
+		fmt.Printf("%s\n", cluster.GetID())
+	},
+}
+
+func init() {
+	snapshotCmd.AddCommand(snapshotRestoreCmd)
+
+	snapshotRestoreCmd.Flags().String("purpose", "", "The purpose for the restored cluster")
+	snapshotRestoreCmd.Flags().Duration("expiry", 0, "The time to keep the restored cluster allocated for")
+}
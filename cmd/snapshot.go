@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Provides the ability to snapshot and restore docker clusters",
+	Run:   nil,
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var startCmd = &cobra.Command{
+	Use:   "start [flags] <cluster-id>",
+	Short: "Starts a previously stopped cluster",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+		err := deployer.StartCluster(ctx, cluster.GetID())
+		if err != nil {
+			logger.Fatal("failed to start cluster", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(startCmd)
+}
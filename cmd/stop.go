@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var stopCmd = &cobra.Command{
+	Use:   "stop [flags] <cluster-id>",
+	Short: "Stops a cluster without removing its data",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+		err := deployer.StopCluster(ctx, cluster.GetID())
+		if err != nil {
+			logger.Fatal("failed to stop cluster", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(stopCmd)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment/dockerdeploy"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+type UpgradeProgressOutput struct {
+	Node      int    `json:"node"`
+	NumNodes  int    `json:"num_nodes"`
+	NodeID    string `json:"node_id"`
+	NewNodeID string `json:"new_node_id,omitempty"`
+	Stage     string `json:"stage"`
+}
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade [flags] <cluster-id> <version>",
+	Short: "Performs a rolling upgrade of a cluster to a new version",
+	Long: `Performs a rolling upgrade of a cluster to a new version, one node at a time.
+
+The swap mode adds a node running the new version for each existing node and
+swap-rebalances the old node out.  The delta mode fails each node over,
+recreates its container on the new version using its existing data and then
+delta-recovers it, which requires a cluster allocated with persistent storage.`,
+	Example: "upgrade {{CLUSTER_ID}} 7.6.2\nupgrade {{CLUSTER_ID}} 7.6.2 --mode delta",
+	Args:    cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		outputJson, _ := cmd.Flags().GetBool("json")
+		mode, _ := cmd.Flags().GetString("mode")
+		image, _ := cmd.Flags().GetString("image")
+		fromVersion, _ := cmd.Flags().GetString("from-version")
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+		dockerDeployer, ok := deployer.(*dockerdeploy.Deployer)
+		if !ok {
+			logger.Fatal("upgrade is only supported for docker deployments, use `cloud upgrade` for cloud deployments")
+		}
+
+		err := dockerDeployer.UpgradeClusterWithOptions(ctx, cluster.GetID(), &dockerdeploy.UpgradeClusterOptions{
+			Version:     args[1],
+			Image:       image,
+			FromVersion: fromVersion,
+			Mode:        dockerdeploy.UpgradeMode(mode),
+			OnProgress: func(progress *dockerdeploy.UpgradeProgress) {
+				if !outputJson {
+					fmt.Printf("[%d/%d] %s: %s\n",
+						progress.NodeIndex+1,
+						progress.NumNodes,
+						progress.NodeID,
+						progress.Stage)
+				} else {
+					helper.OutputJson(UpgradeProgressOutput{
+						Node:      progress.NodeIndex + 1,
+						NumNodes:  progress.NumNodes,
+						NodeID:    progress.NodeID,
+						NewNodeID: progress.NewNodeID,
+						Stage:     string(progress.Stage),
+					})
+				}
+			},
+		})
+		if err != nil {
+			logger.Fatal("failed to upgrade cluster", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+
+	upgradeCmd.Flags().String("mode", string(dockerdeploy.UpgradeModeSwap), "The upgrade mode to use (swap, delta)")
+	upgradeCmd.Flags().String("image", "", "The docker image to use for upgraded nodes")
+	upgradeCmd.Flags().String("from-version", "", "Only upgrade nodes currently running this version")
+}
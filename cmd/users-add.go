@@ -22,11 +22,23 @@ var usersAddCmd = &cobra.Command{
 		password, _ := cmd.Flags().GetString("password")
 		canRead, _ := cmd.Flags().GetBool("can-read")
 		canWrite, _ := cmd.Flags().GetBool("can-write")
+		roles, _ := cmd.Flags().GetStringSlice("role")
+		groups, _ := cmd.Flags().GetStringSlice("group")
+		external, _ := cmd.Flags().GetBool("external")
 
-		if password == "" {
+		if external {
+			if password != "" {
+				logger.Fatal("external users cannot have a password")
+			}
+		} else if password == "" {
 			logger.Fatal("you must specify a password to use")
 		}
 
+		_, err := deployment.ParseRoles(roles)
+		if err != nil {
+			logger.Fatal("invalid role", zap.Error(err))
+		}
+
 		_, deployer, cluster := helper.IdentifyCluster(ctx, clusterID)
 
 		opts := &deployment.CreateUserOptions{
@@ -34,8 +46,11 @@ var usersAddCmd = &cobra.Command{
 			Password: password,
 			CanRead:  canRead,
 			CanWrite: canWrite,
+			Roles:    roles,
+			Groups:   groups,
+			External: external,
 		}
-		err := deployer.CreateUser(ctx, cluster.GetID(), opts)
+		err = deployer.CreateUser(ctx, cluster.GetID(), opts)
 		if err != nil {
 			logger.Fatal("failed to create user", zap.Error(err))
 		}
@@ -49,9 +64,14 @@ var usersAddCmd = &cobra.Command{
 			}
 
 			for _, user := range users {
+				if user.Username != opts.Username {
+					continue
+				}
+
 				// If the target has canWrite = true but canRead = false the created
-				// user will have both as true
-				if user.Username == opts.Username && (user.CanRead == opts.CanRead || user.CanWrite) {
+				// user will have both as true.  Users without the legacy roles are
+				// ready as soon as they are listed.
+				if !opts.UsesLegacyRoles() || user.CanRead == opts.CanRead || user.CanWrite {
 					logger.Info("user is ready", zap.Any("user", user))
 					return
 				}
@@ -67,6 +87,9 @@ func init() {
 	usersCmd.AddCommand(usersAddCmd)
 
 	usersAddCmd.Flags().String("password", "", "The password to assign to the user")
-	usersAddCmd.Flags().Bool("can-read", true, "Whether the user can read data")
-	usersAddCmd.Flags().Bool("can-write", true, "Whether the user can write data")
+	usersAddCmd.Flags().Bool("can-read", true, "Whether the user can read data, ignored for users with roles, groups or which are external")
+	usersAddCmd.Flags().Bool("can-write", true, "Whether the user can write data, ignored for users with roles, groups or which are external")
+	usersAddCmd.Flags().StringSlice("role", nil, "A role to grant the user instead of can-read and can-write, such as data_reader[travel-sample:inventory.airline]")
+	usersAddCmd.Flags().StringSlice("group", nil, "A group the user is a member of")
+	usersAddCmd.Flags().Bool("external", false, "Whether the user is an external (LDAP) user, which has no password")
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -10,9 +11,12 @@ import (
 type UsersListOutput []UsersListOutput_Item
 
 type UsersListOutput_Item struct {
-	Username string `json:"username"`
-	CanRead  bool   `json:"can_read"`
-	CanWrite bool   `json:"can_write"`
+	Username string   `json:"username"`
+	CanRead  bool     `json:"can_read"`
+	CanWrite bool     `json:"can_write"`
+	Roles    []string `json:"roles,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+	External bool     `json:"external,omitempty"`
 }
 
 var usersListCmd = &cobra.Command{
@@ -41,6 +45,15 @@ var usersListCmd = &cobra.Command{
 					user.Username,
 					user.CanRead,
 					user.CanWrite)
+				if user.External {
+					fmt.Printf("    External: true\n")
+				}
+				if len(user.Roles) > 0 {
+					fmt.Printf("    Roles: %s\n", strings.Join(user.Roles, ", "))
+				}
+				if len(user.Groups) > 0 {
+					fmt.Printf("    Groups: %s\n", strings.Join(user.Groups, ", "))
+				}
 			}
 		} else {
 			var out UsersListOutput
@@ -49,6 +62,9 @@ var usersListCmd = &cobra.Command{
 					Username: user.Username,
 					CanRead:  user.CanRead,
 					CanWrite: user.CanWrite,
+					Roles:    user.Roles,
+					Groups:   user.Groups,
+					External: user.External,
 				})
 			}
 			helper.OutputJson(out)
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/deployment/commondeploy"
+	"github.com/couchbaselabs/cbdinocluster/utils/workload"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var workloadRunCmd = &cobra.Command{
+	Use:   "run <cluster-id>",
+	Short: "Runs a continuous workload against a bucket of a cluster",
+	Long: `Runs a continuous workload against a bucket of a cluster.
+
+The workload runs a weighted mix of operations on the documents written by
+'data load', until the duration has passed or it is interrupted.  The
+statistics of each interval are written as text, JSON lines or the prometheus
+text format, and are timestamped with the start of the interval so that they
+line up with the timeline of 'chaos run'.
+
+The profiles are:
+  kv-mixed  gets, upserts and sub-document lookups and mutations
+  query     key lookups through the query service and KV range scans
+  txn       transactions which update two documents, and gets
+
+Range scans read the keys following a random key within its vbucket.
+Transactions make a single attempt, so conflicts are counted as errors
+rather than retried.`,
+	Example: "workload run {{CLUSTER_ID}} --profile kv-mixed --ops-per-sec 5000 --duration 10m --format jsonl --output workload.jsonl",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		outputJson, _ := cmd.Flags().GetBool("json")
+		bucketName, _ := cmd.Flags().GetString("bucket")
+		scopeName, _ := cmd.Flags().GetString("scope")
+		collectionName, _ := cmd.Flags().GetString("collection")
+		username, _ := cmd.Flags().GetString("username")
+		password, _ := cmd.Flags().GetString("password")
+		formatStr, _ := cmd.Flags().GetString("format")
+		outputPath, _ := cmd.Flags().GetString("output")
+
+		if outputJson && !cmd.Flags().Changed("format") {
+			formatStr = string(workload.FormatJSONLines)
+		}
+
+		format, err := workload.ParseFormat(formatStr)
+		if err != nil {
+			logger.Fatal("invalid format", zap.Error(err))
+		}
+
+		opts, durabilityLevel, err := workloadOptionsFromFlags(cmd)
+		if err != nil {
+			logger.Fatal("invalid options", zap.Error(err))
+		}
+
+		keyspace := deployment.Keyspace{
+			Bucket:     bucketName,
+			Scope:      scopeName,
+			Collection: collectionName,
+		}
+		opts.Keyspace = keyspace.N1QL()
+
+		var out io.Writer = os.Stdout
+		if outputPath != "" {
+			outFile, err := os.Create(outputPath)
+			if err != nil {
+				logger.Fatal("failed to create output file", zap.Error(err))
+			}
+			defer outFile.Close()
+			out = outFile
+		}
+
+		opts.OnInterval = func(stats *workload.IntervalStats) {
+			err := workload.WriteInterval(out, format, stats)
+			if err != nil {
+				logger.Warn("failed to write statistics", zap.Error(err))
+			}
+		}
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+		// range scans need the vbucket count of the bucket to find the
+		// vbucket of a key, which is assumed to be the default when the
+		// deployer cannot report it
+		var numVBuckets int
+		if opts.Mix[workload.OpRangeScan] > 0 {
+			bucket, err := deployer.GetBucket(ctx, cluster.GetID(), bucketName)
+			if err != nil && !errors.Is(err, deployment.ErrNotSupported) {
+				logger.Fatal("failed to get bucket", zap.Error(err))
+			}
+
+			if err == nil {
+				numVBuckets = bucket.Settings.NumVBuckets
+			}
+		}
+
+		agent, err := deployer.OpenBucketAgent(ctx, cluster.GetID(), bucketName, &deployment.OpenBucketAgentOptions{
+			Username: username,
+			Password: password,
+		})
+		if err != nil {
+			logger.Fatal("failed to connect to bucket", zap.Error(err))
+		}
+		defer agent.Close()
+
+		store := commondeploy.DocumentStore{
+			Agent:           agent,
+			BucketName:      bucketName,
+			ScopeName:       scopeName,
+			CollectionName:  collectionName,
+			DurabilityLevel: durabilityLevel,
+			NumVBuckets:     numVBuckets,
+		}
+
+		if opts.Mix[workload.OpTxn] > 0 {
+			store.Transactions, err = commondeploy.NewTransactionsManager(agent)
+			if err != nil {
+				logger.Fatal("failed to set up transactions", zap.Error(err))
+			}
+		}
+
+		runCtx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		logger.Info("running workload",
+			zap.String("bucket", bucketName),
+			zap.Any("mix", opts.Mix),
+			zap.Int("ops-per-sec", opts.OpsPerSec))
+
+		summary, err := workload.Run(runCtx, store, opts)
+		if err != nil {
+			logger.Fatal("failed to run workload", zap.Error(err))
+		}
+
+		var errStrs []string
+		for errClass, count := range summary.Errors {
+			errStrs = append(errStrs, fmt.Sprintf("%s:%d", errClass, count))
+		}
+
+		logger.Info("workload finished",
+			zap.Duration("elapsed", summary.Elapsed.Round(time.Millisecond)),
+			zap.Int64("ops", summary.Count),
+			zap.Int64("failed", summary.Failed),
+			zap.String("errors", strings.Join(errStrs, ",")))
+	},
+}
+
+func init() {
+	workloadCmd.AddCommand(workloadRunCmd)
+
+	addWorkloadFlags(workloadRunCmd)
+}
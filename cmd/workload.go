@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/utils/dataloader"
+	"github.com/couchbaselabs/cbdinocluster/utils/workload"
+	"github.com/spf13/cobra"
+)
+
+var workloadCmd = &cobra.Command{
+	Use:   "workload",
+	Short: "Provides the ability to run traffic against a cluster",
+	Run:   nil,
+}
+
+// addWorkloadFlags registers the flags of the workload commands.
+func addWorkloadFlags(cmd *cobra.Command) {
+	cmd.Flags().String("bucket", "default", "The bucket to run the workload against")
+	cmd.Flags().String("scope", "", "The scope of the documents")
+	cmd.Flags().String("collection", "", "The collection of the documents")
+	cmd.Flags().String("count", "1000", "The number of documents to operate on, such as 1000 or 1e6")
+	cmd.Flags().String("key-pattern", dataloader.DefaultKeyPattern, "The fmt pattern which formats the index of a document into its key")
+	cmd.Flags().String("profile", "kv-mixed", "The mix of operations to run ("+strings.Join(workload.ProfileNames(), ", ")+")")
+	cmd.Flags().String("mix", "", "A mix of operations which overrides the profile, such as get=60,upsert=30,range-scan=10")
+	cmd.Flags().Int("ops-per-sec", 1000, "The target rate of operations, or 0 for no limit")
+	cmd.Flags().Int("workers", 16, "The number of concurrent workers")
+	cmd.Flags().Duration("duration", 0, "How long to run for, or until interrupted if not set")
+	cmd.Flags().Duration("timeout", 2500*time.Millisecond, "The timeout of each operation")
+	cmd.Flags().Duration("interval", time.Second, "How often to report statistics")
+	cmd.Flags().String("format", string(workload.FormatText), "The format of the statistics (text, jsonl, prometheus)")
+	cmd.Flags().String("output", "", "The path to write the statistics to, defaults to stdout")
+	cmd.Flags().String("username", "", "the database user to connect as (cloud clusters need this)")
+	cmd.Flags().String("password", "", "the password of the database user (cloud clusters need this)")
+	addDocumentFlags(cmd)
+}
+
+// workloadMixFromFlags returns the mix of the --mix flag, or else the mix of
+// the named --profile.
+func workloadMixFromFlags(cmd *cobra.Command) (workload.Mix, error) {
+	mixStr, _ := cmd.Flags().GetString("mix")
+	if mixStr != "" {
+		return workload.ParseMix(mixStr)
+	}
+
+	profile, _ := cmd.Flags().GetString("profile")
+	mix, ok := workload.Profiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile '%s', expected one of %s",
+			profile, strings.Join(workload.ProfileNames(), ", "))
+	}
+
+	return mix, nil
+}
+
+// workloadOptionsFromFlags builds the options of a workload, other than its
+// keyspace and reporting.
+func workloadOptionsFromFlags(cmd *cobra.Command) (*workload.Options, deployment.DurabilityLevel, error) {
+	flags := cmd.Flags()
+
+	mix, err := workloadMixFromFlags(cmd)
+	if err != nil {
+		return nil, "", err
+	}
+
+	countStr, _ := flags.GetString("count")
+	count, err := dataloader.ParseCount(countStr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	generator, err := generatorFromFlags(cmd)
+	if err != nil {
+		return nil, "", err
+	}
+
+	durabilityStr, _ := flags.GetString("durability")
+	durabilityLevel, err := deployment.ParseDurabilityLevel(durabilityStr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	expiry, _ := flags.GetDuration("expiry")
+	opsPerSec, _ := flags.GetInt("ops-per-sec")
+	workers, _ := flags.GetInt("workers")
+	duration, _ := flags.GetDuration("duration")
+	opTimeout, _ := flags.GetDuration("timeout")
+	interval, _ := flags.GetDuration("interval")
+
+	if opsPerSec < 0 {
+		return nil, "", fmt.Errorf("ops-per-sec cannot be negative")
+	}
+
+	return &workload.Options{
+		Mix:       mix,
+		Generator: generator,
+		Count:     count,
+		Expiry:    expiry,
+		OpsPerSec: opsPerSec,
+		Workers:   workers,
+		Duration:  duration,
+		OpTimeout: opTimeout,
+		Interval:  interval,
+	}, durabilityLevel, nil
+}
+
+func init() {
+	rootCmd.AddCommand(workloadCmd)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/utils/workload"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkloadOptionsFromFlags(t *testing.T) {
+	cmd := &cobra.Command{}
+	addWorkloadFlags(cmd)
+	cmd.Flags().Set("profile", "query")
+	cmd.Flags().Set("ops-per-sec", "200")
+	cmd.Flags().Set("durability", "majority")
+	cmd.Flags().Set("count", "1e5")
+
+	opts, durabilityLevel, err := workloadOptionsFromFlags(cmd)
+	require.NoError(t, err)
+	require.Equal(t, workload.Profiles["query"], opts.Mix)
+	require.Equal(t, 100000, opts.Count)
+	require.Equal(t, 200, opts.OpsPerSec)
+	require.Equal(t, 2500*time.Millisecond, opts.OpTimeout)
+	require.Equal(t, time.Second, opts.Interval)
+	require.Equal(t, deployment.DurabilityLevelMajority, durabilityLevel)
+
+	// a mix overrides the profile
+	cmd.Flags().Set("mix", "get=1,range-scan=1")
+
+	opts, _, err = workloadOptionsFromFlags(cmd)
+	require.NoError(t, err)
+	require.Equal(t, workload.Mix{workload.OpGet: 1, workload.OpRangeScan: 1}, opts.Mix)
+}
+
+func TestWorkloadOptionsFromFlagsInvalid(t *testing.T) {
+	cmd := &cobra.Command{}
+	addWorkloadFlags(cmd)
+	cmd.Flags().Set("profile", "chaos")
+
+	_, _, err := workloadOptionsFromFlags(cmd)
+	require.Error(t, err)
+
+	cmd = &cobra.Command{}
+	addWorkloadFlags(cmd)
+	cmd.Flags().Set("ops-per-sec", "-1")
+
+	_, _, err = workloadOptionsFromFlags(cmd)
+	require.Error(t, err)
+}
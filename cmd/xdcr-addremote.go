@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var xdcrAddRemoteCmd = &cobra.Command{
+	Use:   "add-remote <src-cluster-id> <dst-cluster-id>",
+	Short: "Adds a remote cluster reference to the destination cluster on the source cluster",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		_, srcDeployer, srcCluster := helper.IdentifyCluster(ctx, args[0])
+		_, dstDeployer, dstCluster := helper.IdentifyCluster(ctx, args[1])
+
+		target, err := dstDeployer.GetXdcrTarget(ctx, dstCluster.GetID())
+		if err != nil {
+			logger.Fatal("failed to get target cluster details", zap.Error(err))
+		}
+
+		err = srcDeployer.CreateXdcrRemote(ctx, srcCluster.GetID(), &deployment.CreateXdcrRemoteOptions{
+			Name:   dstCluster.GetID(),
+			Target: target,
+		})
+		if err != nil {
+			logger.Fatal("failed to create remote", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	xdcrCmd.AddCommand(xdcrAddRemoteCmd)
+}
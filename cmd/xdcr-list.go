@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+type XdcrListOutput []XdcrListOutput_Item
+
+type XdcrListOutput_Item struct {
+	ID               string `json:"id"`
+	SourceBucket     string `json:"source_bucket"`
+	TargetClusterID  string `json:"target_cluster_id"`
+	TargetBucket     string `json:"target_bucket"`
+	Status           string `json:"status"`
+	FilterExpression string `json:"filter_expression,omitempty"`
+}
+
+var xdcrListCmd = &cobra.Command{
+	Use:     "list <cluster-id>",
+	Aliases: []string{"ls"},
+	Short:   "Lists the replications of a cluster",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		outputJson, _ := cmd.Flags().GetBool("json")
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+		replications, err := deployer.ListXdcrReplications(ctx, cluster.GetID())
+		if err != nil {
+			logger.Fatal("failed to list replications", zap.Error(err))
+		}
+
+		if !outputJson {
+			fmt.Printf("Replications:\n")
+			for _, replication := range replications {
+				fmt.Printf("  %s [%s]\n",
+					replication.ID,
+					replication.Status)
+				fmt.Printf("    %s -> %s/%s\n",
+					replication.SourceBucket,
+					replication.TargetClusterID,
+					replication.TargetBucket)
+				if replication.FilterExpression != "" {
+					fmt.Printf("    filter: %s\n", replication.FilterExpression)
+				}
+			}
+		} else {
+			var out XdcrListOutput
+			for _, replication := range replications {
+				out = append(out, XdcrListOutput_Item{
+					ID:               replication.ID,
+					SourceBucket:     replication.SourceBucket,
+					TargetClusterID:  replication.TargetClusterID,
+					TargetBucket:     replication.TargetBucket,
+					Status:           replication.Status,
+					FilterExpression: replication.FilterExpression,
+				})
+			}
+			helper.OutputJson(out)
+		}
+	},
+}
+
+func init() {
+	xdcrCmd.AddCommand(xdcrListCmd)
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var xdcrRemoveCmd = &cobra.Command{
+	Use:     "remove <cluster-id> <replication-id>",
+	Aliases: []string{"rm"},
+	Short:   "Removes a replication",
+	Args:    cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+		err := deployer.DeleteXdcrReplication(ctx, cluster.GetID(), args[1])
+		if err != nil {
+			logger.Fatal("failed to remove replication", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	xdcrCmd.AddCommand(xdcrRemoveCmd)
+}
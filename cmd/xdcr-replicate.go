@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var xdcrReplicateCmd = &cobra.Command{
+	Use:   "replicate <src-cluster-id> <src-bucket> <dst-cluster-id> <dst-bucket>",
+	Short: "Replicates a bucket from one cluster to another",
+	Args:  cobra.MinimumNArgs(4),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		filter, _ := cmd.Flags().GetString("filter")
+		mode, _ := cmd.Flags().GetString("mode")
+
+		if mode != "one-way" && mode != "bidirectional" {
+			logger.Fatal("unexpected replication mode", zap.String("mode", mode))
+		}
+
+		_, srcDeployer, srcCluster := helper.IdentifyCluster(ctx, args[0])
+		srcBucket := args[1]
+		_, dstDeployer, dstCluster := helper.IdentifyCluster(ctx, args[2])
+		dstBucket := args[3]
+
+		err := ensureXdcrRemote(ctx, srcDeployer, srcCluster.GetID(), dstDeployer, dstCluster.GetID())
+		if err != nil {
+			logger.Fatal("failed to setup remote", zap.Error(err))
+		}
+
+		replicationID, err := srcDeployer.CreateXdcrReplication(ctx, srcCluster.GetID(), &deployment.CreateXdcrReplicationOptions{
+			SourceBucket:     srcBucket,
+			TargetClusterID:  dstCluster.GetID(),
+			TargetBucket:     dstBucket,
+			FilterExpression: filter,
+		})
+		if err != nil {
+			logger.Fatal("failed to create replication", zap.Error(err))
+		}
+
+		fmt.Printf("%s\n", replicationID)
+
+		if mode == "bidirectional" {
+			err := ensureXdcrRemote(ctx, dstDeployer, dstCluster.GetID(), srcDeployer, srcCluster.GetID())
+			if err != nil {
+				logger.Fatal("failed to setup reverse remote", zap.Error(err))
+			}
+
+			reverseID, err := dstDeployer.CreateXdcrReplication(ctx, dstCluster.GetID(), &deployment.CreateXdcrReplicationOptions{
+				SourceBucket:     dstBucket,
+				TargetClusterID:  srcCluster.GetID(),
+				TargetBucket:     srcBucket,
+				FilterExpression: filter,
+			})
+			if err != nil {
+				logger.Fatal("failed to create reverse replication", zap.Error(err))
+			}
+
+			fmt.Printf("%s\n", reverseID)
+		}
+	},
+}
+
+func init() {
+	xdcrCmd.AddCommand(xdcrReplicateCmd)
+
+	xdcrReplicateCmd.Flags().String("filter", "", "A filter expression for the documents to replicate")
+	xdcrReplicateCmd.Flags().String("mode", "one-way", "The replication mode, one of one-way or bidirectional")
+}
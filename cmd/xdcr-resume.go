@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var xdcrResumeCmd = &cobra.Command{
+	Use:   "resume <cluster-id> <replication-id>",
+	Short: "Resumes a paused replication",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		helper := CmdHelper{}
+		logger := helper.GetLogger()
+		ctx := helper.GetContext()
+
+		_, deployer, cluster := helper.IdentifyCluster(ctx, args[0])
+
+		err := deployer.SetXdcrReplicationPaused(ctx, cluster.GetID(), args[1], false)
+		if err != nil {
+			logger.Fatal("failed to resume replication", zap.Error(err))
+		}
+	},
+}
+
+func init() {
+	xdcrCmd.AddCommand(xdcrResumeCmd)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var xdcrCmd = &cobra.Command{
+	Use:   "xdcr",
+	Short: "Provides the ability to replicate data between clusters",
+	Run:   nil,
+}
+
+// ensureXdcrRemote creates a remote cluster reference on the source cluster
+// for the target cluster, unless one already exists.  Deployers which manage
+// remote references themselves are left alone.
+func ensureXdcrRemote(
+	ctx context.Context,
+	srcDeployer deployment.Deployer, srcClusterID string,
+	dstDeployer deployment.Deployer, dstClusterID string,
+) error {
+	remotes, err := srcDeployer.ListXdcrRemotes(ctx, srcClusterID)
+	if errors.Is(err, deployment.ErrNotSupported) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to list remotes")
+	}
+
+	for _, remote := range remotes {
+		if remote.Name == dstClusterID {
+			return nil
+		}
+	}
+
+	target, err := dstDeployer.GetXdcrTarget(ctx, dstClusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get target cluster details")
+	}
+
+	err = srcDeployer.CreateXdcrRemote(ctx, srcClusterID, &deployment.CreateXdcrRemoteOptions{
+		Name:   dstClusterID,
+		Target: target,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create remote")
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(xdcrCmd)
+}
@@ -2,7 +2,11 @@ package deployment
 
 import (
 	"fmt"
+	"math"
 	"strings"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
 )
 
 // BucketType identifies which kind of Couchbase bucket to create. The values
@@ -48,3 +52,192 @@ func ParseBucketType(s string) (BucketType, error) {
 		return "", fmt.Errorf("invalid bucket type %q (valid types: couchbase, ephemeral, memcached)", s)
 	}
 }
+
+// StorageBackend identifies the storage engine of a couchbase bucket.
+type StorageBackend string
+
+const (
+	StorageBackendCouchstore StorageBackend = "couchstore"
+	StorageBackendMagma      StorageBackend = "magma"
+)
+
+// EvictionPolicy identifies how a bucket evicts data once its quota is full.
+// Couchbase buckets use value-only or full, ephemeral buckets use no-eviction
+// or nru.
+type EvictionPolicy string
+
+const (
+	EvictionPolicyValueOnly       EvictionPolicy = "value-only"
+	EvictionPolicyFull            EvictionPolicy = "full"
+	EvictionPolicyNotRecentlyUsed EvictionPolicy = "nru"
+	EvictionPolicyNoEviction      EvictionPolicy = "no-eviction"
+)
+
+// DurabilityLevel identifies the minimum durability level of a bucket.
+type DurabilityLevel string
+
+const (
+	DurabilityLevelNone                     DurabilityLevel = "none"
+	DurabilityLevelMajority                 DurabilityLevel = "majority"
+	DurabilityLevelMajorityAndPersistActive DurabilityLevel = "majority-and-persist-active"
+	DurabilityLevelPersistToMajority        DurabilityLevel = "persist-to-majority"
+)
+
+// CompressionMode identifies how a bucket compresses documents.
+type CompressionMode string
+
+const (
+	CompressionModeOff     CompressionMode = "off"
+	CompressionModePassive CompressionMode = "passive"
+	CompressionModeActive  CompressionMode = "active"
+)
+
+// ConflictResolution identifies how XDCR resolves conflicting mutations.
+type ConflictResolution string
+
+const (
+	ConflictResolutionSeqno  ConflictResolution = "seqno"
+	ConflictResolutionLww    ConflictResolution = "lww"
+	ConflictResolutionCustom ConflictResolution = "custom"
+)
+
+// parseBucketSetting normalizes a user-supplied bucket setting using a table
+// of accepted names.  An empty string maps to the empty (unset) value, which
+// leaves the choice to the deployer.
+func parseBucketSetting[T ~string](kind string, s string, names map[string]T, valid string) (T, error) {
+	normalized := strings.ToLower(strings.TrimSpace(s))
+	if normalized == "" {
+		return "", nil
+	}
+
+	value, ok := names[normalized]
+	if !ok {
+		return "", fmt.Errorf("invalid %s %q (valid values: %s)", kind, s, valid)
+	}
+
+	return value, nil
+}
+
+// ParseStorageBackend normalizes a user-supplied storage backend.
+func ParseStorageBackend(s string) (StorageBackend, error) {
+	return parseBucketSetting("storage backend", s, map[string]StorageBackend{
+		"couchstore": StorageBackendCouchstore,
+		"magma":      StorageBackendMagma,
+	}, "couchstore, magma")
+}
+
+// ParseEvictionPolicy normalizes a user-supplied eviction policy.  The
+// server-side names (valueOnly, fullEviction, ...) are also accepted.
+func ParseEvictionPolicy(s string) (EvictionPolicy, error) {
+	return parseBucketSetting("eviction policy", s, map[string]EvictionPolicy{
+		"value-only":   EvictionPolicyValueOnly,
+		"valueonly":    EvictionPolicyValueOnly,
+		"full":         EvictionPolicyFull,
+		"fulleviction": EvictionPolicyFull,
+		"nru":          EvictionPolicyNotRecentlyUsed,
+		"nrueviction":  EvictionPolicyNotRecentlyUsed,
+		"no-eviction":  EvictionPolicyNoEviction,
+		"noeviction":   EvictionPolicyNoEviction,
+	}, "value-only, full, nru, no-eviction")
+}
+
+// ParseDurabilityLevel normalizes a user-supplied durability level.  The
+// server-side names (majorityAndPersistActive, ...) are also accepted.
+func ParseDurabilityLevel(s string) (DurabilityLevel, error) {
+	return parseBucketSetting("durability level", s, map[string]DurabilityLevel{
+		"none":                        DurabilityLevelNone,
+		"majority":                    DurabilityLevelMajority,
+		"majority-and-persist-active": DurabilityLevelMajorityAndPersistActive,
+		"majorityandpersistactive":    DurabilityLevelMajorityAndPersistActive,
+		"persist-to-majority":         DurabilityLevelPersistToMajority,
+		"persisttomajority":           DurabilityLevelPersistToMajority,
+	}, "none, majority, majority-and-persist-active, persist-to-majority")
+}
+
+// ParseCompressionMode normalizes a user-supplied compression mode.
+func ParseCompressionMode(s string) (CompressionMode, error) {
+	return parseBucketSetting("compression mode", s, map[string]CompressionMode{
+		"off":     CompressionModeOff,
+		"passive": CompressionModePassive,
+		"active":  CompressionModeActive,
+	}, "off, passive, active")
+}
+
+// ParseConflictResolution normalizes a user-supplied conflict resolution
+// type.  timestamp is accepted as an alias of lww.
+func ParseConflictResolution(s string) (ConflictResolution, error) {
+	return parseBucketSetting("conflict resolution", s, map[string]ConflictResolution{
+		"seqno":     ConflictResolutionSeqno,
+		"lww":       ConflictResolutionLww,
+		"timestamp": ConflictResolutionLww,
+		"custom":    ConflictResolutionCustom,
+	}, "seqno, lww, custom")
+}
+
+// NewCreateBucketOptions validates the settings of a bucket of a cluster
+// definition, returning the options to create it with.  Whether a deployer
+// can honour the settings is left to the deployer.
+func NewCreateBucketOptions(name string, settings *clusterdef.Settings) (*CreateBucketOptions, error) {
+	bucketType, err := ParseBucketType(settings.BucketType)
+	if err != nil {
+		return nil, err
+	}
+
+	storageBackend, err := ParseStorageBackend(settings.StorageBackend)
+	if err != nil {
+		return nil, err
+	}
+
+	evictionPolicy, err := ParseEvictionPolicy(settings.EvictionPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	durabilityLevel, err := ParseDurabilityLevel(settings.DurabilityMinLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	compressionMode, err := ParseCompressionMode(settings.CompressionMode)
+	if err != nil {
+		return nil, err
+	}
+
+	conflictResolution, err := ParseConflictResolution(settings.ConflictResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.RamQuotaMB < 0 || settings.NumReplicas < 0 {
+		return nil, fmt.Errorf("ram quota and replicas cannot be negative")
+	}
+	if settings.MaxTTL < 0 || settings.MaxTTL > math.MaxInt32*time.Second {
+		return nil, fmt.Errorf("invalid max ttl %s", settings.MaxTTL)
+	}
+	if settings.HistoryRetentionDuration < 0 || settings.HistoryRetentionDuration > math.MaxUint32*time.Second {
+		return nil, fmt.Errorf("invalid history retention duration %s", settings.HistoryRetentionDuration)
+	}
+
+	switch settings.NumVBuckets {
+	case 0, 128, 1024:
+	default:
+		return nil, fmt.Errorf("invalid vbucket count %d (valid values: 128, 1024)", settings.NumVBuckets)
+	}
+
+	return &CreateBucketOptions{
+		Name:                     name,
+		BucketType:               bucketType,
+		RamQuotaMB:               settings.RamQuotaMB,
+		FlushEnabled:             settings.FlushEnabled,
+		NumReplicas:              settings.NumReplicas,
+		StorageBackend:           storageBackend,
+		EvictionPolicy:           evictionPolicy,
+		DurabilityMinLevel:       durabilityLevel,
+		MaxTTL:                   settings.MaxTTL,
+		CompressionMode:          compressionMode,
+		ConflictResolution:       conflictResolution,
+		HistoryRetentionBytes:    settings.HistoryRetentionBytes,
+		HistoryRetentionDuration: settings.HistoryRetentionDuration,
+		NumVBuckets:              settings.NumVBuckets,
+	}, nil
+}
@@ -2,7 +2,9 @@ package deployment
 
 import (
 	"testing"
+	"time"
 
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
 	"github.com/stretchr/testify/require"
 )
 
@@ -58,3 +60,79 @@ func TestAllBucketTypesParse(t *testing.T) {
 		})
 	}
 }
+
+func TestParseBucketSettings(t *testing.T) {
+	evictionPolicy, err := ParseEvictionPolicy("fullEviction")
+	require.NoError(t, err)
+	require.Equal(t, EvictionPolicyFull, evictionPolicy)
+
+	durabilityLevel, err := ParseDurabilityLevel("majorityAndPersistActive")
+	require.NoError(t, err)
+	require.Equal(t, DurabilityLevelMajorityAndPersistActive, durabilityLevel)
+
+	conflictResolution, err := ParseConflictResolution("timestamp")
+	require.NoError(t, err)
+	require.Equal(t, ConflictResolutionLww, conflictResolution)
+
+	storageBackend, err := ParseStorageBackend("")
+	require.NoError(t, err)
+	require.Empty(t, storageBackend)
+
+	_, err = ParseCompressionMode("sometimes")
+	require.ErrorContains(t, err, "off, passive, active")
+}
+
+func TestNewCreateBucketOptions(t *testing.T) {
+	opts, err := NewCreateBucketOptions("cdc", &clusterdef.Settings{
+		RamQuotaMB:               1024,
+		NumReplicas:              1,
+		StorageBackend:           "magma",
+		EvictionPolicy:           "full",
+		DurabilityMinLevel:       "majority",
+		MaxTTL:                   time.Hour,
+		CompressionMode:          "active",
+		ConflictResolution:       "lww",
+		HistoryRetentionBytes:    2048,
+		HistoryRetentionDuration: time.Minute,
+		NumVBuckets:              128,
+	})
+	require.NoError(t, err)
+	require.Equal(t, &CreateBucketOptions{
+		Name:                     "cdc",
+		BucketType:               BucketTypeCouchbase,
+		RamQuotaMB:               1024,
+		NumReplicas:              1,
+		StorageBackend:           StorageBackendMagma,
+		EvictionPolicy:           EvictionPolicyFull,
+		DurabilityMinLevel:       DurabilityLevelMajority,
+		MaxTTL:                   time.Hour,
+		CompressionMode:          CompressionModeActive,
+		ConflictResolution:       ConflictResolutionLww,
+		HistoryRetentionBytes:    2048,
+		HistoryRetentionDuration: time.Minute,
+		NumVBuckets:              128,
+	}, opts)
+}
+
+func TestNewCreateBucketOptionsInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings clusterdef.Settings
+	}{
+		{name: "bucket type", settings: clusterdef.Settings{BucketType: "nonsense"}},
+		{name: "storage backend", settings: clusterdef.Settings{StorageBackend: "rocksdb"}},
+		{name: "eviction policy", settings: clusterdef.Settings{EvictionPolicy: "lru"}},
+		{name: "durability level", settings: clusterdef.Settings{DurabilityMinLevel: "all"}},
+		{name: "conflict resolution", settings: clusterdef.Settings{ConflictResolution: "newest"}},
+		{name: "negative max ttl", settings: clusterdef.Settings{MaxTTL: -time.Second}},
+		{name: "vbucket count", settings: clusterdef.Settings{NumVBuckets: 64}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := NewCreateBucketOptions("b", &tt.settings)
+			require.Error(t, err)
+			require.Nil(t, opts)
+		})
+	}
+}
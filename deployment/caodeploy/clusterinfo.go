@@ -10,6 +10,7 @@ type ClusterInfo struct {
 	ClusterID string
 	Expiry    time.Time
 	State     string
+	Nodes     []*ClusterNodeInfo
 }
 
 var _ (deployment.ClusterInfo) = (*ClusterInfo)(nil)
@@ -20,5 +21,23 @@ func (i ClusterInfo) GetPurpose() string              { return "" }
 func (i ClusterInfo) GetExpiry() time.Time            { return i.Expiry }
 func (i ClusterInfo) GetState() string                { return i.State }
 func (i ClusterInfo) GetNodes() []deployment.ClusterNodeInfo {
-	return nil
+	var nodes []deployment.ClusterNodeInfo
+	for _, node := range i.Nodes {
+		nodes = append(nodes, node)
+	}
+	return nodes
 }
+
+// ClusterNodeInfo is a couchbase pod of the cluster, identified by its pod name.
+type ClusterNodeInfo struct {
+	PodName   string
+	IPAddress string
+}
+
+var _ (deployment.ClusterNodeInfo) = (*ClusterNodeInfo)(nil)
+
+func (i ClusterNodeInfo) GetID() string         { return i.PodName }
+func (i ClusterNodeInfo) IsClusterNode() bool   { return true }
+func (i ClusterNodeInfo) GetResourceID() string { return i.PodName }
+func (i ClusterNodeInfo) GetName() string       { return i.PodName }
+func (i ClusterNodeInfo) GetIPAddress() string  { return i.IPAddress }
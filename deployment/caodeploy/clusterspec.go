@@ -0,0 +1,355 @@
+package caodeploy
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func validateQuantity(name string, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	_, err := resource.ParseQuantity(value)
+	if err != nil {
+		return errors.Wrapf(err, "invalid %s '%s'", name, value)
+	}
+
+	return nil
+}
+
+// serverGroupZone returns the zone which backs a server group.
+func serverGroupZone(def *clusterdef.Cluster, serverGroup string) string {
+	if zone, ok := def.Cao.ServerGroupZones[serverGroup]; ok {
+		return zone
+	}
+	return serverGroup
+}
+
+// generateServerGroups returns the zones used by the node groups of a
+// cluster, in a stable order.
+func generateServerGroups(def *clusterdef.Cluster) []string {
+	zoneSet := make(map[string]struct{})
+	for _, nodeGrp := range def.NodeGroups {
+		if nodeGrp.ServerGroup != "" {
+			zoneSet[serverGroupZone(def, nodeGrp.ServerGroup)] = struct{}{}
+		}
+	}
+
+	var zones []string
+	for zone := range zoneSet {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+
+	return zones
+}
+
+func generateServerResources(nodeGrp *clusterdef.NodeGroup, isOpenShift bool) (map[string]interface{}, error) {
+	requests := make(map[string]interface{})
+	limits := make(map[string]interface{})
+
+	fields := []struct {
+		name   string
+		value  string
+		target map[string]interface{}
+		key    string
+	}{
+		{"cpu request", nodeGrp.Cao.CpuRequest, requests, "cpu"},
+		{"memory request", nodeGrp.Cao.MemoryRequest, requests, "memory"},
+		{"cpu limit", nodeGrp.Cao.CpuLimit, limits, "cpu"},
+		{"memory limit", nodeGrp.Cao.MemoryLimit, limits, "memory"},
+	}
+	for _, field := range fields {
+		err := validateQuantity(field.name, field.value)
+		if err != nil {
+			return nil, err
+		}
+
+		if field.value != "" {
+			field.target[field.key] = field.value
+		}
+	}
+
+	// openshift nodes are too small for couchbase without explicit requests
+	if isOpenShift && len(requests) == 0 {
+		requests["cpu"] = "2"
+		requests["memory"] = "4Gi"
+	}
+
+	resources := make(map[string]interface{})
+	if len(requests) > 0 {
+		resources["requests"] = requests
+	}
+	if len(limits) > 0 {
+		resources["limits"] = limits
+	}
+
+	if len(resources) == 0 {
+		return nil, nil
+	}
+	return resources, nil
+}
+
+func volumeClaimTemplateName(nodeGrpIdx int) string {
+	return fmt.Sprintf("group-%d", nodeGrpIdx)
+}
+
+// generateVolumeClaimTemplate returns the claim template for the volumes of
+// a node group, or nil if the group uses ephemeral storage.
+func generateVolumeClaimTemplate(
+	def *clusterdef.Cluster,
+	nodeGrpIdx int,
+	nodeGrp *clusterdef.NodeGroup,
+) (map[string]interface{}, error) {
+	if nodeGrp.Cao.VolumeSize == "" {
+		if nodeGrp.Cao.StorageClass != "" {
+			return nil, errors.New("a storage class requires a volume size")
+		}
+		return nil, nil
+	}
+
+	err := validateQuantity("volume size", nodeGrp.Cao.VolumeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	claimSpec := map[string]interface{}{
+		"resources": map[string]interface{}{
+			"requests": map[string]interface{}{
+				"storage": nodeGrp.Cao.VolumeSize,
+			},
+		},
+	}
+
+	storageClass := def.Cao.StorageClass
+	if nodeGrp.Cao.StorageClass != "" {
+		storageClass = nodeGrp.Cao.StorageClass
+	}
+	if storageClass != "" {
+		claimSpec["storageClassName"] = storageClass
+	}
+
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name": volumeClaimTemplateName(nodeGrpIdx),
+		},
+		"spec": claimSpec,
+	}, nil
+}
+
+// generateMemoryQuotas returns the cluster settings for the service memory
+// quotas of a cluster, or nil if none are specified.
+func generateMemoryQuotas(cao *clusterdef.CaoCluster) map[string]interface{} {
+	quotas := []struct {
+		key   string
+		quota int
+	}{
+		{"dataServiceMemoryQuota", cao.KvMemoryMB},
+		{"indexServiceMemoryQuota", cao.IndexMemoryMB},
+		{"searchServiceMemoryQuota", cao.FtsMemoryMB},
+		{"analyticsServiceMemoryQuota", cao.CbasMemoryMB},
+		{"eventingServiceMemoryQuota", cao.EventingMemoryMB},
+	}
+
+	settings := make(map[string]interface{})
+	for _, quota := range quotas {
+		if quota.quota > 0 {
+			settings[quota.key] = fmt.Sprintf("%dMi", quota.quota)
+		}
+	}
+
+	if len(settings) == 0 {
+		return nil
+	}
+	return settings
+}
+
+func generateTlsSpec() map[string]interface{} {
+	return map[string]interface{}{
+		"secretSource": map[string]interface{}{
+			"serverSecretName": serverTlsSecretName,
+		},
+		"rootCAs": []string{caTlsSecretName},
+	}
+}
+
+// couchbaseClusterSpec is the subset of the CouchbaseCluster spec which is
+// generated by generateClusterSpec.
+type couchbaseClusterSpec struct {
+	Image   string `json:"image"`
+	Cluster struct {
+		DataServiceMemoryQuota      string `json:"dataServiceMemoryQuota"`
+		IndexServiceMemoryQuota     string `json:"indexServiceMemoryQuota"`
+		SearchServiceMemoryQuota    string `json:"searchServiceMemoryQuota"`
+		AnalyticsServiceMemoryQuota string `json:"analyticsServiceMemoryQuota"`
+		EventingServiceMemoryQuota  string `json:"eventingServiceMemoryQuota"`
+	} `json:"cluster"`
+	Networking struct {
+		TLS *struct {
+			SecretSource struct {
+				ServerSecretName string `json:"serverSecretName"`
+			} `json:"secretSource"`
+		} `json:"tls"`
+		CloudNativeGateway *struct {
+			Image    string `json:"image"`
+			LogLevel string `json:"logLevel"`
+		} `json:"cloudNativeGateway"`
+	} `json:"networking"`
+	Servers []struct {
+		Size         int      `json:"size"`
+		Services     []string `json:"services"`
+		ServerGroups []string `json:"serverGroups"`
+		Resources    struct {
+			Requests map[string]string `json:"requests"`
+			Limits   map[string]string `json:"limits"`
+		} `json:"resources"`
+		VolumeMounts struct {
+			Default string `json:"default"`
+		} `json:"volumeMounts"`
+	} `json:"servers"`
+	VolumeClaimTemplates []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Spec struct {
+			StorageClassName string `json:"storageClassName"`
+			Resources        struct {
+				Requests map[string]string `json:"requests"`
+			} `json:"resources"`
+		} `json:"spec"`
+	} `json:"volumeClaimTemplates"`
+}
+
+// versionFromImage returns the version which generates an image, or the
+// image itself when it is not one of the images we would pick.
+func versionFromImage(image string, imageName string) string {
+	for _, repo := range []string{"couchbase/", "ghcr.io/cb-vanilla/", "ghcr.io/cb-rhcc/"} {
+		tag, found := strings.CutPrefix(image, repo+imageName+":")
+		if found {
+			return tag
+		}
+	}
+
+	return "@" + image
+}
+
+func memoryQuotaMB(quota string) (int, error) {
+	if quota == "" {
+		return 0, nil
+	}
+
+	parsedQuota, err := resource.ParseQuantity(quota)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid memory quota '%s'", quota)
+	}
+
+	return int(parsedQuota.Value() / 1024 / 1024), nil
+}
+
+// clusterDefFromSpec reverses generateClusterSpec.  Server groups can only be
+// recovered as their zone names, since that is what the operator uses.
+func clusterDefFromSpec(annotations map[string]string, rawSpec interface{}) (*clusterdef.Cluster, error) {
+	specBytes, err := json.Marshal(rawSpec)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal cluster spec")
+	}
+
+	var spec couchbaseClusterSpec
+	err = json.Unmarshal(specBytes, &spec)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal cluster spec")
+	}
+
+	def := &clusterdef.Cluster{}
+
+	version := versionFromImage(spec.Image, "server")
+
+	for serverIdx, server := range spec.Servers {
+		services := make([]clusterdef.Service, 0, len(server.Services))
+		for _, caoService := range server.Services {
+			service, err := clusterdef.CaoServiceToService(caoService)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse service '%s'", caoService)
+			}
+
+			services = append(services, service)
+		}
+
+		nodeGrp := &clusterdef.NodeGroup{
+			Count:    server.Size,
+			Version:  version,
+			Services: services,
+			Cao: clusterdef.CaoNodeGroup{
+				CpuRequest:    server.Resources.Requests["cpu"],
+				MemoryRequest: server.Resources.Requests["memory"],
+				CpuLimit:      server.Resources.Limits["cpu"],
+				MemoryLimit:   server.Resources.Limits["memory"],
+			},
+		}
+
+		if len(server.ServerGroups) > 1 {
+			return nil, fmt.Errorf("server %d uses multiple server groups", serverIdx)
+		} else if len(server.ServerGroups) == 1 {
+			nodeGrp.ServerGroup = server.ServerGroups[0]
+		}
+
+		if server.VolumeMounts.Default != "" {
+			found := false
+			for _, tmpl := range spec.VolumeClaimTemplates {
+				if tmpl.Metadata.Name == server.VolumeMounts.Default {
+					nodeGrp.Cao.VolumeSize = tmpl.Spec.Resources.Requests["storage"]
+					nodeGrp.Cao.StorageClass = tmpl.Spec.StorageClassName
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("server %d uses unknown volume claim template '%s'", serverIdx, server.VolumeMounts.Default)
+			}
+		}
+
+		def.NodeGroups = append(def.NodeGroups, nodeGrp)
+	}
+
+	quotas := []struct {
+		quota  string
+		target *int
+	}{
+		{spec.Cluster.DataServiceMemoryQuota, &def.Cao.KvMemoryMB},
+		{spec.Cluster.IndexServiceMemoryQuota, &def.Cao.IndexMemoryMB},
+		{spec.Cluster.SearchServiceMemoryQuota, &def.Cao.FtsMemoryMB},
+		{spec.Cluster.AnalyticsServiceMemoryQuota, &def.Cao.CbasMemoryMB},
+		{spec.Cluster.EventingServiceMemoryQuota, &def.Cao.EventingMemoryMB},
+	}
+	for _, quota := range quotas {
+		*quota.target, err = memoryQuotaMB(quota.quota)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if spec.Networking.TLS != nil {
+		if spec.Networking.TLS.SecretSource.ServerSecretName != serverTlsSecretName {
+			return nil, errors.New("cluster uses certificates which were not generated by cbdinocluster")
+		}
+
+		def.Cao.UseDinoCerts = true
+	}
+
+	if spec.Networking.CloudNativeGateway != nil {
+		if spec.Networking.CloudNativeGateway.Image != "" {
+			def.Cao.GatewayVersion = versionFromImage(spec.Networking.CloudNativeGateway.Image, "cloud-native-gateway")
+		}
+		def.Cao.GatewayLogLevel = spec.Networking.CloudNativeGateway.LogLevel
+	}
+
+	def.Cao.GatewayOtlpEndpoint = annotations[gatewayOtlpEndpointAnnotation]
+
+	return def, nil
+}
@@ -0,0 +1,215 @@
+package caodeploy
+
+import (
+	"testing"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateServerGroups(t *testing.T) {
+	def := &clusterdef.Cluster{
+		NodeGroups: []*clusterdef.NodeGroup{
+			{ServerGroup: "group-b"},
+			{ServerGroup: "us-east-1a"},
+			{ServerGroup: "group-a"},
+			{},
+		},
+		Cao: clusterdef.CaoCluster{
+			ServerGroupZones: map[string]string{
+				"group-a": "us-east-1a",
+				"group-b": "us-east-1b",
+			},
+		},
+	}
+
+	require.Equal(t, []string{"us-east-1a", "us-east-1b"}, generateServerGroups(def))
+	require.Equal(t, "us-east-1b", serverGroupZone(def, "group-b"))
+	require.Equal(t, "us-east-1c", serverGroupZone(def, "us-east-1c"))
+}
+
+func TestGenerateServerResources(t *testing.T) {
+	tests := []struct {
+		name        string
+		cao         clusterdef.CaoNodeGroup
+		isOpenShift bool
+		want        map[string]interface{}
+		wantErr     bool
+	}{
+		{name: "none"},
+		{
+			name:        "openshift default",
+			isOpenShift: true,
+			want: map[string]interface{}{
+				"requests": map[string]interface{}{"cpu": "2", "memory": "4Gi"},
+			},
+		},
+		{
+			name:        "requests and limits",
+			cao:         clusterdef.CaoNodeGroup{CpuRequest: "500m", MemoryRequest: "2Gi", MemoryLimit: "4Gi"},
+			isOpenShift: true,
+			want: map[string]interface{}{
+				"requests": map[string]interface{}{"cpu": "500m", "memory": "2Gi"},
+				"limits":   map[string]interface{}{"memory": "4Gi"},
+			},
+		},
+		{name: "invalid", cao: clusterdef.CaoNodeGroup{CpuLimit: "lots"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := generateServerResources(&clusterdef.NodeGroup{Cao: tt.cao}, tt.isOpenShift)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGenerateVolumeClaimTemplate(t *testing.T) {
+	def := &clusterdef.Cluster{
+		Cao: clusterdef.CaoCluster{StorageClass: "standard"},
+	}
+
+	tmpl, err := generateVolumeClaimTemplate(def, 0, &clusterdef.NodeGroup{})
+	require.NoError(t, err)
+	require.Nil(t, tmpl)
+
+	tmpl, err = generateVolumeClaimTemplate(def, 1, &clusterdef.NodeGroup{
+		Cao: clusterdef.CaoNodeGroup{VolumeSize: "10Gi"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "group-1"},
+		"spec": map[string]interface{}{
+			"storageClassName": "standard",
+			"resources": map[string]interface{}{
+				"requests": map[string]interface{}{"storage": "10Gi"},
+			},
+		},
+	}, tmpl)
+
+	tmpl, err = generateVolumeClaimTemplate(def, 2, &clusterdef.NodeGroup{
+		Cao: clusterdef.CaoNodeGroup{VolumeSize: "20Gi", StorageClass: "fast"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "fast", tmpl["spec"].(map[string]interface{})["storageClassName"])
+
+	_, err = generateVolumeClaimTemplate(def, 3, &clusterdef.NodeGroup{
+		Cao: clusterdef.CaoNodeGroup{StorageClass: "fast"},
+	})
+	require.Error(t, err)
+}
+
+func TestGenerateMemoryQuotas(t *testing.T) {
+	require.Nil(t, generateMemoryQuotas(&clusterdef.CaoCluster{}))
+	require.Equal(t, map[string]interface{}{
+		"dataServiceMemoryQuota":  "1024Mi",
+		"indexServiceMemoryQuota": "512Mi",
+	}, generateMemoryQuotas(&clusterdef.CaoCluster{KvMemoryMB: 1024, IndexMemoryMB: 512}))
+}
+
+func TestVersionFromImage(t *testing.T) {
+	require.Equal(t, "7.6.5", versionFromImage("couchbase/server:7.6.5", "server"))
+	require.Equal(t, "community-7.6.5", versionFromImage("couchbase/server:community-7.6.5", "server"))
+	require.Equal(t, "8.0.0-1234", versionFromImage("ghcr.io/cb-vanilla/server:8.0.0-1234", "server"))
+	require.Equal(t, "1.0.1", versionFromImage("couchbase/cloud-native-gateway:1.0.1", "cloud-native-gateway"))
+	require.Equal(t, "@example.com/server:custom", versionFromImage("example.com/server:custom", "server"))
+}
+
+func TestClusterDefFromSpec(t *testing.T) {
+	// the spec as it is read back from kubernetes, rather than as generated
+	spec := map[string]interface{}{
+		"image": "couchbase/server:7.6.5",
+		"cluster": map[string]interface{}{
+			"dataServiceMemoryQuota": "1Gi",
+		},
+		"networking": map[string]interface{}{
+			"exposeAdminConsole": true,
+			"tls": map[string]interface{}{
+				"secretSource": map[string]interface{}{"serverSecretName": serverTlsSecretName},
+				"rootCAs":      []interface{}{caTlsSecretName},
+			},
+			"cloudNativeGateway": map[string]interface{}{
+				"image":    "ghcr.io/cb-vanilla/cloud-native-gateway:1.1.0-12",
+				"logLevel": "debug",
+			},
+		},
+		"servers": []interface{}{
+			map[string]interface{}{
+				"size":         int64(2),
+				"name":         "group_0",
+				"services":     []interface{}{"data", "query"},
+				"serverGroups": []interface{}{"us-east-1a"},
+				"resources": map[string]interface{}{
+					"requests": map[string]interface{}{"cpu": "1", "memory": "4Gi"},
+				},
+				"volumeMounts": map[string]interface{}{"default": "group-0"},
+			},
+			map[string]interface{}{
+				"size":     int64(1),
+				"name":     "group_1",
+				"services": []interface{}{"index"},
+			},
+		},
+		"volumeClaimTemplates": []interface{}{
+			map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "group-0"},
+				"spec": map[string]interface{}{
+					"storageClassName": "standard",
+					"resources": map[string]interface{}{
+						"requests": map[string]interface{}{"storage": "10Gi"},
+					},
+				},
+			},
+		},
+	}
+
+	def, err := clusterDefFromSpec(map[string]string{
+		gatewayOtlpEndpointAnnotation: "otel:4317",
+	}, spec)
+	require.NoError(t, err)
+
+	require.Equal(t, []*clusterdef.NodeGroup{
+		{
+			Count:       2,
+			ServerGroup: "us-east-1a",
+			Version:     "7.6.5",
+			Services:    []clusterdef.Service{clusterdef.KvService, clusterdef.QueryService},
+			Cao: clusterdef.CaoNodeGroup{
+				CpuRequest:    "1",
+				MemoryRequest: "4Gi",
+				VolumeSize:    "10Gi",
+				StorageClass:  "standard",
+			},
+		},
+		{
+			Count:    1,
+			Version:  "7.6.5",
+			Services: []clusterdef.Service{clusterdef.IndexService},
+		},
+	}, def.NodeGroups)
+
+	require.Equal(t, clusterdef.CaoCluster{
+		GatewayVersion:      "1.1.0-12",
+		GatewayLogLevel:     "debug",
+		GatewayOtlpEndpoint: "otel:4317",
+		KvMemoryMB:          1024,
+		UseDinoCerts:        true,
+	}, def.Cao)
+}
+
+func TestClusterDefFromSpecUnknownVolume(t *testing.T) {
+	_, err := clusterDefFromSpec(nil, map[string]interface{}{
+		"servers": []interface{}{
+			map[string]interface{}{
+				"size":         int64(1),
+				"volumeMounts": map[string]interface{}{"default": "missing"},
+			},
+		},
+	})
+	require.Error(t, err)
+}
@@ -27,6 +27,8 @@ const (
 	CngServiceName = CouchbaseClusterName + "-cloud-native-gateway-service"
 )
 
+const gatewayOtlpEndpointAnnotation = "cao.couchbase.com/networking.cloudNativeGateway.otlp.endpoint"
+
 func withAgent[T any](d *Deployer, ctx context.Context, clusterID string, fn func(agent *gocbcorex.Agent) (T, error)) (T, error) {
 	agent, err := d.getAgent(ctx, clusterID, "")
 	if err != nil {
@@ -143,6 +145,12 @@ func (d *Deployer) getAgent(ctx context.Context, clusterID string, bucketName st
 	return agent, nil
 }
 
+// OpenBucketAgent opens an agent for a bucket of the cluster as the cluster
+// administrator, which the caller must close.
+func (d *Deployer) OpenBucketAgent(ctx context.Context, clusterID string, bucketName string, opts *deployment.OpenBucketAgentOptions) (*gocbcorex.Agent, error) {
+	return d.getAgent(ctx, clusterID, bucketName)
+}
+
 func (d *Deployer) getMgmtx(ctx context.Context, clusterID string) (*cbmgmtx.Management, error) {
 	username, password, err := d.getAdminAuth(ctx, clusterID)
 	if err != nil {
@@ -236,10 +244,23 @@ func (d *Deployer) ListClusters(ctx context.Context) ([]deployment.ClusterInfo,
 				}
 			}
 
+			var nodes []*ClusterNodeInfo
+			pods, err := d.listClusterPods(ctx, namespace.Name)
+			if err != nil {
+				d.logger.Debug("failed to list cluster pods", zap.Error(err))
+			}
+			for _, pod := range pods {
+				nodes = append(nodes, &ClusterNodeInfo{
+					PodName:   pod.Name,
+					IPAddress: pod.Status.PodIP,
+				})
+			}
+
 			clusters = append(clusters, &ClusterInfo{
 				ClusterID: namespace.Labels["cbdc2.cluster_id"],
 				Expiry:    expiryTime,
 				State:     clusterStatus,
+				Nodes:     nodes,
 			})
 		}
 	}
@@ -288,6 +309,7 @@ func (d *Deployer) generateClusterSpec(
 	}
 
 	var serversRes []interface{}
+	var volumeClaimTemplates []interface{}
 	for nodeGrpIdx, nodeGrp := range def.NodeGroups {
 		caoServices, err := clusterdef.ServicesToCaoServices(nodeGrp.Services)
 		if err != nil {
@@ -309,13 +331,27 @@ func (d *Deployer) generateClusterSpec(
 			},
 		}
 
-		if isOpenShift {
-			serverEntry["resources"] = map[string]interface{}{
-				"requests": map[string]interface{}{
-					"cpu":    "2",
-					"memory": "4Gi",
-				},
+		resources, err := generateServerResources(nodeGrp, isOpenShift)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to generate resources of node group %d", nodeGrpIdx)
+		}
+		if resources != nil {
+			serverEntry["resources"] = resources
+		}
+
+		volumeClaimTemplate, err := generateVolumeClaimTemplate(def, nodeGrpIdx, nodeGrp)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to generate volumes of node group %d", nodeGrpIdx)
+		}
+		if volumeClaimTemplate != nil {
+			serverEntry["volumeMounts"] = map[string]interface{}{
+				"default": volumeClaimTemplateName(nodeGrpIdx),
 			}
+			volumeClaimTemplates = append(volumeClaimTemplates, volumeClaimTemplate)
+		}
+
+		if nodeGrp.ServerGroup != "" {
+			serverEntry["serverGroups"] = []string{serverGroupZone(def, nodeGrp.ServerGroup)}
 		}
 
 		serversRes = append(serversRes, serverEntry)
@@ -352,9 +388,32 @@ func (d *Deployer) generateClusterSpec(
 		"servers": serversRes,
 	}
 
+	if len(volumeClaimTemplates) > 0 {
+		clusterSpec["volumeClaimTemplates"] = volumeClaimTemplates
+	}
+
+	serverGroups := generateServerGroups(def)
+	if len(serverGroups) > 0 {
+		clusterSpec["serverGroups"] = serverGroups
+	}
+
+	memoryQuotas := generateMemoryQuotas(&def.Cao)
+	if memoryQuotas != nil {
+		clusterSpec["cluster"] = memoryQuotas
+	}
+
+	if def.Cao.UseDinoCerts {
+		_, err := newDinoCertKey(&def.Cao.DinoCerts)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "invalid dino cert settings")
+		}
+
+		clusterSpec["networking"].(map[string]interface{})["tls"] = generateTlsSpec()
+	}
+
 	annotations := make(map[string]string)
 	if gatewayOtlpEndpoint != "" {
-		annotations["cao.couchbase.com/networking.cloudNativeGateway.otlp.endpoint"] = gatewayOtlpEndpoint
+		annotations[gatewayOtlpEndpointAnnotation] = gatewayOtlpEndpoint
 	}
 
 	return annotations, clusterSpec, nil
@@ -366,7 +425,7 @@ func (d *Deployer) NewCluster(ctx context.Context, def *clusterdef.Cluster) (dep
 		return nil, errors.Wrap(err, "failed to detect whether we are using openshift")
 	}
 	if def.Columnar {
-		return nil, errors.New("columnar is not supported for caodeploy")
+		return nil, deployment.NewNotSupportedError("columnar is not supported for caodeploy")
 	}
 	clusterID := cbdcuuid.New()
 	namespace := "cbdc2-" + clusterID.String()
@@ -385,6 +444,13 @@ func (d *Deployer) NewCluster(ctx context.Context, def *clusterdef.Cluster) (dep
 		password = def.Cao.Password
 	}
 
+	// the spec is generated up-front so an invalid definition fails before
+	// any resources are created.
+	clusterAnnotations, clusterSpec, err := d.generateClusterSpec(ctx, def, isOpenShift)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate cluster spec")
+	}
+
 	err = d.client.CreateNamespace(ctx, namespace, map[string]string{
 		"cbdc2.type":       "cluster",
 		"cbdc2.cluster_id": clusterID.String(),
@@ -410,9 +476,11 @@ func (d *Deployer) NewCluster(ctx context.Context, def *clusterdef.Cluster) (dep
 		return nil, errors.Wrap(err, "failed to create admin auth")
 	}
 
-	clusterAnnotations, clusterSpec, err := d.generateClusterSpec(ctx, def, isOpenShift)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to generate cluster spec")
+	if def.Cao.UseDinoCerts {
+		err = d.installDinoCerts(ctx, clusterID.String(), namespace, &def.Cao.DinoCerts)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to install dino certs")
+		}
 	}
 
 	err = d.client.CreateCouchbaseCluster(ctx,
@@ -453,17 +521,51 @@ func (d *Deployer) NewCluster(ctx context.Context, def *clusterdef.Cluster) (dep
 
 	return ClusterInfo{
 		ClusterID: clusterID.String(),
-		Expiry:    time.Time{},
+		Expiry:    expiryTime,
 		State:     "running",
 	}, nil
 }
 
 func (d *Deployer) GetDefinition(ctx context.Context, clusterID string) (*clusterdef.Cluster, error) {
-	return nil, errors.New("caodeploy does not support fetching the cluster definition")
+	namespaceName, err := d.getClusterNamespace(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace, err := d.client.GetNamespace(ctx, namespaceName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster namespace")
+	}
+
+	cluster, err := d.client.GetCouchbaseCluster(ctx, namespaceName, CouchbaseClusterName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster resource")
+	}
+
+	def, err := clusterDefFromSpec(cluster.GetAnnotations(), cluster.Object["spec"])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse cluster spec")
+	}
+
+	def.Purpose = namespace.Labels["cbdc2.purpose"]
+
+	return def, nil
 }
 
 func (d *Deployer) UpdateClusterExpiry(ctx context.Context, clusterID string, newExpiryTime time.Time) error {
-	return errors.New("caodeploy does not support updating cluster expiry")
+	namespaceName, err := d.getClusterNamespace(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	err = d.client.UpdateNamespaceLabels(ctx, namespaceName, map[string]string{
+		"cbdc2.expiry": d.formatExpiry(newExpiryTime),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to update cluster expiry")
+	}
+
+	return nil
 }
 
 func (d *Deployer) ModifyCluster(ctx context.Context, clusterID string, def *clusterdef.Cluster) error {
@@ -488,6 +590,13 @@ func (d *Deployer) ModifyCluster(ctx context.Context, clusterID string, def *clu
 		return errors.Wrap(err, "failed to generate cluster spec")
 	}
 
+	if def.Cao.UseDinoCerts {
+		err = d.installDinoCerts(ctx, clusterID, namespaceName, &def.Cao.DinoCerts)
+		if err != nil {
+			return errors.Wrap(err, "failed to install dino certs")
+		}
+	}
+
 	err = d.client.UpdateCouchbaseClusterSpec(ctx, namespaceName, CouchbaseClusterName, clusterAnnotations, clusterSpec)
 	if err != nil {
 		return errors.Wrap(err, "failed to update cluster spec")
@@ -497,11 +606,11 @@ func (d *Deployer) ModifyCluster(ctx context.Context, clusterID string, def *clu
 }
 
 func (d *Deployer) AddNode(ctx context.Context, clusterID string) (string, error) {
-	return "", errors.New("caodeploy does not support cluster node addition")
+	return "", deployment.NewNotSupportedError("caodeploy does not support cluster node addition")
 }
 
 func (d *Deployer) RemoveNode(ctx context.Context, clusterID string, nodeID string) error {
-	return errors.New("caodeploy does not support cluster node removal")
+	return deployment.NewNotSupportedError("caodeploy does not support cluster node removal")
 }
 
 func (d *Deployer) getClusterNamespace(ctx context.Context, clusterID string) (string, error) {
@@ -967,16 +1076,51 @@ func (d *Deployer) Cleanup(ctx context.Context) error {
 	return nil
 }
 
+func (d *Deployer) getUsersHelper(ctx context.Context, clusterID string) (*commondeploy.UsersHelper, error) {
+	nodeMgr, err := d.getNodeManager(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &commondeploy.UsersHelper{
+		Controller: nodeMgr.Controller(),
+	}, nil
+}
+
 func (d *Deployer) ListUsers(ctx context.Context, clusterID string) ([]deployment.UserInfo, error) {
-	return nil, errors.New("caodeploy does not support listing users")
+	helper, err := d.getUsersHelper(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.ListUsers(ctx)
 }
 
 func (d *Deployer) CreateUser(ctx context.Context, clusterID string, opts *deployment.CreateUserOptions) error {
-	return errors.New("caodeploy does not support creating users")
+	helper, err := d.getUsersHelper(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	return helper.CreateUser(ctx, opts)
 }
 
 func (d *Deployer) DeleteUser(ctx context.Context, clusterID string, username string) error {
-	return errors.New("caodeploy does not support deleting users")
+	helper, err := d.getUsersHelper(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	return helper.DeleteUser(ctx, username)
+}
+
+func (d *Deployer) CreateGroup(ctx context.Context, clusterID string, opts *deployment.CreateGroupOptions) error {
+	helper, err := d.getUsersHelper(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	return helper.CreateGroup(ctx, opts)
 }
 
 func (d *Deployer) ListBuckets(ctx context.Context, clusterID string) ([]deployment.BucketInfo, error) {
@@ -999,12 +1143,46 @@ func (d *Deployer) DeleteBucket(ctx context.Context, clusterID string, bucketNam
 	return err
 }
 
-func (d *Deployer) LoadSampleBucket(ctx context.Context, clusterID string, bucketName string) error {
-	return errors.New("caodeploy does not support loading sample buckets")
+func (d *Deployer) getBucketHelper(ctx context.Context, clusterID string) (*commondeploy.BucketHelper, error) {
+	nodeMgr, err := d.getNodeManager(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &commondeploy.BucketHelper{
+		Controller: nodeMgr.Controller(),
+	}, nil
 }
 
-func (d *Deployer) GetCertificate(ctx context.Context, clusterID string) (string, error) {
-	return "", errors.New("caodeploy does not support getting certificates")
+func (d *Deployer) GetBucket(ctx context.Context, clusterID string, bucketName string) (*deployment.BucketDetails, error) {
+	helper, err := d.getBucketHelper(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.GetBucket(ctx, bucketName)
+}
+
+func (d *Deployer) UpdateBucket(ctx context.Context, clusterID string, bucketName string, opts *deployment.UpdateBucketOptions) error {
+	helper, err := d.getBucketHelper(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	return helper.UpdateBucket(ctx, bucketName, opts)
+}
+
+func (d *Deployer) FlushBucket(ctx context.Context, clusterID string, bucketName string) error {
+	helper, err := d.getBucketHelper(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	return helper.FlushBucket(ctx, bucketName)
+}
+
+func (d *Deployer) LoadSampleBucket(ctx context.Context, clusterID string, bucketName string) error {
+	return deployment.NewNotSupportedError("caodeploy does not support loading sample buckets")
 }
 
 func (d *Deployer) GetGatewayCertificate(ctx context.Context, clusterID string) (string, error) {
@@ -1027,7 +1205,7 @@ func (d *Deployer) GetGatewayCertificate(ctx context.Context, clusterID string)
 }
 
 func (d *Deployer) GetMetrics(ctx context.Context, clusterID string) (string, error) {
-	return "", errors.New("caodeploy does not support getting metrics")
+	return "", deployment.NewNotSupportedError("caodeploy does not support getting metrics")
 }
 
 func (d *Deployer) ExecuteQuery(ctx context.Context, clusterID string, query string, opts *deployment.ExecuteQueryOptions) (string, error) {
@@ -1070,16 +1248,12 @@ func (d *Deployer) DeleteCollection(ctx context.Context, clusterID string, bucke
 	return err
 }
 
-func (d *Deployer) BlockNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string, trafficType deployment.BlockNodeTrafficType, rejectType string) error {
-	return errors.New("caodeploy does not support traffic control")
-}
-
-func (d *Deployer) AllowNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string) error {
-	return errors.New("caodeploy does not support traffic control")
+func (d *Deployer) DegradeNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string, opts *deployment.DegradeNodeTrafficOptions) error {
+	return deployment.NewNotSupportedError("caodeploy does not support degrading traffic")
 }
 
-func (d *Deployer) PartitionNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string, rejectType string) error {
-	return errors.New("caodeploy does not support traffic control")
+func (d *Deployer) PartitionNodeGroups(ctx context.Context, clusterID string, spec *deployment.PartitionSpec, rejectType string) error {
+	return deployment.NewNotSupportedError("caodeploy does not support partitioning node groups")
 }
 
 func (d *Deployer) CollectLogs(ctx context.Context, clusterID string, destPath string) ([]string, error) {
@@ -1097,61 +1271,72 @@ func (d *Deployer) CollectLogs(ctx context.Context, clusterID string, destPath s
 }
 
 func (d *Deployer) ListImages(ctx context.Context) ([]deployment.Image, error) {
-	return nil, errors.New("caodeploy does not support image listing")
+	return nil, deployment.NewNotSupportedError("caodeploy does not support image listing")
 }
 
 func (d *Deployer) SearchImages(ctx context.Context, version string) ([]deployment.Image, error) {
-	return nil, errors.New("caodeploy does not support image search")
-}
-
-func (d *Deployer) PauseNode(ctx context.Context, clusterID string, nodeIDs []string) error {
-	return errors.New("caodeploy does not support node pausing")
-}
-
-func (d *Deployer) UnpauseNode(ctx context.Context, clusterID string, nodeIDs []string) error {
-	return errors.New("caodeploy does not support node pausing")
+	return nil, deployment.NewNotSupportedError("caodeploy does not support image search")
 }
 
 func (d *Deployer) RedeployCluster(ctx context.Context, clusterID string) error {
-	return errors.New("caodeploy does not support redeploy cluster")
+	return deployment.NewNotSupportedError("caodeploy does not support redeploy cluster")
 }
 
 func (d *Deployer) CreateCapellaLink(ctx context.Context, columnarID, linkName, clusterId, directID string) error {
-	return errors.New("caodeploy does not support create capella link")
+	return deployment.NewNotSupportedError("caodeploy does not support create capella link")
 }
 
 func (d *Deployer) CreateS3Link(ctx context.Context, columnarID, linkName, region, endpoint, accessKey, secretKey string) error {
-	return errors.New("caodeploy does not support create S3 link")
+	return deployment.NewNotSupportedError("caodeploy does not support create S3 link")
 }
 
 func (d *Deployer) DropLink(ctx context.Context, columnarID, linkName string) error {
-	return errors.New("caodeploy does not support drop link")
+	return deployment.NewNotSupportedError("caodeploy does not support drop link")
 }
 
 func (d *Deployer) UpgradeCluster(ctx context.Context, clusterID string, CurrentImages string, NewImage string) error {
-	return errors.New("caodeploy does not support upgrade cluster command")
+	return deployment.NewNotSupportedError("caodeploy does not support upgrade cluster command")
 }
 
 func (d *Deployer) EnableDataApi(ctx context.Context, clusterID string) error {
-	return errors.New("caodeploy does not support enabling data api")
-}
-
-func (d *Deployer) FailOverNode(ctx context.Context, clusterID string, nodeID string, failOverType deployment.FailOverType, allowUnsafe bool) error {
-	return errors.New("caodeploy does not support failing over a node")
-}
-
-func (d *Deployer) SetNodeRecovery(ctx context.Context, clusterID string, nodeID string, recoverType deployment.RecoveryType) error {
-	return errors.New("caodeploy does not support failover recovery")
-}
-
-func (d *Deployer) RebalanceCluster(ctx context.Context, clusterID string, nodesToEject []string) error {
-	return errors.New("caodeploy does not support rebalance cluster")
-}
-
-func (d *Deployer) KillCouchbase(ctx context.Context, clusterID string, nodes []string) error {
-	return errors.New("caodeploy does not support killing couchbase process")
+	return deployment.NewNotSupportedError("caodeploy does not support enabling data api")
 }
 
 func (d *Deployer) SetAutoFailover(ctx context.Context, clusterID string, enabled bool, timeout int) error {
-	return errors.New("caodeploy does not support setting auto-failover")
+	return deployment.NewNotSupportedError("caodeploy does not support setting auto-failover")
+}
+
+func (d *Deployer) StopCluster(ctx context.Context, clusterID string) error {
+	return deployment.NewNotSupportedError("caodeploy does not support stopping clusters")
+}
+
+func (d *Deployer) StartCluster(ctx context.Context, clusterID string) error {
+	return deployment.NewNotSupportedError("caodeploy does not support starting clusters")
+}
+
+// Capabilities reports traffic control for blocking and partitioning nodes,
+// which are implemented with network policies, but not for degrading traffic
+// or partitioning groups of nodes.
+func (d *Deployer) Capabilities(ctx context.Context) ([]deployment.Capability, error) {
+	return []deployment.Capability{
+		deployment.CapabilityGetDefinition,
+		deployment.CapabilityUpdateExpiry,
+		deployment.CapabilityModifyCluster,
+		deployment.CapabilityFailOver,
+		deployment.CapabilityRebalance,
+		deployment.CapabilityUsers,
+		deployment.CapabilityBuckets,
+		deployment.CapabilityCollections,
+		deployment.CapabilityQuery,
+		deployment.CapabilityCertificates,
+		deployment.CapabilityGatewayCertificates,
+		deployment.CapabilityCollectLogs,
+		deployment.CapabilityTrafficControl,
+		deployment.CapabilityPauseNode,
+		deployment.CapabilityKillCouchbase,
+		deployment.CapabilityXdcr,
+		deployment.CapabilityIndexes,
+		deployment.CapabilitySearchIndexes,
+		deployment.CapabilityDocuments,
+	}, nil
 }
@@ -0,0 +1,158 @@
+package caodeploy
+
+import (
+	"context"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/utils/dinocerts"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	serverTlsSecretName = "cbdc2-server-tls"
+	caTlsSecretName     = "cbdc2-ca-tls"
+)
+
+func newDinoCertKey(settings *clusterdef.DinoCertSettings) (dinocerts.KeySpec, error) {
+	if settings.Passphrase != "" {
+		return dinocerts.KeySpec{}, deployment.NewNotSupportedError("caodeploy does not support encrypted certificate keys")
+	}
+
+	key := dinocerts.KeySpec{
+		Type:   dinocerts.KeyType(settings.KeyType),
+		Format: dinocerts.KeyFormat(settings.KeyFormat),
+	}
+
+	err := key.Validate()
+	if err != nil {
+		return dinocerts.KeySpec{}, err
+	}
+
+	return key, nil
+}
+
+func (d *Deployer) getClusterDinoCert(clusterID string) (*dinocerts.CertAuthority, error) {
+	rootCa, err := dinocerts.GetRootCertAuthority()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get root dino ca")
+	}
+
+	clusterCa, err := rootCa.MakeIntermediaryCA("cao-cluster-" + clusterID[:8])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster dino ca")
+	}
+
+	return clusterCa, nil
+}
+
+// clusterTlsDnsNames returns the names the operator requires the server
+// certificate to be valid for.
+func clusterTlsDnsNames(namespace string) []string {
+	return []string{
+		"*." + CouchbaseClusterName,
+		"*." + CouchbaseClusterName + "." + namespace,
+		"*." + CouchbaseClusterName + "." + namespace + ".svc",
+		"*." + CouchbaseClusterName + "." + namespace + ".svc.cluster.local",
+		CouchbaseClusterName + "-srv",
+		CouchbaseClusterName + "-srv." + namespace,
+		CouchbaseClusterName + "-srv." + namespace + ".svc",
+		"localhost",
+	}
+}
+
+// installDinoCerts creates or updates the tls secrets of a cluster.  The
+// certificates are deterministic, so reinstalling them is a no-op unless the
+// key settings have changed.
+func (d *Deployer) installDinoCerts(
+	ctx context.Context,
+	clusterID string,
+	namespace string,
+	settings *clusterdef.DinoCertSettings,
+) error {
+	key, err := newDinoCertKey(settings)
+	if err != nil {
+		return err
+	}
+
+	clusterCa, err := d.getClusterDinoCert(clusterID)
+	if err != nil {
+		return err
+	}
+
+	certPem, keyPem, err := clusterCa.MakeServerCertificateWithOptions("cao-server-"+clusterID[:8], &dinocerts.ServerCertificateOptions{
+		DNSNames: clusterTlsDnsNames(namespace),
+		Key:      key,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to generate server certificate")
+	}
+
+	var chainPem []byte
+	chainPem = append(chainPem, certPem...)
+	chainPem = append(chainPem, clusterCa.CertPem...)
+
+	d.logger.Debug("installing dino cert secrets", zap.String("namespace", namespace))
+
+	err = d.client.CreateSecret(ctx, namespace, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: caTlsSecretName,
+		},
+		Data: map[string][]byte{
+			corev1.ServiceAccountRootCAKey: clusterCa.CertPem,
+		},
+		Type: corev1.SecretTypeOpaque,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create ca secret")
+	}
+
+	err = d.client.CreateSecret(ctx, namespace, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: serverTlsSecretName,
+		},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       chainPem,
+			corev1.TLSPrivateKeyKey: keyPem,
+		},
+		Type: corev1.SecretTypeTLS,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create server tls secret")
+	}
+
+	return nil
+}
+
+func (d *Deployer) GetCertificate(ctx context.Context, clusterID string) (string, error) {
+	namespaceName, err := d.getClusterNamespace(ctx, clusterID)
+	if err != nil {
+		return "", err
+	}
+
+	cluster, err := d.client.GetCouchbaseCluster(ctx, namespaceName, CouchbaseClusterName)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get cluster resource")
+	}
+
+	_, usingTls, _ := unstructured.NestedMap(cluster.Object, "spec", "networking", "tls")
+	if !usingTls {
+		return "", deployment.NewNotSupportedError("caodeploy only supports getting certificates of clusters using dino certs")
+	}
+
+	secret, err := d.client.GetSecret(ctx, namespaceName, caTlsSecretName)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get ca secret")
+	}
+
+	certPem := secret.Data[corev1.ServiceAccountRootCAKey]
+	if len(certPem) == 0 {
+		return "", errors.New("ca secret data was unexpectedly empty")
+	}
+
+	return string(certPem), nil
+}
@@ -0,0 +1,519 @@
+package caodeploy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/utils/clustercontrol"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// these are the labels the operator applies to the pods of a cluster
+	podClusterLabel = "couchbase_cluster"
+	podNodeLabel    = "couchbase_node"
+
+	couchbaseContainerName = "couchbase-server"
+
+	chaosPolicyTypeLabel = "cbdc2.type"
+	chaosPolicyNodeLabel = "cbdc2.node"
+	chaosPolicyRuleKey   = "cbdc2.rule"
+)
+
+func (d *Deployer) listClusterPods(ctx context.Context, namespace string) ([]corev1.Pod, error) {
+	pods, err := d.client.ListPods(ctx, namespace, podClusterLabel+"="+CouchbaseClusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(pods, func(a, b corev1.Pod) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return pods, nil
+}
+
+// getNodePods returns the pods of the specified nodes, or all of the pods of
+// the cluster if no nodes are specified.
+func (d *Deployer) getNodePods(ctx context.Context, clusterID string, nodeIDs []string) (string, []corev1.Pod, []corev1.Pod, error) {
+	namespace, err := d.getClusterNamespace(ctx, clusterID)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	allPods, err := d.listClusterPods(ctx, namespace)
+	if err != nil {
+		return "", nil, nil, errors.Wrap(err, "failed to list cluster pods")
+	}
+
+	if len(nodeIDs) == 0 {
+		return namespace, allPods, allPods, nil
+	}
+
+	var pods []corev1.Pod
+	for _, nodeID := range nodeIDs {
+		idx := slices.IndexFunc(allPods, func(pod corev1.Pod) bool {
+			return pod.Name == nodeID
+		})
+		if idx < 0 {
+			return "", nil, nil, fmt.Errorf("node %s not found in cluster %s", nodeID, clusterID)
+		}
+		pods = append(pods, allPods[idx])
+	}
+
+	return namespace, pods, allPods, nil
+}
+
+func chaosPolicyName(podName string) string {
+	return "cbdc2-chaos-" + podName
+}
+
+type nodeTrafficPolicyOptions struct {
+	PodName     string
+	TrafficType deployment.BlockNodeTrafficType
+
+	// IslandPodNames are cluster pods which remain reachable when blocking
+	// node traffic, in order to partition the cluster.
+	IslandPodNames []string
+
+	// BlockedIPs are the addresses of the cluster pods which are not part of
+	// the island, and are excluded from the external traffic which is allowed.
+	BlockedIPs []string
+
+	Rule string
+}
+
+// buildNodeTrafficPolicy builds a network policy which isolates a pod.  Since
+// network policies can only allow traffic, the blocked traffic is everything
+// that is not explicitly allowed, in both directions.  DNS is always allowed
+// so that the nodes can still resolve each other.
+func buildNodeTrafficPolicy(opts *nodeTrafficPolicyOptions) (*networkingv1.NetworkPolicy, error) {
+	var peers []networkingv1.NetworkPolicyPeer
+	switch opts.TrafficType {
+	case deployment.BlockNodeTrafficNodes:
+		// anything which is not part of the cluster, whether a pod or not
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{},
+			PodSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      podClusterLabel,
+					Operator: metav1.LabelSelectorOpDoesNotExist,
+				}},
+			},
+		})
+		peers = append(peers, externalTrafficPeers(opts.BlockedIPs)...)
+
+		if len(opts.IslandPodNames) > 0 {
+			peers = append(peers, networkingv1.NetworkPolicyPeer{
+				PodSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{
+						Key:      podNodeLabel,
+						Operator: metav1.LabelSelectorOpIn,
+						Values:   opts.IslandPodNames,
+					}},
+				},
+			})
+		}
+	case deployment.BlockNodeTrafficClients:
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{podClusterLabel: CouchbaseClusterName},
+			},
+		})
+	case deployment.BlockNodeTrafficAll:
+	default:
+		return nil, fmt.Errorf("unexpected traffic type `%s`", opts.TrafficType)
+	}
+
+	udp := corev1.ProtocolUDP
+	tcp := corev1.ProtocolTCP
+	dnsPort := intstr.FromInt32(53)
+
+	// a rule with no peers allows everything, so blocking all traffic needs
+	// no rules at all rather than a rule with no peers.
+	ingress := []networkingv1.NetworkPolicyIngressRule{}
+	egress := []networkingv1.NetworkPolicyEgressRule{{
+		Ports: []networkingv1.NetworkPolicyPort{
+			{Protocol: &udp, Port: &dnsPort},
+			{Protocol: &tcp, Port: &dnsPort},
+		},
+	}}
+	if len(peers) > 0 {
+		ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{From: peers})
+		egress = append(egress, networkingv1.NetworkPolicyEgressRule{To: peers})
+	}
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: chaosPolicyName(opts.PodName),
+			Labels: map[string]string{
+				chaosPolicyTypeLabel: "chaos",
+				chaosPolicyNodeLabel: opts.PodName,
+			},
+			Annotations: map[string]string{
+				chaosPolicyRuleKey: opts.Rule,
+			},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{podNodeLabel: opts.PodName},
+			},
+			PolicyTypes: []networkingv1.PolicyType{
+				networkingv1.PolicyTypeIngress,
+				networkingv1.PolicyTypeEgress,
+			},
+			Ingress: ingress,
+			Egress:  egress,
+		},
+	}, nil
+}
+
+// externalTrafficPeers allows traffic from outside of the kubernetes cluster.
+// Some network plugins also match pod addresses against ip blocks, so the
+// addresses of blocked pods are excluded explicitly.
+func externalTrafficPeers(blockedIPs []string) []networkingv1.NetworkPolicyPeer {
+	var exceptV4, exceptV6 []string
+	for _, ip := range blockedIPs {
+		if strings.Contains(ip, ":") {
+			exceptV6 = append(exceptV6, ip+"/128")
+		} else {
+			exceptV4 = append(exceptV4, ip+"/32")
+		}
+	}
+
+	return []networkingv1.NetworkPolicyPeer{
+		{IPBlock: &networkingv1.IPBlock{CIDR: "0.0.0.0/0", Except: exceptV4}},
+		{IPBlock: &networkingv1.IPBlock{CIDR: "::/0", Except: exceptV6}},
+	}
+}
+
+func (d *Deployer) warnRejectType(rejectType string) {
+	if rejectType != "" {
+		d.logger.Warn("network policies always drop blocked traffic, ignoring reject type",
+			zap.String("rejectType", rejectType))
+	}
+}
+
+func (d *Deployer) BlockNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string, trafficType deployment.BlockNodeTrafficType, rejectType string) error {
+	d.warnRejectType(rejectType)
+
+	namespace, pods, allPods, err := d.getNodePods(ctx, clusterID, nodeIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods {
+		var blockedIPs []string
+		for _, otherPod := range allPods {
+			if otherPod.Name != pod.Name && otherPod.Status.PodIP != "" {
+				blockedIPs = append(blockedIPs, otherPod.Status.PodIP)
+			}
+		}
+
+		policy, err := buildNodeTrafficPolicy(&nodeTrafficPolicyOptions{
+			PodName:     pod.Name,
+			TrafficType: trafficType,
+			BlockedIPs:  blockedIPs,
+			Rule:        fmt.Sprintf("block %s", trafficType),
+		})
+		if err != nil {
+			return err
+		}
+
+		err = d.client.ApplyNetworkPolicy(ctx, namespace, policy)
+		if err != nil {
+			return errors.Wrapf(err, "failed to block traffic for node %s", pod.Name)
+		}
+	}
+
+	return nil
+}
+
+func (d *Deployer) AllowNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string) error {
+	namespace, pods, _, err := d.getNodePods(ctx, clusterID, nodeIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods {
+		err := d.client.DeleteNetworkPolicy(ctx, namespace, chaosPolicyName(pod.Name))
+		if err != nil {
+			return errors.Wrapf(err, "failed to allow traffic for node %s", pod.Name)
+		}
+	}
+
+	return nil
+}
+
+func (d *Deployer) PartitionNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string, rejectType string) error {
+	d.warnRejectType(rejectType)
+
+	if len(nodeIDs) == 0 {
+		return errors.New("at least one node must be specified to partition")
+	}
+
+	namespace, islandPods, allPods, err := d.getNodePods(ctx, clusterID, nodeIDs)
+	if err != nil {
+		return err
+	}
+
+	var islandPodNames []string
+	for _, pod := range islandPods {
+		islandPodNames = append(islandPodNames, pod.Name)
+	}
+
+	var blockedIPs []string
+	for _, pod := range allPods {
+		if !slices.Contains(islandPodNames, pod.Name) && pod.Status.PodIP != "" {
+			blockedIPs = append(blockedIPs, pod.Status.PodIP)
+		}
+	}
+
+	d.logger.Info("partitioning traffic for nodes",
+		zap.Strings("island", islandPodNames))
+
+	for _, pod := range islandPods {
+		policy, err := buildNodeTrafficPolicy(&nodeTrafficPolicyOptions{
+			PodName:        pod.Name,
+			TrafficType:    deployment.BlockNodeTrafficNodes,
+			IslandPodNames: islandPodNames,
+			BlockedIPs:     blockedIPs,
+			Rule:           fmt.Sprintf("partition with %s", strings.Join(islandPodNames, ",")),
+		})
+		if err != nil {
+			return err
+		}
+
+		err = d.client.ApplyNetworkPolicy(ctx, namespace, policy)
+		if err != nil {
+			return errors.Wrapf(err, "failed to partition traffic for node %s", pod.Name)
+		}
+	}
+
+	return nil
+}
+
+func (d *Deployer) ListNodeTrafficRules(ctx context.Context, clusterID string) ([]deployment.NodeTrafficRules, error) {
+	namespace, pods, _, err := d.getNodePods(ctx, clusterID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	policies, err := d.client.ListNetworkPolicies(ctx, namespace, chaosPolicyTypeLabel+"=chaos")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list network policies")
+	}
+
+	var out []deployment.NodeTrafficRules
+	for _, pod := range pods {
+		var rules []string
+		for _, policy := range policies {
+			if policy.Labels[chaosPolicyNodeLabel] == pod.Name {
+				rules = append(rules, policy.Annotations[chaosPolicyRuleKey])
+			}
+		}
+
+		out = append(out, deployment.NodeTrafficRules{
+			NodeID:    pod.Name,
+			IPAddress: pod.Status.PodIP,
+			Rules:     rules,
+		})
+	}
+
+	return out, nil
+}
+
+func (d *Deployer) signalNodes(ctx context.Context, clusterID string, nodeIDs []string, signal string) error {
+	namespace, pods, _, err := d.getNodePods(ctx, clusterID, nodeIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods {
+		// signalling -1 signals every process other than the container init
+		// process and the shell itself, which freezes the node much like
+		// pausing a docker container does.
+		_, err := d.client.ExecInPod(ctx, namespace, pod.Name, couchbaseContainerName,
+			[]string{"sh", "-c", "kill -" + signal + " -1"})
+		if err != nil {
+			return errors.Wrapf(err, "failed to signal node %s", pod.Name)
+		}
+	}
+
+	return nil
+}
+
+// PauseNode stops every process of the couchbase container.  Note that the
+// liveness probe of the pod will fail while it is paused, so kubernetes may
+// restart a node which is paused for long enough.
+func (d *Deployer) PauseNode(ctx context.Context, clusterID string, nodeIDs []string) error {
+	return d.signalNodes(ctx, clusterID, nodeIDs, "STOP")
+}
+
+func (d *Deployer) UnpauseNode(ctx context.Context, clusterID string, nodeIDs []string) error {
+	return d.signalNodes(ctx, clusterID, nodeIDs, "CONT")
+}
+
+// KillCouchbase stops the couchbase-server process of the nodes, which is
+// restarted by the supervisor of the container as it is with docker nodes.
+func (d *Deployer) KillCouchbase(ctx context.Context, clusterID string, nodeIDs []string) error {
+	namespace, pods, _, err := d.getNodePods(ctx, clusterID, nodeIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods {
+		d.logger.Info("killing couchbase process on node",
+			zap.String("pod", pod.Name))
+
+		_, err := d.client.ExecInPod(ctx, namespace, pod.Name, couchbaseContainerName,
+			[]string{"pkill", "-f", "couchbase-server"})
+		if err != nil {
+			return errors.Wrapf(err, "failed to kill couchbase process on node %s", pod.Name)
+		}
+	}
+
+	return nil
+}
+
+// getNodeManager returns a node manager which talks to ns_server through the
+// management service of the cluster, since the pods themselves are generally
+// not reachable from outside of kubernetes.
+func (d *Deployer) getNodeManager(ctx context.Context, clusterID string) (*clustercontrol.NodeManager, error) {
+	username, password, err := d.getAdminAuth(ctx, clusterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get admin auth")
+	}
+
+	connectInfo, err := d.GetConnectInfo(ctx, clusterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get connect info")
+	}
+
+	if connectInfo.Mgmt == "" {
+		return nil, errors.New("no management endpoint available")
+	}
+
+	return &clustercontrol.NodeManager{
+		Logger:   d.logger,
+		Endpoint: connectInfo.Mgmt,
+		Username: username,
+		Password: password,
+	}, nil
+}
+
+// findPodOTP finds the otp node name of a pod, which the operator names with
+// the fully qualified hostname of the pod.
+func findPodOTP(nodeOTPs []string, podName string) (string, error) {
+	for _, otp := range nodeOTPs {
+		_, hostname, _ := strings.Cut(otp, "@")
+		if hostname == podName || strings.HasPrefix(hostname, podName+".") {
+			return otp, nil
+		}
+	}
+
+	return "", fmt.Errorf("node %s is not part of the couchbase cluster", podName)
+}
+
+func (d *Deployer) getNodeOTPs(ctx context.Context, nodeMgr *clustercontrol.NodeManager, nodeIDs []string) ([]string, error) {
+	allOTPs, err := nodeMgr.Controller().ListNodeOTPs(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list node otps")
+	}
+
+	var otps []string
+	for _, nodeID := range nodeIDs {
+		otp, err := findPodOTP(allOTPs, nodeID)
+		if err != nil {
+			return nil, err
+		}
+		otps = append(otps, otp)
+	}
+
+	return otps, nil
+}
+
+// FailOverNode fails over a node through ns_server.  The operator will
+// typically recover or replace the failed over node on its next reconcile.
+func (d *Deployer) FailOverNode(ctx context.Context, clusterID string, nodeID string, failOverType deployment.FailOverType, allowUnsafe bool) error {
+	nodeMgr, err := d.getNodeManager(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	otps, err := d.getNodeOTPs(ctx, nodeMgr, []string{nodeID})
+	if err != nil {
+		return err
+	}
+
+	switch failOverType {
+	case deployment.HardFailOver:
+		err := nodeMgr.Controller().HardFailOver(ctx, &clustercontrol.HardFailOverOptions{
+			NodeOTPs:    otps,
+			AllowUnsafe: allowUnsafe,
+		})
+		if err != nil {
+			return errors.Wrap(err, "hard failover failed")
+		}
+	case deployment.GracefulFailOver:
+		err := nodeMgr.Controller().GracefulFailOver(ctx, otps)
+		if err != nil {
+			return errors.Wrap(err, "graceful failover start failed")
+		}
+
+		d.logger.Info("waiting for rebalance completion started by graceful failover")
+
+		err = nodeMgr.WaitForNoRunningTasks(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to wait for tasks to complete")
+		}
+	default:
+		return fmt.Errorf("unexpected fail over type `%s`", failOverType)
+	}
+
+	return nil
+}
+
+func (d *Deployer) SetNodeRecovery(ctx context.Context, clusterID string, nodeID string, recoveryType deployment.RecoveryType) error {
+	nodeMgr, err := d.getNodeManager(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	otps, err := d.getNodeOTPs(ctx, nodeMgr, []string{nodeID})
+	if err != nil {
+		return err
+	}
+
+	err = nodeMgr.Controller().SetRecovery(ctx, &clustercontrol.FailOverRecoveryType{
+		NodeOTPs:     otps,
+		RecoveryType: string(recoveryType),
+	})
+	if err != nil {
+		return errors.Wrap(err, "set recovery failed")
+	}
+
+	return nil
+}
+
+func (d *Deployer) RebalanceCluster(ctx context.Context, clusterID string, nodeIDsToEject []string) error {
+	nodeMgr, err := d.getNodeManager(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	otps, err := d.getNodeOTPs(ctx, nodeMgr, nodeIDsToEject)
+	if err != nil {
+		return err
+	}
+
+	return nodeMgr.Rebalance(ctx, otps)
+}
@@ -0,0 +1,111 @@
+package caodeploy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildNodeTrafficPolicy(t *testing.T) {
+	t.Run("nodes", func(t *testing.T) {
+		policy, err := buildNodeTrafficPolicy(&nodeTrafficPolicyOptions{
+			PodName:     "cluster-0000",
+			TrafficType: deployment.BlockNodeTrafficNodes,
+			BlockedIPs:  []string{"10.0.0.2", "fd00::3"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "cbdc2-chaos-cluster-0000", policy.Name)
+		require.Equal(t, map[string]string{podNodeLabel: "cluster-0000"}, policy.Spec.PodSelector.MatchLabels)
+
+		require.Len(t, policy.Spec.Ingress, 1)
+		peers := policy.Spec.Ingress[0].From
+		require.Len(t, peers, 3)
+		require.Equal(t, []string{"10.0.0.2/32"}, peers[1].IPBlock.Except)
+		require.Equal(t, []string{"fd00::3/128"}, peers[2].IPBlock.Except)
+
+		// dns and the allowed peers
+		require.Len(t, policy.Spec.Egress, 2)
+		require.Equal(t, peers, policy.Spec.Egress[1].To)
+	})
+
+	t.Run("partition", func(t *testing.T) {
+		policy, err := buildNodeTrafficPolicy(&nodeTrafficPolicyOptions{
+			PodName:        "cluster-0000",
+			TrafficType:    deployment.BlockNodeTrafficNodes,
+			IslandPodNames: []string{"cluster-0000", "cluster-0001"},
+		})
+		require.NoError(t, err)
+
+		peers := policy.Spec.Ingress[0].From
+		require.Len(t, peers, 4)
+		require.Equal(t, []string{"cluster-0000", "cluster-0001"},
+			peers[3].PodSelector.MatchExpressions[0].Values)
+	})
+
+	t.Run("clients", func(t *testing.T) {
+		policy, err := buildNodeTrafficPolicy(&nodeTrafficPolicyOptions{
+			PodName:     "cluster-0000",
+			TrafficType: deployment.BlockNodeTrafficClients,
+		})
+		require.NoError(t, err)
+
+		peers := policy.Spec.Ingress[0].From
+		require.Len(t, peers, 1)
+		require.Equal(t, map[string]string{podClusterLabel: CouchbaseClusterName}, peers[0].PodSelector.MatchLabels)
+	})
+
+	t.Run("all", func(t *testing.T) {
+		policy, err := buildNodeTrafficPolicy(&nodeTrafficPolicyOptions{
+			PodName:     "cluster-0000",
+			TrafficType: deployment.BlockNodeTrafficAll,
+		})
+		require.NoError(t, err)
+
+		// an ingress rule without peers would allow everything
+		require.Empty(t, policy.Spec.Ingress)
+		require.Len(t, policy.Spec.Egress, 1)
+		require.Empty(t, policy.Spec.Egress[0].To)
+		require.Len(t, policy.Spec.Egress[0].Ports, 2)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := buildNodeTrafficPolicy(&nodeTrafficPolicyOptions{
+			PodName:     "cluster-0000",
+			TrafficType: "nonsense",
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestFindPodOTP(t *testing.T) {
+	otps := []string{
+		"ns_1@cluster-0000.cluster.cbdc2-abc.svc",
+		"ns_1@cluster-0001.cluster.cbdc2-abc.svc",
+		"ns_1@cluster-00010.cluster.cbdc2-abc.svc",
+	}
+
+	otp, err := findPodOTP(otps, "cluster-0001")
+	require.NoError(t, err)
+	require.Equal(t, "ns_1@cluster-0001.cluster.cbdc2-abc.svc", otp)
+
+	_, err = findPodOTP(otps, "cluster-0002")
+	require.Error(t, err)
+}
+
+func TestChaosCapabilities(t *testing.T) {
+	d := &Deployer{}
+
+	caps, err := d.Capabilities(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, caps, deployment.CapabilityTrafficControl)
+	require.NotContains(t, caps, deployment.CapabilityDegradeTraffic)
+	require.NotContains(t, caps, deployment.CapabilityPartitionGroups)
+
+	err = d.DegradeNodeTraffic(context.Background(), "cluster", nil, &deployment.DegradeNodeTrafficOptions{})
+	require.ErrorIs(t, err, deployment.ErrNotSupported)
+
+	err = d.PartitionNodeGroups(context.Background(), "cluster", &deployment.PartitionSpec{}, "")
+	require.ErrorIs(t, err, deployment.ErrNotSupported)
+}
@@ -0,0 +1,74 @@
+package caodeploy
+
+import (
+	"context"
+
+	"github.com/couchbase/gocbcorex"
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/deployment/commondeploy"
+)
+
+func (d *Deployer) ListIndexes(ctx context.Context, clusterID string) ([]deployment.IndexInfo, error) {
+	return withAgent(d, ctx, clusterID, func(agent *gocbcorex.Agent) ([]deployment.IndexInfo, error) {
+		return commondeploy.AgentHelper{Agent: agent}.ListIndexes(ctx)
+	})
+}
+
+func (d *Deployer) CreateIndex(ctx context.Context, clusterID string, opts *deployment.CreateIndexOptions) error {
+	_, err := withAgent(d, ctx, clusterID, func(agent *gocbcorex.Agent) (struct{}, error) {
+		return struct{}{}, commondeploy.AgentHelper{Agent: agent}.CreateIndex(ctx, opts)
+	})
+	return err
+}
+
+func (d *Deployer) DropIndex(ctx context.Context, clusterID string, keyspace deployment.Keyspace, indexName string) error {
+	_, err := withAgent(d, ctx, clusterID, func(agent *gocbcorex.Agent) (struct{}, error) {
+		return struct{}{}, commondeploy.AgentHelper{Agent: agent}.DropIndex(ctx, keyspace, indexName)
+	})
+	return err
+}
+
+func (d *Deployer) BuildIndexes(ctx context.Context, clusterID string, keyspace deployment.Keyspace, indexNames []string) error {
+	_, err := withAgent(d, ctx, clusterID, func(agent *gocbcorex.Agent) (struct{}, error) {
+		return struct{}{}, commondeploy.AgentHelper{Agent: agent}.BuildIndexes(ctx, keyspace, indexNames)
+	})
+	return err
+}
+
+func (d *Deployer) getSearchHelper(ctx context.Context, clusterID string) (*commondeploy.SearchHelper, error) {
+	nodeMgr, err := d.getNodeManager(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &commondeploy.SearchHelper{
+		Controller: nodeMgr.Controller(),
+	}, nil
+}
+
+func (d *Deployer) ListSearchIndexes(ctx context.Context, clusterID string) ([]deployment.SearchIndexInfo, error) {
+	helper, err := d.getSearchHelper(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.ListSearchIndexes(ctx)
+}
+
+func (d *Deployer) CreateSearchIndex(ctx context.Context, clusterID string, opts *deployment.CreateSearchIndexOptions) error {
+	helper, err := d.getSearchHelper(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	return helper.CreateSearchIndex(ctx, opts)
+}
+
+func (d *Deployer) DropSearchIndex(ctx context.Context, clusterID string, indexName string) error {
+	helper, err := d.getSearchHelper(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	return helper.DropSearchIndex(ctx, indexName)
+}
@@ -0,0 +1,105 @@
+package caodeploy
+
+import (
+	"context"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/deployment/commondeploy"
+	"github.com/pkg/errors"
+)
+
+func (d *Deployer) getXdcrHelper(ctx context.Context, clusterID string) (*commondeploy.XdcrHelper, error) {
+	nodeMgr, err := d.getNodeManager(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &commondeploy.XdcrHelper{
+		Controller: nodeMgr.Controller(),
+	}, nil
+}
+
+// GetXdcrTarget returns the in-cluster address of the cluster, so only
+// clusters in the same kubernetes cluster can replicate to it.
+func (d *Deployer) GetXdcrTarget(ctx context.Context, clusterID string) (*deployment.XdcrTarget, error) {
+	namespaceName, err := d.getClusterNamespace(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	username, password, err := d.getAdminAuth(ctx, clusterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get admin auth")
+	}
+
+	target := &deployment.XdcrTarget{
+		Hostname: CouchbaseClusterName + "-srv." + namespaceName + ".svc:8091",
+		Username: username,
+		Password: password,
+	}
+
+	certPem, err := d.GetCertificate(ctx, clusterID)
+	if err != nil {
+		if !errors.Is(err, deployment.ErrNotSupported) {
+			return nil, errors.Wrap(err, "failed to get cluster certificate")
+		}
+	} else {
+		target.Certificate = certPem
+	}
+
+	return target, nil
+}
+
+func (d *Deployer) ListXdcrRemotes(ctx context.Context, clusterID string) ([]deployment.XdcrRemoteInfo, error) {
+	helper, err := d.getXdcrHelper(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.ListXdcrRemotes(ctx)
+}
+
+func (d *Deployer) CreateXdcrRemote(ctx context.Context, clusterID string, opts *deployment.CreateXdcrRemoteOptions) error {
+	helper, err := d.getXdcrHelper(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	return helper.CreateXdcrRemote(ctx, opts)
+}
+
+func (d *Deployer) ListXdcrReplications(ctx context.Context, clusterID string) ([]deployment.XdcrReplicationInfo, error) {
+	helper, err := d.getXdcrHelper(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.ListXdcrReplications(ctx)
+}
+
+func (d *Deployer) CreateXdcrReplication(ctx context.Context, clusterID string, opts *deployment.CreateXdcrReplicationOptions) (string, error) {
+	helper, err := d.getXdcrHelper(ctx, clusterID)
+	if err != nil {
+		return "", err
+	}
+
+	return helper.CreateXdcrReplication(ctx, opts)
+}
+
+func (d *Deployer) SetXdcrReplicationPaused(ctx context.Context, clusterID string, replicationID string, paused bool) error {
+	helper, err := d.getXdcrHelper(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	return helper.SetXdcrReplicationPaused(ctx, replicationID, paused)
+}
+
+func (d *Deployer) DeleteXdcrReplication(ctx context.Context, clusterID string, replicationID string) error {
+	helper, err := d.getXdcrHelper(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	return helper.DeleteXdcrReplication(ctx, replicationID)
+}
@@ -0,0 +1,77 @@
+package deployment
+
+type Capability string
+
+const (
+	CapabilityGetDefinition       Capability = "get-definition"
+	CapabilityUpdateExpiry        Capability = "update-expiry"
+	CapabilityModifyCluster       Capability = "modify-cluster"
+	CapabilityUpgradeCluster      Capability = "upgrade-cluster"
+	CapabilityAddRemoveNodes      Capability = "add-remove-nodes"
+	CapabilityFailOver            Capability = "failover"
+	CapabilityRebalance           Capability = "rebalance"
+	CapabilityStopStart           Capability = "stop-start"
+	CapabilityRedeploy            Capability = "redeploy"
+	CapabilityUsers               Capability = "users"
+	CapabilityBuckets             Capability = "buckets"
+	CapabilitySampleBuckets       Capability = "sample-buckets"
+	CapabilityCollections         Capability = "collections"
+	CapabilityQuery               Capability = "query"
+	CapabilityCertificates        Capability = "certificates"
+	CapabilityGatewayCertificates Capability = "gateway-certificates"
+	CapabilityMetrics             Capability = "metrics"
+	CapabilityCollectLogs         Capability = "collect-logs"
+	CapabilityImages              Capability = "images"
+	CapabilityTrafficControl      Capability = "traffic-control"
+	CapabilityDegradeTraffic      Capability = "degrade-traffic"
+	CapabilityPartitionGroups     Capability = "partition-groups"
+	CapabilityPauseNode           Capability = "pause-node"
+	CapabilityKillCouchbase       Capability = "kill-couchbase"
+	CapabilityAutoFailover        Capability = "auto-failover"
+	CapabilityLinks               Capability = "links"
+	CapabilityDataApi             Capability = "data-api"
+	CapabilityXdcr                Capability = "xdcr"
+	CapabilityIndexes             Capability = "indexes"
+	CapabilitySearchIndexes       Capability = "search-indexes"
+	CapabilityDocuments           Capability = "documents"
+	CapabilitySnapshots           Capability = "snapshots"
+	CapabilityLoadBalancers       Capability = "load-balancers"
+)
+
+// AllCapabilities lists every capability a deployer may report, in the
+// order they are displayed.
+var AllCapabilities = []Capability{
+	CapabilityGetDefinition,
+	CapabilityUpdateExpiry,
+	CapabilityModifyCluster,
+	CapabilityUpgradeCluster,
+	CapabilityAddRemoveNodes,
+	CapabilityFailOver,
+	CapabilityRebalance,
+	CapabilityStopStart,
+	CapabilityRedeploy,
+	CapabilityUsers,
+	CapabilityBuckets,
+	CapabilitySampleBuckets,
+	CapabilityCollections,
+	CapabilityQuery,
+	CapabilityCertificates,
+	CapabilityGatewayCertificates,
+	CapabilityMetrics,
+	CapabilityCollectLogs,
+	CapabilityImages,
+	CapabilityTrafficControl,
+	CapabilityDegradeTraffic,
+	CapabilityPartitionGroups,
+	CapabilityPauseNode,
+	CapabilityKillCouchbase,
+	CapabilityAutoFailover,
+	CapabilityLinks,
+	CapabilityDataApi,
+	CapabilityXdcr,
+	CapabilityIndexes,
+	CapabilitySearchIndexes,
+	CapabilityDocuments,
+	CapabilitySnapshots,
+	CapabilityLoadBalancers,
+}
@@ -2,8 +2,10 @@ package clouddeploy
 
 import (
 	"testing"
+	"time"
 
 	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/utils/capellav4"
 	"github.com/stretchr/testify/require"
 )
 
@@ -49,20 +51,118 @@ func TestCapellaBucketParams(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			capellaType, storageBackend, err := capellaBucketParams(tt.bucketType)
+			req, err := capellaBucketParams(&deployment.CreateBucketOptions{
+				Name:       "b",
+				BucketType: tt.bucketType,
+			})
 			if tt.wantErr {
 				require.Error(t, err)
 				return
 			}
 			require.NoError(t, err)
-			require.Equal(t, tt.wantType, capellaType)
-			require.Equal(t, tt.wantStorageBackend, storageBackend)
+			require.Equal(t, tt.wantType, req.Type)
+			require.Equal(t, tt.wantStorageBackend, req.StorageBackend)
 		})
 	}
 }
 
 func TestCapellaBucketParamsMemcachedMessage(t *testing.T) {
-	_, _, err := capellaBucketParams(deployment.BucketTypeMemcached)
+	_, err := capellaBucketParams(&deployment.CreateBucketOptions{
+		BucketType: deployment.BucketTypeMemcached,
+	})
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "memcached")
 }
+
+func TestCapellaBucketParamsSettings(t *testing.T) {
+	req, err := capellaBucketParams(&deployment.CreateBucketOptions{
+		Name:               "b",
+		StorageBackend:     deployment.StorageBackendMagma,
+		EvictionPolicy:     deployment.EvictionPolicyFull,
+		DurabilityMinLevel: deployment.DurabilityLevelPersistToMajority,
+		MaxTTL:             time.Hour,
+		ConflictResolution: deployment.ConflictResolutionLww,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "magma", req.StorageBackend)
+	require.Equal(t, "fullEviction", req.EvictionPolicy)
+	require.Equal(t, "persistToMajority", req.DurabilityLevel)
+	require.Equal(t, 3600, req.TimeToLiveInSeconds)
+	require.Equal(t, "lww", req.BucketConflictResolution)
+}
+
+func TestCapellaBucketParamsUnsupportedSettings(t *testing.T) {
+	tests := []struct {
+		name string
+		opts deployment.CreateBucketOptions
+	}{
+		{name: "compression", opts: deployment.CreateBucketOptions{CompressionMode: deployment.CompressionModeActive}},
+		{name: "custom conflict resolution", opts: deployment.CreateBucketOptions{ConflictResolution: deployment.ConflictResolutionCustom}},
+		{name: "history retention", opts: deployment.CreateBucketOptions{HistoryRetentionDuration: time.Hour}},
+		{name: "vbuckets", opts: deployment.CreateBucketOptions{NumVBuckets: 128}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := capellaBucketParams(&tt.opts)
+			require.ErrorIs(t, err, deployment.ErrNotSupported)
+		})
+	}
+}
+
+func TestBucketDetailsFromCapella(t *testing.T) {
+	details, err := bucketDetailsFromCapella(&capellav4.BucketInfo{
+		Name:                     "b",
+		Type:                     "couchbase",
+		StorageBackend:           "magma",
+		MemoryAllocationInMb:     1024,
+		BucketConflictResolution: "lww",
+		DurabilityLevel:          "majority",
+		Replicas:                 2,
+		TimeToLiveInSeconds:      60,
+		EvictionPolicy:           "fullEviction",
+		Stats: &capellav4.BucketStats{
+			ItemCount:       5,
+			DiskUsedInMib:   2,
+			MemoryUsedInMib: 1,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, deployment.StorageBackendMagma, details.Settings.StorageBackend)
+	require.Equal(t, deployment.ConflictResolutionLww, details.Settings.ConflictResolution)
+	require.Equal(t, deployment.DurabilityLevelMajority, details.Settings.DurabilityMinLevel)
+	require.Equal(t, deployment.EvictionPolicyFull, details.Settings.EvictionPolicy)
+	require.Equal(t, time.Minute, details.Settings.MaxTTL)
+	require.Equal(t, uint64(5), details.ItemCount)
+	require.Equal(t, uint64(1024*1024), details.MemUsedBytes)
+	require.Equal(t, uint64(2*1024*1024), details.DiskUsedBytes)
+	require.Nil(t, details.ResidentRatio)
+}
+
+func TestCapellaUpdateBucketParams(t *testing.T) {
+	bucket := &capellav4.BucketInfo{
+		MemoryAllocationInMb: 256,
+		DurabilityLevel:      "none",
+		Replicas:             1,
+		FlushEnabled:         true,
+		TimeToLiveInSeconds:  60,
+	}
+
+	numReplicas := 2
+	maxTTL := time.Duration(0)
+	req, err := capellaUpdateBucketParams(bucket, &deployment.UpdateBucketOptions{
+		NumReplicas:        &numReplicas,
+		MaxTTL:             &maxTTL,
+		DurabilityMinLevel: deployment.DurabilityLevelMajority,
+	})
+	require.NoError(t, err)
+
+	// unchanged settings must be carried over, as the v4 API replaces them
+	require.Equal(t, &capellav4.UpdateBucketRequest{
+		MemoryAllocationInMb: 256,
+		DurabilityLevel:      "majority",
+		Replicas:             2,
+		FlushEnabled:         true,
+		TimeToLiveInSeconds:  0,
+	}, req)
+}
@@ -848,7 +848,7 @@ func (p *Deployer) NewCluster(ctx context.Context, def *clusterdef.Cluster) (dep
 }
 
 func (d *Deployer) GetDefinition(ctx context.Context, clusterID string) (*clusterdef.Cluster, error) {
-	return nil, errors.New("clouddeploy does not support fetching the cluster definition")
+	return nil, deployment.NewNotSupportedError("clouddeploy does not support fetching the cluster definition")
 }
 
 func (d *Deployer) UpdateClusterExpiry(ctx context.Context, clusterID string, newExpiryTime time.Time) error {
@@ -1111,11 +1111,11 @@ func (d *Deployer) UpgradeCluster(ctx context.Context, clusterID string, Current
 }
 
 func (d *Deployer) AddNode(ctx context.Context, clusterID string) (string, error) {
-	return "", errors.New("clouddeploy does not support cluster node addition")
+	return "", deployment.NewNotSupportedError("clouddeploy does not support cluster node addition")
 }
 
 func (d *Deployer) RemoveNode(ctx context.Context, clusterID string, nodeID string) error {
-	return errors.New("clouddeploy does not support cluster node removal")
+	return deployment.NewNotSupportedError("clouddeploy does not support cluster node removal")
 }
 
 // A free tier cluster has its own delete endpoint, and the generic cluster
@@ -1389,7 +1389,7 @@ func (p *Deployer) GenPrivateEndpointLinkCommand(ctx context.Context, clusterID
 		}
 		return cmd.Command, nil
 	} else {
-		return "", errors.New("private endpoint link command generation is not supported for columnar yet")
+		return "", deployment.NewNotSupportedError("private endpoint link command generation is not supported for columnar yet")
 	}
 }
 
@@ -1727,6 +1727,7 @@ func (p *Deployer) ListUsers(ctx context.Context, clusterID string) ([]deploymen
 				Username: user.Name,
 				CanRead:  user.HasPrivilege(capellav4.PrivilegeDataReader),
 				CanWrite: user.HasPrivilege(capellav4.PrivilegeDataWriter),
+				Roles:    capellaRolesFromAccess(user.Access),
 			})
 		}
 
@@ -1768,21 +1769,37 @@ func (p *Deployer) CreateUser(ctx context.Context, clusterID string, opts *deplo
 	if err != nil {
 		return err
 	}
+	if len(opts.Groups) > 0 {
+		return deployment.NewNotSupportedError("clouddeploy does not support user groups")
+	}
+	if opts.External {
+		return deployment.NewNotSupportedError("clouddeploy does not support external users")
+	}
+
 	if clusterInfo.Cluster != nil {
-		var privileges []string
-		if opts.CanRead {
-			privileges = append(privileges, capellav4.PrivilegeDataReader)
-		}
-		if opts.CanWrite {
-			privileges = append(privileges, capellav4.PrivilegeDataWriter)
+		var access []capellav4.UserAccess
+		if len(opts.Roles) > 0 {
+			access, err = capellaAccessFromRoles(opts.Roles)
+			if err != nil {
+				return err
+			}
+		} else {
+			var privileges []string
+			if opts.CanRead {
+				privileges = append(privileges, capellav4.PrivilegeDataReader)
+			}
+			if opts.CanWrite {
+				privileges = append(privileges, capellav4.PrivilegeDataWriter)
+			}
+			access = []capellav4.UserAccess{
+				{Privileges: privileges},
+			}
 		}
 
 		_, err = p.v4.CreateUser(ctx, p.tenantID, clusterInfo.ProjectID, clusterInfo.Cluster.ID, &capellav4.CreateUserRequest{
 			Name:     opts.Username,
 			Password: opts.Password,
-			Access: []capellav4.UserAccess{
-				{Privileges: privileges},
-			},
+			Access:   access,
 		})
 		if err != nil {
 			return errors.Wrap(err, "failed to create user")
@@ -1792,6 +1809,10 @@ func (p *Deployer) CreateUser(ctx context.Context, clusterID string, opts *deplo
 			return err
 		}
 
+		if len(opts.Roles) > 0 {
+			return deployment.NewNotSupportedError("clouddeploy does not support roles for columnar users")
+		}
+
 		roles, err := p.mgr.Client.GetColumnarRoles(ctx, p.tenantID, clusterInfo.ProjectID, clusterInfo.Columnar.ID, &capellacontrol.PaginatedRequest{
 			Page:          1,
 			PerPage:       250,
@@ -1821,6 +1842,10 @@ func (p *Deployer) CreateUser(ctx context.Context, clusterID string, opts *deplo
 	return nil
 }
 
+func (p *Deployer) CreateGroup(ctx context.Context, clusterID string, opts *deployment.CreateGroupOptions) error {
+	return deployment.NewNotSupportedError("clouddeploy does not support user groups")
+}
+
 func (p *Deployer) DeleteUser(ctx context.Context, clusterID string, username string) error {
 	clusterInfo, err := p.getCluster(ctx, clusterID)
 	if err != nil {
@@ -1911,6 +1936,48 @@ func (p *Deployer) CreateBucket(ctx context.Context, clusterID string, opts *dep
 		return err
 	}
 
+	req, err := capellaBucketParams(opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.v4.CreateBucket(ctx, p.tenantID, clusterInfo.ProjectID, clusterInfo.Cluster.ID, req)
+	if err != nil {
+		return errors.Wrap(err, "failed to create bucket")
+	}
+
+	return nil
+}
+
+var capellaDurabilityLevels = map[deployment.DurabilityLevel]string{
+	deployment.DurabilityLevelNone:                     "none",
+	deployment.DurabilityLevelMajority:                 "majority",
+	deployment.DurabilityLevelMajorityAndPersistActive: "majorityAndPersistActive",
+	deployment.DurabilityLevelPersistToMajority:        "persistToMajority",
+}
+
+// capellaBucketParams maps the deployment bucket options onto a Capella
+// create bucket request. Memcached buckets are not offered by Capella, and
+// compression, custom conflict resolution, history retention and the vbucket
+// count cannot be set through the v4 API, so they are rejected explicitly
+// rather than sent as an invalid request. An empty bucket type defaults to
+// couchbase.
+func capellaBucketParams(opts *deployment.CreateBucketOptions) (*capellav4.CreateBucketRequest, error) {
+	bucketType := opts.BucketType
+	if bucketType == "" {
+		bucketType = deployment.BucketTypeCouchbase
+	}
+
+	if opts.CompressionMode != "" {
+		return nil, deployment.NewNotSupportedError("the cloud deployer does not support setting the compression mode")
+	}
+	if opts.HistoryRetentionBytes > 0 || opts.HistoryRetentionDuration > 0 {
+		return nil, deployment.NewNotSupportedError("the cloud deployer does not support history retention")
+	}
+	if opts.NumVBuckets > 0 {
+		return nil, deployment.NewNotSupportedError("the cloud deployer does not support setting the vbucket count")
+	}
+
 	ramQuotaMb := 256
 	if opts.RamQuotaMB > 0 {
 		ramQuotaMb = opts.RamQuotaMB
@@ -1921,49 +1988,217 @@ func (p *Deployer) CreateBucket(ctx context.Context, clusterID string, opts *dep
 		numReplicas = opts.NumReplicas
 	}
 
-	capellaBucketType, storageBackend, err := capellaBucketParams(opts.BucketType)
-	if err != nil {
-		return err
-	}
-
-	_, err = p.v4.CreateBucket(ctx, p.tenantID, clusterInfo.ProjectID, clusterInfo.Cluster.ID, &capellav4.CreateBucketRequest{
+	req := &capellav4.CreateBucketRequest{
+		Name:                     opts.Name,
 		BucketConflictResolution: "seqno",
 		DurabilityLevel:          "none",
 		FlushEnabled:             opts.FlushEnabled,
 		MemoryAllocationInMb:     ramQuotaMb,
-		Name:                     opts.Name,
 		Replicas:                 numReplicas,
-		StorageBackend:           storageBackend,
-		Type:                     capellaBucketType,
-	})
-	if err != nil {
-		return errors.Wrap(err, "failed to create bucket")
-	}
-
-	return nil
-}
-
-// capellaBucketParams maps a deployment bucket type onto the Capella bucket
-// "type" and storage backend values. Memcached buckets are not offered by
-// Capella, so they are rejected explicitly rather than sent as an invalid
-// request. An empty bucket type defaults to couchbase.
-func capellaBucketParams(bucketType deployment.BucketType) (capellaType string, storageBackend string, err error) {
-	if bucketType == "" {
-		bucketType = deployment.BucketTypeCouchbase
+		TimeToLiveInSeconds:      int(opts.MaxTTL / time.Second),
 	}
 
 	switch bucketType {
 	case deployment.BucketTypeCouchbase:
-		return "couchbase", "couchstore", nil
+		req.Type = "couchbase"
+		req.StorageBackend = "couchstore"
+		if opts.StorageBackend == deployment.StorageBackendMagma {
+			req.StorageBackend = "magma"
+		}
+
+		switch opts.EvictionPolicy {
+		case "":
+		case deployment.EvictionPolicyValueOnly:
+			req.EvictionPolicy = "valueOnly"
+		case deployment.EvictionPolicyFull:
+			req.EvictionPolicy = "fullEviction"
+		default:
+			return nil, errors.Errorf("eviction policy %q is not valid for couchbase buckets", opts.EvictionPolicy)
+		}
 	case deployment.BucketTypeEphemeral:
 		// Ephemeral buckets are memory-only and reject a disk storage
 		// backend, so leave it empty (omitted from the request).
-		return "ephemeral", "", nil
+		req.Type = "ephemeral"
+		if opts.StorageBackend != "" {
+			return nil, errors.New("ephemeral buckets do not have a storage backend")
+		}
+
+		switch opts.EvictionPolicy {
+		case "":
+		case deployment.EvictionPolicyNoEviction:
+			req.EvictionPolicy = "noEviction"
+		case deployment.EvictionPolicyNotRecentlyUsed:
+			req.EvictionPolicy = "nruEviction"
+		default:
+			return nil, errors.Errorf("eviction policy %q is not valid for ephemeral buckets", opts.EvictionPolicy)
+		}
 	case deployment.BucketTypeMemcached:
-		return "", "", errors.New("memcached buckets are not supported by the cloud deployer")
+		return nil, deployment.NewNotSupportedError("memcached buckets are not supported by the cloud deployer")
 	default:
-		return "", "", errors.Errorf("unsupported bucket type %q", bucketType)
+		return nil, errors.Errorf("unsupported bucket type %q", bucketType)
+	}
+
+	if opts.DurabilityMinLevel != "" {
+		durabilityLevel, ok := capellaDurabilityLevels[opts.DurabilityMinLevel]
+		if !ok {
+			return nil, errors.Errorf("unsupported durability level %q", opts.DurabilityMinLevel)
+		}
+		req.DurabilityLevel = durabilityLevel
+	}
+
+	switch opts.ConflictResolution {
+	case "", deployment.ConflictResolutionSeqno:
+	case deployment.ConflictResolutionLww:
+		req.BucketConflictResolution = "lww"
+	case deployment.ConflictResolutionCustom:
+		return nil, deployment.NewNotSupportedError("the cloud deployer does not support custom conflict resolution")
+	default:
+		return nil, errors.Errorf("unsupported conflict resolution %q", opts.ConflictResolution)
+	}
+
+	return req, nil
+}
+
+func (p *Deployer) GetBucket(ctx context.Context, clusterID string, bucketName string) (*deployment.BucketDetails, error) {
+	clusterInfo, err := p.getCluster(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	bucketId := base64.StdEncoding.EncodeToString([]byte(bucketName))
+
+	bucket, err := p.v4.GetBucket(ctx, p.tenantID, clusterInfo.ProjectID, clusterInfo.Cluster.ID, bucketId)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get bucket")
+	}
+
+	return bucketDetailsFromCapella(bucket)
+}
+
+// bucketDetailsFromCapella maps a Capella bucket onto the deployment bucket
+// details. Capella does not report the resident ratio of a bucket, nor the
+// settings which cannot be set through the v4 API.
+func bucketDetailsFromCapella(bucket *capellav4.BucketInfo) (*deployment.BucketDetails, error) {
+	bucketType, err := deployment.ParseBucketType(bucket.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	storageBackend, err := deployment.ParseStorageBackend(bucket.StorageBackend)
+	if err != nil {
+		return nil, err
+	}
+
+	evictionPolicy, err := deployment.ParseEvictionPolicy(bucket.EvictionPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	durabilityLevel, err := deployment.ParseDurabilityLevel(bucket.DurabilityLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	conflictResolution, err := deployment.ParseConflictResolution(bucket.BucketConflictResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &deployment.BucketDetails{
+		Settings: deployment.CreateBucketOptions{
+			Name:               bucket.Name,
+			BucketType:         bucketType,
+			RamQuotaMB:         bucket.MemoryAllocationInMb,
+			FlushEnabled:       bucket.FlushEnabled,
+			NumReplicas:        bucket.Replicas,
+			StorageBackend:     storageBackend,
+			EvictionPolicy:     evictionPolicy,
+			DurabilityMinLevel: durabilityLevel,
+			MaxTTL:             time.Duration(bucket.TimeToLiveInSeconds) * time.Second,
+			ConflictResolution: conflictResolution,
+		},
+	}
+	if bucket.Stats != nil {
+		details.ItemCount = bucket.Stats.ItemCount
+		details.MemUsedBytes = bucket.Stats.MemoryUsedInMib * 1024 * 1024
+		details.DiskUsedBytes = bucket.Stats.DiskUsedInMib * 1024 * 1024
+	}
+
+	return details, nil
+}
+
+func (p *Deployer) UpdateBucket(ctx context.Context, clusterID string, bucketName string, opts *deployment.UpdateBucketOptions) error {
+	clusterInfo, err := p.getCluster(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	bucketId := base64.StdEncoding.EncodeToString([]byte(bucketName))
+
+	// the v4 API replaces every mutable setting, so we start from the
+	// current settings of the bucket.
+	bucket, err := p.v4.GetBucket(ctx, p.tenantID, clusterInfo.ProjectID, clusterInfo.Cluster.ID, bucketId)
+	if err != nil {
+		return errors.Wrap(err, "failed to get bucket")
+	}
+
+	req, err := capellaUpdateBucketParams(bucket, opts)
+	if err != nil {
+		return err
+	}
+
+	err = p.v4.UpdateBucket(ctx, p.tenantID, clusterInfo.ProjectID, clusterInfo.Cluster.ID, bucketId, req)
+	if err != nil {
+		return errors.Wrap(err, "failed to update bucket")
+	}
+
+	return nil
+}
+
+func capellaUpdateBucketParams(bucket *capellav4.BucketInfo, opts *deployment.UpdateBucketOptions) (*capellav4.UpdateBucketRequest, error) {
+	req := &capellav4.UpdateBucketRequest{
+		MemoryAllocationInMb: bucket.MemoryAllocationInMb,
+		DurabilityLevel:      bucket.DurabilityLevel,
+		Replicas:             bucket.Replicas,
+		FlushEnabled:         bucket.FlushEnabled,
+		TimeToLiveInSeconds:  bucket.TimeToLiveInSeconds,
+	}
+
+	if opts.RamQuotaMB != nil {
+		req.MemoryAllocationInMb = *opts.RamQuotaMB
+	}
+	if opts.NumReplicas != nil {
+		req.Replicas = *opts.NumReplicas
+	}
+	if opts.MaxTTL != nil {
+		req.TimeToLiveInSeconds = int(*opts.MaxTTL / time.Second)
+	}
+
+	if opts.DurabilityMinLevel != "" {
+		durabilityLevel, ok := capellaDurabilityLevels[opts.DurabilityMinLevel]
+		if !ok {
+			return nil, errors.Errorf("unsupported durability level %q", opts.DurabilityMinLevel)
+		}
+		req.DurabilityLevel = durabilityLevel
 	}
+
+	return req, nil
+}
+
+func (p *Deployer) FlushBucket(ctx context.Context, clusterID string, bucketName string) error {
+	clusterInfo, err := p.getCluster(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	bucketId := base64.StdEncoding.EncodeToString([]byte(bucketName))
+
+	err = p.v4.FlushBucket(ctx, p.tenantID, clusterInfo.ProjectID, clusterInfo.Cluster.ID, bucketId)
+	if err != nil {
+		return errors.Wrap(err, "failed to flush bucket")
+	}
+
+	return nil
 }
 
 func (p *Deployer) DeleteBucket(ctx context.Context, clusterID string, bucketName string) error {
@@ -2039,7 +2274,7 @@ func (p *Deployer) GetCertificate(ctx context.Context, clusterID string) (string
 }
 
 func (d *Deployer) GetMetrics(ctx context.Context, clusterID string) (string, error) {
-	return "", errors.New("clouddeploy does not support getting required metrics as of now. Refer - AV-118082")
+	return "", deployment.NewNotSupportedError("clouddeploy does not support getting required metrics as of now. Refer - AV-118082")
 }
 
 func (d *Deployer) startLogCollection(ctx context.Context, cloudClusterId string) error {
@@ -2134,7 +2369,7 @@ func (d *Deployer) RedeployCluster(ctx context.Context, clusterID string) error
 		return err
 	}
 	if cluster.Columnar != nil {
-		return errors.New("redeploy not supported for columanr clusters yet")
+		return deployment.NewNotSupportedError("redeploy not supported for columanr clusters yet")
 	}
 
 	err = d.mgr.Client.RedeployCluster(ctx, cluster.Cluster.ID, d.internalSupportToken)
@@ -2270,7 +2505,7 @@ func (d *Deployer) enableDataApi(ctx context.Context, cloudProjectID, cloudClust
 }
 
 func (d *Deployer) GetGatewayCertificate(ctx context.Context, clusterID string) (string, error) {
-	return "", errors.New("clouddeploy does not support getting gateway certificates")
+	return "", deployment.NewNotSupportedError("clouddeploy does not support getting gateway certificates")
 }
 
 func (d *Deployer) bucketTarget(ctx context.Context, clusterID string, bucketName string) (projectID string, cloudClusterID string, bucketID string, err error) {
@@ -2279,7 +2514,7 @@ func (d *Deployer) bucketTarget(ctx context.Context, clusterID string, bucketNam
 		return "", "", "", err
 	}
 	if clusterInfo.Cluster == nil {
-		return "", "", "", errors.New("buckets are not supported for columnar clusters")
+		return "", "", "", deployment.NewNotSupportedError("buckets are not supported for columnar clusters")
 	}
 
 	return clusterInfo.ProjectID,
@@ -2288,38 +2523,36 @@ func (d *Deployer) bucketTarget(ctx context.Context, clusterID string, bucketNam
 		nil
 }
 
-func (d *Deployer) ExecuteQuery(ctx context.Context, clusterID string, query string, opts *deployment.ExecuteQueryOptions) (string, error) {
-	if opts == nil || opts.Username == "" || opts.Password == "" {
-		return "", errors.New("cloud queries need the username and password of an existing database user")
-	}
-
+// getAgent connects to a cluster as one of its database users, since the
+// organization credentials cannot be used for data access.
+func (d *Deployer) getAgent(ctx context.Context, clusterID string, bucketName string, username, password string) (*gocbcorex.Agent, error) {
 	clusterInfo, err := d.getCluster(ctx, clusterID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if clusterInfo.Cluster == nil {
-		return "", errors.New("queries are not supported for columnar clusters")
+		return nil, deployment.NewNotSupportedError("data access is not supported for columnar clusters")
 	}
 
 	cert, err := d.v4.GetCertificate(ctx, d.tenantID, clusterInfo.ProjectID, clusterInfo.Cluster.ID)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to get cluster certificate")
+		return nil, errors.Wrap(err, "failed to get cluster certificate")
 	}
 
 	caPool := x509.NewCertPool()
 	if !caPool.AppendCertsFromPEM([]byte(cert)) {
-		return "", errors.New("failed to parse cluster certificate")
+		return nil, errors.New("failed to parse cluster certificate")
 	}
 
 	srvName := strings.TrimPrefix(clusterInfo.Cluster.ConnectionString, "couchbases://")
 	baseSpec, err := gocbconnstr.Parse(fmt.Sprintf("couchbases://%s", srvName))
 	if err != nil {
-		return "", errors.Wrap(err, "failed to parse connstr")
+		return nil, errors.Wrap(err, "failed to parse connstr")
 	}
 
 	resolvedSpec, err := gocbconnstr.Resolve(baseSpec)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to resolve connstr")
+		return nil, errors.Wrap(err, "failed to resolve connstr")
 	}
 
 	var httpAddrs []string
@@ -2342,11 +2575,12 @@ func (d *Deployer) ExecuteQuery(ctx context.Context, clusterID string, query str
 
 	// The connection times out when the caller's IP is not on the allow list.
 	agent, err := gocbcorex.CreateAgent(ctx, gocbcorex.AgentOptions{
-		Logger:    d.logger.Named("agent"),
-		TLSConfig: &tls.Config{RootCAs: caPool},
+		Logger:     d.logger.Named("agent"),
+		TLSConfig:  &tls.Config{RootCAs: caPool},
+		BucketName: bucketName,
 		Authenticator: &gocbcorex.PasswordAuthenticator{
-			Username: opts.Username,
-			Password: opts.Password,
+			Username: username,
+			Password: password,
 		},
 		SeedConfig: gocbcorex.SeedConfig{
 			HTTPAddrs: httpAddrs,
@@ -2354,13 +2588,34 @@ func (d *Deployer) ExecuteQuery(ctx context.Context, clusterID string, query str
 		},
 	})
 	if err != nil {
-		return "", errors.Wrap(err, "failed to create gocbcorex agent")
+		return nil, errors.Wrap(err, "failed to create gocbcorex agent")
+	}
+
+	return agent, nil
+}
+
+func (d *Deployer) ExecuteQuery(ctx context.Context, clusterID string, query string, opts *deployment.ExecuteQueryOptions) (string, error) {
+	if opts == nil || opts.Username == "" || opts.Password == "" {
+		return "", errors.New("cloud queries need the username and password of an existing database user")
+	}
+
+	agent, err := d.getAgent(ctx, clusterID, "", opts.Username, opts.Password)
+	if err != nil {
+		return "", err
 	}
 	defer agent.Close()
 
 	return commondeploy.AgentHelper{Agent: agent}.ExecuteQuery(ctx, query)
 }
 
+func (d *Deployer) OpenBucketAgent(ctx context.Context, clusterID string, bucketName string, opts *deployment.OpenBucketAgentOptions) (*gocbcorex.Agent, error) {
+	if opts == nil || opts.Username == "" || opts.Password == "" {
+		return nil, errors.New("cloud data access needs the username and password of an existing database user")
+	}
+
+	return d.getAgent(ctx, clusterID, bucketName, opts.Username, opts.Password)
+}
+
 func (d *Deployer) ListCollections(ctx context.Context, clusterID string, bucketName string) ([]deployment.ScopeInfo, error) {
 	projectID, cloudClusterID, bucketID, err := d.bucketTarget(ctx, clusterID, bucketName)
 	if err != nil {
@@ -2450,49 +2705,90 @@ func (d *Deployer) DeleteCollection(ctx context.Context, clusterID string, bucke
 }
 
 func (d *Deployer) BlockNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string, trafficType deployment.BlockNodeTrafficType, rejectType string) error {
-	return errors.New("clouddeploy does not support traffic control")
+	return deployment.NewNotSupportedError("clouddeploy does not support traffic control")
 }
 
 func (d *Deployer) AllowNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string) error {
-	return errors.New("clouddeploy does not support traffic control")
+	return deployment.NewNotSupportedError("clouddeploy does not support traffic control")
 }
 
 func (d *Deployer) PartitionNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string, rejectType string) error {
-	return errors.New("clouddeploy does not support traffic control")
+	return deployment.NewNotSupportedError("clouddeploy does not support traffic control")
+}
+
+func (d *Deployer) DegradeNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string, opts *deployment.DegradeNodeTrafficOptions) error {
+	return deployment.NewNotSupportedError("clouddeploy does not support traffic control")
+}
+
+func (d *Deployer) PartitionNodeGroups(ctx context.Context, clusterID string, spec *deployment.PartitionSpec, rejectType string) error {
+	return deployment.NewNotSupportedError("clouddeploy does not support traffic control")
+}
+
+func (d *Deployer) ListNodeTrafficRules(ctx context.Context, clusterID string) ([]deployment.NodeTrafficRules, error) {
+	return nil, deployment.NewNotSupportedError("clouddeploy does not support traffic control")
 }
 
 func (d *Deployer) ListImages(ctx context.Context) ([]deployment.Image, error) {
-	return nil, errors.New("clouddeploy does not support image listing")
+	return nil, deployment.NewNotSupportedError("clouddeploy does not support image listing")
 }
 
 func (d *Deployer) SearchImages(ctx context.Context, version string) ([]deployment.Image, error) {
-	return nil, errors.New("clouddeploy does not support image search")
+	return nil, deployment.NewNotSupportedError("clouddeploy does not support image search")
 }
 
 func (d *Deployer) PauseNode(ctx context.Context, clusterID string, nodeIDs []string) error {
-	return errors.New("clouddeploy does not support node pausing")
+	return deployment.NewNotSupportedError("clouddeploy does not support node pausing")
 }
 
 func (d *Deployer) UnpauseNode(ctx context.Context, clusterID string, nodeIDs []string) error {
-	return errors.New("clouddeploy does not support node pausing")
+	return deployment.NewNotSupportedError("clouddeploy does not support node pausing")
 }
 
 func (d *Deployer) FailOverNode(ctx context.Context, clusterID string, nodeID string, failOverType deployment.FailOverType, allowUnsafe bool) error {
-	return errors.New("clouddeploy does not support failing over a node")
+	return deployment.NewNotSupportedError("clouddeploy does not support failing over a node")
 }
 
 func (d *Deployer) SetNodeRecovery(ctx context.Context, clusterID string, nodeID string, recoverType deployment.RecoveryType) error {
-	return errors.New("clouddeploy does not support failover recovery")
+	return deployment.NewNotSupportedError("clouddeploy does not support failover recovery")
 }
 
 func (d *Deployer) RebalanceCluster(ctx context.Context, clusterID string, nodesToEject []string) error {
-	return errors.New("clouddeploy does not support rebalance cluster")
+	return deployment.NewNotSupportedError("clouddeploy does not support rebalance cluster")
 }
 
 func (d *Deployer) KillCouchbase(ctx context.Context, clusterID string, nodeIDs []string) error {
-	return errors.New("clouddeploy does not support killing couchbase process")
+	return deployment.NewNotSupportedError("clouddeploy does not support killing couchbase process")
 }
 
 func (d *Deployer) SetAutoFailover(ctx context.Context, clusterID string, enabled bool, timeout int) error {
-	return errors.New("clouddeploy does not support setting auto-failover")
+	return deployment.NewNotSupportedError("clouddeploy does not support setting auto-failover")
+}
+
+func (d *Deployer) StopCluster(ctx context.Context, clusterID string) error {
+	return deployment.NewNotSupportedError("clouddeploy does not support stopping clusters")
+}
+
+func (d *Deployer) StartCluster(ctx context.Context, clusterID string) error {
+	return deployment.NewNotSupportedError("clouddeploy does not support starting clusters")
+}
+
+func (d *Deployer) Capabilities(ctx context.Context) ([]deployment.Capability, error) {
+	return []deployment.Capability{
+		deployment.CapabilityUpdateExpiry,
+		deployment.CapabilityModifyCluster,
+		deployment.CapabilityUpgradeCluster,
+		deployment.CapabilityRedeploy,
+		deployment.CapabilityUsers,
+		deployment.CapabilityBuckets,
+		deployment.CapabilitySampleBuckets,
+		deployment.CapabilityCollections,
+		deployment.CapabilityQuery,
+		deployment.CapabilityCertificates,
+		deployment.CapabilityCollectLogs,
+		deployment.CapabilityLinks,
+		deployment.CapabilityDataApi,
+		deployment.CapabilityXdcr,
+		deployment.CapabilityIndexes,
+		deployment.CapabilityDocuments,
+	}, nil
 }
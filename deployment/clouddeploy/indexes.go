@@ -0,0 +1,133 @@
+package clouddeploy
+
+import (
+	"context"
+	"strings"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/utils/capellav4"
+	"github.com/pkg/errors"
+)
+
+// Capella manages indexes through the query service endpoints of the v4
+// API, which only expose the definitions and build status of the indexes.
+
+// capellaIndexState maps the build status of the v4 API onto the states
+// reported by the index service.
+func capellaIndexState(status string) string {
+	switch status {
+	case capellav4.IndexBuildStatusReady:
+		return deployment.IndexStateOnline
+	case capellav4.IndexBuildStatusCreated:
+		return deployment.IndexStateDeferred
+	}
+	return strings.ToLower(status)
+}
+
+func (p *Deployer) manageIndex(ctx context.Context, clusterID string, statement string) error {
+	clusterInfo, err := p.getCluster(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	if clusterInfo.Cluster == nil {
+		return deployment.NewNotSupportedError("indexes are not supported for columnar clusters")
+	}
+
+	return p.v4.ManageIndex(ctx, p.tenantID, clusterInfo.ProjectID, clusterInfo.Cluster.ID,
+		&capellav4.ManageIndexRequest{Definition: statement})
+}
+
+func (p *Deployer) ListIndexes(ctx context.Context, clusterID string) ([]deployment.IndexInfo, error) {
+	clusterInfo, err := p.getCluster(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if clusterInfo.Cluster == nil {
+		return nil, deployment.NewNotSupportedError("indexes are not supported for columnar clusters")
+	}
+
+	buckets, err := p.ListBuckets(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	var indexes []deployment.IndexInfo
+	for _, bucket := range buckets {
+		scopes, err := p.ListCollections(ctx, clusterID, bucket.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, scope := range scopes {
+			for _, collection := range scope.Collections {
+				keyspace := deployment.Keyspace{
+					Bucket:     bucket.Name,
+					Scope:      scope.Name,
+					Collection: collection.Name,
+				}
+
+				defs, err := p.v4.ListIndexDefinitions(ctx, p.tenantID, clusterInfo.ProjectID, clusterInfo.Cluster.ID,
+					keyspace.Bucket, keyspace.ScopeName(), keyspace.CollectionName())
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to list indexes of %s", keyspace)
+				}
+
+				for _, def := range defs {
+					status, err := p.v4.GetIndexBuildStatus(ctx, p.tenantID, clusterInfo.ProjectID, clusterInfo.Cluster.ID,
+						keyspace.Bucket, keyspace.ScopeName(), keyspace.CollectionName(), def.IndexName)
+					if err != nil {
+						return nil, errors.Wrapf(err, "failed to get build status of index %s", def.IndexName)
+					}
+
+					indexes = append(indexes, deployment.IndexInfo{
+						Name:      def.IndexName,
+						Keyspace:  keyspace,
+						IsPrimary: strings.HasPrefix(strings.ToUpper(def.Definition), "CREATE PRIMARY INDEX"),
+						State:     capellaIndexState(status.Status),
+					})
+				}
+			}
+		}
+	}
+
+	return indexes, nil
+}
+
+func (p *Deployer) CreateIndex(ctx context.Context, clusterID string, opts *deployment.CreateIndexOptions) error {
+	err := p.manageIndex(ctx, clusterID, deployment.CreateIndexStatement(opts))
+	if err != nil {
+		return errors.Wrap(err, "failed to create index")
+	}
+
+	return nil
+}
+
+func (p *Deployer) DropIndex(ctx context.Context, clusterID string, keyspace deployment.Keyspace, indexName string) error {
+	err := p.manageIndex(ctx, clusterID, deployment.DropIndexStatement(keyspace, indexName))
+	if err != nil {
+		return errors.Wrap(err, "failed to drop index")
+	}
+
+	return nil
+}
+
+func (p *Deployer) BuildIndexes(ctx context.Context, clusterID string, keyspace deployment.Keyspace, indexNames []string) error {
+	err := p.manageIndex(ctx, clusterID, deployment.BuildIndexesStatement(keyspace, indexNames))
+	if err != nil {
+		return errors.Wrap(err, "failed to build indexes")
+	}
+
+	return nil
+}
+
+func (p *Deployer) ListSearchIndexes(ctx context.Context, clusterID string) ([]deployment.SearchIndexInfo, error) {
+	return nil, deployment.NewNotSupportedError("clouddeploy does not support search indexes")
+}
+
+func (p *Deployer) CreateSearchIndex(ctx context.Context, clusterID string, opts *deployment.CreateSearchIndexOptions) error {
+	return deployment.NewNotSupportedError("clouddeploy does not support search indexes")
+}
+
+func (p *Deployer) DropSearchIndex(ctx context.Context, clusterID string, indexName string) error {
+	return deployment.NewNotSupportedError("clouddeploy does not support search indexes")
+}
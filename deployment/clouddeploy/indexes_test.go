@@ -0,0 +1,14 @@
+package clouddeploy
+
+import (
+	"testing"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapellaIndexState(t *testing.T) {
+	require.Equal(t, deployment.IndexStateOnline, capellaIndexState("Ready"))
+	require.Equal(t, deployment.IndexStateDeferred, capellaIndexState("Created"))
+	require.Equal(t, "building", capellaIndexState("Building"))
+}
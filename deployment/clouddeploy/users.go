@@ -0,0 +1,171 @@
+package clouddeploy
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/utils/capellav4"
+)
+
+// Capella database credentials only grant data read and write privileges, so
+// the server roles which fall within those privileges are mapped onto them.
+var (
+	capellaReaderRoles = []string{
+		"data_reader", "query_select", "views_reader", "fts_searcher", "analytics_reader",
+	}
+	capellaWriterRoles = []string{
+		"data_writer", "query_insert", "query_update", "query_delete",
+	}
+)
+
+// capellaAccessFromRoles maps server roles onto the access of a Capella
+// database credential.  Roles which Capella has no equivalent for are
+// rejected rather than silently granting more or less than was asked for.
+func capellaAccessFromRoles(roleStrs []string) ([]capellav4.UserAccess, error) {
+	roles, err := deployment.ParseRoles(roleStrs)
+	if err != nil {
+		return nil, err
+	}
+
+	var privileges []string
+	roleResources := make(map[string][]deployment.Role)
+	for _, role := range roles {
+		var privilege string
+		if slices.Contains(capellaReaderRoles, role.Name) {
+			privilege = capellav4.PrivilegeDataReader
+		} else if slices.Contains(capellaWriterRoles, role.Name) {
+			privilege = capellav4.PrivilegeDataWriter
+		} else {
+			return nil, deployment.NewNotSupportedError(fmt.Sprintf("clouddeploy does not support the %s role", role.Name))
+		}
+
+		if !slices.Contains(privileges, privilege) {
+			privileges = append(privileges, privilege)
+		}
+		roleResources[privilege] = append(roleResources[privilege], role)
+	}
+
+	var access []capellav4.UserAccess
+	for _, privilege := range privileges {
+		access = append(access, capellav4.UserAccess{
+			Privileges: []string{privilege},
+			Resources:  capellaResourcesFromRoles(roleResources[privilege]),
+		})
+	}
+
+	return access, nil
+}
+
+// capellaResourcesFromRoles merges the resources of a set of roles, returning
+// nil if any of them applies to every bucket.
+func capellaResourcesFromRoles(roles []deployment.Role) *capellav4.UserResources {
+	resources := &capellav4.UserResources{}
+	wholeBuckets := make(map[string]bool)
+	wholeScopes := make(map[string]bool)
+	for _, role := range roles {
+		if role.Bucket == "" || role.Bucket == "*" {
+			return nil
+		}
+
+		bucketIdx := slices.IndexFunc(resources.Buckets, func(bucket capellav4.UserBucket) bool {
+			return bucket.Name == role.Bucket
+		})
+		if bucketIdx < 0 {
+			resources.Buckets = append(resources.Buckets, capellav4.UserBucket{Name: role.Bucket})
+			bucketIdx = len(resources.Buckets) - 1
+		}
+		bucket := &resources.Buckets[bucketIdx]
+
+		// an unrestricted grant on a bucket or scope covers everything in it
+		if wholeBuckets[role.Bucket] {
+			continue
+		}
+		if role.Scope == "" || role.Scope == "*" {
+			wholeBuckets[role.Bucket] = true
+			bucket.Scopes = nil
+			continue
+		}
+
+		scopeIdx := slices.IndexFunc(bucket.Scopes, func(scope capellav4.UserScope) bool {
+			return scope.Name == role.Scope
+		})
+		if scopeIdx < 0 {
+			bucket.Scopes = append(bucket.Scopes, capellav4.UserScope{Name: role.Scope})
+			scopeIdx = len(bucket.Scopes) - 1
+		}
+		scope := &bucket.Scopes[scopeIdx]
+
+		scopeKey := role.Bucket + ":" + role.Scope
+		if wholeScopes[scopeKey] {
+			continue
+		}
+		if role.Collection == "" || role.Collection == "*" {
+			wholeScopes[scopeKey] = true
+			scope.Collections = nil
+			continue
+		}
+
+		if !slices.Contains(scope.Collections, role.Collection) {
+			scope.Collections = append(scope.Collections, role.Collection)
+		}
+	}
+
+	return resources
+}
+
+// capellaRolesFromAccess maps the access of a Capella database credential
+// back onto server roles.
+func capellaRolesFromAccess(access []capellav4.UserAccess) []string {
+	var roles []string
+	addRole := func(role deployment.Role) {
+		roleStr := role.String()
+		if !slices.Contains(roles, roleStr) {
+			roles = append(roles, roleStr)
+		}
+	}
+
+	for _, entry := range access {
+		for _, privilege := range entry.Privileges {
+			var roleName string
+			switch privilege {
+			case capellav4.PrivilegeDataReader, "read":
+				roleName = "data_reader"
+			case capellav4.PrivilegeDataWriter, "write":
+				roleName = "data_writer"
+			default:
+				roleName = privilege
+			}
+
+			if entry.Resources == nil || len(entry.Resources.Buckets) == 0 {
+				addRole(deployment.Role{Name: roleName, Bucket: "*"})
+				continue
+			}
+
+			for _, bucket := range entry.Resources.Buckets {
+				if len(bucket.Scopes) == 0 {
+					addRole(deployment.Role{Name: roleName, Bucket: bucket.Name})
+					continue
+				}
+
+				for _, scope := range bucket.Scopes {
+					if len(scope.Collections) == 0 {
+						addRole(deployment.Role{Name: roleName, Bucket: bucket.Name, Scope: scope.Name})
+						continue
+					}
+
+					for _, collection := range scope.Collections {
+						addRole(deployment.Role{
+							Name:       roleName,
+							Bucket:     bucket.Name,
+							Scope:      scope.Name,
+							Collection: collection,
+						})
+					}
+				}
+			}
+		}
+	}
+
+	return roles
+}
@@ -0,0 +1,105 @@
+package clouddeploy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/utils/capellav4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapellaAccessFromRoles(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []string
+		want  []capellav4.UserAccess
+	}{
+		{
+			name:  "all buckets",
+			roles: []string{"data_reader[*]", "query_select[*]"},
+			want: []capellav4.UserAccess{
+				{Privileges: []string{capellav4.PrivilegeDataReader}},
+			},
+		},
+		{
+			name:  "reader and writer",
+			roles: []string{"data_reader[default]", "data_writer[*]"},
+			want: []capellav4.UserAccess{
+				{
+					Privileges: []string{capellav4.PrivilegeDataReader},
+					Resources: &capellav4.UserResources{Buckets: []capellav4.UserBucket{
+						{Name: "default"},
+					}},
+				},
+				{Privileges: []string{capellav4.PrivilegeDataWriter}},
+			},
+		},
+		{
+			name: "collections are merged",
+			roles: []string{
+				"data_reader[travel-sample:inventory.airline]",
+				"data_reader[travel-sample:inventory.route]",
+				"data_reader[travel-sample:tenant_agent_00]",
+			},
+			want: []capellav4.UserAccess{
+				{
+					Privileges: []string{capellav4.PrivilegeDataReader},
+					Resources: &capellav4.UserResources{Buckets: []capellav4.UserBucket{
+						{Name: "travel-sample", Scopes: []capellav4.UserScope{
+							{Name: "inventory", Collections: []string{"airline", "route"}},
+							{Name: "tenant_agent_00"},
+						}},
+					}},
+				},
+			},
+		},
+		{
+			name: "bucket grant covers scopes regardless of order",
+			roles: []string{
+				"data_reader[default]",
+				"data_reader[default:inventory]",
+			},
+			want: []capellav4.UserAccess{
+				{
+					Privileges: []string{capellav4.PrivilegeDataReader},
+					Resources: &capellav4.UserResources{Buckets: []capellav4.UserBucket{
+						{Name: "default"},
+					}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := capellaAccessFromRoles(tt.roles)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCapellaAccessFromRolesUnsupported(t *testing.T) {
+	_, err := capellaAccessFromRoles([]string{"security_admin"})
+	require.True(t, errors.Is(err, deployment.ErrNotSupported))
+
+	_, err = capellaAccessFromRoles([]string{"data_reader["})
+	require.Error(t, err)
+}
+
+func TestCapellaRolesFromAccess(t *testing.T) {
+	roles := []string{
+		"data_reader[travel-sample:inventory.airline]",
+		"data_reader[travel-sample:tenant_agent_00]",
+		"data_writer[*]",
+	}
+
+	access, err := capellaAccessFromRoles(roles)
+	require.NoError(t, err)
+	require.Equal(t, roles, capellaRolesFromAccess(access))
+
+	require.Equal(t, []string{"data_reader[*]", "data_writer[*]"}, capellaRolesFromAccess([]capellav4.UserAccess{
+		{Privileges: []string{"read", "write"}},
+	}))
+}
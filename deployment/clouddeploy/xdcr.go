@@ -0,0 +1,153 @@
+package clouddeploy
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/utils/capellav4"
+	"github.com/pkg/errors"
+)
+
+// Capella only replicates between Capella clusters of the same organization,
+// and manages the remote cluster references of those replications itself.
+
+func (p *Deployer) GetXdcrTarget(ctx context.Context, clusterID string) (*deployment.XdcrTarget, error) {
+	return nil, deployment.NewNotSupportedError("clouddeploy clusters can only be replicated to from other clouddeploy clusters")
+}
+
+func (p *Deployer) ListXdcrRemotes(ctx context.Context, clusterID string) ([]deployment.XdcrRemoteInfo, error) {
+	return nil, deployment.NewNotSupportedError("clouddeploy does not expose remote cluster references")
+}
+
+func (p *Deployer) CreateXdcrRemote(ctx context.Context, clusterID string, opts *deployment.CreateXdcrRemoteOptions) error {
+	return deployment.NewNotSupportedError("clouddeploy manages remote cluster references itself")
+}
+
+// bucketNameFromID reverses the base64 bucket ids of the v4 API.
+func bucketNameFromID(bucketID string) string {
+	name, err := base64.StdEncoding.DecodeString(bucketID)
+	if err != nil {
+		return bucketID
+	}
+	return string(name)
+}
+
+func (p *Deployer) ListXdcrReplications(ctx context.Context, clusterID string) ([]deployment.XdcrReplicationInfo, error) {
+	clusterInfo, err := p.getCluster(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if clusterInfo.Cluster == nil {
+		return nil, errors.New("columnar clusters do not support xdcr")
+	}
+
+	replications, err := p.v4.ListReplications(ctx, p.tenantID, clusterInfo.ProjectID, clusterInfo.Cluster.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list replications")
+	}
+
+	clusters, err := p.listClusters(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list clusters")
+	}
+
+	// targets outside of cbdinocluster are reported by their capella id
+	clusterIDs := make(map[string]string)
+	for _, cluster := range clusters {
+		if cluster.Cluster != nil {
+			clusterIDs[cluster.Cluster.ID] = cluster.Meta.ID.String()
+		}
+	}
+
+	var out []deployment.XdcrReplicationInfo
+	for _, replication := range replications {
+		targetClusterID := replication.Target.Cluster
+		if dinoID, ok := clusterIDs[targetClusterID]; ok {
+			targetClusterID = dinoID
+		}
+
+		filterExpression := ""
+		if replication.Filter != nil {
+			filterExpression = replication.Filter.Expression
+		}
+
+		out = append(out, deployment.XdcrReplicationInfo{
+			ID:               replication.ID,
+			SourceBucket:     bucketNameFromID(replication.SourceBucket),
+			TargetClusterID:  targetClusterID,
+			TargetBucket:     bucketNameFromID(replication.Target.Bucket),
+			Status:           replication.Status,
+			FilterExpression: filterExpression,
+		})
+	}
+
+	return out, nil
+}
+
+func (p *Deployer) CreateXdcrReplication(ctx context.Context, clusterID string, opts *deployment.CreateXdcrReplicationOptions) (string, error) {
+	clusterInfo, err := p.getCluster(ctx, clusterID)
+	if err != nil {
+		return "", err
+	}
+	if clusterInfo.Cluster == nil {
+		return "", errors.New("columnar clusters do not support xdcr")
+	}
+
+	targetInfo, err := p.getCluster(ctx, opts.TargetClusterID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to find target cluster")
+	}
+	if targetInfo.Cluster == nil {
+		return "", errors.New("cannot replicate to a columnar cluster")
+	}
+
+	req := &capellav4.CreateReplicationRequest{
+		SourceBucket: base64.StdEncoding.EncodeToString([]byte(opts.SourceBucket)),
+		Target: capellav4.ReplicationTarget{
+			Type:    "capella",
+			Cluster: targetInfo.Cluster.ID,
+			Bucket:  base64.StdEncoding.EncodeToString([]byte(opts.TargetBucket)),
+		},
+		Direction: capellav4.ReplicationDirectionOneWay,
+	}
+	if opts.FilterExpression != "" {
+		req.Filter = &capellav4.ReplicationFilter{
+			Expression: opts.FilterExpression,
+		}
+	}
+
+	resp, err := p.v4.CreateReplication(ctx, p.tenantID, clusterInfo.ProjectID, clusterInfo.Cluster.ID, req)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create replication")
+	}
+
+	return resp.ID, nil
+}
+
+func (p *Deployer) SetXdcrReplicationPaused(ctx context.Context, clusterID string, replicationID string, paused bool) error {
+	clusterInfo, err := p.getCluster(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	if clusterInfo.Cluster == nil {
+		return errors.New("columnar clusters do not support xdcr")
+	}
+
+	status := capellav4.ReplicationStatusRunning
+	if paused {
+		status = capellav4.ReplicationStatusPaused
+	}
+
+	err = p.v4.UpdateReplicationStatus(ctx, p.tenantID, clusterInfo.ProjectID, clusterInfo.Cluster.ID, replicationID,
+		&capellav4.UpdateReplicationStatusRequest{Status: status})
+	if err != nil {
+		return errors.Wrap(err, "failed to update replication")
+	}
+
+	return nil
+}
+
+func (p *Deployer) DeleteXdcrReplication(ctx context.Context, clusterID string, replicationID string) error {
+	return deployment.NewNotSupportedError("the capella v4 api does not support removing replications")
+}
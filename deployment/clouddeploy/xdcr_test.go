@@ -0,0 +1,12 @@
+package clouddeploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketNameFromID(t *testing.T) {
+	require.Equal(t, "travel-sample", bucketNameFromID("dHJhdmVsLXNhbXBsZQ=="))
+	require.Equal(t, "not base64!", bucketNameFromID("not base64!"))
+}
@@ -0,0 +1,142 @@
+package deployment
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type bucketSetup struct {
+	Opts   *CreateBucketOptions
+	Scopes clusterdef.Scopes
+}
+
+// ClusterSetup is everything a cluster definition sets up on a cluster once it
+// has been allocated, which is the buckets with their scopes, collections and
+// indexes, followed by the groups and users.
+type ClusterSetup struct {
+	buckets []*bucketSetup
+	indexes *DefinedIndexes
+	groups  []*CreateGroupOptions
+	users   []*CreateUserOptions
+}
+
+// NewClusterSetup validates the setup of a cluster definition, so that an
+// invalid definition fails before a cluster is allocated for it.
+func NewClusterSetup(def *clusterdef.Cluster) (*ClusterSetup, error) {
+	setup := &ClusterSetup{}
+
+	for _, bucketName := range slices.Sorted(maps.Keys(def.Buckets)) {
+		bucketDef := def.Buckets[bucketName]
+
+		opts, err := NewCreateBucketOptions(bucketName, &bucketDef.Settings)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid bucket `%s`", bucketName)
+		}
+
+		setup.buckets = append(setup.buckets, &bucketSetup{
+			Opts:   opts,
+			Scopes: bucketDef.Scopes,
+		})
+	}
+
+	indexes, err := NewDefinedIndexes(def.Buckets)
+	if err != nil {
+		return nil, err
+	}
+	setup.indexes = indexes
+
+	for _, groupDef := range def.Groups {
+		opts, err := NewCreateGroupOptions(&groupDef)
+		if err != nil {
+			return nil, err
+		}
+		setup.groups = append(setup.groups, opts)
+	}
+
+	for _, userDef := range def.Users {
+		opts, err := NewCreateUserOptions(&userDef)
+		if err != nil {
+			return nil, err
+		}
+		setup.users = append(setup.users, opts)
+	}
+
+	return setup, nil
+}
+
+// Apply sets up a newly allocated cluster.
+func (s *ClusterSetup) Apply(
+	ctx context.Context,
+	logger *zap.Logger,
+	deployer Deployer,
+	clusterID string,
+) error {
+	for _, bucket := range s.buckets {
+		bucketName := bucket.Opts.Name
+
+		err := deployer.CreateBucket(ctx, clusterID, bucket.Opts)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create bucket `%s`", bucketName)
+		}
+		logger.Info("bucket created", zap.String("bucket", bucketName))
+
+		for _, scopeName := range slices.Sorted(maps.Keys(bucket.Scopes)) {
+			if scopeName == "" {
+				continue
+			}
+
+			err := deployer.CreateScope(ctx, clusterID, bucketName, scopeName)
+			if err != nil {
+				return errors.Wrapf(err, "failed to create scope `%s`", scopeName)
+			}
+			logger.Info("scope created",
+				zap.String("bucket", bucketName),
+				zap.String("scope", scopeName))
+
+			for _, collectionName := range bucket.Scopes[scopeName] {
+				if collectionName == "" {
+					continue
+				}
+
+				err := deployer.CreateCollection(ctx, clusterID, bucketName, scopeName, collectionName)
+				if err != nil {
+					return errors.Wrapf(err, "failed to create collection `%s`", collectionName)
+				}
+				logger.Info("collection created",
+					zap.String("bucket", bucketName),
+					zap.String("scope", scopeName),
+					zap.String("collection", collectionName))
+			}
+		}
+	}
+
+	// indexes are created once their collections exist
+	err := s.indexes.CreateAndWait(ctx, logger, deployer, clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to set up indexes")
+	}
+
+	// groups are created before users, since users may be members of them
+	for _, opts := range s.groups {
+		err := deployer.CreateGroup(ctx, clusterID, opts)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create group `%s`", opts.Name)
+		}
+		logger.Info("group created", zap.String("group", opts.Name))
+	}
+
+	for _, opts := range s.users {
+		err := deployer.CreateUser(ctx, clusterID, opts)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create user `%s`", opts.Username)
+		}
+		logger.Info("user created", zap.String("user", opts.Username))
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClusterSetup(t *testing.T) {
+	setup, err := NewClusterSetup(&clusterdef.Cluster{
+		Buckets: map[string]clusterdef.Bucket{
+			"b": {},
+			"a": {
+				Scopes:  clusterdef.Scopes{"inventory": {"airline"}},
+				Indexes: []clusterdef.Index{{Name: "idx_a", Primary: true}},
+			},
+		},
+		Groups: []clusterdef.Group{{Name: "readers", Roles: []string{"ro_admin"}}},
+		Users:  []clusterdef.User{{Username: "app", Password: "password", Groups: []string{"readers"}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, setup.buckets, 2)
+	require.Equal(t, "a", setup.buckets[0].Opts.Name)
+	require.Equal(t, "b", setup.buckets[1].Opts.Name)
+	require.Len(t, setup.indexes.Indexes, 1)
+	require.Len(t, setup.groups, 1)
+	require.Len(t, setup.users, 1)
+
+	// nothing is allocated for a definition which cannot be set up
+	_, err = NewClusterSetup(&clusterdef.Cluster{
+		Buckets: map[string]clusterdef.Bucket{
+			"a": {Settings: clusterdef.Settings{RamQuotaMB: -1}},
+		},
+	})
+	require.Error(t, err)
+
+	_, err = NewClusterSetup(&clusterdef.Cluster{
+		Users: []clusterdef.User{{Username: "app"}},
+	})
+	require.Error(t, err)
+}
@@ -176,3 +176,117 @@ func (h AgentHelper) ExecuteQuery(ctx context.Context, query string) (string, er
 
 	return string(rowsBytes), nil
 }
+
+func (h AgentHelper) executeStatement(ctx context.Context, statement string) error {
+	results, err := h.Agent.Query(ctx, &gocbcorex.QueryOptions{
+		Statement: statement,
+	})
+	if err != nil {
+		return err
+	}
+
+	for results.HasMoreRows() {
+		_, err := results.ReadRow()
+		if err != nil {
+			return errors.Wrap(err, "failed to read row")
+		}
+	}
+
+	return nil
+}
+
+type systemIndexRow struct {
+	Name       string   `json:"name"`
+	BucketID   string   `json:"bucket_id"`
+	ScopeID    string   `json:"scope_id"`
+	KeyspaceID string   `json:"keyspace_id"`
+	IsPrimary  bool     `json:"is_primary"`
+	IndexKey   []string `json:"index_key"`
+	Condition  string   `json:"condition"`
+	Partition  string   `json:"partition"`
+	State      string   `json:"state"`
+}
+
+func indexInfoFromRow(row json.RawMessage) (*deployment.IndexInfo, error) {
+	var index systemIndexRow
+	err := json.Unmarshal(row, &index)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse index")
+	}
+
+	// indexes of the default collection which were created against the
+	// bucket itself have no bucket_id, and the bucket as their keyspace.
+	keyspace := deployment.Keyspace{
+		Bucket: index.KeyspaceID,
+	}
+	if index.BucketID != "" {
+		keyspace = deployment.Keyspace{
+			Bucket:     index.BucketID,
+			Scope:      index.ScopeID,
+			Collection: index.KeyspaceID,
+		}
+	}
+
+	return &deployment.IndexInfo{
+		Name:      index.Name,
+		Keyspace:  keyspace,
+		IsPrimary: index.IsPrimary,
+		Keys:      index.IndexKey,
+		Condition: index.Condition,
+		Partition: index.Partition,
+		State:     index.State,
+	}, nil
+}
+
+func (h AgentHelper) ListIndexes(ctx context.Context) ([]deployment.IndexInfo, error) {
+	results, err := h.Agent.Query(ctx, &gocbcorex.QueryOptions{
+		Statement: "SELECT i.* FROM system:indexes AS i WHERE i.`using` = \"gsi\"",
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list indexes")
+	}
+
+	var indexes []deployment.IndexInfo
+	for results.HasMoreRows() {
+		row, err := results.ReadRow()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read row")
+		}
+
+		index, err := indexInfoFromRow(row)
+		if err != nil {
+			return nil, err
+		}
+
+		indexes = append(indexes, *index)
+	}
+
+	return indexes, nil
+}
+
+func (h AgentHelper) CreateIndex(ctx context.Context, opts *deployment.CreateIndexOptions) error {
+	err := h.executeStatement(ctx, deployment.CreateIndexStatement(opts))
+	if err != nil {
+		return errors.Wrap(err, "failed to create index")
+	}
+
+	return nil
+}
+
+func (h AgentHelper) DropIndex(ctx context.Context, keyspace deployment.Keyspace, indexName string) error {
+	err := h.executeStatement(ctx, deployment.DropIndexStatement(keyspace, indexName))
+	if err != nil {
+		return errors.Wrap(err, "failed to drop index")
+	}
+
+	return nil
+}
+
+func (h AgentHelper) BuildIndexes(ctx context.Context, keyspace deployment.Keyspace, indexNames []string) error {
+	err := h.executeStatement(ctx, deployment.BuildIndexesStatement(keyspace, indexNames))
+	if err != nil {
+		return errors.Wrap(err, "failed to build indexes")
+	}
+
+	return nil
+}
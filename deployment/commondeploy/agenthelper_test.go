@@ -0,0 +1,39 @@
+package commondeploy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexInfoFromRow(t *testing.T) {
+	index, err := indexInfoFromRow(json.RawMessage(`{
+		"name": "idx_city",
+		"bucket_id": "travel-sample",
+		"scope_id": "inventory",
+		"keyspace_id": "hotel",
+		"index_key": ["city"],
+		"condition": "(free_parking = true)",
+		"state": "online"
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, &deployment.IndexInfo{
+		Name:      "idx_city",
+		Keyspace:  deployment.Keyspace{Bucket: "travel-sample", Scope: "inventory", Collection: "hotel"},
+		Keys:      []string{"city"},
+		Condition: "(free_parking = true)",
+		State:     "online",
+	}, index)
+
+	index, err = indexInfoFromRow(json.RawMessage(`{
+		"name": "#primary",
+		"keyspace_id": "travel-sample",
+		"is_primary": true,
+		"state": "deferred"
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, "travel-sample._default._default", index.Keyspace.String())
+	require.True(t, index.IsPrimary)
+}
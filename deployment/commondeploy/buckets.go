@@ -1,6 +1,8 @@
 package commondeploy
 
 import (
+	"time"
+
 	"github.com/couchbase/gocbcorex/cbmgmtx"
 	"github.com/couchbaselabs/cbdinocluster/deployment"
 	"github.com/pkg/errors"
@@ -10,14 +12,35 @@ import (
 // RAM quota for the bucket.
 const defaultBucketRAMQuotaMB = 256
 
+var mgmtxDurabilityLevels = map[deployment.DurabilityLevel]cbmgmtx.DurabilityLevel{
+	deployment.DurabilityLevelNone:                     cbmgmtx.DurabilityLevelNone,
+	deployment.DurabilityLevelMajority:                 cbmgmtx.DurabilityLevelMajority,
+	deployment.DurabilityLevelMajorityAndPersistActive: cbmgmtx.DurabilityLevelMajorityAndPersistOnMaster,
+	deployment.DurabilityLevelPersistToMajority:        cbmgmtx.DurabilityLevelPersistToMajority,
+}
+
+var mgmtxConflictResolutions = map[deployment.ConflictResolution]cbmgmtx.ConflictResolutionType{
+	deployment.ConflictResolutionSeqno:  cbmgmtx.ConflictResolutionTypeSequenceNumber,
+	deployment.ConflictResolutionLww:    cbmgmtx.ConflictResolutionTypeTimestamp,
+	deployment.ConflictResolutionCustom: cbmgmtx.ConflictResolutionTypeCustom,
+}
+
+var mgmtxCompressionModes = map[deployment.CompressionMode]cbmgmtx.CompressionMode{
+	deployment.CompressionModeOff:     cbmgmtx.CompressionModeOff,
+	deployment.CompressionModePassive: cbmgmtx.CompressionModePassive,
+	deployment.CompressionModeActive:  cbmgmtx.CompressionModeActive,
+}
+
 // buildMgmtxCreateBucketOptions translates the deployment-level bucket creation
 // options into the cbmgmtx settings used against a live server, applying the
 // per-bucket-type rules that Couchbase Server enforces:
 //
-//   - couchbase ("membase"): persistent, couchstore storage backend, valueOnly
-//     eviction.
-//   - ephemeral: memory-only, so no disk storage backend may be specified and
-//     only the noEviction/nruEviction policies are valid.
+//   - couchbase ("membase"): persistent, couchstore (or magma) storage
+//     backend, valueOnly (or fullEviction) eviction.  History retention and
+//     the vbucket count are only valid with magma.
+//   - ephemeral: memory-only, so no disk storage backend may be specified,
+//     only the noEviction/nruEviction policies are valid and the durability
+//     levels which persist to disk are rejected.
 //   - memcached: a legacy, flat in-memory cache with no replicas, no conflict
 //     resolution, no durability, no eviction policy and no storage backend.
 //     Couchbase Server 8.0+ rejects this type outright ("memcached buckets are
@@ -49,19 +72,74 @@ func buildMgmtxCreateBucketOptions(opts *deployment.CreateBucketOptions) (*cbmgm
 		},
 	}
 
+	if opts.DurabilityMinLevel != "" {
+		settings.DurabilityMinLevel = mgmtxDurabilityLevels[opts.DurabilityMinLevel]
+	}
+	if opts.ConflictResolution != "" {
+		settings.ConflictResolutionType = mgmtxConflictResolutions[opts.ConflictResolution]
+	}
+	settings.MaxTTL = opts.MaxTTL
+	settings.CompressionMode = mgmtxCompressionModes[opts.CompressionMode]
+	settings.NumVBuckets = uint16(opts.NumVBuckets)
+
+	if opts.HistoryRetentionBytes > 0 || opts.HistoryRetentionDuration > 0 {
+		if opts.StorageBackend != deployment.StorageBackendMagma {
+			return nil, errors.New("history retention requires the magma storage backend")
+		}
+		settings.HistoryRetentionCollectionDefault = true
+		settings.HistoryRetentionBytes = opts.HistoryRetentionBytes
+		settings.HistoryRetentionSeconds = uint32(opts.HistoryRetentionDuration / time.Second)
+	}
+	if opts.NumVBuckets > 0 && opts.StorageBackend != deployment.StorageBackendMagma {
+		return nil, errors.New("the vbucket count can only be set with the magma storage backend")
+	}
+
 	switch bucketType {
 	case deployment.BucketTypeCouchbase:
 		settings.BucketType = cbmgmtx.BucketTypeCouchbase
+
 		settings.StorageBackend = cbmgmtx.StorageBackendCouchstore
-		settings.EvictionPolicy = cbmgmtx.EvictionPolicyTypeValueOnly
+		if opts.StorageBackend == deployment.StorageBackendMagma {
+			settings.StorageBackend = cbmgmtx.StorageBackendMagma
+		}
+
+		switch opts.EvictionPolicy {
+		case "", deployment.EvictionPolicyValueOnly:
+			settings.EvictionPolicy = cbmgmtx.EvictionPolicyTypeValueOnly
+		case deployment.EvictionPolicyFull:
+			settings.EvictionPolicy = cbmgmtx.EvictionPolicyTypeFull
+		default:
+			return nil, errors.Errorf("eviction policy %q is not valid for couchbase buckets", opts.EvictionPolicy)
+		}
 	case deployment.BucketTypeEphemeral:
 		settings.BucketType = cbmgmtx.BucketTypeEphemeral
 		// Ephemeral buckets keep no data on disk; the server rejects a
 		// storage backend and only accepts the noEviction/nruEviction
 		// policies, so leave the storage backend unset and default to
 		// noEviction.
-		settings.EvictionPolicy = cbmgmtx.EvictionPolicyTypeNoEviction
+		if opts.StorageBackend != "" {
+			return nil, errors.New("ephemeral buckets do not have a storage backend")
+		}
+
+		switch opts.EvictionPolicy {
+		case "", deployment.EvictionPolicyNoEviction:
+			settings.EvictionPolicy = cbmgmtx.EvictionPolicyTypeNoEviction
+		case deployment.EvictionPolicyNotRecentlyUsed:
+			settings.EvictionPolicy = cbmgmtx.EvictionPolicyTypeNotRecentlyUsed
+		default:
+			return nil, errors.Errorf("eviction policy %q is not valid for ephemeral buckets", opts.EvictionPolicy)
+		}
+
+		switch opts.DurabilityMinLevel {
+		case deployment.DurabilityLevelMajorityAndPersistActive, deployment.DurabilityLevelPersistToMajority:
+			return nil, errors.Errorf("durability level %q is not valid for ephemeral buckets", opts.DurabilityMinLevel)
+		}
 	case deployment.BucketTypeMemcached:
+		if opts.StorageBackend != "" || opts.EvictionPolicy != "" || opts.DurabilityMinLevel != "" ||
+			opts.MaxTTL > 0 || opts.CompressionMode != "" || opts.ConflictResolution != "" {
+			return nil, errors.New("memcached buckets only support the ram quota and flush settings")
+		}
+
 		settings.BucketType = cbmgmtx.BucketTypeMemcached
 		// Memcached buckets are a legacy, flat in-memory cache: they support
 		// no replicas, no conflict resolution, no durability, no eviction
@@ -74,6 +152,7 @@ func buildMgmtxCreateBucketOptions(opts *deployment.CreateBucketOptions) (*cbmgm
 		settings.DurabilityMinLevel = cbmgmtx.DurabilityLevelUnset
 		settings.EvictionPolicy = cbmgmtx.EvictionPolicyTypeUnset
 		settings.StorageBackend = cbmgmtx.StorageBackendUnset
+		settings.CompressionMode = cbmgmtx.CompressionModeUnset
 	default:
 		return nil, errors.Errorf("unsupported bucket type %q", bucketType)
 	}
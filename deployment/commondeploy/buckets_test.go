@@ -2,6 +2,7 @@ package commondeploy
 
 import (
 	"testing"
+	"time"
 
 	"github.com/couchbase/gocbcorex/cbmgmtx"
 	"github.com/couchbaselabs/cbdinocluster/deployment"
@@ -109,3 +110,85 @@ func TestBuildMgmtxCreateBucketOptionsHandlesAllTypes(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildMgmtxCreateBucketOptionsMagma(t *testing.T) {
+	got, err := buildMgmtxCreateBucketOptions(&deployment.CreateBucketOptions{
+		Name:                     "cdc",
+		StorageBackend:           deployment.StorageBackendMagma,
+		EvictionPolicy:           deployment.EvictionPolicyFull,
+		DurabilityMinLevel:       deployment.DurabilityLevelMajorityAndPersistActive,
+		MaxTTL:                   time.Hour,
+		CompressionMode:          deployment.CompressionModeActive,
+		ConflictResolution:       deployment.ConflictResolutionLww,
+		HistoryRetentionBytes:    4 * 1024 * 1024 * 1024,
+		HistoryRetentionDuration: 24 * time.Hour,
+		NumVBuckets:              128,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, cbmgmtx.StorageBackendMagma, got.BucketSettings.StorageBackend)
+	require.Equal(t, cbmgmtx.EvictionPolicyTypeFull, got.BucketSettings.EvictionPolicy)
+	require.Equal(t, cbmgmtx.DurabilityLevelMajorityAndPersistOnMaster, got.BucketSettings.DurabilityMinLevel)
+	require.Equal(t, time.Hour, got.BucketSettings.MaxTTL)
+	require.Equal(t, cbmgmtx.CompressionModeActive, got.BucketSettings.CompressionMode)
+	require.Equal(t, cbmgmtx.ConflictResolutionTypeTimestamp, got.BucketSettings.ConflictResolutionType)
+	require.True(t, got.BucketSettings.HistoryRetentionCollectionDefault)
+	require.Equal(t, uint64(4*1024*1024*1024), got.BucketSettings.HistoryRetentionBytes)
+	require.Equal(t, uint32(86400), got.BucketSettings.HistoryRetentionSeconds)
+	require.Equal(t, uint16(128), got.BucketSettings.NumVBuckets)
+}
+
+func TestBuildMgmtxCreateBucketOptionsInvalidSettings(t *testing.T) {
+	tests := []struct {
+		name string
+		opts deployment.CreateBucketOptions
+	}{
+		{
+			name: "history retention without magma",
+			opts: deployment.CreateBucketOptions{HistoryRetentionBytes: 1024},
+		},
+		{
+			name: "vbuckets without magma",
+			opts: deployment.CreateBucketOptions{NumVBuckets: 128},
+		},
+		{
+			name: "ephemeral eviction on couchbase",
+			opts: deployment.CreateBucketOptions{EvictionPolicy: deployment.EvictionPolicyNoEviction},
+		},
+		{
+			name: "ephemeral with storage backend",
+			opts: deployment.CreateBucketOptions{
+				BucketType:     deployment.BucketTypeEphemeral,
+				StorageBackend: deployment.StorageBackendMagma,
+			},
+		},
+		{
+			name: "ephemeral with value-only eviction",
+			opts: deployment.CreateBucketOptions{
+				BucketType:     deployment.BucketTypeEphemeral,
+				EvictionPolicy: deployment.EvictionPolicyValueOnly,
+			},
+		},
+		{
+			name: "ephemeral with persisted durability",
+			opts: deployment.CreateBucketOptions{
+				BucketType:         deployment.BucketTypeEphemeral,
+				DurabilityMinLevel: deployment.DurabilityLevelPersistToMajority,
+			},
+		},
+		{
+			name: "memcached with compression",
+			opts: deployment.CreateBucketOptions{
+				BucketType:      deployment.BucketTypeMemcached,
+				CompressionMode: deployment.CompressionModeActive,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildMgmtxCreateBucketOptions(&tt.opts)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package commondeploy
+
+import (
+	"context"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/utils/clustercontrol"
+	"github.com/pkg/errors"
+)
+
+// BucketHelper implements the bucket operations which are not covered by
+// gocbcorex using the ns_server REST API.  Unlike cbmgmtx, it can update a
+// bucket to zero replicas or no max TTL.
+type BucketHelper struct {
+	Controller *clustercontrol.Controller
+}
+
+func (h BucketHelper) GetBucket(ctx context.Context, bucketName string) (*deployment.BucketDetails, error) {
+	resp, err := h.Controller.GetBucket(ctx, bucketName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get bucket")
+	}
+
+	details, err := bucketDetailsFromResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	// memcached buckets have no resident ratio to report, and the stats of a
+	// bucket may not be available yet, in which case it is left out rather
+	// than failing to describe the bucket.
+	if details.Settings.BucketType != deployment.BucketTypeMemcached {
+		residentRatio, err := h.Controller.GetBucketResidentRatio(ctx, bucketName)
+		if err == nil {
+			details.ResidentRatio = &residentRatio
+		}
+	}
+
+	return details, nil
+}
+
+func bucketDetailsFromResponse(resp *clustercontrol.GetBucketResponse) (*deployment.BucketDetails, error) {
+	bucketType, err := deployment.ParseBucketType(resp.BucketType)
+	if err != nil {
+		return nil, err
+	}
+
+	storageBackend, err := deployment.ParseStorageBackend(resp.StorageBackend)
+	if err != nil {
+		return nil, err
+	}
+
+	evictionPolicy, err := deployment.ParseEvictionPolicy(resp.EvictionPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	durabilityLevel, err := deployment.ParseDurabilityLevel(resp.DurabilityMinLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	compressionMode, err := deployment.ParseCompressionMode(resp.CompressionMode)
+	if err != nil {
+		return nil, err
+	}
+
+	conflictResolution, err := deployment.ParseConflictResolution(resp.ConflictResolutionType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deployment.BucketDetails{
+		Settings: deployment.CreateBucketOptions{
+			Name:                     resp.Name,
+			BucketType:               bucketType,
+			RamQuotaMB:               int(resp.Quota.RawRAM / 1024 / 1024),
+			FlushEnabled:             resp.Controllers.Flush != "",
+			NumReplicas:              resp.ReplicaNumber,
+			StorageBackend:           storageBackend,
+			EvictionPolicy:           evictionPolicy,
+			DurabilityMinLevel:       durabilityLevel,
+			MaxTTL:                   time.Duration(resp.MaxTTL) * time.Second,
+			CompressionMode:          compressionMode,
+			ConflictResolution:       conflictResolution,
+			HistoryRetentionBytes:    resp.HistoryRetentionBytes,
+			HistoryRetentionDuration: time.Duration(resp.HistoryRetentionSeconds) * time.Second,
+			NumVBuckets:              resp.NumVBuckets,
+		},
+		ItemCount:     resp.BasicStats.ItemCount,
+		MemUsedBytes:  resp.BasicStats.MemUsed,
+		DiskUsedBytes: resp.BasicStats.DiskUsed,
+	}, nil
+}
+
+func buildUpdateBucketRequest(opts *deployment.UpdateBucketOptions) (*clustercontrol.UpdateBucketRequest, error) {
+	req := &clustercontrol.UpdateBucketRequest{
+		RamQuotaMB:    opts.RamQuotaMB,
+		ReplicaNumber: opts.NumReplicas,
+	}
+
+	if opts.MaxTTL != nil {
+		maxTTL := int(*opts.MaxTTL / time.Second)
+		req.MaxTTL = &maxTTL
+	}
+
+	if opts.DurabilityMinLevel != "" {
+		durabilityLevel, ok := mgmtxDurabilityLevels[opts.DurabilityMinLevel]
+		if !ok {
+			return nil, errors.Errorf("unsupported durability level %q", opts.DurabilityMinLevel)
+		}
+		req.DurabilityMinLevel = string(durabilityLevel)
+	}
+
+	return req, nil
+}
+
+func (h BucketHelper) UpdateBucket(ctx context.Context, bucketName string, opts *deployment.UpdateBucketOptions) error {
+	req, err := buildUpdateBucketRequest(opts)
+	if err != nil {
+		return err
+	}
+
+	err = h.Controller.UpdateBucket(ctx, bucketName, req)
+	if err != nil {
+		return errors.Wrap(err, "failed to update bucket")
+	}
+
+	return nil
+}
+
+func (h BucketHelper) FlushBucket(ctx context.Context, bucketName string) error {
+	err := h.Controller.FlushBucket(ctx, bucketName)
+	if err != nil {
+		return errors.Wrap(err, "failed to flush bucket")
+	}
+
+	return nil
+}
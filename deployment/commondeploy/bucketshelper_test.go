@@ -0,0 +1,78 @@
+package commondeploy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/utils/clustercontrol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketDetailsFromResponse(t *testing.T) {
+	details, err := bucketDetailsFromResponse(&clustercontrol.GetBucketResponse{
+		Name:                    "events",
+		BucketType:              "membase",
+		StorageBackend:          "magma",
+		EvictionPolicy:          "fullEviction",
+		DurabilityMinLevel:      "majorityAndPersistActive",
+		MaxTTL:                  3600,
+		CompressionMode:         "passive",
+		ConflictResolutionType:  "seqno",
+		ReplicaNumber:           1,
+		NumVBuckets:             128,
+		HistoryRetentionSeconds: 86400,
+		Quota:                   clustercontrol.GetBucketResponse_Quota{RawRAM: 1024 * 1024 * 1024},
+		Controllers:             clustercontrol.GetBucketResponse_Controllers{Flush: "/pools/default/buckets/events/controller/doFlush"},
+		BasicStats: clustercontrol.GetBucketResponse_BasicStats{
+			ItemCount: 10,
+			MemUsed:   2048,
+			DiskUsed:  4096,
+		},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, &deployment.BucketDetails{
+		Settings: deployment.CreateBucketOptions{
+			Name:                     "events",
+			BucketType:               deployment.BucketTypeCouchbase,
+			RamQuotaMB:               1024,
+			FlushEnabled:             true,
+			NumReplicas:              1,
+			StorageBackend:           deployment.StorageBackendMagma,
+			EvictionPolicy:           deployment.EvictionPolicyFull,
+			DurabilityMinLevel:       deployment.DurabilityLevelMajorityAndPersistActive,
+			MaxTTL:                   time.Hour,
+			CompressionMode:          deployment.CompressionModePassive,
+			ConflictResolution:       deployment.ConflictResolutionSeqno,
+			HistoryRetentionDuration: 24 * time.Hour,
+			NumVBuckets:              128,
+		},
+		ItemCount:     10,
+		MemUsedBytes:  2048,
+		DiskUsedBytes: 4096,
+	}, details)
+}
+
+func TestBuildUpdateBucketRequest(t *testing.T) {
+	numReplicas := 0
+	maxTTL := time.Duration(0)
+
+	req, err := buildUpdateBucketRequest(&deployment.UpdateBucketOptions{
+		NumReplicas:        &numReplicas,
+		MaxTTL:             &maxTTL,
+		DurabilityMinLevel: deployment.DurabilityLevelPersistToMajority,
+	})
+	require.NoError(t, err)
+
+	// settings which are set to zero must still be sent
+	require.Nil(t, req.RamQuotaMB)
+	require.Equal(t, 0, *req.ReplicaNumber)
+	require.Equal(t, 0, *req.MaxTTL)
+	require.Equal(t, "persistToMajority", req.DurabilityMinLevel)
+
+	_, err = buildUpdateBucketRequest(&deployment.UpdateBucketOptions{
+		DurabilityMinLevel: deployment.DurabilityLevel("all"),
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,277 @@
+package commondeploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"time"
+
+	"github.com/couchbase/gocbcorex"
+	"github.com/couchbase/gocbcorex/memdx"
+	"github.com/couchbase/gocbcorex/transactions"
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+)
+
+// levels which are missing use no durability
+var memdxDurabilityLevels = map[deployment.DurabilityLevel]memdx.DurabilityLevel{
+	deployment.DurabilityLevelMajority:                 memdx.DurabilityLevelMajority,
+	deployment.DurabilityLevelMajorityAndPersistActive: memdx.DurabilityLevelMajorityAndPersistToActive,
+	deployment.DurabilityLevelPersistToMajority:        memdx.DurabilityLevelPersistToMajority,
+}
+
+// maxRelativeExpiry is the longest expiry which memcached treats as relative
+// to now, rather than as a unix timestamp.
+const maxRelativeExpiry = 30 * 24 * time.Hour
+
+func memdExpiry(expiry time.Duration, now time.Time) uint32 {
+	if expiry <= 0 {
+		return 0
+	}
+	if expiry > maxRelativeExpiry {
+		return uint32(now.Add(expiry).Unix())
+	}
+	if expiry < time.Second {
+		return 1
+	}
+	return uint32(expiry / time.Second)
+}
+
+// defaultNumVBuckets is the number of vbuckets of a bucket which does not
+// report its own.
+const defaultNumVBuckets = 1024
+
+// vbucketForKey returns the vbucket which a key belongs to.
+func vbucketForKey(key string, numVBuckets int) uint16 {
+	if numVBuckets <= 0 {
+		numVBuckets = defaultNumVBuckets
+	}
+	hash := (crc32.ChecksumIEEE([]byte(key)) >> 16) & 0x7fff
+	return uint16(hash % uint32(numVBuckets))
+}
+
+// DocumentStore operates on the documents of a collection through an agent
+// of its bucket.
+type DocumentStore struct {
+	Agent           *gocbcorex.Agent
+	BucketName      string
+	ScopeName       string
+	CollectionName  string
+	DurabilityLevel deployment.DurabilityLevel
+
+	// NumVBuckets is the number of vbuckets of the bucket, which range scans
+	// need to find the vbucket of a key.  It defaults to 1024 when zero.
+	NumVBuckets int
+
+	// Transactions runs the transactions of the store, see
+	// NewTransactionsManager.
+	Transactions *transactions.TransactionsManager
+}
+
+// NewTransactionsManager returns a transactions manager which keeps its
+// transaction records in the bucket of an agent.
+func NewTransactionsManager(agent *gocbcorex.Agent) (*transactions.TransactionsManager, error) {
+	return transactions.NewTransactionsManager(&transactions.TransactionsConfig{
+		BucketAgentProvider: func(ctx context.Context, bucketName string) (*gocbcorex.Agent, string, error) {
+			return agent, "", nil
+		},
+	})
+}
+
+func (s DocumentStore) Upsert(ctx context.Context, key string, value []byte, expiry time.Duration) error {
+	_, err := s.Agent.Upsert(ctx, &gocbcorex.UpsertOptions{
+		Key:             []byte(key),
+		ScopeName:       s.ScopeName,
+		CollectionName:  s.CollectionName,
+		Value:           value,
+		Datatype:        memdx.DatatypeFlagJSON,
+		Expiry:          memdExpiry(expiry, time.Now()),
+		DurabilityLevel: memdxDurabilityLevels[s.DurabilityLevel],
+	})
+	return err
+}
+
+func (s DocumentStore) Delete(ctx context.Context, key string) error {
+	_, err := s.Agent.Delete(ctx, &gocbcorex.DeleteOptions{
+		Key:             []byte(key),
+		ScopeName:       s.ScopeName,
+		CollectionName:  s.CollectionName,
+		DurabilityLevel: memdxDurabilityLevels[s.DurabilityLevel],
+	})
+	return err
+}
+
+func (s DocumentStore) Touch(ctx context.Context, key string, expiry time.Duration) error {
+	_, err := s.Agent.Touch(ctx, &gocbcorex.TouchOptions{
+		Key:            []byte(key),
+		ScopeName:      s.ScopeName,
+		CollectionName: s.CollectionName,
+		Expiry:         memdExpiry(expiry, time.Now()),
+	})
+	return err
+}
+
+func (s DocumentStore) Get(ctx context.Context, key string) error {
+	_, err := s.Agent.Get(ctx, &gocbcorex.GetOptions{
+		Key:            []byte(key),
+		ScopeName:      s.ScopeName,
+		CollectionName: s.CollectionName,
+	})
+	return err
+}
+
+// LookupIn reads a path of a document.  Only errors with the document itself
+// are returned, a missing path is not an error.
+func (s DocumentStore) LookupIn(ctx context.Context, key string, path string) error {
+	_, err := s.Agent.LookupIn(ctx, &gocbcorex.LookupInOptions{
+		Key:            []byte(key),
+		ScopeName:      s.ScopeName,
+		CollectionName: s.CollectionName,
+		Ops: []memdx.LookupInOp{
+			{Op: memdx.LookupInOpTypeGet, Path: []byte(path)},
+		},
+	})
+	return err
+}
+
+// MutateIn sets a path of a document to a JSON value.
+func (s DocumentStore) MutateIn(ctx context.Context, key string, path string, value []byte) error {
+	_, err := s.Agent.MutateIn(ctx, &gocbcorex.MutateInOptions{
+		Key:            []byte(key),
+		ScopeName:      s.ScopeName,
+		CollectionName: s.CollectionName,
+		Ops: []memdx.MutateInOp{
+			{Op: memdx.MutateInOpTypeDictSet, Path: []byte(path), Value: value},
+		},
+		DurabilityLevel: memdxDurabilityLevels[s.DurabilityLevel],
+	})
+	return err
+}
+
+// Query executes a N1QL statement and discards its rows.
+func (s DocumentStore) Query(ctx context.Context, statement string) error {
+	return AgentHelper{Agent: s.Agent}.executeStatement(ctx, statement)
+}
+
+// RangeScan scans the keys of up to limit documents, starting at a key and
+// staying within its vbucket.  An empty range is not an error.
+func (s DocumentStore) RangeScan(ctx context.Context, fromKey string, limit int) error {
+	vbID := vbucketForKey(fromKey, s.NumVBuckets)
+
+	scan, err := s.Agent.RangeScanCreate(ctx, &gocbcorex.RangeScanCreateOptions{
+		ScopeName:      s.ScopeName,
+		CollectionName: s.CollectionName,
+		VbucketID:      vbID,
+		Range: &memdx.RangeScanCreateRangeScanConfig{
+			Start: []byte(fromKey),
+			End:   []byte{0xff},
+		},
+		KeysOnly: true,
+	})
+	if errors.Is(err, memdx.ErrDocNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	res, err := s.Agent.RangeScanContinue(ctx, &gocbcorex.RangeScanContinueOptions{
+		ScopeName:      s.ScopeName,
+		CollectionName: s.CollectionName,
+		VbucketID:      vbID,
+		ScanUUID:       scan.ScanUUID,
+		MaxCount:       uint32(limit),
+	}, func(data gocbcorex.RangeScanDataResult) {})
+	if err != nil {
+		return err
+	}
+
+	// scans which stop early are cancelled rather than left to time out
+	if !res.Complete {
+		return s.Agent.RangeScanCancel(ctx, &gocbcorex.RangeScanCancelOptions{
+			ScopeName:      s.ScopeName,
+			CollectionName: s.CollectionName,
+			VbucketID:      vbID,
+			ScanUUID:       scan.ScanUUID,
+		})
+	}
+
+	return nil
+}
+
+// Transaction sets a path of each of the documents within one transaction.
+// The transaction makes a single attempt, so that conflicts and failures
+// are returned rather than retried.
+func (s DocumentStore) Transaction(ctx context.Context, keys []string, path string, value []byte) error {
+	if s.Transactions == nil {
+		return errors.New("the store has no transactions manager")
+	}
+
+	txn, err := s.Transactions.BeginTransaction(&transactions.TransactionOptions{})
+	if err != nil {
+		return err
+	}
+
+	err = txn.NewAttempt()
+	if err != nil {
+		return err
+	}
+
+	err = s.updateInTransaction(ctx, txn, keys, path, value)
+	if err != nil {
+		_ = txn.Rollback(ctx)
+		return err
+	}
+
+	return txn.Commit(ctx)
+}
+
+func (s DocumentStore) updateInTransaction(
+	ctx context.Context,
+	txn *transactions.Transaction,
+	keys []string,
+	path string,
+	value []byte,
+) error {
+	for _, key := range keys {
+		doc, err := txn.Get(ctx, &transactions.TransactionGetOptions{
+			BucketName:     s.BucketName,
+			ScopeName:      s.ScopeName,
+			CollectionName: s.CollectionName,
+			Key:            []byte(key),
+		})
+		if err != nil {
+			return err
+		}
+
+		newValue, err := setJsonPath(doc.Value, path, value)
+		if err != nil {
+			return err
+		}
+
+		_, err = txn.Replace(ctx, &transactions.TransactionReplaceOptions{
+			Document: doc,
+			Value:    newValue,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setJsonPath sets a top-level path of a JSON object.
+func setJsonPath(doc []byte, path string, value []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(doc, &fields)
+	if err != nil {
+		return nil, fmt.Errorf("document is not a JSON object: %w", err)
+	}
+	if fields == nil {
+		fields = make(map[string]json.RawMessage)
+	}
+
+	fields[path] = value
+	return json.Marshal(fields)
+}
@@ -0,0 +1,39 @@
+package commondeploy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemdExpiry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	require.Equal(t, uint32(0), memdExpiry(0, now))
+	require.Equal(t, uint32(1), memdExpiry(time.Millisecond, now))
+	require.Equal(t, uint32(3600), memdExpiry(time.Hour, now))
+	require.Equal(t, uint32(30*24*3600), memdExpiry(30*24*time.Hour, now))
+
+	// expiries beyond 30 days are absolute
+	require.Equal(t, uint32(1700000000+31*24*3600), memdExpiry(31*24*time.Hour, now))
+}
+
+func TestVbucketForKey(t *testing.T) {
+	require.Equal(t, uint16(115), vbucketForKey("foo", 1024))
+	require.Equal(t, uint16(115), vbucketForKey("foo", 0))
+	require.Equal(t, uint16(115), vbucketForKey("foo", 128))
+}
+
+func TestSetJsonPath(t *testing.T) {
+	doc, err := setJsonPath([]byte(`{"name":"a","workload":{"updated_ms":1}}`), "workload", []byte(`{"updated_ms":2}`))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"a","workload":{"updated_ms":2}}`, string(doc))
+
+	doc, err = setJsonPath([]byte(`null`), "workload", []byte(`1`))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"workload":1}`, string(doc))
+
+	_, err = setJsonPath([]byte(`[1]`), "workload", []byte(`1`))
+	require.Error(t, err)
+}
@@ -0,0 +1,78 @@
+package commondeploy
+
+import (
+	"context"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/utils/clustercontrol"
+	"github.com/pkg/errors"
+)
+
+// SearchHelper implements search indexes using the REST API of the search
+// service.
+type SearchHelper struct {
+	Controller *clustercontrol.Controller
+}
+
+// buildSearchIndexDefinition fills in the parts of a search index definition
+// which identify the index, leaving the rest of it to the search service.
+func buildSearchIndexDefinition(opts *deployment.CreateSearchIndexOptions) map[string]interface{} {
+	def := make(map[string]interface{}, len(opts.Definition)+4)
+	for key, value := range opts.Definition {
+		def[key] = value
+	}
+
+	def["name"] = opts.Name
+	def["sourceName"] = opts.SourceName
+	if _, ok := def["type"]; !ok {
+		def["type"] = "fulltext-index"
+	}
+	if _, ok := def["sourceType"]; !ok {
+		def["sourceType"] = "gocbcore"
+	}
+
+	return def
+}
+
+func (h SearchHelper) ListSearchIndexes(ctx context.Context) ([]deployment.SearchIndexInfo, error) {
+	indexes, err := h.Controller.ListSearchIndexes(ctx)
+	if err != nil {
+		// clusters without the search service have no search indexes
+		if clustercontrol.IsNotFoundError(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(err, "failed to list search indexes")
+	}
+
+	var out []deployment.SearchIndexInfo
+	for _, index := range indexes {
+		_, err := h.Controller.GetSearchIndexCount(ctx, index.Name)
+
+		out = append(out, deployment.SearchIndexInfo{
+			Name:       index.Name,
+			SourceName: index.SourceName,
+			Ready:      err == nil,
+		})
+	}
+
+	return out, nil
+}
+
+func (h SearchHelper) CreateSearchIndex(ctx context.Context, opts *deployment.CreateSearchIndexOptions) error {
+	err := h.Controller.CreateSearchIndex(ctx, opts.Name, buildSearchIndexDefinition(opts))
+	if err != nil {
+		return errors.Wrap(err, "failed to create search index")
+	}
+
+	return nil
+}
+
+func (h SearchHelper) DropSearchIndex(ctx context.Context, indexName string) error {
+	err := h.Controller.DeleteSearchIndex(ctx, indexName)
+	if err != nil {
+		return errors.Wrap(err, "failed to drop search index")
+	}
+
+	return nil
+}
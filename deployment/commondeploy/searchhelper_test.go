@@ -0,0 +1,33 @@
+package commondeploy
+
+import (
+	"testing"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSearchIndexDefinition(t *testing.T) {
+	def := buildSearchIndexDefinition(&deployment.CreateSearchIndexOptions{
+		Name:       "hotels",
+		SourceName: "travel-sample",
+		Definition: map[string]interface{}{
+			"name":   "ignored",
+			"params": map[string]interface{}{},
+		},
+	})
+	require.Equal(t, map[string]interface{}{
+		"name":       "hotels",
+		"sourceName": "travel-sample",
+		"type":       "fulltext-index",
+		"sourceType": "gocbcore",
+		"params":     map[string]interface{}{},
+	}, def)
+
+	def = buildSearchIndexDefinition(&deployment.CreateSearchIndexOptions{
+		Name:       "hotels",
+		SourceName: "travel-sample",
+		Definition: map[string]interface{}{"type": "fulltext-alias"},
+	})
+	require.Equal(t, "fulltext-alias", def["type"])
+}
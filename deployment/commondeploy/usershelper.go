@@ -0,0 +1,119 @@
+package commondeploy
+
+import (
+	"context"
+	"slices"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/utils/clustercontrol"
+	"github.com/pkg/errors"
+)
+
+// UsersHelper implements the user and group operations using the ns_server
+// REST API.
+type UsersHelper struct {
+	Controller *clustercontrol.Controller
+}
+
+func (h UsersHelper) ListUsers(ctx context.Context) ([]deployment.UserInfo, error) {
+	resp, err := h.Controller.ListAllUsers(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list users")
+	}
+
+	var users []deployment.UserInfo
+	for _, user := range resp {
+		users = append(users, userInfoFromResponse(&user))
+	}
+
+	return users, nil
+}
+
+func userInfoFromResponse(user *clustercontrol.ListUsersResponse_User) deployment.UserInfo {
+	var roles []string
+	for _, role := range user.Roles {
+		// roles inherited from a group are reported by the group instead
+		isGroupRole := len(role.Origins) > 0 && !slices.ContainsFunc(role.Origins,
+			func(origin clustercontrol.ListUsersResponse_User_Role_Origin) bool {
+				return origin.Type == "user"
+			})
+		if isGroupRole {
+			continue
+		}
+
+		roles = append(roles, deployment.Role{
+			Name:       role.Role,
+			Bucket:     role.BucketName,
+			Scope:      role.ScopeName,
+			Collection: role.CollectionName,
+		}.String())
+	}
+
+	return deployment.UserInfo{
+		Username: user.ID,
+		CanRead:  deployment.RolesCanRead(roles),
+		CanWrite: deployment.RolesCanWrite(roles),
+		Roles:    roles,
+		Groups:   user.Groups,
+		External: user.Domain == "external",
+	}
+}
+
+func (h UsersHelper) CreateUser(ctx context.Context, opts *deployment.CreateUserOptions) error {
+	_, err := deployment.ParseRoles(opts.Roles)
+	if err != nil {
+		return err
+	}
+
+	domain := "local"
+	if opts.External {
+		if opts.Password != "" {
+			return errors.New("external users cannot have a password")
+		}
+		domain = "external"
+	}
+
+	err = h.Controller.CreateUser(ctx, opts.Username, &clustercontrol.CreateUserRequest{
+		Domain:   domain,
+		Name:     "",
+		Password: opts.Password,
+		Roles:    opts.EffectiveRoles(),
+		Groups:   opts.Groups,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create user")
+	}
+
+	return nil
+}
+
+func (h UsersHelper) DeleteUser(ctx context.Context, username string) error {
+	// the user is looked for in each domain rather than by listing every user
+	err := h.Controller.DeleteUserInDomain(ctx, "local", username)
+	if clustercontrol.IsNotFoundError(err) {
+		err = h.Controller.DeleteUserInDomain(ctx, "external", username)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to delete user")
+	}
+
+	return nil
+}
+
+func (h UsersHelper) CreateGroup(ctx context.Context, opts *deployment.CreateGroupOptions) error {
+	_, err := deployment.ParseRoles(opts.Roles)
+	if err != nil {
+		return err
+	}
+
+	err = h.Controller.CreateGroup(ctx, opts.Name, &clustercontrol.CreateGroupRequest{
+		Roles:        opts.Roles,
+		Description:  opts.Description,
+		LdapGroupRef: opts.LdapGroupRef,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create group")
+	}
+
+	return nil
+}
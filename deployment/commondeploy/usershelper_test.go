@@ -0,0 +1,52 @@
+package commondeploy
+
+import (
+	"testing"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/utils/clustercontrol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserInfoFromResponse(t *testing.T) {
+	user := userInfoFromResponse(&clustercontrol.ListUsersResponse_User{
+		ID:     "app",
+		Domain: "external",
+		Groups: []string{"readers"},
+		Roles: []clustercontrol.ListUsersResponse_User_Role{
+			{
+				Role:       "data_writer",
+				BucketName: "travel-sample",
+				ScopeName:  "*",
+				Origins: []clustercontrol.ListUsersResponse_User_Role_Origin{
+					{Type: "user"},
+					{Type: "group"},
+				},
+			},
+			{
+				// inherited only from a group, so reported by the group instead
+				Role:       "data_reader",
+				BucketName: "travel-sample",
+				Origins: []clustercontrol.ListUsersResponse_User_Role_Origin{
+					{Type: "group"},
+				},
+			},
+			{
+				Role: "query_select",
+			},
+		},
+	})
+
+	roles := []string{
+		deployment.Role{Name: "data_writer", Bucket: "travel-sample", Scope: "*"}.String(),
+		deployment.Role{Name: "query_select"}.String(),
+	}
+	require.Equal(t, deployment.UserInfo{
+		Username: "app",
+		CanRead:  deployment.RolesCanRead(roles),
+		CanWrite: deployment.RolesCanWrite(roles),
+		Roles:    roles,
+		Groups:   []string{"readers"},
+		External: true,
+	}, user)
+}
@@ -0,0 +1,118 @@
+package commondeploy
+
+import (
+	"context"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/utils/clustercontrol"
+	"github.com/pkg/errors"
+)
+
+// XdcrHelper implements XDCR using the ns_server REST API.  Remote cluster
+// references are named after the cluster id of the remote.
+type XdcrHelper struct {
+	Controller *clustercontrol.Controller
+}
+
+func (h XdcrHelper) ListXdcrRemotes(ctx context.Context) ([]deployment.XdcrRemoteInfo, error) {
+	remotes, err := h.Controller.ListRemoteClusters(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list remote clusters")
+	}
+
+	var out []deployment.XdcrRemoteInfo
+	for _, remote := range remotes {
+		out = append(out, deployment.XdcrRemoteInfo{
+			Name:      remote.Name,
+			Uuid:      remote.Uuid,
+			Hostname:  remote.Hostname,
+			Encrypted: remote.DemandEncryption,
+		})
+	}
+
+	return out, nil
+}
+
+func (h XdcrHelper) CreateXdcrRemote(ctx context.Context, opts *deployment.CreateXdcrRemoteOptions) error {
+	req := &clustercontrol.CreateRemoteClusterRequest{
+		Name:     opts.Name,
+		Hostname: opts.Target.Hostname,
+		Username: opts.Target.Username,
+		Password: opts.Target.Password,
+	}
+	if opts.Target.Certificate != "" {
+		req.DemandEncryption = true
+		req.EncryptionType = "full"
+		req.Certificate = opts.Target.Certificate
+	}
+
+	err := h.Controller.CreateRemoteCluster(ctx, req)
+	if err != nil {
+		return errors.Wrap(err, "failed to create remote cluster")
+	}
+
+	return nil
+}
+
+func (h XdcrHelper) ListXdcrReplications(ctx context.Context) ([]deployment.XdcrReplicationInfo, error) {
+	remotes, err := h.Controller.ListRemoteClusters(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list remote clusters")
+	}
+
+	remoteNames := make(map[string]string)
+	for _, remote := range remotes {
+		remoteNames[remote.Uuid] = remote.Name
+	}
+
+	replications, err := h.Controller.ListReplications(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list replications")
+	}
+
+	var out []deployment.XdcrReplicationInfo
+	for _, replication := range replications {
+		out = append(out, deployment.XdcrReplicationInfo{
+			ID:               replication.ID,
+			SourceBucket:     replication.SourceBucket,
+			TargetClusterID:  remoteNames[replication.RemoteUuid],
+			TargetBucket:     replication.TargetBucket,
+			Status:           replication.Status,
+			FilterExpression: replication.FilterExpression,
+		})
+	}
+
+	return out, nil
+}
+
+func (h XdcrHelper) CreateXdcrReplication(ctx context.Context, opts *deployment.CreateXdcrReplicationOptions) (string, error) {
+	resp, err := h.Controller.CreateReplication(ctx, &clustercontrol.CreateReplicationRequest{
+		FromBucket:       opts.SourceBucket,
+		ToCluster:        opts.TargetClusterID,
+		ToBucket:         opts.TargetBucket,
+		FilterExpression: opts.FilterExpression,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create replication")
+	}
+
+	return resp.ID, nil
+}
+
+func (h XdcrHelper) SetXdcrReplicationPaused(ctx context.Context, replicationID string, paused bool) error {
+	err := h.Controller.SetReplicationPaused(ctx, replicationID, paused)
+	if err != nil {
+		return errors.Wrap(err, "failed to update replication")
+	}
+
+	return nil
+}
+
+func (h XdcrHelper) DeleteXdcrReplication(ctx context.Context, replicationID string) error {
+	err := h.Controller.DeleteReplication(ctx, replicationID)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete replication")
+	}
+
+	return nil
+}
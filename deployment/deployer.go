@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/couchbase/gocbcorex"
 	"github.com/couchbaselabs/cbdinocluster/clusterdef"
 )
 
@@ -43,12 +44,19 @@ type ConnectInfo struct {
 	DataApiConnstr string
 	DnsAName       string
 	DnsSRVName     string
+
+	// DnsServer is the address of the dns server serving DnsAName and
+	// DnsSRVName, when they are not resolvable through the host resolver.
+	DnsServer string
 }
 
 type UserInfo struct {
 	Username string
 	CanRead  bool
 	CanWrite bool
+	Roles    []string
+	Groups   []string
+	External bool
 }
 
 type CreateUserOptions struct {
@@ -56,6 +64,23 @@ type CreateUserOptions struct {
 	Password string
 	CanRead  bool
 	CanWrite bool
+
+	// Roles are granted to the user instead of those implied by CanRead and
+	// CanWrite, when set.  Users with groups or in the external domain are
+	// only granted these roles.
+	Roles  []string
+	Groups []string
+
+	// External creates the user in the external (LDAP) domain, in which case
+	// it has no password.
+	External bool
+}
+
+type CreateGroupOptions struct {
+	Name         string
+	Description  string
+	Roles        []string
+	LdapGroupRef string
 }
 
 type ExecuteQueryOptions struct {
@@ -63,16 +88,61 @@ type ExecuteQueryOptions struct {
 	Password string
 }
 
+// OpenBucketAgentOptions holds the database user to connect as, which is only
+// needed by deployers that cannot connect as the cluster administrator.
+type OpenBucketAgentOptions struct {
+	Username string
+	Password string
+}
+
 type BucketInfo struct {
 	Name string
 }
 
+// CreateBucketOptions describes a bucket to create.  Settings left at their
+// zero value use the defaults of the deployer.
 type CreateBucketOptions struct {
 	Name         string
 	BucketType   BucketType
 	RamQuotaMB   int
 	FlushEnabled bool
 	NumReplicas  int
+
+	StorageBackend     StorageBackend
+	EvictionPolicy     EvictionPolicy
+	DurabilityMinLevel DurabilityLevel
+	MaxTTL             time.Duration
+	CompressionMode    CompressionMode
+	ConflictResolution ConflictResolution
+
+	// History retention is used by change data capture, and is only
+	// available on magma buckets.
+	HistoryRetentionBytes    uint64
+	HistoryRetentionDuration time.Duration
+
+	NumVBuckets int
+}
+
+// UpdateBucketOptions describes changes to a bucket.  Settings left unset
+// are not changed.
+type UpdateBucketOptions struct {
+	RamQuotaMB         *int
+	NumReplicas        *int
+	MaxTTL             *time.Duration
+	DurabilityMinLevel DurabilityLevel
+}
+
+// BucketDetails describes the settings and the usage of a bucket.
+type BucketDetails struct {
+	Settings CreateBucketOptions
+
+	ItemCount     uint64
+	MemUsedBytes  uint64
+	DiskUsedBytes uint64
+
+	// ResidentRatio is the percentage of active items resident in memory,
+	// and is nil when the deployer cannot report it.
+	ResidentRatio *float64
 }
 
 type ScopeInfo struct {
@@ -98,6 +168,23 @@ const (
 	BlockNodeTrafficAll     BlockNodeTrafficType = "all"
 )
 
+type DegradeNodeTrafficDirection string
+
+const (
+	DegradeNodeTrafficIngress DegradeNodeTrafficDirection = "ingress"
+	DegradeNodeTrafficEgress  DegradeNodeTrafficDirection = "egress"
+	DegradeNodeTrafficBoth    DegradeNodeTrafficDirection = "both"
+)
+
+type DegradeNodeTrafficOptions struct {
+	TrafficType BlockNodeTrafficType
+	Direction   DegradeNodeTrafficDirection
+	Latency     time.Duration
+	Jitter      time.Duration
+	LossPercent float64
+	Rate        string
+}
+
 type FailOverType string
 
 const (
@@ -112,6 +199,48 @@ const (
 	DeltaRecovery RecoveryType = "delta"
 )
 
+// XdcrTarget describes how the nodes of another cluster can reach a cluster
+// when replicating to it.
+type XdcrTarget struct {
+	Hostname string
+	Username string
+	Password string
+
+	// Certificate is the CA of the cluster, and enables full encryption of
+	// replications to it when set.
+	Certificate string
+}
+
+type XdcrRemoteInfo struct {
+	Name      string
+	Uuid      string
+	Hostname  string
+	Encrypted bool
+}
+
+type CreateXdcrRemoteOptions struct {
+	// Name is the cluster id of the remote, which replications use to refer
+	// to it.
+	Name   string
+	Target *XdcrTarget
+}
+
+type XdcrReplicationInfo struct {
+	ID               string
+	SourceBucket     string
+	TargetClusterID  string
+	TargetBucket     string
+	Status           string
+	FilterExpression string
+}
+
+type CreateXdcrReplicationOptions struct {
+	SourceBucket     string
+	TargetClusterID  string
+	TargetBucket     string
+	FilterExpression string
+}
+
 type Deployer interface {
 	ListClusters(ctx context.Context) ([]ClusterInfo, error)
 	NewCluster(ctx context.Context, def *clusterdef.Cluster) (ClusterInfo, error)
@@ -125,15 +254,21 @@ type Deployer interface {
 	SetNodeRecovery(ctx context.Context, clusterID string, nodeID string, recoveryType RecoveryType) error
 	RebalanceCluster(ctx context.Context, clusterID string, nodesToEject []string) error
 	RemoveCluster(ctx context.Context, clusterID string) error
+	StopCluster(ctx context.Context, clusterID string) error
+	StartCluster(ctx context.Context, clusterID string) error
 	RemoveAll(ctx context.Context) error
 	Cleanup(ctx context.Context) error
 	GetConnectInfo(ctx context.Context, clusterID string) (*ConnectInfo, error)
 	ListUsers(ctx context.Context, clusterID string) ([]UserInfo, error)
 	CreateUser(ctx context.Context, clusterID string, opts *CreateUserOptions) error
 	DeleteUser(ctx context.Context, clusterID string, username string) error
+	CreateGroup(ctx context.Context, clusterID string, opts *CreateGroupOptions) error
 	ListBuckets(ctx context.Context, clusterID string) ([]BucketInfo, error)
 	CreateBucket(ctx context.Context, clusterID string, opts *CreateBucketOptions) error
 	DeleteBucket(ctx context.Context, clusterID string, bucketName string) error
+	GetBucket(ctx context.Context, clusterID string, bucketName string) (*BucketDetails, error)
+	UpdateBucket(ctx context.Context, clusterID string, bucketName string, opts *UpdateBucketOptions) error
+	FlushBucket(ctx context.Context, clusterID string, bucketName string) error
 	LoadSampleBucket(ctx context.Context, clusterID string, bucketName string) error
 	GetCertificate(ctx context.Context, clusterID string) (string, error)
 	GetGatewayCertificate(ctx context.Context, clusterID string) (string, error)
@@ -147,6 +282,9 @@ type Deployer interface {
 	BlockNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string, trafficType BlockNodeTrafficType, rejectType string) error
 	AllowNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string) error
 	PartitionNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string, rejectType string) error
+	DegradeNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string, opts *DegradeNodeTrafficOptions) error
+	PartitionNodeGroups(ctx context.Context, clusterID string, spec *PartitionSpec, rejectType string) error
+	ListNodeTrafficRules(ctx context.Context, clusterID string) ([]NodeTrafficRules, error)
 	CollectLogs(ctx context.Context, clusterID string, destPath string) ([]string, error)
 	ListImages(ctx context.Context) ([]Image, error)
 	SearchImages(ctx context.Context, version string) ([]Image, error)
@@ -159,4 +297,20 @@ type Deployer interface {
 	EnableDataApi(ctx context.Context, clusterID string) error
 	KillCouchbase(ctx context.Context, clusterID string, nodes []string) error
 	SetAutoFailover(ctx context.Context, clusterID string, enabled bool, timeout int) error
+	GetXdcrTarget(ctx context.Context, clusterID string) (*XdcrTarget, error)
+	ListXdcrRemotes(ctx context.Context, clusterID string) ([]XdcrRemoteInfo, error)
+	CreateXdcrRemote(ctx context.Context, clusterID string, opts *CreateXdcrRemoteOptions) error
+	ListXdcrReplications(ctx context.Context, clusterID string) ([]XdcrReplicationInfo, error)
+	CreateXdcrReplication(ctx context.Context, clusterID string, opts *CreateXdcrReplicationOptions) (string, error)
+	SetXdcrReplicationPaused(ctx context.Context, clusterID string, replicationID string, paused bool) error
+	DeleteXdcrReplication(ctx context.Context, clusterID string, replicationID string) error
+	ListIndexes(ctx context.Context, clusterID string) ([]IndexInfo, error)
+	CreateIndex(ctx context.Context, clusterID string, opts *CreateIndexOptions) error
+	DropIndex(ctx context.Context, clusterID string, keyspace Keyspace, indexName string) error
+	BuildIndexes(ctx context.Context, clusterID string, keyspace Keyspace, indexNames []string) error
+	ListSearchIndexes(ctx context.Context, clusterID string) ([]SearchIndexInfo, error)
+	CreateSearchIndex(ctx context.Context, clusterID string, opts *CreateSearchIndexOptions) error
+	DropSearchIndex(ctx context.Context, clusterID string, indexName string) error
+	OpenBucketAgent(ctx context.Context, clusterID string, bucketName string, opts *OpenBucketAgentOptions) (*gocbcorex.Agent, error)
+	Capabilities(ctx context.Context) ([]Capability, error)
 }
@@ -29,6 +29,7 @@ type ClusterInfo struct {
 	Owner     string
 	Purpose   string
 	Expiry    time.Time
+	State     string
 	Nodes     []deployment.ClusterNodeInfo
 }
 
@@ -38,5 +39,5 @@ func (i ClusterInfo) GetID() string                          { return i.ClusterI
 func (i ClusterInfo) GetType() deployment.ClusterType        { return i.Type }
 func (i ClusterInfo) GetPurpose() string                     { return i.Purpose }
 func (i ClusterInfo) GetExpiry() time.Time                   { return i.Expiry }
-func (i ClusterInfo) GetState() string                       { return "ready" }
+func (i ClusterInfo) GetState() string                       { return i.State }
 func (i ClusterInfo) GetNodes() []deployment.ClusterNodeInfo { return i.Nodes }
@@ -8,12 +8,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
 	"github.com/couchbaselabs/cbdinocluster/utils/clustercontrol"
+	"github.com/couchbaselabs/cbdinocluster/utils/dinocerts"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
 	units "github.com/docker/go-units"
 	"github.com/google/uuid"
@@ -43,6 +50,12 @@ type ContainerInfo struct {
 	IPAddress            string
 	InitialServerVersion string
 	UsingDinoCerts       bool
+	DataVolume           string
+	State                string
+	Pool                 string
+	PoolClaimed          bool
+	DinoCerts            *DinoCertState
+	AddressTranslations  map[string]string
 }
 
 func (c *Controller) parseContainerInfo(container container.Summary) *ContainerInfo {
@@ -55,6 +68,7 @@ func (c *Controller) parseContainerInfo(container container.Summary) *ContainerI
 	purpose := container.Labels["com.couchbase.dyncluster.purpose"]
 	initialServerVersion := container.Labels["com.couchbase.dyncluster.initial_server_version"]
 	usingDinoCerts := container.Labels["com.couchbase.dyncluster.using_dino_certs"]
+	dataVolume := container.Labels["com.couchbase.dyncluster.data_volume"]
 
 	// If there is no cluster ID specified, this is not a cbdyncluster container
 	if clusterID == "" {
@@ -99,6 +113,8 @@ func (c *Controller) parseContainerInfo(container container.Summary) *ContainerI
 		IPAddress:            pickedNetwork.IPAddress,
 		InitialServerVersion: initialServerVersion,
 		UsingDinoCerts:       usingDinoCertsBool,
+		DataVolume:           dataVolume,
+		State:                container.State,
 	}
 }
 
@@ -122,6 +138,16 @@ func (c *Controller) ListNodes(ctx context.Context) ([]*ContainerInfo, error) {
 			nodeState, err := c.ReadNodeState(ctx, node.ContainerID)
 			if err == nil && nodeState != nil {
 				node.Expiry = nodeState.Expiry
+				node.Pool = nodeState.Pool
+				node.PoolClaimed = nodeState.PoolClaimed
+				node.DinoCerts = nodeState.DinoCerts
+				node.AddressTranslations = nodeState.AddressTranslations
+
+				// the purpose label cannot be changed once the container is
+				// created, so changes to it are recorded in the node state.
+				if nodeState.Purpose != "" {
+					node.Purpose = nodeState.Purpose
+				}
 			}
 
 			nodes = append(nodes, node)
@@ -132,18 +158,77 @@ func (c *Controller) ListNodes(ctx context.Context) ([]*ContainerInfo, error) {
 }
 
 type DockerNodeState struct {
-	Expiry time.Time
+	Expiry      time.Time
+	IPAddress   string
+	Purpose     string
+	Pool        string
+	PoolClaimed bool
+
+	// LoadBalancer holds the settings of an active load balancer node, which
+	// are needed whenever its config is rewritten.
+	LoadBalancer *clusterdef.LoadBalancerSettings
+
+	// DinoCerts tracks certificate rotations of a cluster using dino-certs.
+	DinoCerts *DinoCertState
+
+	// MemorySwap is the memory-swap limit the node was requested with, which
+	// cannot be read back from docker since it fills in a default when only
+	// memory is limited.
+	MemorySwap int64
+
+	// AddressTranslations maps the addresses of the cluster a node was
+	// restored from to the addresses of the restored nodes.  The translations
+	// are lost when the container is restarted, so they are reapplied on start.
+	AddressTranslations map[string]string
+}
+
+// DinoCertState records which certificates are currently installed on a
+// cluster, so that they can be regenerated deterministically.
+type DinoCertState struct {
+	// Rotation is incremented each time the certificates are rotated.
+	Rotation int
+
+	// CaGeneration identifies the intermediate CA of the cluster, where 0 is
+	// the CA the cluster was created with.
+	CaGeneration int
+
+	// PrevCaGeneration is the CA which was replaced by the last rotation, and
+	// remains trusted until PrevCaTrustedUntil.
+	PrevCaGeneration   int
+	PrevCaTrustedUntil time.Time
+
+	// Key is the spec of the node keys, which load balancers also use but
+	// without encryption.
+	Key dinocerts.KeySpec
 }
 
 type DockerNodeStateJson struct {
-	Expiry time.Time
+	Expiry       time.Time
+	IPAddress    string                           `json:",omitempty"`
+	Purpose      string                           `json:",omitempty"`
+	Pool         string                           `json:",omitempty"`
+	PoolClaimed  bool                             `json:",omitempty"`
+	LoadBalancer *clusterdef.LoadBalancerSettings `json:",omitempty"`
+	DinoCerts    *DinoCertState                   `json:",omitempty"`
+	MemorySwap   int64                            `json:",omitempty"`
+
+	AddressTranslations map[string]string `json:",omitempty"`
 }
 
 func (c *Controller) WriteNodeState(ctx context.Context, containerID string, state *DockerNodeState) error {
 	c.Logger.Debug("writing node state", zap.String("container", containerID), zap.Any("state", state))
 
 	jsonState := &DockerNodeStateJson{
-		Expiry: state.Expiry,
+		Expiry:       state.Expiry,
+		IPAddress:    state.IPAddress,
+		Purpose:      state.Purpose,
+		Pool:         state.Pool,
+		PoolClaimed:  state.PoolClaimed,
+		LoadBalancer: state.LoadBalancer,
+		DinoCerts:    state.DinoCerts,
+		MemorySwap:   state.MemorySwap,
+
+		AddressTranslations: state.AddressTranslations,
 	}
 
 	jsonBytes, err := json.Marshal(jsonState)
@@ -209,7 +294,16 @@ func (c *Controller) ReadNodeState(ctx context.Context, containerID string) (*Do
 	}
 
 	return &DockerNodeState{
-		Expiry: nodeStateJson.Expiry,
+		Expiry:       nodeStateJson.Expiry,
+		IPAddress:    nodeStateJson.IPAddress,
+		Purpose:      nodeStateJson.Purpose,
+		Pool:         nodeStateJson.Pool,
+		PoolClaimed:  nodeStateJson.PoolClaimed,
+		LoadBalancer: nodeStateJson.LoadBalancer,
+		DinoCerts:    nodeStateJson.DinoCerts,
+		MemorySwap:   nodeStateJson.MemorySwap,
+
+		AddressTranslations: nodeStateJson.AddressTranslations,
 	}, nil
 }
 
@@ -310,6 +404,7 @@ func (c *Controller) DeployS3MockNode(ctx context.Context, clusterID string, exp
 }
 
 type ProxyTargetNode struct {
+	NodeID                string
 	Address               string
 	IsEnterpriseAnalytics bool
 }
@@ -410,7 +505,12 @@ func (c *Controller) DeployNginxNode(ctx context.Context, clusterID string, expi
 	return node, nil
 }
 
-func (c *Controller) DeployHaproxyNode(ctx context.Context, clusterID string, expiry time.Duration) (*ContainerInfo, error) {
+func (c *Controller) DeployHaproxyNode(
+	ctx context.Context,
+	clusterID string,
+	expiry time.Duration,
+	settings *clusterdef.LoadBalancerSettings,
+) (*ContainerInfo, error) {
 	nodeID := "haproxy"
 	logger := c.Logger.With(zap.String("nodeId", nodeID))
 
@@ -485,7 +585,8 @@ func (c *Controller) DeployHaproxyNode(ctx context.Context, clusterID string, ex
 	}
 
 	err = c.WriteNodeState(ctx, containerID, &DockerNodeState{
-		Expiry: expiryTime,
+		Expiry:       expiryTime,
+		LoadBalancer: settings,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed write node state")
@@ -558,23 +659,71 @@ func (c *Controller) UpdateHaproxyConfig(
 	enableSsl,
 	isColumnar bool,
 ) error {
-	// this is configured to broadly match AWS Network Load Balancer defaults
-	maxRetrys := 0
-	connectTimeout := "350s"
-	clientTimeout := "350s"
-	serverTimeout := "350s"
-	checkConfig := "check inter 30s fall 2 rise 5"
+	settings := clusterdef.LoadBalancerSettings{}
+	state, err := c.ReadNodeState(ctx, containerID)
+	if err != nil {
+		return errors.Wrap(err, "failed to read load balancer settings")
+	}
+	if state != nil && state.LoadBalancer != nil {
+		settings = *state.LoadBalancer
+	}
 
 	c.Logger.Debug("writing haproxy config", zap.String("container", containerID), zap.Any("targets", targets))
 
+	haConf := buildHaproxyConfig(targets, enableSsl, isColumnar, &settings)
+
+	confBytes := []byte(haConf)
+
+	tarBuf := bytes.NewBuffer(nil)
+	tarFile := tar.NewWriter(tarBuf)
+	tarFile.WriteHeader(&tar.Header{
+		Name: "haproxy.cfg",
+		Size: int64(len(confBytes)),
+		Mode: 0666,
+	})
+	tarFile.Write(confBytes)
+	tarFile.Flush()
+
+	err = c.DockerCli.CopyToContainer(ctx, containerID, "/usr/local/etc/haproxy/", tarBuf, container.CopyToContainerOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to write haproxy config")
+	}
+
+	err = c.DockerCli.ContainerKill(ctx, containerID, "HUP")
+	if err != nil {
+		return errors.Wrap(err, "failed to reload haproxy config")
+	}
+
+	return nil
+}
+
+func buildHaproxyConfig(
+	targets []ProxyTargetNode,
+	enableSsl,
+	isColumnar bool,
+	settings *clusterdef.LoadBalancerSettings,
+) string {
+	settings = loadBalancerSettingsWithDefaults(settings)
+
+	checkConfig := fmt.Sprintf("check inter %s fall %d rise %d",
+		haproxyDuration(settings.HealthCheckInterval),
+		settings.HealthCheckFall,
+		settings.HealthCheckRise)
+
 	var haConf string
 
+	// the runtime api is used to change the state of servers without
+	// rewriting the config, see haproxyruntime.go
+	haConf += "global\n"
+	haConf += fmt.Sprintf("  stats socket ipv4@:%d level admin\n", haproxyRuntimePort)
+	haConf += "\n"
+
 	haConf += "defaults\n"
 	haConf += "  mode http\n"
-	haConf += fmt.Sprintf("  retries %d\n", maxRetrys)
-	haConf += fmt.Sprintf("  timeout connect %s\n", connectTimeout)
-	haConf += fmt.Sprintf("  timeout client %s\n", clientTimeout)
-	haConf += fmt.Sprintf("  timeout server %s\n", serverTimeout)
+	haConf += fmt.Sprintf("  retries %d\n", settings.Retries)
+	haConf += fmt.Sprintf("  timeout connect %s\n", haproxyDuration(settings.ConnectTimeout))
+	haConf += fmt.Sprintf("  timeout client %s\n", haproxyDuration(settings.ClientTimeout))
+	haConf += fmt.Sprintf("  timeout server %s\n", haproxyDuration(settings.ServerTimeout))
 	haConf += "\n"
 
 	haConf += "backend static_backend\n"
@@ -612,18 +761,20 @@ func (c *Controller) UpdateHaproxyConfig(
 		haConf += "\n"
 		haConf += fmt.Sprintf("backend backend%d\n", port)
 		if !stickySession {
-			haConf += "  balance roundrobin\n"
+			haConf += fmt.Sprintf("  balance %s\n", settings.Balance)
 		} else {
 			haConf += "  balance source\n"
 		}
 		haConf += fmt.Sprintf("  option httpchk GET %s\n", healthPath)
-		for i, target := range targets {
+		// servers are named after their nodes, so that the haproxy stats and
+		// logs identify which node each server is
+		for _, target := range targets {
 			sslConfig := ""
 			if withSsl {
 				sslConfig = "ssl verify none"
 			}
 
-			haConf += fmt.Sprintf("  server node%d %s:%d %s %s\n", i, target.Address, port, sslConfig, checkConfig)
+			haConf += fmt.Sprintf("  server %s %s:%d %s %s\n", target.NodeID, target.Address, port, sslConfig, checkConfig)
 		}
 		haConf += "\n"
 	}
@@ -669,29 +820,7 @@ func (c *Controller) UpdateHaproxyConfig(
 		haConf += "\n"
 	}
 
-	confBytes := []byte(haConf)
-
-	tarBuf := bytes.NewBuffer(nil)
-	tarFile := tar.NewWriter(tarBuf)
-	tarFile.WriteHeader(&tar.Header{
-		Name: "haproxy.cfg",
-		Size: int64(len(confBytes)),
-		Mode: 0666,
-	})
-	tarFile.Write(confBytes)
-	tarFile.Flush()
-
-	err := c.DockerCli.CopyToContainer(ctx, containerID, "/usr/local/etc/haproxy/", tarBuf, container.CopyToContainerOptions{})
-	if err != nil {
-		return errors.Wrap(err, "failed to write nginx config")
-	}
-
-	err = c.DockerCli.ContainerKill(ctx, containerID, "HUP")
-	if err != nil {
-		return errors.Wrap(err, "failed to reload haproxy config")
-	}
-
-	return nil
+	return haConf
 }
 
 func (c *Controller) UpdateNginxCertificates(ctx context.Context, containerID string, certPem []byte, keyPem []byte) error {
@@ -917,10 +1046,33 @@ type DeployNodeOptions struct {
 	DnsSuffix          string
 	EnvVars            map[string]string
 	UseDinoCerts       bool
+	Resources          NodeResources
+	Persistent         bool
+
+	// DnsServers are the resolvers used by the node in place of the ones of
+	// the docker host.
+	DnsServers []string
+
+	// NodeID, DataVolume and IPAddress allow a node to be recreated with the
+	// identity and data of a previously removed node container.
+	NodeID     string
+	DataVolume string
+	IPAddress  string
+
+	// DataArchivePath is the path to a tar archive of a node data directory
+	// which is copied into the container before it is started.
+	DataArchivePath string
+
+	// AddressTranslations are applied to the node once it is started, which
+	// nodes joining a restored cluster need to reach the restored nodes.
+	AddressTranslations map[string]string
 }
 
 func (c *Controller) DeployNode(ctx context.Context, def *DeployNodeOptions) (*ContainerInfo, error) {
-	nodeID := uuid.NewString()
+	nodeID := def.NodeID
+	if nodeID == "" {
+		nodeID = uuid.NewString()
+	}
 	logger := c.Logger.With(zap.String("nodeId", nodeID))
 
 	logger.Debug("deploying node", zap.Any("def", def))
@@ -947,6 +1099,55 @@ func (c *Controller) DeployNode(ctx context.Context, def *DeployNodeOptions) (*C
 		usingDinoCerts = "true"
 	}
 
+	resources := container.Resources{
+		Ulimits: []*units.Ulimit{
+			{Name: "nofile", Soft: 200000, Hard: 200000},
+		},
+	}
+	def.Resources.ApplyTo(&resources)
+
+	// persistent nodes keep their data directory in a named volume and must not
+	// be auto-removed, otherwise stopping the container would destroy the node.
+	dataVolume := def.DataVolume
+	if def.Persistent && dataVolume == "" {
+		dataVolume = "cbdynnode-data-" + nodeID
+
+		logger.Debug("creating data volume", zap.String("volume", dataVolume))
+
+		_, err := c.DockerCli.VolumeCreate(ctx, volume.CreateOptions{
+			Name: dataVolume,
+			Labels: map[string]string{
+				"com.couchbase.dyncluster.cluster_id": def.ClusterID,
+				"com.couchbase.dyncluster.node_id":    nodeID,
+			},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create data volume")
+		}
+	}
+
+	var mounts []mount.Mount
+	if dataVolume != "" {
+		mounts = append(mounts, mount.Mount{
+			Type:   mount.TypeVolume,
+			Source: dataVolume,
+			Target: "/opt/couchbase/var",
+		})
+	}
+
+	var networkingConfig *network.NetworkingConfig
+	if def.IPAddress != "" {
+		networkingConfig = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				c.NetworkName: {
+					IPAMConfig: &network.EndpointIPAMConfig{
+						IPv4Address: def.IPAddress,
+					},
+				},
+			},
+		}
+	}
+
 	createResult, err := c.DockerCli.ContainerCreate(context.Background(), &container.Config{
 		Image: def.Image.ImagePath,
 		Labels: map[string]string{
@@ -957,26 +1158,34 @@ func (c *Controller) DeployNode(ctx context.Context, def *DeployNodeOptions) (*C
 			"com.couchbase.dyncluster.node_id":                nodeID,
 			"com.couchbase.dyncluster.initial_server_version": def.ImageServerVersion,
 			"com.couchbase.dyncluster.using_dino_certs":       usingDinoCerts,
+			"com.couchbase.dyncluster.data_volume":            dataVolume,
 		},
 		// same effect as ntp
 		Volumes: map[string]struct{}{"/etc/localtime:/etc/localtime": {}},
 		Env:     envVars,
 	}, &container.HostConfig{
-		AutoRemove:  true,
+		AutoRemove:  dataVolume == "",
 		NetworkMode: container.NetworkMode(c.NetworkName),
 		CapAdd:      []string{"NET_ADMIN"},
-		Resources: container.Resources{
-			Ulimits: []*units.Ulimit{
-				{Name: "nofile", Soft: 200000, Hard: 200000},
-			},
-		},
-	}, nil, nil, containerName)
+		Resources:   resources,
+		Mounts:      mounts,
+		DNS:         def.DnsServers,
+	}, networkingConfig, nil, containerName)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create container")
 	}
 
 	containerID := createResult.ID
 
+	if def.DataArchivePath != "" {
+		logger.Debug("importing node data", zap.String("archive", def.DataArchivePath))
+
+		err := c.ImportNodeData(ctx, containerID, def.DataArchivePath)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to import node data")
+		}
+	}
+
 	logger.Debug("container created, starting", zap.String("container", containerID))
 
 	err = c.DockerCli.ContainerStart(context.Background(), containerID, container.StartOptions{})
@@ -990,12 +1199,19 @@ func (c *Controller) DeployNode(ctx context.Context, def *DeployNodeOptions) (*C
 	}
 
 	err = c.WriteNodeState(ctx, containerID, &DockerNodeState{
-		Expiry: expiryTime,
+		Expiry:              expiryTime,
+		MemorySwap:          def.Resources.MemorySwap,
+		AddressTranslations: def.AddressTranslations,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed write node state")
 	}
 
+	err = c.applyAddressTranslations(ctx, containerID, def.AddressTranslations)
+	if err != nil {
+		return nil, err
+	}
+
 	// Cheap hack for simpler parsing...
 	allNodes, err := c.ListNodes(ctx)
 	if err != nil {
@@ -1029,7 +1245,380 @@ func (c *Controller) DeployNode(ctx context.Context, def *DeployNodeOptions) (*C
 	return node, nil
 }
 
+func (c *Controller) GetNodeResources(ctx context.Context, containerID string) (NodeResources, error) {
+	inspect, err := c.DockerCli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return NodeResources{}, errors.Wrap(err, "failed to inspect container")
+	}
+
+	nodeState, err := c.ReadNodeState(ctx, containerID)
+	if err != nil {
+		return NodeResources{}, errors.Wrap(err, "failed to read node state")
+	}
+
+	var memorySwap int64
+	if nodeState != nil {
+		memorySwap = nodeState.MemorySwap
+	}
+
+	return NodeResourcesFromHostConfig(inspect.HostConfig, memorySwap), nil
+}
+
+// GetNodeEnvVars returns the environment variables a node container was
+// deployed with, leaving out the ones which come from its image.
+func (c *Controller) GetNodeEnvVars(ctx context.Context, containerID string) (map[string]string, error) {
+	inspect, err := c.DockerCli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to inspect container")
+	}
+
+	imageInspect, err := c.DockerCli.ImageInspect(ctx, inspect.Image)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to inspect container image")
+	}
+
+	var imageEnv []string
+	if imageInspect.Config != nil {
+		imageEnv = imageInspect.Config.Env
+	}
+
+	return nodeEnvVars(inspect.Config.Env, imageEnv), nil
+}
+
+func nodeEnvVars(containerEnv []string, imageEnv []string) map[string]string {
+	envVars := make(map[string]string)
+	for _, envVar := range containerEnv {
+		if slices.Contains(imageEnv, envVar) {
+			continue
+		}
+
+		varName, varValue, _ := strings.Cut(envVar, "=")
+		envVars[varName] = varValue
+	}
+	return envVars
+}
+
+// IsAddressInUse checks whether an address of the node network is assigned
+// to any container.
+func (c *Controller) IsAddressInUse(ctx context.Context, ipAddress string) (bool, error) {
+	netInfo, err := c.DockerCli.NetworkInspect(ctx, c.NetworkName, network.InspectOptions{})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to inspect network")
+	}
+
+	for _, endpoint := range netInfo.Containers {
+		endpointIP, _, _ := strings.Cut(endpoint.IPv4Address, "/")
+		if endpointIP == ipAddress {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (c *Controller) UpdateNodeResources(ctx context.Context, containerID string, res NodeResources) error {
+	logger := c.Logger.With(zap.String("container", containerID))
+
+	logger.Debug("updating node resources", zap.Any("resources", res))
+
+	var updateConfig container.UpdateConfig
+	res.ApplyTo(&updateConfig.Resources)
+
+	// docker treats a nil pids limit as unchanged, so we explicitly
+	// request an unlimited value when the limit is being removed.
+	if updateConfig.PidsLimit == nil {
+		updateConfig.PidsLimit = ptr.To[int64](-1)
+	}
+
+	// docker rejects memory updates that conflict with the existing swap
+	// limit, so we mirror the default docker applies when creating one.
+	if updateConfig.Memory > 0 && updateConfig.MemorySwap == 0 {
+		updateConfig.MemorySwap = updateConfig.Memory * 2
+	}
+
+	_, err := c.DockerCli.ContainerUpdate(ctx, containerID, updateConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to update container")
+	}
+
+	state, err := c.ReadNodeState(ctx, containerID)
+	if err != nil {
+		return errors.Wrap(err, "failed read existing node state")
+	}
+	if state == nil {
+		state = &DockerNodeState{}
+	}
+
+	state.MemorySwap = res.MemorySwap
+
+	err = c.WriteNodeState(ctx, containerID, state)
+	if err != nil {
+		return errors.Wrap(err, "failed write updated node state")
+	}
+
+	return nil
+}
+
+func (c *Controller) StopNode(ctx context.Context, containerID string) error {
+	logger := c.Logger.With(zap.String("container", containerID))
+	logger.Debug("stopping node")
+
+	inspect, err := c.DockerCli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return errors.Wrap(err, "failed to inspect container")
+	}
+
+	// we record the address the node was using so that we can restore it when
+	// the node is started again, since couchbase identifies nodes by address.
+	nodeState, err := c.ReadNodeState(ctx, containerID)
+	if err != nil {
+		return errors.Wrap(err, "failed to read node state")
+	}
+	if nodeState == nil {
+		nodeState = &DockerNodeState{}
+	}
+
+	if endpoint, ok := inspect.NetworkSettings.Networks[c.NetworkName]; ok && endpoint.IPAddress != "" {
+		nodeState.IPAddress = endpoint.IPAddress
+
+		err = c.WriteNodeState(ctx, containerID, nodeState)
+		if err != nil {
+			return errors.Wrap(err, "failed to write node state")
+		}
+	}
+
+	logger.Debug("stopping container")
+
+	// couchbase needs some time to flush its data to disk on shutdown
+	err = c.DockerCli.ContainerStop(ctx, containerID, container.StopOptions{
+		Timeout: ptr.To(120),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to stop container")
+	}
+
+	logger.Debug("node has been stopped!")
+
+	return nil
+}
+
+func (c *Controller) StartNode(ctx context.Context, containerID string) error {
+	logger := c.Logger.With(zap.String("container", containerID))
+	logger.Debug("starting node")
+
+	nodeState, err := c.ReadNodeState(ctx, containerID)
+	if err != nil {
+		return errors.Wrap(err, "failed to read node state")
+	}
+
+	if nodeState != nil && nodeState.IPAddress != "" {
+		logger.Debug("restoring node address", zap.String("address", nodeState.IPAddress))
+
+		err := c.DockerCli.NetworkDisconnect(ctx, c.NetworkName, containerID, true)
+		if err != nil {
+			return errors.Wrap(err, "failed to disconnect container from network")
+		}
+
+		err = c.DockerCli.NetworkConnect(ctx, c.NetworkName, containerID, &network.EndpointSettings{
+			IPAMConfig: &network.EndpointIPAMConfig{
+				IPv4Address: nodeState.IPAddress,
+			},
+		})
+		if err != nil {
+			// networks without a user-configured subnet do not permit static
+			// addresses, in which case the node will receive a new address.
+			logger.Warn("failed to restore node address, node address may change",
+				zap.Error(err))
+
+			err = c.DockerCli.NetworkConnect(ctx, c.NetworkName, containerID, nil)
+			if err != nil {
+				return errors.Wrap(err, "failed to reconnect container to network")
+			}
+		}
+	}
+
+	err = c.DockerCli.ContainerStart(ctx, containerID, container.StartOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to start container")
+	}
+
+	if nodeState != nil {
+		err := c.applyAddressTranslations(ctx, containerID, nodeState.AddressTranslations)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Debug("node has been started!")
+
+	return nil
+}
+
+// StopCouchbase gracefully stops the couchbase server process within a node
+// container, leaving the container itself running.  Couchbase flushes all
+// of its data to disk as part of a graceful shutdown.
+func (c *Controller) StopCouchbase(ctx context.Context, containerID string) error {
+	err := c.execCmd(ctx, containerID, []string{"sv", "-w", "300", "stop", "couchbase-server"})
+	if err != nil {
+		return errors.Wrap(err, "failed to stop couchbase-server")
+	}
+
+	return nil
+}
+
+// StartCouchbase starts a couchbase server process previously stopped with
+// StopCouchbase.
+func (c *Controller) StartCouchbase(ctx context.Context, containerID string) error {
+	err := c.execCmd(ctx, containerID, []string{"sv", "start", "couchbase-server"})
+	if err != nil {
+		return errors.Wrap(err, "failed to start couchbase-server")
+	}
+
+	return nil
+}
+
+// ExportNodeData writes a tar archive of the node data directory to w.
+func (c *Controller) ExportNodeData(ctx context.Context, containerID string, w io.Writer) error {
+	resp, _, err := c.DockerCli.CopyFromContainer(ctx, containerID, "/opt/couchbase/var")
+	if err != nil {
+		return errors.Wrap(err, "failed to read node data")
+	}
+	defer resp.Close()
+
+	_, err = io.Copy(w, resp)
+	if err != nil {
+		return errors.Wrap(err, "failed to write node data")
+	}
+
+	return nil
+}
+
+// ImportNodeData copies an archive written by ExportNodeData into a node.
+func (c *Controller) ImportNodeData(ctx context.Context, containerID string, archivePath string) error {
+	archiveFile, err := os.Open(archivePath)
+	if err != nil {
+		return errors.Wrap(err, "failed to open node data archive")
+	}
+	defer archiveFile.Close()
+
+	err = c.DockerCli.CopyToContainer(ctx, containerID, "/opt/couchbase/", archiveFile, container.CopyToContainerOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to write node data")
+	}
+
+	return nil
+}
+
+// TranslateAddresses rewrites any traffic originating in the container which
+// is destined for one of the keys of addrs to the corresponding value.  The
+// translations are recorded in the node state so they survive restarts.
+func (c *Controller) TranslateAddresses(ctx context.Context, containerID string, addrs map[string]string) error {
+	err := c.applyAddressTranslations(ctx, containerID, addrs)
+	if err != nil {
+		return err
+	}
+
+	state, err := c.ReadNodeState(ctx, containerID)
+	if err != nil {
+		return errors.Wrap(err, "failed read existing node state")
+	}
+	if state == nil {
+		state = &DockerNodeState{}
+	}
+
+	state.AddressTranslations = addrs
+
+	err = c.WriteNodeState(ctx, containerID, state)
+	if err != nil {
+		return errors.Wrap(err, "failed write updated node state")
+	}
+
+	return nil
+}
+
+func (c *Controller) applyAddressTranslations(ctx context.Context, containerID string, addrs map[string]string) error {
+	for fromAddr, toAddr := range addrs {
+		err := c.execIptables(ctx, containerID, []string{
+			"-t", "nat", "-A", "OUTPUT", "-d", fromAddr, "-j", "DNAT", "--to-destination", toAddr,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to add address translation")
+		}
+	}
+
+	return nil
+}
+
+type VolumeInfo struct {
+	Name      string
+	ClusterID string
+	NodeID    string
+	CreatedAt time.Time
+}
+
+func (c *Controller) ListVolumes(ctx context.Context) ([]*VolumeInfo, error) {
+	c.Logger.Debug("listing volumes")
+
+	resp, err := c.DockerCli.VolumeList(ctx, volume.ListOptions{
+		Filters: filters.NewArgs(filters.Arg("label", "com.couchbase.dyncluster.cluster_id")),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list volumes")
+	}
+
+	var volumes []*VolumeInfo
+	for _, vol := range resp.Volumes {
+		// the creation time is informational, so volumes of daemons which do
+		// not report it are treated as having been created long ago
+		createdAt, _ := time.Parse(time.RFC3339, vol.CreatedAt)
+
+		volumes = append(volumes, &VolumeInfo{
+			Name:      vol.Name,
+			ClusterID: vol.Labels["com.couchbase.dyncluster.cluster_id"],
+			NodeID:    vol.Labels["com.couchbase.dyncluster.node_id"],
+			CreatedAt: createdAt,
+		})
+	}
+
+	return volumes, nil
+}
+
+func (c *Controller) RemoveVolume(ctx context.Context, volumeName string) error {
+	c.Logger.Debug("removing volume", zap.String("volume", volumeName))
+
+	err := c.DockerCli.VolumeRemove(ctx, volumeName, true)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove volume")
+	}
+
+	return nil
+}
+
 func (c *Controller) RemoveNode(ctx context.Context, containerID string) error {
+	dataVolume := ""
+	inspect, err := c.DockerCli.ContainerInspect(ctx, containerID)
+	if err == nil && inspect.Config != nil {
+		dataVolume = inspect.Config.Labels["com.couchbase.dyncluster.data_volume"]
+	}
+
+	err = c.RemoveNodeContainer(ctx, containerID)
+	if err != nil {
+		return err
+	}
+
+	if dataVolume != "" {
+		err := c.RemoveVolume(ctx, dataVolume)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemoveNodeContainer removes the container for a node, but leaves its data
+// volume in place so that it can be reused by a new container.
+func (c *Controller) RemoveNodeContainer(ctx context.Context, containerID string) error {
 	logger := c.Logger.With(zap.String("container", containerID))
 	logger.Debug("removing node")
 
@@ -1100,6 +1689,81 @@ func (c *Controller) UpdateExpiry(ctx context.Context, containerID string, newEx
 	return nil
 }
 
+type UpdatePoolStateOptions struct {
+	Pool    string
+	Claimed bool
+	Purpose string
+	Expiry  time.Time
+}
+
+func (c *Controller) UpdatePoolState(ctx context.Context, containerID string, opts *UpdatePoolStateOptions) error {
+	state, err := c.ReadNodeState(ctx, containerID)
+	if err != nil {
+		return errors.Wrap(err, "failed read existing node state")
+	}
+	if state == nil {
+		state = &DockerNodeState{}
+	}
+
+	state.Pool = opts.Pool
+	state.PoolClaimed = opts.Claimed
+	state.Purpose = opts.Purpose
+	state.Expiry = opts.Expiry
+
+	err = c.WriteNodeState(ctx, containerID, state)
+	if err != nil {
+		return errors.Wrap(err, "failed write updated node state")
+	}
+
+	return nil
+}
+
+func (c *Controller) UpdateDinoCertState(ctx context.Context, containerID string, dinoCerts *DinoCertState) error {
+	state, err := c.ReadNodeState(ctx, containerID)
+	if err != nil {
+		return errors.Wrap(err, "failed read existing node state")
+	}
+	if state == nil {
+		state = &DockerNodeState{}
+	}
+
+	state.DinoCerts = dinoCerts
+
+	err = c.WriteNodeState(ctx, containerID, state)
+	if err != nil {
+		return errors.Wrap(err, "failed write updated node state")
+	}
+
+	return nil
+}
+
+const poolClaimPath = "/var/cbdyncluster-claim"
+
+// TryClaimNode atomically marks a node as claimed, returning false if it was
+// already claimed.  This allows several processes to claim from the same pool
+// without handing out the same cluster twice.
+func (c *Controller) TryClaimNode(ctx context.Context, containerID string) (bool, error) {
+	// mkdir fails if the directory already exists, which makes it atomic
+	lines, err := c.execCmdOutput(ctx, containerID, []string{"sh", "-c",
+		"mkdir " + poolClaimPath + " 2>/dev/null && echo claimed || echo taken"})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create claim marker")
+	}
+
+	return slices.ContainsFunc(lines, func(line string) bool {
+		return strings.TrimSpace(line) == "claimed"
+	}), nil
+}
+
+func (c *Controller) ReleaseNodeClaim(ctx context.Context, containerID string) error {
+	err := c.execCmd(ctx, containerID, []string{"rm", "-rf", poolClaimPath})
+	if err != nil {
+		return errors.Wrap(err, "failed to remove claim marker")
+	}
+
+	return nil
+}
+
 func (c *Controller) execCmd(ctx context.Context, containerID string, cmd []string) error {
 	c.Logger.Debug("executing cmd",
 		zap.String("containerID", containerID),
@@ -1134,6 +1798,45 @@ func (c *Controller) execIptables(ctx context.Context, containerID string, args
 	return nil
 }
 
+// getNetworkAddresses returns the gateway address of the node network, which
+// is the source of all client traffic, along with the range of node addresses.
+func (c *Controller) getNetworkAddresses(ctx context.Context) (string, string, error) {
+	netInfo, err := c.DockerCli.NetworkInspect(ctx, c.NetworkName, network.InspectOptions{})
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to inspect network")
+	}
+
+	if len(netInfo.IPAM.Config) < 1 {
+		return "", "", errors.New("more than one ipam config, cannot identify node subnet")
+	}
+	ipamConfig := netInfo.IPAM.Config[0]
+
+	gatewayIP := ipamConfig.Gateway
+	ipRange := ipamConfig.Subnet
+	if ipamConfig.IPRange != "" {
+		ipRange = ipamConfig.IPRange
+	}
+
+	if ipRange == "" || gatewayIP == "" {
+		return "", "", errors.New("failed to identify subnet or gateway ip")
+	}
+
+	return gatewayIP, ipRange, nil
+}
+
+// TrafficPartitionRule blocks traffic between a node and a peer.  The
+// one-way rules apply to connections rather than packets, so that blocking
+// inbound traffic still permits the node to reach the peer and get a reply.
+type TrafficPartitionRule struct {
+	IPAddress string
+
+	// BlockInbound prevents the peer from reaching the node.
+	BlockInbound bool
+
+	// BlockOutbound prevents the node from reaching the peer.
+	BlockOutbound bool
+}
+
 type TrafficControlType string
 
 const (
@@ -1150,12 +1853,13 @@ func (c *Controller) SetTrafficControl(
 	blockType string,
 	extraBlocked []string,
 	extraAllowed []string,
+	partitionRules []*TrafficPartitionRule,
 ) error {
 	logger := c.Logger.With(zap.String("container", containerID))
 	logger.Debug("setting up traffic control",
 		zap.String("blockType", string(tcType)))
 
-	if tcType == TrafficControlAllowAll && len(extraBlocked) == 0 {
+	if tcType == TrafficControlAllowAll && len(extraBlocked) == 0 && len(partitionRules) == 0 {
 		// if there are no extra blocked ips, and we are just allowing all traffic,
 		// we can skip iptables setup when its not installed.
 		err := c.execCmd(ctx, containerID, []string{"iptables", "-F"})
@@ -1167,24 +1871,9 @@ func (c *Controller) SetTrafficControl(
 		return nil
 	}
 
-	netInfo, err := c.DockerCli.NetworkInspect(ctx, c.NetworkName, network.InspectOptions{})
+	gatewayIP, ipRange, err := c.getNetworkAddresses(ctx)
 	if err != nil {
-		return errors.Wrap(err, "failed to inspect network")
-	}
-
-	if len(netInfo.IPAM.Config) < 1 {
-		return errors.New("more than one ipam config, cannot identify node subnet")
-	}
-	ipamConfig := netInfo.IPAM.Config[0]
-
-	gatewayIP := ipamConfig.Gateway
-	ipRange := ipamConfig.Subnet
-	if ipamConfig.IPRange != "" {
-		ipRange = ipamConfig.IPRange
-	}
-
-	if ipRange == "" || gatewayIP == "" {
-		return errors.New("failed to identify subnet or gateway ip")
+		return err
 	}
 
 	err = c.execIptables(ctx, containerID, []string{"-F"})
@@ -1198,32 +1887,50 @@ func (c *Controller) SetTrafficControl(
 	iptableAllow := func(table string, cidr string) error {
 		return c.execIptables(ctx, containerID, []string{"-A", table, "-s", cidr, "-j", "ACCEPT"})
 	}
-	iptableBlockAll := func(table string) error {
+	iptableBlockMatching := func(table string, match []string) error {
+		rule := append([]string{"-A", table}, match...)
 		if blockType == "" {
-			return c.execIptables(ctx, containerID, []string{"-A", table, "-j", "DROP"})
+			return c.execIptables(ctx, containerID, append(rule, "-j", "DROP"))
 		} else if blockType == "tcp-reset" {
-			err := c.execIptables(ctx, containerID, []string{"-A", table, "-p", "tcp", "-j", "REJECT", "--reject-with", "tcp-reset"})
+			err := c.execIptables(ctx, containerID, append(slices.Clone(rule), "-p", "tcp", "-j", "REJECT", "--reject-with", "tcp-reset"))
 			if err != nil {
 				return err
 			}
 
-			return c.execIptables(ctx, containerID, []string{"-A", table, "-j", "REJECT"})
+			return c.execIptables(ctx, containerID, append(rule, "-j", "REJECT"))
 		} else {
-			return c.execIptables(ctx, containerID, []string{"-A", table, "-j", "REJECT", "--reject-with", blockType})
+			return c.execIptables(ctx, containerID, append(rule, "-j", "REJECT", "--reject-with", blockType))
 		}
 	}
+	iptableBlockAll := func(table string) error {
+		return iptableBlockMatching(table, nil)
+	}
 	iptableBlock := func(table string, cidr string) error {
-		if blockType == "" {
-			return c.execIptables(ctx, containerID, []string{"-A", table, "-s", cidr, "-j", "DROP"})
-		} else if blockType == "tcp-reset" {
-			err := c.execIptables(ctx, containerID, []string{"-A", table, "-s", cidr, "-p", "tcp", "-j", "REJECT", "--reject-with", "tcp-reset"})
+		return iptableBlockMatching(table, []string{"-s", cidr})
+	}
+
+	// add partition rules, these take precedence over everything else
+	for _, rule := range partitionRules {
+		if rule.BlockInbound && rule.BlockOutbound {
+			err = iptableBlockMatching("INPUT", []string{"-s", rule.IPAddress})
 			if err != nil {
-				return err
+				return errors.Wrapf(err, "failed to create INPUT iptables rule to partition %s", rule.IPAddress)
 			}
 
-			return c.execIptables(ctx, containerID, []string{"-A", table, "-s", cidr, "-j", "REJECT"})
-		} else {
-			return c.execIptables(ctx, containerID, []string{"-A", table, "-s", cidr, "-j", "REJECT", "--reject-with", blockType})
+			err = iptableBlockMatching("OUTPUT", []string{"-d", rule.IPAddress})
+			if err != nil {
+				return errors.Wrapf(err, "failed to create OUTPUT iptables rule to partition %s", rule.IPAddress)
+			}
+		} else if rule.BlockInbound {
+			err = iptableBlockMatching("INPUT", []string{"-s", rule.IPAddress, "-m", "conntrack", "--ctdir", "ORIGINAL"})
+			if err != nil {
+				return errors.Wrapf(err, "failed to create INPUT iptables rule to partition %s", rule.IPAddress)
+			}
+		} else if rule.BlockOutbound {
+			err = iptableBlockMatching("OUTPUT", []string{"-d", rule.IPAddress, "-m", "conntrack", "--ctdir", "ORIGINAL"})
+			if err != nil {
+				return errors.Wrapf(err, "failed to create OUTPUT iptables rule to partition %s", rule.IPAddress)
+			}
 		}
 	}
 
@@ -1323,3 +2030,49 @@ func (c *Controller) SetTrafficControl(
 
 	return nil
 }
+
+func (c *Controller) execCmdOutput(ctx context.Context, containerID string, cmd []string) ([]string, error) {
+	c.Logger.Debug("executing cmd",
+		zap.String("containerID", containerID),
+		zap.Strings("cmd", cmd))
+
+	return dockerExecAndCapture(ctx, c.Logger, c.DockerCli, containerID, cmd)
+}
+
+// GetTrafficRules returns the iptables rules and the tc netem qdiscs which are
+// currently applied to a node by SetTrafficControl and SetTrafficDegrade.
+func (c *Controller) GetTrafficRules(ctx context.Context, containerID string) ([]string, error) {
+	var rules []string
+
+	// iptables or tc not being installed means no rules were ever applied
+	iptablesRules, err := c.execCmdOutput(ctx, containerID, []string{"iptables", "-S"})
+	if err != nil {
+		c.Logger.Debug("failed to list iptables rules", zap.Error(err))
+	}
+	for _, rule := range iptablesRules {
+		rule = strings.TrimSpace(rule)
+		if rule == "" || strings.HasPrefix(rule, "-P ") {
+			// default policies are not rules we apply
+			continue
+		}
+
+		rules = append(rules, "iptables "+rule)
+	}
+
+	for _, device := range []string{degradeDevice, degradeIfbDevice} {
+		qdiscs, err := c.execCmdOutput(ctx, containerID, []string{"tc", "qdisc", "show", "dev", device})
+		if err != nil {
+			c.Logger.Debug("failed to list tc qdiscs", zap.Error(err))
+		}
+		for _, qdisc := range qdiscs {
+			qdisc = strings.TrimSpace(qdisc)
+			if !strings.Contains(qdisc, "netem") {
+				continue
+			}
+
+			rules = append(rules, fmt.Sprintf("tc %s: %s", device, qdisc))
+		}
+	}
+
+	return rules, nil
+}
@@ -50,6 +50,7 @@ type Deployer struct {
 	imageProvider ImageProvider
 	controller    *Controller
 	dnsProvider   DnsProvider
+	oidcIssuer    *LocalOidcIssuer
 }
 
 var _ deployment.Deployer = (*Deployer)(nil)
@@ -79,9 +80,18 @@ func NewDeployer(opts *DeployerOptions) (*Deployer, error) {
 			NetworkName: opts.NetworkName,
 		},
 		dnsProvider: opts.DnsProvider,
+		oidcIssuer: NewLocalOidcIssuer(&LocalOidcIssuerOptions{
+			Logger:      opts.Logger,
+			DockerCli:   opts.DockerCli,
+			NetworkName: opts.NetworkName,
+		}),
 	}, nil
 }
 
+func (d *Deployer) OidcIssuer() *LocalOidcIssuer {
+	return d.oidcIssuer
+}
+
 func (d *Deployer) ListClusters(ctx context.Context) ([]deployment.ClusterInfo, error) {
 	clusters, err := d.listClusters(ctx)
 	if err != nil {
@@ -118,11 +128,22 @@ func (d *Deployer) GetDefinition(ctx context.Context, clusterID string) (*cluste
 	var nodeGroups []*clusterdef.NodeGroup
 
 	for _, node := range clusterInfoEx.NodesEx {
-		nodeGroups = append(nodeGroups, &clusterdef.NodeGroup{
+		nodeGrp := &clusterdef.NodeGroup{
 			Count:    1,
 			Version:  node.InitialServerVersion,
 			Services: node.Services,
-		})
+		}
+
+		if node.IsClusterNode() {
+			resources, err := d.controller.GetNodeResources(ctx, node.ContainerID)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to get node resources")
+			}
+
+			nodeGrp.Docker = resources.ToDef()
+		}
+
+		nodeGroups = append(nodeGroups, nodeGrp)
 	}
 
 	return &clusterdef.Cluster{
@@ -200,17 +221,47 @@ func (d *Deployer) ModifyCluster(ctx context.Context, clusterID string, def *clu
 			return !node.IsClusterNode()
 		})
 
+		nodeResources := make(map[string]NodeResources)
+		for _, node := range nodesToRemove {
+			resources, err := d.controller.GetNodeResources(ctx, node.ContainerID)
+			if err != nil {
+				return errors.Wrap(err, "failed to get node resources")
+			}
+
+			nodeResources[node.ContainerID] = resources
+		}
+
+		nodeGrpResources := make(map[*clusterdef.NodeGroup]NodeResources)
+		for _, nodeGrp := range nodesToAdd {
+			resources, err := ParseNodeResources(nodeGrp.Docker)
+			if err != nil {
+				return errors.Wrap(err, "invalid node resources")
+			}
+
+			nodeGrpResources[nodeGrp] = resources
+		}
+
+		// nodes which are reused but need their resource limits changed
+		nodesToUpdate := make(map[string]NodeResources)
+
 		// first iterate and find any exact matches and use those
 		nodesToAdd = slices.DeleteFunc(nodesToAdd, func(nodeGrp *clusterdef.NodeGroup) bool {
 			if nodeGrp.ForceNew {
 				return false
 			}
 
+			wantResources := nodeGrpResources[nodeGrp]
+
 			for nodeIdx, node := range nodesToRemove {
 				if node.InitialServerVersion != nodeGrp.Version {
 					continue
 				}
 
+				haveResources := nodeResources[node.ContainerID]
+				if !haveResources.CanUpdateTo(wantResources) {
+					continue
+				}
+
 				nodeGrpServices := nodeGrp.Services
 				if len(nodeGrpServices) == 0 {
 					nodeGrpServices = DEFAULT_SERVICES
@@ -221,6 +272,10 @@ func (d *Deployer) ModifyCluster(ctx context.Context, clusterID string, def *clu
 					continue
 				}
 
+				if haveResources != wantResources {
+					nodesToUpdate[node.ContainerID] = wantResources
+				}
+
 				nodesToRemove = slices.Delete(nodesToRemove, nodeIdx, nodeIdx+1)
 				return true
 			}
@@ -232,6 +287,18 @@ func (d *Deployer) ModifyCluster(ctx context.Context, clusterID string, def *clu
 			zap.Any("nodes", nodesToAdd))
 		d.logger.Debug("identified nodes to remove",
 			zap.Any("nodes", nodesToRemove))
+		d.logger.Debug("identified nodes to update",
+			zap.Any("nodes", nodesToUpdate))
+
+		for containerID, resources := range nodesToUpdate {
+			d.logger.Info("updating node resources",
+				zap.String("container", containerID))
+
+			err := d.controller.UpdateNodeResources(ctx, containerID, resources)
+			if err != nil {
+				return errors.Wrap(err, "failed to update node resources")
+			}
+		}
 
 		_, err := d.addRemoveNodes(ctx, clusterInfoEx, nodesToAdd, nodesToRemove)
 		if err != nil {
@@ -339,7 +406,12 @@ func (d *Deployer) RemoveAll(ctx context.Context) error {
 		return err
 	}
 
-	return d.removeNodes(ctx, nodes)
+	err = d.removeNodes(ctx, nodes)
+	if err != nil {
+		return err
+	}
+
+	return d.removeOrphanedVolumes(ctx)
 }
 
 func (d *Deployer) GetConnectInfo(ctx context.Context, clusterID string) (*deployment.ConnectInfo, error) {
@@ -357,6 +429,12 @@ func (d *Deployer) GetConnectInfo(ctx context.Context, clusterID string) (*deplo
 		} else if thisCluster.Type == deployment.ClusterTypeColumnar {
 			dnsAName = thisCluster.DnsName
 		}
+
+		dnsServer, err := d.lookupDnsServerAddress(ctx)
+		if err != nil {
+			return nil, err
+		}
+
 		return &deployment.ConnectInfo{
 			ConnStr:      fmt.Sprintf("couchbase://%s", "srv."+thisCluster.DnsName),
 			ConnStrTls:   fmt.Sprintf("couchbases://%s", "srv."+thisCluster.DnsName),
@@ -366,6 +444,7 @@ func (d *Deployer) GetConnectInfo(ctx context.Context, clusterID string) (*deplo
 			MgmtTls:      fmt.Sprintf("https://%s", thisCluster.DnsName),
 			DnsAName:     dnsAName,
 			DnsSRVName:   dnsSRVName,
+			DnsServer:    dnsServer,
 		}, nil
 	}
 
@@ -445,7 +524,90 @@ func (d *Deployer) Cleanup(ctx context.Context) error {
 		}
 	}
 
-	return d.removeNodes(ctx, nodesToRemove)
+	err = d.removeNodes(ctx, nodesToRemove)
+	if err != nil {
+		return err
+	}
+
+	d.expirePrevCAs(ctx, curTime)
+
+	return d.removeOrphanedVolumes(ctx)
+}
+
+// expirePrevCAs enforces the overlap of certificate rotations which replaced
+// the CA of a cluster.  Clusters which cannot be updated right now, such as
+// stopped ones, are left for the next cleanup.
+func (d *Deployer) expirePrevCAs(ctx context.Context, now time.Time) {
+	clusters, err := d.listClusters(ctx)
+	if err != nil {
+		d.logger.Warn("failed to list clusters to expire previous CAs", zap.Error(err))
+		return
+	}
+
+	for _, cluster := range clusters {
+		if !cluster.UsingDinoCerts || cluster.State() != "ready" {
+			continue
+		}
+
+		err := d.expirePrevCA(ctx, cluster, now)
+		if err != nil {
+			d.logger.Warn("failed to expire previous cluster CA",
+				zap.String("cluster", cluster.ClusterID),
+				zap.Error(err))
+		}
+	}
+}
+
+// orphanedVolumeGracePeriod is how old a data volume must be before it can be
+// considered orphaned.  Volumes are created before the container of their node,
+// so a cleanup running while a node is deployed must not remove its volume.
+const orphanedVolumeGracePeriod = 10 * time.Minute
+
+// orphanedVolumes returns the data volumes whose cluster no longer has any
+// containers, leaving out volumes created within the grace period.
+func orphanedVolumes(volumes []*VolumeInfo, nodes []*ContainerInfo, now time.Time) []*VolumeInfo {
+	var orphaned []*VolumeInfo
+	for _, volume := range volumes {
+		if now.Sub(volume.CreatedAt) < orphanedVolumeGracePeriod {
+			continue
+		}
+
+		hasNodes := slices.ContainsFunc(nodes, func(node *ContainerInfo) bool {
+			return node.ClusterID == volume.ClusterID
+		})
+		if !hasNodes {
+			orphaned = append(orphaned, volume)
+		}
+	}
+	return orphaned
+}
+
+// removeOrphanedVolumes removes any data volumes whose cluster no longer
+// has any containers, such as when a container was removed outside of
+// cbdinocluster.
+func (d *Deployer) removeOrphanedVolumes(ctx context.Context) error {
+	nodes, err := d.controller.ListNodes(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to list nodes")
+	}
+
+	volumes, err := d.controller.ListVolumes(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to list volumes")
+	}
+
+	for _, volume := range orphanedVolumes(volumes, nodes, time.Now()) {
+		d.logger.Info("removing orphaned data volume",
+			zap.String("volume", volume.Name),
+			zap.String("cluster", volume.ClusterID))
+
+		err := d.controller.RemoveVolume(ctx, volume.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (d *Deployer) DestroyAllResources(ctx context.Context) error {
@@ -525,87 +687,57 @@ func (d *Deployer) getAgent(ctx context.Context, clusterID string, bucketName st
 	return agent, nil
 }
 
-func (d *Deployer) ListUsers(ctx context.Context, clusterID string) ([]deployment.UserInfo, error) {
+// OpenBucketAgent opens an agent for a bucket of the cluster as the cluster
+// administrator, which the caller must close.
+func (d *Deployer) OpenBucketAgent(ctx context.Context, clusterID string, bucketName string, opts *deployment.OpenBucketAgentOptions) (*gocbcorex.Agent, error) {
+	return d.getAgent(ctx, clusterID, bucketName)
+}
+
+func (d *Deployer) getUsersHelper(ctx context.Context, clusterID string) (*commondeploy.UsersHelper, error) {
 	controller, err := d.getController(ctx, clusterID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get cluster controller")
 	}
 
-	resp, err := controller.Controller().ListUsers(ctx, &clustercontrol.ListUsersRequest{
-		Order:    "asc",
-		PageSize: 100,
-		SortBy:   "id",
-	})
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to list users")
-	}
-
-	var users []deployment.UserInfo
-	for _, user := range resp.Users {
-		canRead := false
-		canWrite := false
-		for _, perm := range user.Roles {
-			if perm.Role == "admin" {
-				canWrite = true
-				canRead = true
-			} else if perm.Role == "data_reader" {
-				canRead = true
-			}
-		}
-
-		users = append(users, deployment.UserInfo{
-			Username: user.ID,
-			CanRead:  canRead,
-			CanWrite: canWrite,
-		})
-	}
-
-	return users, nil
+	return &commondeploy.UsersHelper{
+		Controller: controller.Controller(),
+	}, nil
 }
 
-func (d *Deployer) CreateUser(ctx context.Context, clusterID string, opts *deployment.CreateUserOptions) error {
-	controller, err := d.getController(ctx, clusterID)
+func (d *Deployer) ListUsers(ctx context.Context, clusterID string) ([]deployment.UserInfo, error) {
+	helper, err := d.getUsersHelper(ctx, clusterID)
 	if err != nil {
-		return errors.Wrap(err, "failed to get cluster controller")
+		return nil, err
 	}
 
-	var roles []string
-	if opts.CanWrite {
-		roles = append(roles, "admin")
-	} else if opts.CanRead {
-		roles = append(roles,
-			"ro_admin",
-			"analytics_reader",
-			"data_reader[*]",
-			"views_reader[*]",
-			"query_select[*]",
-			"fts_searcher[*]")
-	}
+	return helper.ListUsers(ctx)
+}
 
-	err = controller.Controller().CreateUser(ctx, opts.Username, &clustercontrol.CreateUserRequest{
-		Name:     "",
-		Password: opts.Password,
-		Roles:    roles,
-	})
+func (d *Deployer) CreateUser(ctx context.Context, clusterID string, opts *deployment.CreateUserOptions) error {
+	helper, err := d.getUsersHelper(ctx, clusterID)
 	if err != nil {
-		return errors.Wrap(err, "failed to create user")
+		return err
 	}
 
-	return nil
+	return helper.CreateUser(ctx, opts)
 }
 
 func (d *Deployer) DeleteUser(ctx context.Context, clusterID string, username string) error {
-	controller, err := d.getController(ctx, clusterID)
+	helper, err := d.getUsersHelper(ctx, clusterID)
 	if err != nil {
-		return errors.Wrap(err, "failed to get cluster controller")
+		return err
 	}
 
-	err = controller.Controller().DeleteUser(ctx, username)
+	return helper.DeleteUser(ctx, username)
+}
+
+func (d *Deployer) CreateGroup(ctx context.Context, clusterID string, opts *deployment.CreateGroupOptions) error {
+	helper, err := d.getUsersHelper(ctx, clusterID)
 	if err != nil {
-		return errors.Wrap(err, "failed to delete user")
+		return err
 	}
 
-	return nil
+	return helper.CreateGroup(ctx, opts)
 }
 
 func (d *Deployer) ListBuckets(ctx context.Context, clusterID string) ([]deployment.BucketInfo, error) {
@@ -657,6 +789,44 @@ func (d *Deployer) DeleteBucket(ctx context.Context, clusterID string, bucketNam
 	return err
 }
 
+func (d *Deployer) getBucketHelper(ctx context.Context, clusterID string) (*commondeploy.BucketHelper, error) {
+	controller, err := d.getController(ctx, clusterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster controller")
+	}
+
+	return &commondeploy.BucketHelper{
+		Controller: controller.Controller(),
+	}, nil
+}
+
+func (d *Deployer) GetBucket(ctx context.Context, clusterID string, bucketName string) (*deployment.BucketDetails, error) {
+	helper, err := d.getBucketHelper(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.GetBucket(ctx, bucketName)
+}
+
+func (d *Deployer) UpdateBucket(ctx context.Context, clusterID string, bucketName string, opts *deployment.UpdateBucketOptions) error {
+	helper, err := d.getBucketHelper(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	return helper.UpdateBucket(ctx, bucketName, opts)
+}
+
+func (d *Deployer) FlushBucket(ctx context.Context, clusterID string, bucketName string) error {
+	helper, err := d.getBucketHelper(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	return helper.FlushBucket(ctx, bucketName)
+}
+
 func (d *Deployer) GetCertificate(ctx context.Context, clusterID string) (string, error) {
 	cluster, err := d.getCluster(ctx, clusterID)
 	if err != nil {
@@ -664,12 +834,25 @@ func (d *Deployer) GetCertificate(ctx context.Context, clusterID string) (string
 	}
 
 	if cluster.UsingDinoCerts {
-		clusterCa, _, err := d.getClusterDinoCert(clusterID)
+		clusterCa, _, err := d.getClusterDinoCert(clusterID, cluster.DinoCerts.CaGeneration)
 		if err != nil {
 			return "", errors.Wrap(err, "failed to get cluster CA")
 		}
 
-		return string(clusterCa.CertPem), nil
+		certPem := string(clusterCa.CertPem)
+
+		// the previous CA remains trusted until the overlap of the last
+		// rotation has ended.
+		if cluster.DinoCerts.prevCaTrusted(time.Now()) {
+			prevCa, _, err := d.getClusterDinoCert(clusterID, cluster.DinoCerts.PrevCaGeneration)
+			if err != nil {
+				return "", errors.Wrap(err, "failed to get previous cluster CA")
+			}
+
+			certPem += string(prevCa.CertPem)
+		}
+
+		return certPem, nil
 	}
 
 	controller, err := d.getController(ctx, clusterID)
@@ -698,7 +881,7 @@ func (d *Deployer) GetCertificate(ctx context.Context, clusterID string) (string
 }
 
 func (d *Deployer) GetGatewayCertificate(ctx context.Context, clusterID string) (string, error) {
-	return "", errors.New("dockerdeploy does not support getting gateway certificates")
+	return "", deployment.NewNotSupportedError("dockerdeploy does not support getting gateway certificates")
 }
 
 func (d *Deployer) GetMetrics(ctx context.Context, clusterID string) (string, error) {
@@ -806,7 +989,7 @@ func (d *Deployer) BlockNodeTraffic(ctx context.Context, clusterID string, nodeI
 	}
 
 	for _, nodeContainerID := range nodeContainerIDs {
-		err := d.controller.SetTrafficControl(ctx, nodeContainerID, tcType, rejectType, nil, nil)
+		err := d.controller.SetTrafficControl(ctx, nodeContainerID, tcType, rejectType, nil, nil, nil)
 		if err != nil {
 			return errors.Wrap(err, "failed to block traffic")
 		}
@@ -837,10 +1020,15 @@ func (d *Deployer) AllowNodeTraffic(ctx context.Context, clusterID string, nodeI
 	}
 
 	for _, nodeContainerID := range nodeContainerIDs {
-		err := d.controller.SetTrafficControl(ctx, nodeContainerID, TrafficControlAllowAll, "", nil, nil)
+		err := d.controller.SetTrafficControl(ctx, nodeContainerID, TrafficControlAllowAll, "", nil, nil, nil)
 		if err != nil {
 			return errors.Wrap(err, "failed to allow traffic")
 		}
+
+		err = d.controller.ClearTrafficDegrade(ctx, nodeContainerID)
+		if err != nil {
+			return errors.Wrap(err, "failed to clear traffic degrade")
+		}
 	}
 
 	return nil
@@ -885,7 +1073,7 @@ func (d *Deployer) PartitionNodeTraffic(ctx context.Context, clusterID string, n
 			zap.String("ipAddress", node.IPAddress))
 
 		// block all inter-node traffic for this specific node, but allow traffic from the partition
-		err := d.controller.SetTrafficControl(ctx, node.ContainerID, TrafficControlBlockNodes, rejectType, nil, islandAllowedIps)
+		err := d.controller.SetTrafficControl(ctx, node.ContainerID, TrafficControlBlockNodes, rejectType, nil, islandAllowedIps, nil)
 		if err != nil {
 			return errors.Wrapf(err, "failed to partition traffic for node %s", node.NodeID)
 		}
@@ -894,6 +1082,71 @@ func (d *Deployer) PartitionNodeTraffic(ctx context.Context, clusterID string, n
 	return nil
 }
 
+func (d *Deployer) DegradeNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string, opts *deployment.DegradeNodeTrafficOptions) error {
+	var nodeContainerIDs []string
+	for _, nodeId := range nodeIDs {
+		node, err := d.getNode(ctx, clusterID, nodeId)
+		if err != nil {
+			return errors.Wrap(err, "failed to get node")
+		}
+
+		nodeContainerIDs = append(nodeContainerIDs, node.ContainerID)
+	}
+	if len(nodeIDs) == 0 {
+		clusterInfo, err := d.getCluster(ctx, clusterID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get cluster info")
+		}
+
+		for _, node := range clusterInfo.Nodes {
+			if !node.IsClusterNode() {
+				continue
+			}
+
+			nodeContainerIDs = append(nodeContainerIDs, node.ContainerID)
+		}
+	}
+
+	degradeOpts := &TrafficDegradeOptions{
+		Latency:     opts.Latency,
+		Jitter:      opts.Jitter,
+		LossPercent: opts.LossPercent,
+		Rate:        opts.Rate,
+	}
+
+	switch opts.TrafficType {
+	case deployment.BlockNodeTrafficNodes:
+		degradeOpts.Scope = TrafficControlBlockNodes
+	case deployment.BlockNodeTrafficClients:
+		degradeOpts.Scope = TrafficControlBlockClients
+	case deployment.BlockNodeTrafficAll, "":
+		degradeOpts.Scope = TrafficControlBlockAll
+	default:
+		return fmt.Errorf("unexpected traffic type `%s`", opts.TrafficType)
+	}
+
+	switch opts.Direction {
+	case deployment.DegradeNodeTrafficIngress:
+		degradeOpts.Ingress = true
+	case deployment.DegradeNodeTrafficEgress:
+		degradeOpts.Egress = true
+	case deployment.DegradeNodeTrafficBoth, "":
+		degradeOpts.Ingress = true
+		degradeOpts.Egress = true
+	default:
+		return fmt.Errorf("unexpected traffic direction `%s`", opts.Direction)
+	}
+
+	for _, nodeContainerID := range nodeContainerIDs {
+		err := d.controller.SetTrafficDegrade(ctx, nodeContainerID, degradeOpts)
+		if err != nil {
+			return errors.Wrap(err, "failed to degrade traffic")
+		}
+	}
+
+	return nil
+}
+
 func (d *Deployer) CollectLogs(ctx context.Context, clusterID string, destPath string) ([]string, error) {
 	clusterInfo, err := d.getCluster(ctx, clusterID)
 	if err != nil {
@@ -1248,7 +1501,7 @@ func (d *Deployer) SetAppTelemetry(ctx context.Context, clusterID string, enable
 }
 
 func (d *Deployer) RedeployCluster(ctx context.Context, clusterID string) error {
-	return errors.New("docker deploy does not support redeploy cluster")
+	return deployment.NewNotSupportedError("dockerdeploy does not support redeploy cluster")
 }
 
 func (d *Deployer) SetAutoFailover(ctx context.Context, clusterID string, enabled bool, timeout int) error {
@@ -1264,21 +1517,51 @@ func (d *Deployer) SetAutoFailover(ctx context.Context, clusterID string, enable
 }
 
 func (d *Deployer) CreateCapellaLink(ctx context.Context, columnarID, linkName, clusterId, directID string) error {
-	return errors.New("docker deploy does not support create capella link")
+	return deployment.NewNotSupportedError("dockerdeploy does not support create capella link")
 }
 
 func (d *Deployer) CreateS3Link(ctx context.Context, columnarID, linkName, region, endpoint, accessKey, secretKey string) error {
-	return errors.New("docker deploy does not support create S3 link")
+	return deployment.NewNotSupportedError("dockerdeploy does not support create S3 link")
 }
 
 func (d *Deployer) DropLink(ctx context.Context, columnarID, linkName string) error {
-	return errors.New("docker deploy does not support drop link")
+	return deployment.NewNotSupportedError("dockerdeploy does not support drop link")
 }
 
-func (d *Deployer) UpgradeCluster(ctx context.Context, clusterID string, CurrentImages string, NewImage string) error {
-	return errors.New("docker deploy does not support upgrade cluster command")
+func (d *Deployer) EnableDataApi(ctx context.Context, clusterID string) error {
+	return deployment.NewNotSupportedError("dockerdeploy does not support enabling data api")
 }
 
-func (d *Deployer) EnableDataApi(ctx context.Context, clusterID string) error {
-	return errors.New("docker deploy does not support enabling data api")
+func (d *Deployer) Capabilities(ctx context.Context) ([]deployment.Capability, error) {
+	return []deployment.Capability{
+		deployment.CapabilityGetDefinition,
+		deployment.CapabilityUpdateExpiry,
+		deployment.CapabilityModifyCluster,
+		deployment.CapabilityUpgradeCluster,
+		deployment.CapabilityAddRemoveNodes,
+		deployment.CapabilityFailOver,
+		deployment.CapabilityRebalance,
+		deployment.CapabilityStopStart,
+		deployment.CapabilityUsers,
+		deployment.CapabilityBuckets,
+		deployment.CapabilitySampleBuckets,
+		deployment.CapabilityCollections,
+		deployment.CapabilityQuery,
+		deployment.CapabilityCertificates,
+		deployment.CapabilityMetrics,
+		deployment.CapabilityCollectLogs,
+		deployment.CapabilityImages,
+		deployment.CapabilityTrafficControl,
+		deployment.CapabilityDegradeTraffic,
+		deployment.CapabilityPartitionGroups,
+		deployment.CapabilityPauseNode,
+		deployment.CapabilityKillCouchbase,
+		deployment.CapabilityAutoFailover,
+		deployment.CapabilityXdcr,
+		deployment.CapabilityIndexes,
+		deployment.CapabilitySearchIndexes,
+		deployment.CapabilityDocuments,
+		deployment.CapabilitySnapshots,
+		deployment.CapabilityLoadBalancers,
+	}, nil
 }
@@ -4,20 +4,23 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strings"
+	"time"
 
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
 	"github.com/couchbaselabs/cbdinocluster/utils/clustercontrol"
 	"github.com/couchbaselabs/cbdinocluster/utils/dinocerts"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
-func (d *Deployer) getClusterDinoCert(clusterID string) (*dinocerts.CertAuthority, []byte, error) {
+func (d *Deployer) getClusterDinoCert(clusterID string, caGeneration int) (*dinocerts.CertAuthority, []byte, error) {
 	rootCa, err := dinocerts.GetRootCertAuthority()
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to get root dino ca")
 	}
 
-	fetchedClusterCa, err := rootCa.MakeIntermediaryCA("cluster-" + clusterID[:8])
+	fetchedClusterCa, err := rootCa.MakeIntermediaryCA(dinoCertCaSeed(clusterID, caGeneration))
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to get cluster dino ca")
 	}
@@ -25,14 +28,90 @@ func (d *Deployer) getClusterDinoCert(clusterID string) (*dinocerts.CertAuthorit
 	return fetchedClusterCa, rootCa.CertPem, nil
 }
 
-func (d *Deployer) setupNodeCertificates(
-	ctx context.Context,
-	node *ContainerInfo,
-	clusterCa *dinocerts.CertAuthority,
-	rootCaPem []byte,
-) error {
-	nodeIP := net.ParseIP(node.IPAddress)
+// dinoCertCaSeed returns the seed of the intermediate CA of a cluster.  The
+// first generation keeps the original seed so existing clusters are unchanged.
+func dinoCertCaSeed(clusterID string, caGeneration int) string {
+	if caGeneration == 0 {
+		return "cluster-" + clusterID[:8]
+	}
+	return fmt.Sprintf("cluster-%s-ca%d", clusterID[:8], caGeneration)
+}
+
+// dinoCertSeed returns the seed of a certificate issued by the cluster CA,
+// which changes with each rotation so that rotated certificates have new keys.
+func dinoCertSeed(seed string, rotation int) string {
+	if rotation == 0 {
+		return seed
+	}
+	return fmt.Sprintf("%s-r%d", seed, rotation)
+}
+
+// newDinoCertState returns the initial certificate state of a cluster.
+func newDinoCertState(settings *clusterdef.DinoCertSettings) (DinoCertState, error) {
+	key := dinocerts.KeySpec{
+		Type:       dinocerts.KeyType(settings.KeyType),
+		Format:     dinocerts.KeyFormat(settings.KeyFormat),
+		Passphrase: settings.Passphrase,
+	}
+
+	err := key.Validate()
+	if err != nil {
+		return DinoCertState{}, err
+	}
+
+	return DinoCertState{Key: key}, nil
+}
+
+// loadBalancerKey returns the key spec for load balancer certificates, which
+// cannot be encrypted.
+func (s DinoCertState) loadBalancerKey() dinocerts.KeySpec {
+	key := s.Key
+	key.Passphrase = ""
+	return key
+}
+
+// prevCaTrusted returns whether the CA replaced by the last rotation should
+// still be trusted.
+func (s DinoCertState) prevCaTrusted(now time.Time) bool {
+	return s.CaGeneration != s.PrevCaGeneration && now.Before(s.PrevCaTrustedUntil)
+}
+
+// prevCaExpired returns whether the CA replaced by the last rotation is still
+// trusted by the nodes even though its overlap has ended.
+func (s DinoCertState) prevCaExpired(now time.Time) bool {
+	return s.CaGeneration != s.PrevCaGeneration && !now.Before(s.PrevCaTrustedUntil)
+}
 
+type dinoCertOptions struct {
+	Seed      string
+	IPs       []net.IP
+	DnsNames  []string
+	NotBefore time.Time
+	NotAfter  time.Time
+	Key       dinocerts.KeySpec
+}
+
+// makeDinoCertChain generates a certificate from the cluster CA, returning
+// its full chain and key.
+func makeDinoCertChain(clusterCa *dinocerts.CertAuthority, opts *dinoCertOptions) ([]byte, []byte, error) {
+	certPem, keyPem, err := clusterCa.MakeServerCertificateWithOptions(opts.Seed, &dinocerts.ServerCertificateOptions{
+		IPAddresses: opts.IPs,
+		DNSNames:    opts.DnsNames,
+		NotBefore:   opts.NotBefore,
+		NotAfter:    opts.NotAfter,
+		Key:         opts.Key,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var chainPem []byte
+	chainPem = append(chainPem, certPem...)
+	chainPem = append(chainPem, clusterCa.CertPem...)
+	return chainPem, keyPem, nil
+}
+
+func nodeDinoCertOptions(node *ContainerInfo, state *DinoCertState) *dinoCertOptions {
 	var dnsNames []string
 	if node.DnsName != "" {
 		dnsNames = append(dnsNames, node.DnsName)
@@ -41,23 +120,28 @@ func (d *Deployer) setupNodeCertificates(
 		dnsNames = append(dnsNames, node.DnsSuffix)
 	}
 
-	d.logger.Debug("generating node dinocert certificate",
-		zap.String("node", node.NodeID),
-		zap.Any("IP", nodeIP),
-		zap.Any("dnsNames", dnsNames))
-
-	certPem, keyPem, err := clusterCa.MakeServerCertificate("node-"+node.NodeID[:8], []net.IP{nodeIP}, dnsNames)
-	if err != nil {
-		return errors.Wrap(err, "failed to create server certificate")
+	return &dinoCertOptions{
+		Seed:     dinoCertSeed("node-"+node.NodeID[:8], state.Rotation),
+		IPs:      []net.IP{net.ParseIP(node.IPAddress)},
+		DnsNames: dnsNames,
+		Key:      state.Key,
 	}
+}
 
+// installNodeCertificates uploads a certificate chain to a node and has it
+// load both the certificate and the trusted CAs.
+func (d *Deployer) installNodeCertificates(
+	ctx context.Context,
+	node *ContainerInfo,
+	chainPem []byte,
+	keyPem []byte,
+	keyPassphrase string,
+	caPems [][]byte,
+) error {
 	d.logger.Debug("uploading dinocert certificates",
 		zap.String("node", node.NodeID))
 
-	var chainPem []byte
-	chainPem = append(chainPem, certPem...)
-	chainPem = append(chainPem, clusterCa.CertPem...)
-	err = d.controller.UploadCertificates(ctx, node.ContainerID, chainPem, keyPem, [][]byte{rootCaPem})
+	err := d.controller.UploadCertificates(ctx, node.ContainerID, chainPem, keyPem, caPems)
 	if err != nil {
 		return errors.Wrap(err, "failed to upload certificates")
 	}
@@ -78,11 +162,45 @@ func (d *Deployer) setupNodeCertificates(
 	d.logger.Debug("refreshing certificate for node",
 		zap.String("node", node.NodeID))
 
-	err = nodeCtrl.Controller().ReloadCertificate(ctx, &clustercontrol.ReloadCertificateOptions{})
+	err = nodeCtrl.Controller().ReloadCertificate(ctx, &clustercontrol.ReloadCertificateOptions{
+		PrivateKeyPassphrase: keyPassphrase,
+	})
 	if err != nil {
 		return errors.Wrap(err, "failed to refresh certificates")
 	}
 
+	return nil
+}
+
+func (d *Deployer) setupNodeCertificates(
+	ctx context.Context,
+	node *ContainerInfo,
+	clusterCa *dinocerts.CertAuthority,
+	rootCaPem []byte,
+	state *DinoCertState,
+) error {
+	certOpts := nodeDinoCertOptions(node, state)
+
+	d.logger.Debug("generating node dinocert certificate",
+		zap.String("node", node.NodeID),
+		zap.Any("IP", certOpts.IPs),
+		zap.Any("dnsNames", certOpts.DnsNames))
+
+	chainPem, keyPem, err := makeDinoCertChain(clusterCa, certOpts)
+	if err != nil {
+		return errors.Wrap(err, "failed to create server certificate")
+	}
+
+	err = d.installNodeCertificates(ctx, node, chainPem, keyPem, state.Key.Passphrase, [][]byte{rootCaPem})
+	if err != nil {
+		return err
+	}
+
+	nodeCtrl := clustercontrol.NodeManager{
+		Logger:   d.logger,
+		Endpoint: fmt.Sprintf("http://%s:8091", node.IPAddress),
+	}
+
 	d.logger.Debug("removing default self-signed certificate for node",
 		zap.String("node", node.NodeID))
 
@@ -95,3 +213,256 @@ func (d *Deployer) setupNodeCertificates(
 
 	return nil
 }
+
+type RotateCertificatesOptions struct {
+	// NewCA replaces the intermediate CA of the cluster, rather than only
+	// reissuing the certificates from the existing one.
+	NewCA bool
+
+	// NotBefore and NotAfter bound the validity of the new certificates, and
+	// default to the standard dino-cert validity when zero.
+	NotBefore time.Time
+	NotAfter  time.Time
+
+	// Overlap is how long the replaced CA remains trusted when NewCA is set.
+	Overlap time.Duration
+}
+
+// RotateCertificates regenerates the certificates of the nodes and load
+// balancers of a cluster which is using dino-certs.
+func (d *Deployer) RotateCertificates(ctx context.Context, clusterID string, opts *RotateCertificatesOptions) error {
+	if !opts.NotBefore.IsZero() && !opts.NotAfter.IsZero() && !opts.NotAfter.After(opts.NotBefore) {
+		return errors.New("certificate not-after must be after not-before")
+	}
+	if opts.Overlap < 0 {
+		return errors.New("ca overlap must not be negative")
+	}
+
+	cluster, err := d.getCluster(ctx, clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster info")
+	}
+
+	if !cluster.UsingDinoCerts {
+		return errors.New("cluster is not using dino-certs")
+	}
+	if cluster.State() != "ready" {
+		return errors.New("cannot rotate certificates of a cluster which is not running")
+	}
+
+	now := time.Now()
+	state := cluster.DinoCerts
+	state.Rotation++
+	if opts.NewCA {
+		state.PrevCaGeneration = state.CaGeneration
+		state.PrevCaTrustedUntil = now.Add(opts.Overlap)
+		state.CaGeneration++
+	}
+
+	d.logger.Info("rotating cluster certificates",
+		zap.String("cluster", clusterID),
+		zap.Int("rotation", state.Rotation),
+		zap.Int("caGeneration", state.CaGeneration))
+
+	clusterCa, rootCaPem, err := d.getClusterDinoCert(clusterID, state.CaGeneration)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster dino ca")
+	}
+
+	caPems := [][]byte{rootCaPem}
+	if state.prevCaTrusted(now) {
+		prevCa, _, err := d.getClusterDinoCert(clusterID, state.PrevCaGeneration)
+		if err != nil {
+			return errors.Wrap(err, "failed to get previous cluster dino ca")
+		}
+
+		caPems = append(caPems, prevCa.CertPem)
+	}
+
+	for _, node := range cluster.Nodes {
+		certOpts := &dinoCertOptions{
+			IPs:       []net.IP{net.ParseIP(node.IPAddress)},
+			NotBefore: opts.NotBefore,
+			NotAfter:  opts.NotAfter,
+			Key:       state.loadBalancerKey(),
+		}
+		if cluster.DnsName != "" {
+			certOpts.DnsNames = []string{cluster.DnsName}
+		}
+
+		// cluster nodes are identified the same way as when they were deployed
+		nodeContainer := &ContainerInfo{
+			NodeID:      node.NodeID,
+			ContainerID: node.ContainerID,
+			IPAddress:   node.IPAddress,
+			DnsName:     node.DnsName,
+			DnsSuffix:   cluster.DnsName,
+		}
+
+		if node.IsClusterNode() {
+			nodeOpts := nodeDinoCertOptions(nodeContainer, &state)
+			certOpts.Seed = nodeOpts.Seed
+			certOpts.DnsNames = nodeOpts.DnsNames
+			certOpts.Key = nodeOpts.Key
+		} else if node.IsPassiveLoadBalancerNode() {
+			certOpts.Seed = dinoCertSeed("nginx-"+clusterID[:8], state.Rotation)
+		} else if node.IsActiveLoadBalancerNode() {
+			certOpts.Seed = dinoCertSeed("haproxy-"+clusterID[:8], state.Rotation)
+		} else {
+			continue
+		}
+
+		d.logger.Debug("rotating node certificate",
+			zap.String("node", node.NodeID),
+			zap.String("seed", certOpts.Seed))
+
+		chainPem, keyPem, err := makeDinoCertChain(clusterCa, certOpts)
+		if err != nil {
+			return errors.Wrap(err, "failed to create certificate")
+		}
+
+		if node.IsClusterNode() {
+			err = d.installNodeCertificates(ctx, nodeContainer, chainPem, keyPem, state.Key.Passphrase, caPems)
+			if err != nil {
+				return errors.Wrapf(err, "failed to install certificates on node %s", node.NodeID)
+			}
+		} else if node.IsPassiveLoadBalancerNode() {
+			err = d.controller.UpdateNginxCertificates(ctx, node.ContainerID, chainPem, keyPem)
+			if err != nil {
+				return errors.Wrap(err, "failed to upload nginx certificates")
+			}
+
+			// rewriting the config reloads nginx with the new certificates
+			err = d.updatePassiveLoadBalancer(ctx, node.ContainerID, cluster.Nodes, cluster.IsColumnar(), true)
+			if err != nil {
+				return errors.Wrap(err, "failed to reload passive load balancer")
+			}
+		} else if node.IsActiveLoadBalancerNode() {
+			err = d.controller.UpdateHaproxyCertificates(ctx, node.ContainerID, chainPem, keyPem)
+			if err != nil {
+				return errors.Wrap(err, "failed to upload haproxy certificates")
+			}
+
+			err = d.updateActiveLoadBalancer(ctx, node.ContainerID, cluster.Nodes, true, cluster.IsColumnar())
+			if err != nil {
+				return errors.Wrap(err, "failed to reload active load balancer")
+			}
+		}
+	}
+
+	err = d.pruneTrustedCAs(ctx, cluster, state.CaGeneration, caPems)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range cluster.Nodes {
+		err := d.controller.UpdateDinoCertState(ctx, node.ContainerID, &state)
+		if err != nil {
+			return errors.Wrap(err, "failed to update node certificate state")
+		}
+	}
+
+	return nil
+}
+
+// expirePrevCA stops the nodes of a cluster from trusting the CA replaced by
+// its last rotation once the overlap of that rotation has ended.
+func (d *Deployer) expirePrevCA(ctx context.Context, cluster *clusterInfo, now time.Time) error {
+	state := cluster.DinoCerts
+	if !cluster.UsingDinoCerts || !state.prevCaExpired(now) {
+		return nil
+	}
+
+	d.logger.Info("removing previous cluster CA after its overlap",
+		zap.String("cluster", cluster.ClusterID),
+		zap.Int("caGeneration", state.PrevCaGeneration))
+
+	_, rootCaPem, err := d.getClusterDinoCert(cluster.ClusterID, state.CaGeneration)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster dino ca")
+	}
+
+	err = d.pruneTrustedCAs(ctx, cluster, state.CaGeneration, [][]byte{rootCaPem})
+	if err != nil {
+		return err
+	}
+
+	state.PrevCaGeneration = state.CaGeneration
+	state.PrevCaTrustedUntil = time.Time{}
+
+	for _, node := range cluster.Nodes {
+		err := d.controller.UpdateDinoCertState(ctx, node.ContainerID, &state)
+		if err != nil {
+			return errors.Wrap(err, "failed to update node certificate state")
+		}
+	}
+
+	return nil
+}
+
+// pruneTrustedCAs removes CAs which were trusted by an earlier rotation but
+// whose overlap has since ended.
+func (d *Deployer) pruneTrustedCAs(ctx context.Context, cluster *clusterInfo, caGeneration int, caPems [][]byte) error {
+	controller, err := d.getController(ctx, cluster.ClusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster controller")
+	}
+
+	trustedCAs, err := controller.Controller().GetTrustedCAs(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to list trusted CAs")
+	}
+
+	for _, trustedCA := range *trustedCAs {
+		keep := false
+		for _, caPem := range caPems {
+			if strings.TrimSpace(trustedCA.Pem) == strings.TrimSpace(string(caPem)) {
+				keep = true
+			}
+		}
+		if keep {
+			continue
+		}
+
+		// only CAs previously issued to this cluster are removed, any other
+		// CAs were added by the user.
+		if !isClusterDinoCa(cluster.ClusterID, caGeneration, trustedCA.Pem) {
+			continue
+		}
+
+		d.logger.Debug("removing expired cluster CA",
+			zap.Int("id", trustedCA.ID),
+			zap.String("subject", trustedCA.Subject))
+
+		err := controller.Controller().DeleteTrustedCA(ctx, &clustercontrol.DeleteTrustedCAOptions{
+			ID: trustedCA.ID,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to remove expired cluster CA")
+		}
+	}
+
+	return nil
+}
+
+// isClusterDinoCa checks whether a pem is one of the intermediate CAs a
+// cluster has had, up to the given generation.
+func isClusterDinoCa(clusterID string, maxGeneration int, caPem string) bool {
+	rootCa, err := dinocerts.GetRootCertAuthority()
+	if err != nil {
+		return false
+	}
+
+	for gen := 0; gen <= maxGeneration; gen++ {
+		ca, err := rootCa.MakeIntermediaryCA(dinoCertCaSeed(clusterID, gen))
+		if err != nil {
+			return false
+		}
+
+		if strings.TrimSpace(string(ca.CertPem)) == strings.TrimSpace(caPem) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,64 @@
+package dockerdeploy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDinoCertSeeds(t *testing.T) {
+	clusterID := "0123456789abcdef"
+
+	// the first generation and rotation must match clusters created before
+	// rotation was supported
+	require.Equal(t, "cluster-01234567", dinoCertCaSeed(clusterID, 0))
+	require.Equal(t, "cluster-01234567-ca2", dinoCertCaSeed(clusterID, 2))
+	require.Equal(t, "node-01234567", dinoCertSeed("node-01234567", 0))
+	require.Equal(t, "node-01234567-r3", dinoCertSeed("node-01234567", 3))
+}
+
+func TestDinoCertStatePrevCaTrusted(t *testing.T) {
+	now := time.Now()
+
+	require.False(t, DinoCertState{}.prevCaTrusted(now))
+	require.True(t, DinoCertState{
+		CaGeneration:       1,
+		PrevCaGeneration:   0,
+		PrevCaTrustedUntil: now.Add(time.Hour),
+	}.prevCaTrusted(now))
+	require.False(t, DinoCertState{
+		CaGeneration:       1,
+		PrevCaGeneration:   0,
+		PrevCaTrustedUntil: now.Add(-time.Hour),
+	}.prevCaTrusted(now))
+	require.False(t, DinoCertState{
+		Rotation:           2,
+		CaGeneration:       1,
+		PrevCaGeneration:   1,
+		PrevCaTrustedUntil: now.Add(time.Hour),
+	}.prevCaTrusted(now))
+}
+
+func TestDinoCertStatePrevCaExpired(t *testing.T) {
+	now := time.Now()
+
+	require.False(t, DinoCertState{}.prevCaExpired(now))
+	require.False(t, DinoCertState{
+		CaGeneration:       1,
+		PrevCaGeneration:   0,
+		PrevCaTrustedUntil: now.Add(time.Hour),
+	}.prevCaExpired(now))
+	require.True(t, DinoCertState{
+		CaGeneration:       1,
+		PrevCaGeneration:   0,
+		PrevCaTrustedUntil: now.Add(-time.Hour),
+	}.prevCaExpired(now))
+
+	// a zero overlap ends immediately
+	require.True(t, DinoCertState{
+		CaGeneration:       2,
+		PrevCaGeneration:   1,
+		PrevCaTrustedUntil: now,
+	}.prevCaExpired(now))
+}
@@ -23,13 +23,45 @@ type clusterInfo struct {
 	Expiry         time.Time
 	DnsName        string
 	UsingDinoCerts bool
+	Persistent     bool
+	Pool           string
+	PoolClaimed    bool
+	DinoCerts      DinoCertState
 	Nodes          []*nodeInfo
+
+	// AddressTranslations are the address translations of a restored cluster,
+	// which any nodes added to it need as well.
+	AddressTranslations map[string]string
 }
 
 func (c clusterInfo) IsColumnar() bool {
 	return c.Type == deployment.ClusterTypeColumnar
 }
 
+// State returns "stopped" when every cluster node container has been stopped,
+// "degraded" when only some of them are running, and "ready" otherwise.
+func (c clusterInfo) State() string {
+	numClusterNodes := 0
+	numRunningNodes := 0
+	for _, node := range c.Nodes {
+		if !node.IsClusterNode() {
+			continue
+		}
+
+		numClusterNodes++
+		if node.IsRunning() {
+			numRunningNodes++
+		}
+	}
+
+	if numClusterNodes > 0 && numRunningNodes == 0 {
+		return "stopped"
+	} else if numRunningNodes < numClusterNodes {
+		return "degraded"
+	}
+	return "ready"
+}
+
 func (c clusterInfo) LoadBalancerIPAddress() string {
 	for _, node := range c.Nodes {
 		if node.IsActiveLoadBalancerNode() {
@@ -52,12 +84,18 @@ type nodeInfo struct {
 	IPAddress            string
 	DnsName              string
 	InitialServerVersion string
+	DataVolume           string
+	State                string
 }
 
 func (i nodeInfo) IsClusterNode() bool {
 	return i.Type == "server-node" || i.Type == "columnar-node"
 }
 
+func (i nodeInfo) IsRunning() bool {
+	return i.State == "running" || i.State == "paused"
+}
+
 func (i nodeInfo) IsColumnarNode() bool {
 	return i.Type == "columnar-node"
 }
@@ -114,6 +152,8 @@ func (d *Deployer) listClusters(ctx context.Context) ([]*clusterInfo, error) {
 			IPAddress:            node.IPAddress,
 			DnsName:              node.DnsName,
 			InitialServerVersion: node.InitialServerVersion,
+			DataVolume:           node.DataVolume,
+			State:                node.State,
 		}
 		cluster.Nodes = append(cluster.Nodes, nodeInfo)
 
@@ -126,6 +166,15 @@ func (d *Deployer) listClusters(ctx context.Context) ([]*clusterInfo, error) {
 			}
 			cluster.DnsName = node.DnsSuffix
 			cluster.UsingDinoCerts = node.UsingDinoCerts
+			cluster.Persistent = node.DataVolume != ""
+			cluster.Pool = node.Pool
+			cluster.PoolClaimed = node.PoolClaimed
+			if node.DinoCerts != nil {
+				cluster.DinoCerts = *node.DinoCerts
+			}
+			if node.AddressTranslations != nil {
+				cluster.AddressTranslations = node.AddressTranslations
+			}
 		}
 
 		// if any nodes are columnar nodes, the cluster is a columnar cluster
@@ -259,6 +308,7 @@ func (d *Deployer) clusterInfoFromCluster(cluster *clusterInfo) deployment.Clust
 		Owner:     cluster.Owner,
 		Purpose:   cluster.Purpose,
 		Expiry:    cluster.Expiry,
+		State:     cluster.State(),
 		Nodes:     nodes,
 	}
 }
@@ -0,0 +1,39 @@
+package dockerdeploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterInfoState(t *testing.T) {
+	tests := []struct {
+		name   string
+		states []string
+		want   string
+	}{
+		{"all running", []string{"running", "running"}, "ready"},
+		{"paused counts as running", []string{"running", "paused"}, "ready"},
+		{"all stopped", []string{"exited", "exited"}, "stopped"},
+		{"some stopped", []string{"running", "exited"}, "degraded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := clusterInfo{
+				Nodes: []*nodeInfo{
+					// utility nodes should never affect the cluster state
+					{Type: "nginx", State: "exited"},
+				},
+			}
+			for _, state := range tt.states {
+				cluster.Nodes = append(cluster.Nodes, &nodeInfo{
+					Type:  "server-node",
+					State: state,
+				})
+			}
+
+			require.Equal(t, tt.want, cluster.State())
+		})
+	}
+}
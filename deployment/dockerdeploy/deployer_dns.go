@@ -4,6 +4,7 @@ import (
 	"context"
 	"slices"
 
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
@@ -82,6 +83,38 @@ func (d *Deployer) updateDnsRecords(
 	return nil
 }
 
+// getNodeDnsServers returns the resolvers to use for new nodes, so that nodes
+// resolve the records of a local dns server like any other client would.
+func (d *Deployer) getNodeDnsServers(ctx context.Context) ([]string, error) {
+	localDns, ok := d.dnsProvider.(*LocalDnsProvider)
+	if !ok {
+		return nil, nil
+	}
+
+	dnsAddress, err := localDns.GetServerAddress(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get local dns server address")
+	}
+
+	return []string{dnsAddress}, nil
+}
+
+// lookupDnsServerAddress returns the address of the local dns server if one
+// is running, without starting it.
+func (d *Deployer) lookupDnsServerAddress(ctx context.Context) (string, error) {
+	localDns, ok := d.dnsProvider.(*LocalDnsProvider)
+	if !ok {
+		return "", nil
+	}
+
+	dnsAddress, err := localDns.LookupServerAddress(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get local dns server address")
+	}
+
+	return dnsAddress, nil
+}
+
 func (d *Deployer) appendNodeDnsNames(dnsNames []string, node *ContainerInfo) []string {
 	if node.DnsName != "" {
 		if !slices.Contains(dnsNames, node.DnsName) {
@@ -0,0 +1,79 @@
+package dockerdeploy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/couchbaselabs/cbdinocluster/utils/clustercontrol"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// setupClusterJwt configures a cluster to accept tokens from every issuer of
+// the local oidc issuer, fetching their keys from its JWKS endpoints.
+func (d *Deployer) setupClusterJwt(ctx context.Context, cluster *clusterInfo) error {
+	issuers, err := d.oidcIssuer.ListIssuers(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to list jwt issuers")
+	}
+
+	serverAddress, err := d.oidcIssuer.GetServerAddress(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to start oidc issuer")
+	}
+
+	var jwtIssuers []clustercontrol.SetupJwtOptions_Issuer
+	for _, issuer := range issuers {
+		jwtIssuers = append(jwtIssuers, clustercontrol.SetupJwtOptions_Issuer{
+			Name:             issuer.Name,
+			SigningAlgorithm: "RS256",
+			AudienceHandling: "all",
+			SubClaim:         "sub",
+			AudClaim:         "aud",
+			Audiences:        issuer.Audiences,
+			RolesClaim:       "roles",
+			RolesMaps:        []string{"(.*) \\1"},
+			GroupsClaim:      "groups",
+			GroupsMaps:       []string{"(.*) \\1"},
+			PublicKeySource:  "jwks_uri",
+			JwksUri:          d.oidcIssuer.JwksUri(serverAddress, issuer.Name),
+			JitProvisioning:  true,
+		})
+	}
+
+	d.logger.Debug("configuring jwt issuers",
+		zap.String("cluster", cluster.ClusterID),
+		zap.Int("numIssuers", len(jwtIssuers)))
+
+	var nodeIP string
+	for _, node := range cluster.Nodes {
+		if node.IsClusterNode() {
+			nodeIP = node.IPAddress
+			break
+		}
+	}
+	if nodeIP == "" {
+		return errors.New("cluster has no nodes to configure")
+	}
+
+	nodeCtrl := clustercontrol.NodeManager{
+		Logger:   d.logger,
+		Endpoint: fmt.Sprintf("http://%s:8091", nodeIP),
+	}
+
+	return nodeCtrl.Controller().SetupJwtAuth(ctx, &clustercontrol.SetupJwtAuthOptions{
+		Enabled: true,
+		Issuers: jwtIssuers,
+	})
+}
+
+// SetupJwt configures a cluster against the current issuers of the local oidc
+// issuer, which is needed after issuers are added.
+func (d *Deployer) SetupJwt(ctx context.Context, clusterID string) error {
+	cluster, err := d.getCluster(ctx, clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster info")
+	}
+
+	return d.setupClusterJwt(ctx, cluster)
+}
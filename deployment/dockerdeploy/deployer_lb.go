@@ -1,6 +1,11 @@
 package dockerdeploy
 
-import "context"
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
 
 func proxyTargetsFromNodeInfos(nodes []*nodeInfo) []ProxyTargetNode {
 	var targets []ProxyTargetNode
@@ -10,6 +15,7 @@ func proxyTargetsFromNodeInfos(nodes []*nodeInfo) []ProxyTargetNode {
 		}
 
 		targets = append(targets, ProxyTargetNode{
+			NodeID:                node.NodeID,
 			Address:               node.IPAddress,
 			IsEnterpriseAnalytics: isColumnarVersionEA(node.InitialServerVersion),
 		})
@@ -38,3 +44,117 @@ func (d *Deployer) updateActiveLoadBalancer(
 	targets := proxyTargetsFromNodeInfos(nodes)
 	return d.controller.UpdateHaproxyConfig(ctx, loadBalancerContainerId, targets, enableSsl, isColumnar)
 }
+
+type LoadBalancerServer struct {
+	Backend     string
+	NodeID      string
+	Address     string
+	Status      string
+	CheckStatus string
+	Weight      int
+	Sessions    int
+}
+
+type LoadBalancerServerState string
+
+const (
+	LoadBalancerServerStateReady LoadBalancerServerState = "ready"
+	LoadBalancerServerStateDrain LoadBalancerServerState = "drain"
+	LoadBalancerServerStateMaint LoadBalancerServerState = "maint"
+)
+
+func (d *Deployer) getActiveLoadBalancerNode(ctx context.Context, clusterID string) (*nodeInfo, error) {
+	clusterInfo, err := d.getCluster(ctx, clusterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster info")
+	}
+
+	for _, node := range clusterInfo.Nodes {
+		if node.IsActiveLoadBalancerNode() {
+			return node, nil
+		}
+	}
+
+	return nil, errors.New("cluster does not have an active load balancer")
+}
+
+// ListLoadBalancerServers lists the state of each node in each backend of the
+// active load balancer of a cluster.
+func (d *Deployer) ListLoadBalancerServers(ctx context.Context, clusterID string) ([]*LoadBalancerServer, error) {
+	lbNode, err := d.getActiveLoadBalancerNode(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := d.controller.GetHaproxyServers(ctx, lbNode.IPAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get load balancer servers")
+	}
+
+	var out []*LoadBalancerServer
+	for _, stat := range stats {
+		out = append(out, &LoadBalancerServer{
+			Backend:     stat.Backend,
+			NodeID:      stat.Server,
+			Address:     stat.Address,
+			Status:      stat.Status,
+			CheckStatus: stat.CheckStatus,
+			Weight:      stat.Weight,
+			Sessions:    stat.Sessions,
+		})
+	}
+
+	return out, nil
+}
+
+// setLoadBalancerServer applies a `set server` command to a node in every
+// backend of the active load balancer.  Changes made this way are reset when
+// the load balancer config is next rewritten, such as when nodes are added.
+func (d *Deployer) setLoadBalancerServer(ctx context.Context, clusterID string, nodeID string, args string) error {
+	lbNode, err := d.getActiveLoadBalancerNode(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	stats, err := d.controller.GetHaproxyServers(ctx, lbNode.IPAddress)
+	if err != nil {
+		return errors.Wrap(err, "failed to get load balancer servers")
+	}
+
+	found := false
+	for _, stat := range stats {
+		if stat.Server != nodeID {
+			continue
+		}
+
+		found = true
+		err := d.controller.SetHaproxyServer(ctx, lbNode.IPAddress, stat.Backend, stat.Server, args)
+		if err != nil {
+			return errors.Wrapf(err, "failed to update server in %s", stat.Backend)
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("node %s is not behind the load balancer", nodeID)
+	}
+
+	return nil
+}
+
+func (d *Deployer) SetLoadBalancerServerState(ctx context.Context, clusterID string, nodeID string, state LoadBalancerServerState) error {
+	switch state {
+	case LoadBalancerServerStateReady, LoadBalancerServerStateDrain, LoadBalancerServerStateMaint:
+	default:
+		return fmt.Errorf("unsupported server state `%s`", state)
+	}
+
+	return d.setLoadBalancerServer(ctx, clusterID, nodeID, "state "+string(state))
+}
+
+func (d *Deployer) SetLoadBalancerServerWeight(ctx context.Context, clusterID string, nodeID string, weight int) error {
+	if weight < 0 || weight > 256 {
+		return errors.New("weight must be between 0 and 256")
+	}
+
+	return d.setLoadBalancerServer(ctx, clusterID, nodeID, fmt.Sprintf("weight %d", weight))
+}
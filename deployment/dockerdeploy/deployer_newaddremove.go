@@ -70,6 +70,15 @@ func (d *Deployer) getImagesForNodeGrps(ctx context.Context, nodeGrps []*cluster
 	return nodeGrpImages, nil
 }
 
+// newClusterDnsName returns the dns name for a new cluster, which must only be
+// called when a dns provider is configured.
+func (d *Deployer) newClusterDnsName(clusterID string) string {
+	return fmt.Sprintf("%s-%s.%s",
+		clusterID[:8],
+		time.Now().Format("20060102"),
+		d.dnsProvider.GetHostname())
+}
+
 func (d *Deployer) newCluster(ctx context.Context, def *clusterdef.Cluster) (*clusterInfo, error) {
 	if def.Columnar {
 		for _, nodeGrp := range def.NodeGroups {
@@ -84,6 +93,23 @@ func (d *Deployer) newCluster(ctx context.Context, def *clusterdef.Cluster) (*cl
 		}
 	}
 
+	nodeGrpResources := make([]NodeResources, len(def.NodeGroups))
+	for nodeGrpIdx, nodeGrp := range def.NodeGroups {
+		resources, err := ParseNodeResources(nodeGrp.Docker)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid node resources")
+		}
+
+		nodeGrpResources[nodeGrpIdx] = resources
+	}
+
+	if def.Docker.ActiveLoadBalancer {
+		err := ValidateLoadBalancerSettings(&def.Docker.LoadBalancer)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid load balancer settings")
+		}
+	}
+
 	clusterID := uuid.NewString()
 	d.logger.Debug("creating new cluster",
 		zap.String("id", clusterID))
@@ -95,18 +121,20 @@ func (d *Deployer) newCluster(ctx context.Context, def *clusterdef.Cluster) (*cl
 
 	var dnsName string
 	if useDns {
-		dnsHostname := d.dnsProvider.GetHostname()
-		dnsName = fmt.Sprintf("%s-%s.%s",
-			clusterID[:8],
-			time.Now().Format("20060102"),
-			dnsHostname)
+		dnsName = d.newClusterDnsName(clusterID)
 	}
 
 	var rootCaPem []byte
 	var clusterCa *dinocerts.CertAuthority
+	var dinoCertState DinoCertState
 	if def.Docker.UseDinoCerts {
 		var err error
-		clusterCa, rootCaPem, err = d.getClusterDinoCert(clusterID)
+		dinoCertState, err = newDinoCertState(&def.Docker.DinoCerts)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid dino-cert settings")
+		}
+
+		clusterCa, rootCaPem, err = d.getClusterDinoCert(clusterID, 0)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to get cluster dino ca")
 		}
@@ -194,14 +222,16 @@ func (d *Deployer) newCluster(ctx context.Context, def *clusterdef.Cluster) (*cl
 				dnsNames = append(dnsNames, dnsName)
 			}
 
-			certPem, keyPem, err := clusterCa.MakeServerCertificate("nginx-"+clusterID[:8], []net.IP{ip}, dnsNames)
+			chainPem, keyPem, err := makeDinoCertChain(clusterCa, &dinoCertOptions{
+				Seed:     "nginx-" + clusterID[:8],
+				IPs:      []net.IP{ip},
+				DnsNames: dnsNames,
+				Key:      dinoCertState.loadBalancerKey(),
+			})
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to create nginx certificate")
 			}
 
-			var chainPem []byte
-			chainPem = append(chainPem, certPem...)
-			chainPem = append(chainPem, clusterCa.CertPem...)
 			err = d.controller.UpdateNginxCertificates(ctx, node.ContainerID, chainPem, keyPem)
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to upload nginx certificates")
@@ -215,7 +245,7 @@ func (d *Deployer) newCluster(ctx context.Context, def *clusterdef.Cluster) (*cl
 	if def.Docker.ActiveLoadBalancer {
 		d.logger.Info("deploying haproxy for active load balancing")
 
-		node, err := d.controller.DeployHaproxyNode(ctx, clusterID, def.Expiry)
+		node, err := d.controller.DeployHaproxyNode(ctx, clusterID, def.Expiry, &def.Docker.LoadBalancer)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to deploy haproxy node")
 		}
@@ -233,14 +263,16 @@ func (d *Deployer) newCluster(ctx context.Context, def *clusterdef.Cluster) (*cl
 				dnsNames = append(dnsNames, dnsName)
 			}
 
-			certPem, keyPem, err := clusterCa.MakeServerCertificate("haproxy-"+clusterID[:8], []net.IP{ip}, dnsNames)
+			chainPem, keyPem, err := makeDinoCertChain(clusterCa, &dinoCertOptions{
+				Seed:     "haproxy-" + clusterID[:8],
+				IPs:      []net.IP{ip},
+				DnsNames: dnsNames,
+				Key:      dinoCertState.loadBalancerKey(),
+			})
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to create haproxy certificate")
 			}
 
-			var chainPem []byte
-			chainPem = append(chainPem, certPem...)
-			chainPem = append(chainPem, clusterCa.CertPem...)
 			err = d.controller.UpdateHaproxyCertificates(ctx, node.ContainerID, chainPem, keyPem)
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to upload haproxy certificates")
@@ -257,6 +289,11 @@ func (d *Deployer) newCluster(ctx context.Context, def *clusterdef.Cluster) (*cl
 		return nil, errors.Wrap(err, "failed to fetch images")
 	}
 
+	dnsServers, err := d.getNodeDnsServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	d.logger.Info("deploying nodes")
 
 	nodes := make([]*ContainerInfo, 0)
@@ -286,6 +323,7 @@ func (d *Deployer) newCluster(ctx context.Context, def *clusterdef.Cluster) (*cl
 			d.logger.Info("deploying", zap.Any("nodeGrp", nodeGrp))
 
 			image := nodeGrpImages[nodeGrpIdx]
+			resources := nodeGrpResources[nodeGrpIdx]
 
 			deployOpts := &DeployNodeOptions{
 				Purpose:            def.Purpose,
@@ -297,6 +335,9 @@ func (d *Deployer) newCluster(ctx context.Context, def *clusterdef.Cluster) (*cl
 				Expiry:             def.Expiry,
 				EnvVars:            nodeGrp.Docker.EnvVars,
 				UseDinoCerts:       def.Docker.UseDinoCerts,
+				Resources:          resources,
+				Persistent:         def.Docker.Persistent,
+				DnsServers:         dnsServers,
 			}
 
 			nodeOpts = append(nodeOpts, deployOpts)
@@ -343,11 +384,22 @@ func (d *Deployer) newCluster(ctx context.Context, def *clusterdef.Cluster) (*cl
 		d.logger.Info("setting up dinocert certificates", zap.String("cluster", clusterID))
 
 		for _, node := range nodes {
-			err := d.setupNodeCertificates(ctx, node, clusterCa, rootCaPem)
+			err := d.setupNodeCertificates(ctx, node, clusterCa, rootCaPem, &dinoCertState)
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to setup node certificates")
 			}
 		}
+
+		// the key settings are needed to issue certificates to nodes which
+		// are added later, so they are recorded with the cluster.
+		if dinoCertState != (DinoCertState{}) {
+			for _, node := range thisCluster.Nodes {
+				err := d.controller.UpdateDinoCertState(ctx, node.ContainerID, &dinoCertState)
+				if err != nil {
+					return nil, errors.Wrap(err, "failed to update node certificate state")
+				}
+			}
+		}
 	}
 
 	if useDns {
@@ -364,7 +416,7 @@ func (d *Deployer) newCluster(ctx context.Context, def *clusterdef.Cluster) (*cl
 	}
 
 	if nginxContainerId != "" {
-		err = d.updatePassiveLoadBalancer(ctx, nginxContainerId, thisCluster.Nodes, useDinoCerts, def.Columnar)
+		err = d.updatePassiveLoadBalancer(ctx, nginxContainerId, thisCluster.Nodes, def.Columnar, useDinoCerts)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to update passive load balancer")
 		}
@@ -560,39 +612,7 @@ func (d *Deployer) newCluster(ctx context.Context, def *clusterdef.Cluster) (*cl
 	if def.Docker.EnableJwt {
 		d.logger.Info("enabling jwt authentication")
 
-		nodeCtrl := clustercontrol.NodeManager{
-			Logger:   d.logger,
-			Endpoint: fmt.Sprintf("http://%s:8091", thisCluster.Nodes[0].IPAddress),
-		}
-
-		rootCa, err := dinocerts.GetRootCertAuthority()
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to get dinocluster cert authority")
-		}
-
-		pubKeyPem, err := rootCa.GetRS256PublicKeyPem()
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to get RS256 public key")
-		}
-
-		err = nodeCtrl.Controller().SetupJwtAuth(ctx, &clustercontrol.SetupJwtAuthOptions{
-			Enabled: true,
-			Issuers: []clustercontrol.SetupJwtOptions_Issuer{
-				{
-					Name:             "dino",
-					SigningAlgorithm: "RS256",
-					AudienceHandling: "all",
-					SubClaim:         "sub",
-					AudClaim:         "aud",
-					Audiences:        []string{"client"},
-					RolesClaim:       "roles",
-					RolesMaps:        []string{"(.*) \\1"},
-					PublicKeySource:  "pem",
-					PublicKey:        string(pubKeyPem),
-					JitProvisioning:  true,
-				},
-			},
-		})
+		err := d.setupClusterJwt(ctx, thisCluster)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to setup jwt authentication")
 		}
@@ -622,6 +642,16 @@ func (d *Deployer) addRemoveNodes(
 		Endpoint: fmt.Sprintf("http://%s:8091", ctrlNode.IPAddress),
 	}
 
+	nodesToAddResources := make([]NodeResources, len(nodesToAdd))
+	for nodeGrpIdx, nodeGrp := range nodesToAdd {
+		resources, err := ParseNodeResources(nodeGrp.Docker)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid node resources")
+		}
+
+		nodesToAddResources[nodeGrpIdx] = resources
+	}
+
 	d.logger.Info("gathering node images")
 
 	nodesToAddImages, err := d.getImagesForNodeGrps(ctx, nodesToAdd, clusterInfo.IsColumnar())
@@ -629,12 +659,18 @@ func (d *Deployer) addRemoveNodes(
 		return nil, errors.Wrap(err, "failed to fetch images")
 	}
 
+	dnsServers, err := d.getNodeDnsServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	d.logger.Info("deploying new node containers")
 
 	var deployedNodes []*ContainerInfo
 	var setupNodeOpts []*clustercontrol.AddNodeOptions
 	for nodeGrpIdx, nodeGrp := range nodesToAdd {
 		image := nodesToAddImages[nodeGrpIdx]
+		resources := nodesToAddResources[nodeGrpIdx]
 
 		deployOpts := &DeployNodeOptions{
 			Purpose:            clusterInfo.Purpose,
@@ -646,6 +682,11 @@ func (d *Deployer) addRemoveNodes(
 			Expiry:             time.Until(clusterInfo.Expiry),
 			EnvVars:            nodeGrp.Docker.EnvVars,
 			UseDinoCerts:       clusterInfo.UsingDinoCerts,
+			Resources:          resources,
+			Persistent:         clusterInfo.Persistent,
+			DnsServers:         dnsServers,
+
+			AddressTranslations: clusterInfo.AddressTranslations,
 		}
 
 		d.logger.Info("deploying node", zap.Any("deployOpts", deployOpts))
@@ -674,16 +715,26 @@ func (d *Deployer) addRemoveNodes(
 	if clusterInfo.UsingDinoCerts {
 		d.logger.Info("setting up dinocert certificates", zap.String("cluster", clusterInfo.ClusterID))
 
-		clusterCa, rootCaPem, err := d.getClusterDinoCert(clusterInfo.ClusterID)
+		dinoCerts := clusterInfo.DinoCerts
+		clusterCa, rootCaPem, err := d.getClusterDinoCert(clusterInfo.ClusterID, dinoCerts.CaGeneration)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to get cluster dino ca")
 		}
 
 		for _, node := range deployedNodes {
-			err := d.setupNodeCertificates(ctx, node, clusterCa, rootCaPem)
+			err := d.setupNodeCertificates(ctx, node, clusterCa, rootCaPem, &dinoCerts)
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to setup node certificates")
 			}
+
+			// new nodes need to know about earlier rotations and the key
+			// settings, since the cluster state is read from any of its nodes.
+			if dinoCerts != (DinoCertState{}) {
+				err := d.controller.UpdateDinoCertState(ctx, node.ContainerID, &dinoCerts)
+				if err != nil {
+					return nil, errors.Wrap(err, "failed to update node certificate state")
+				}
+			}
 		}
 	}
 
@@ -717,7 +768,7 @@ func (d *Deployer) addRemoveNodes(
 
 		for _, node := range clusterInfo.Nodes {
 			if node.IsActiveLoadBalancerNode() {
-				err = d.updateActiveLoadBalancer(ctx, node.ContainerID, thisCluster.Nodes, clusterInfo.IsColumnar(), clusterInfo.UsingDinoCerts)
+				err = d.updateActiveLoadBalancer(ctx, node.ContainerID, thisCluster.Nodes, clusterInfo.UsingDinoCerts, clusterInfo.IsColumnar())
 				if err != nil {
 					return nil, errors.Wrap(err, "failed to update active load balancer")
 				}
@@ -784,7 +835,7 @@ func (d *Deployer) addRemoveNodes(
 
 			for _, node := range clusterInfo.Nodes {
 				if node.IsPassiveLoadBalancerNode() {
-					err := d.updatePassiveLoadBalancer(ctx, node.ContainerID, thisCluster.Nodes, clusterInfo.UsingDinoCerts, clusterInfo.IsColumnar())
+					err := d.updatePassiveLoadBalancer(ctx, node.ContainerID, thisCluster.Nodes, clusterInfo.IsColumnar(), clusterInfo.UsingDinoCerts)
 					if err != nil {
 						return nil, errors.Wrap(err, "failed to update passive load balancer")
 					}
@@ -0,0 +1,258 @@
+package dockerdeploy
+
+import (
+	"context"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// DefaultPoolIdleExpiry is how long an unclaimed pool cluster is kept before
+// it expires, unless a pool manager keeps extending it.
+const DefaultPoolIdleExpiry = 24 * time.Hour
+
+// ErrPoolEmpty is returned when a pool has no ready clusters to claim.
+var ErrPoolEmpty = errors.New("pool has no ready clusters")
+
+type PoolCluster struct {
+	ClusterID string
+	Pool      string
+	Claimed   bool
+	State     string
+	Expiry    time.Time
+}
+
+func poolIdlePurpose(pool string) string {
+	return "idle in pool " + pool
+}
+
+// poolClaimNode returns the node whose claim marker guards the cluster.
+func poolClaimNode(cluster *clusterInfo) *nodeInfo {
+	for _, node := range cluster.Nodes {
+		if node.IsClusterNode() {
+			return node
+		}
+	}
+	return nil
+}
+
+// ListPoolClusters lists the clusters belonging to a pool, including claimed
+// ones.  An empty pool name lists the clusters of every pool.
+func (d *Deployer) ListPoolClusters(ctx context.Context, pool string) ([]*PoolCluster, error) {
+	clusters, err := d.listClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*PoolCluster
+	for _, cluster := range clusters {
+		if cluster.Pool == "" || (pool != "" && cluster.Pool != pool) {
+			continue
+		}
+
+		out = append(out, &PoolCluster{
+			ClusterID: cluster.ClusterID,
+			Pool:      cluster.Pool,
+			Claimed:   cluster.PoolClaimed,
+			State:     cluster.State(),
+			Expiry:    cluster.Expiry,
+		})
+	}
+
+	return out, nil
+}
+
+func (d *Deployer) updateClusterPoolState(ctx context.Context, cluster *clusterInfo, opts *UpdatePoolStateOptions) error {
+	for _, node := range cluster.Nodes {
+		err := d.controller.UpdatePoolState(ctx, node.ContainerID, opts)
+		if err != nil {
+			return errors.Wrap(err, "failed to update node pool state")
+		}
+	}
+
+	return nil
+}
+
+// AddPoolCluster allocates a new unclaimed cluster in a pool.
+func (d *Deployer) AddPoolCluster(ctx context.Context, pool string, def *clusterdef.Cluster, idleExpiry time.Duration) (deployment.ClusterInfo, error) {
+	def.Purpose = poolIdlePurpose(pool)
+	def.Expiry = idleExpiry
+
+	cluster, err := d.newCluster(ctx, def)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to allocate pool cluster")
+	}
+
+	err = d.updateClusterPoolState(ctx, cluster, &UpdatePoolStateOptions{
+		Pool:   pool,
+		Expiry: time.Now().Add(idleExpiry),
+	})
+	if err != nil {
+		d.logger.Warn("failed to add cluster to pool, removing it",
+			zap.String("cluster", cluster.ClusterID),
+			zap.Error(err))
+
+		removeErr := d.RemoveCluster(ctx, cluster.ClusterID)
+		if removeErr != nil {
+			d.logger.Warn("failed to remove cluster", zap.Error(removeErr))
+		}
+
+		return nil, err
+	}
+
+	return d.clusterInfoFromCluster(cluster), nil
+}
+
+// ClaimPoolCluster claims a ready cluster from a pool, giving it a purpose and
+// expiry like a freshly allocated cluster.  Returns ErrPoolEmpty if there are
+// no clusters available to claim.
+func (d *Deployer) ClaimPoolCluster(ctx context.Context, pool string, purpose string, expiry time.Duration) (deployment.ClusterInfo, error) {
+	clusters, err := d.listClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cluster := range clusters {
+		if cluster.Pool != pool || cluster.PoolClaimed || cluster.State() != "ready" {
+			continue
+		}
+
+		claimNode := poolClaimNode(cluster)
+		if claimNode == nil {
+			continue
+		}
+
+		claimed, err := d.controller.TryClaimNode(ctx, claimNode.ContainerID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to claim pool cluster")
+		}
+		if !claimed {
+			// another process claimed it first
+			continue
+		}
+
+		expiryTime := time.Time{}
+		if expiry > 0 {
+			expiryTime = time.Now().Add(expiry)
+		}
+
+		err = d.updateClusterPoolState(ctx, cluster, &UpdatePoolStateOptions{
+			Pool:    pool,
+			Claimed: true,
+			Purpose: purpose,
+			Expiry:  expiryTime,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		d.logger.Info("claimed cluster from pool",
+			zap.String("pool", pool),
+			zap.String("cluster", cluster.ClusterID))
+
+		claimedCluster, err := d.getCluster(ctx, cluster.ClusterID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get claimed cluster info")
+		}
+
+		return d.clusterInfoFromCluster(claimedCluster), nil
+	}
+
+	return nil, ErrPoolEmpty
+}
+
+// ReturnPoolCluster resets a claimed pool cluster to a clean state, removing
+// its buckets, users and any chaos applied to it, and returns it to its pool
+// to be claimed again.
+func (d *Deployer) ReturnPoolCluster(ctx context.Context, clusterID string, idleExpiry time.Duration) error {
+	cluster, err := d.getCluster(ctx, clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster info")
+	}
+
+	if cluster.Pool == "" {
+		return errors.New("cluster was not allocated from a pool")
+	}
+	if !cluster.PoolClaimed {
+		return errors.New("cluster has not been claimed from its pool")
+	}
+	if cluster.State() != "ready" {
+		return errors.New("cannot return a cluster which is not running")
+	}
+
+	var nodeIDs []string
+	var pausedNodeIDs []string
+	for _, node := range cluster.Nodes {
+		if node.IsClusterNode() {
+			nodeIDs = append(nodeIDs, node.NodeID)
+			if node.State == "paused" {
+				pausedNodeIDs = append(pausedNodeIDs, node.NodeID)
+			}
+		}
+	}
+
+	d.logger.Info("resetting cluster", zap.String("cluster", clusterID))
+
+	if len(pausedNodeIDs) > 0 {
+		err = d.UnpauseNode(ctx, clusterID, pausedNodeIDs)
+		if err != nil {
+			return errors.Wrap(err, "failed to unpause nodes")
+		}
+	}
+
+	err = d.AllowNodeTraffic(ctx, clusterID, nodeIDs)
+	if err != nil {
+		return errors.Wrap(err, "failed to reset node traffic")
+	}
+
+	buckets, err := d.ListBuckets(ctx, clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to list buckets")
+	}
+
+	for _, bucket := range buckets {
+		err := d.DeleteBucket(ctx, clusterID, bucket.Name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete bucket %s", bucket.Name)
+		}
+	}
+
+	users, err := d.ListUsers(ctx, clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to list users")
+	}
+
+	for _, user := range users {
+		err := d.DeleteUser(ctx, clusterID, user.Username)
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete user %s", user.Username)
+		}
+	}
+
+	err = d.updateClusterPoolState(ctx, cluster, &UpdatePoolStateOptions{
+		Pool:   cluster.Pool,
+		Expiry: time.Now().Add(idleExpiry),
+	})
+	if err != nil {
+		return err
+	}
+
+	// the claim is released last, so the cluster cannot be claimed again
+	// until it has been fully reset.
+	claimNode := poolClaimNode(cluster)
+	if claimNode != nil {
+		err := d.controller.ReleaseNodeClaim(ctx, claimNode.ContainerID)
+		if err != nil {
+			return errors.Wrap(err, "failed to release cluster claim")
+		}
+	}
+
+	d.logger.Info("returned cluster to pool",
+		zap.String("pool", cluster.Pool),
+		zap.String("cluster", clusterID))
+
+	return nil
+}
@@ -0,0 +1,548 @@
+package dockerdeploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/utils/clustercontrol"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	snapshotManifestFile   = "snapshot.json"
+	snapshotDefinitionFile = "definition.yaml"
+)
+
+type SnapshotNode struct {
+	NodeID    string
+	Version   string
+	ImagePath string
+	IPAddress string
+	Services  []clusterdef.Service
+	Resources NodeResources
+	EnvVars   map[string]string `json:",omitempty"`
+	Archive   string
+}
+
+// SnapshotManifest describes a snapshot.  Couchbase identifies nodes by their
+// addresses, so the addresses of the nodes the snapshot was taken from are
+// translated to the addresses of the restored nodes.
+type SnapshotManifest struct {
+	Name            string
+	CreatedAt       time.Time
+	SourceClusterID string
+	Persistent      bool
+	DnsName         string         `json:",omitempty"`
+	UsingDinoCerts  bool           `json:",omitempty"`
+	DinoCerts       *DinoCertState `json:",omitempty"`
+	Nodes           []*SnapshotNode
+}
+
+func (m *SnapshotManifest) validate() error {
+	if m.SourceClusterID == "" {
+		return errors.New("snapshot has no cluster id")
+	}
+	if len(m.Nodes) == 0 {
+		return errors.New("snapshot has no nodes")
+	}
+
+	seenAddresses := make(map[string]bool)
+	for _, node := range m.Nodes {
+		if node.NodeID == "" || node.IPAddress == "" || node.Archive == "" {
+			return errors.New("snapshot node is missing its id, address or archive")
+		}
+		if seenAddresses[node.IPAddress] {
+			return fmt.Errorf("snapshot has several nodes with address %s", node.IPAddress)
+		}
+		seenAddresses[node.IPAddress] = true
+	}
+
+	return nil
+}
+
+func writeSnapshotManifest(snapshotPath string, manifest *SnapshotManifest) error {
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal snapshot manifest")
+	}
+
+	err = os.WriteFile(filepath.Join(snapshotPath, snapshotManifestFile), manifestBytes, 0644)
+	if err != nil {
+		return errors.Wrap(err, "failed to write snapshot manifest")
+	}
+
+	return nil
+}
+
+// ReadSnapshotManifest reads the manifest of the snapshot stored at snapshotPath.
+func ReadSnapshotManifest(snapshotPath string) (*SnapshotManifest, error) {
+	manifestBytes, err := os.ReadFile(filepath.Join(snapshotPath, snapshotManifestFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read snapshot manifest")
+	}
+
+	var manifest *SnapshotManifest
+	err = json.Unmarshal(manifestBytes, &manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse snapshot manifest")
+	}
+
+	return manifest, nil
+}
+
+// ListSnapshots lists the manifests of all the snapshots stored beneath rootPath.
+func ListSnapshots(rootPath string) ([]*SnapshotManifest, error) {
+	entries, err := os.ReadDir(rootPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(err, "failed to list snapshots directory")
+	}
+
+	var manifests []*SnapshotManifest
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		manifest, err := ReadSnapshotManifest(filepath.Join(rootPath, entry.Name()))
+		if err != nil {
+			// directories without a manifest are not snapshots
+			continue
+		}
+
+		manifests = append(manifests, manifest)
+	}
+
+	return manifests, nil
+}
+
+// DeleteSnapshot removes the snapshot stored at snapshotPath.
+func DeleteSnapshot(snapshotPath string) error {
+	_, err := ReadSnapshotManifest(snapshotPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to find snapshot")
+	}
+
+	err = os.RemoveAll(snapshotPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove snapshot")
+	}
+
+	return nil
+}
+
+func (d *Deployer) CreateSnapshot(ctx context.Context, clusterID string, snapshotPath string) (*SnapshotManifest, error) {
+	clusterInfo, err := d.getCluster(ctx, clusterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster info")
+	}
+
+	if clusterInfo.IsColumnar() {
+		return nil, deployment.NewNotSupportedError("snapshots of columnar clusters are not supported")
+	}
+	if clusterInfo.State() != "ready" {
+		return nil, errors.New("cannot snapshot a cluster which is not running")
+	}
+
+	clusterInfoEx, err := d.getClusterInfoEx(ctx, clusterInfo)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get extended cluster info")
+	}
+
+	def, err := d.GetDefinition(ctx, clusterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster definition")
+	}
+	def.Docker.Persistent = clusterInfo.Persistent
+
+	defStr, err := clusterdef.Stringify(def)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to stringify cluster definition")
+	}
+
+	manifest := &SnapshotManifest{
+		Name:            filepath.Base(snapshotPath),
+		CreatedAt:       time.Now(),
+		SourceClusterID: clusterID,
+		Persistent:      clusterInfo.Persistent,
+		DnsName:         clusterInfo.DnsName,
+		UsingDinoCerts:  clusterInfo.UsingDinoCerts,
+	}
+	if clusterInfo.UsingDinoCerts {
+		dinoCerts := clusterInfo.DinoCerts
+		manifest.DinoCerts = &dinoCerts
+	}
+
+	var snapshotNodes []*nodeInfoEx
+	for _, node := range clusterInfoEx.NodesEx {
+		if !node.IsClusterNode() {
+			continue
+		}
+
+		inspect, err := d.dockerCli.ContainerInspect(ctx, node.ContainerID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to inspect node container")
+		}
+
+		resources, err := d.controller.GetNodeResources(ctx, node.ContainerID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get node resources")
+		}
+
+		envVars, err := d.controller.GetNodeEnvVars(ctx, node.ContainerID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get node environment")
+		}
+
+		manifest.Nodes = append(manifest.Nodes, &SnapshotNode{
+			NodeID:    node.NodeID,
+			Version:   node.InitialServerVersion,
+			ImagePath: inspect.Config.Image,
+			IPAddress: node.IPAddress,
+			Services:  node.Services,
+			Resources: resources,
+			EnvVars:   envVars,
+			Archive:   fmt.Sprintf("node-%s.tar", node.NodeID),
+		})
+		snapshotNodes = append(snapshotNodes, node)
+	}
+
+	err = os.Mkdir(snapshotPath, 0755)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return nil, errors.New("a snapshot with that name already exists")
+		}
+		return nil, errors.Wrap(err, "failed to create snapshot directory")
+	}
+
+	snapshotComplete := false
+	defer func() {
+		if !snapshotComplete {
+			os.RemoveAll(snapshotPath)
+		}
+	}()
+
+	nodeCtrl, _, err := d.getNodeManager(ctx, clusterInfo)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get node manager")
+	}
+
+	d.logger.Info("waiting for running tasks to complete")
+
+	err = nodeCtrl.WaitForNoRunningTasks(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wait for running tasks")
+	}
+
+	// we stop couchbase on every node before exporting any of them so that
+	// the data directories are consistent with one another.
+	d.logger.Info("quiescing cluster")
+
+	defer func() {
+		for _, node := range snapshotNodes {
+			err := d.controller.StartCouchbase(ctx, node.ContainerID)
+			if err != nil {
+				d.logger.Warn("failed to restart couchbase after snapshot",
+					zap.String("node", node.NodeID),
+					zap.Error(err))
+			}
+		}
+
+		for _, node := range snapshotNodes {
+			nodeCtrl := &clustercontrol.NodeManager{
+				Logger:   d.logger,
+				Endpoint: fmt.Sprintf("http://%s:8091", node.IPAddress),
+			}
+			err := nodeCtrl.WaitForOnline(ctx)
+			if err != nil {
+				d.logger.Warn("failed to wait for node after snapshot",
+					zap.String("node", node.NodeID),
+					zap.Error(err))
+			}
+		}
+	}()
+
+	for _, node := range snapshotNodes {
+		err := d.controller.StopCouchbase(ctx, node.ContainerID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to stop couchbase")
+		}
+	}
+
+	for nodeIdx, node := range snapshotNodes {
+		d.logger.Info("exporting node data",
+			zap.String("node", node.NodeID))
+
+		archiveFile, err := os.Create(filepath.Join(snapshotPath, manifest.Nodes[nodeIdx].Archive))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create node archive")
+		}
+
+		err = d.controller.ExportNodeData(ctx, node.ContainerID, archiveFile)
+		archiveFile.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to export node data")
+		}
+	}
+
+	err = os.WriteFile(filepath.Join(snapshotPath, snapshotDefinitionFile), []byte(defStr), 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to write snapshot definition")
+	}
+
+	err = writeSnapshotManifest(snapshotPath, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotComplete = true
+	return manifest, nil
+}
+
+type RestoreSnapshotOptions struct {
+	Purpose string
+	Expiry  time.Duration
+}
+
+// snapshotDeployOptions returns the options to deploy each node of a snapshot
+// with as a node of a new cluster, in the order of the nodes of its manifest.
+func snapshotDeployOptions(
+	snapshotPath string,
+	manifest *SnapshotManifest,
+	clusterID string,
+	dnsName string,
+	purpose string,
+	expiry time.Duration,
+	dnsServers []string,
+) []*DeployNodeOptions {
+	var deployOpts []*DeployNodeOptions
+	for _, snapshotNode := range manifest.Nodes {
+		deployOpts = append(deployOpts, &DeployNodeOptions{
+			Purpose:   purpose,
+			Expiry:    expiry,
+			ClusterID: clusterID,
+			Image: &ImageRef{
+				ImagePath: snapshotNode.ImagePath,
+			},
+			ImageServerVersion: snapshotNode.Version,
+			DnsSuffix:          dnsName,
+			EnvVars:            snapshotNode.EnvVars,
+			UseDinoCerts:       manifest.UsingDinoCerts,
+			Resources:          snapshotNode.Resources,
+			Persistent:         manifest.Persistent,
+			DnsServers:         dnsServers,
+			DataArchivePath:    filepath.Join(snapshotPath, snapshotNode.Archive),
+		})
+	}
+	return deployOpts
+}
+
+// RestoreSnapshot restores a snapshot as a new cluster, which can be done as
+// many times as needed.  The nodes of the new cluster translate the addresses
+// of the nodes the snapshot was taken from, which the cluster data refers to,
+// to their own addresses.
+func (d *Deployer) RestoreSnapshot(ctx context.Context, snapshotPath string, opts *RestoreSnapshotOptions) (deployment.ClusterInfo, error) {
+	manifest, err := ReadSnapshotManifest(snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+
+	err = manifest.validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid snapshot manifest")
+	}
+
+	defBytes, err := os.ReadFile(filepath.Join(snapshotPath, snapshotDefinitionFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read snapshot definition")
+	}
+
+	def, err := clusterdef.Parse(defBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse snapshot definition")
+	}
+
+	purpose := def.Purpose
+	if opts.Purpose != "" {
+		purpose = opts.Purpose
+	}
+
+	expiry := def.Expiry
+	if opts.Expiry != 0 {
+		expiry = opts.Expiry
+	}
+
+	dnsServers, err := d.getNodeDnsServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterID := uuid.NewString()
+
+	var dnsName string
+	if manifest.DnsName != "" {
+		if d.dnsProvider == nil {
+			return nil, errors.New("cannot restore a snapshot of a cluster using dns, dns not configured")
+		}
+
+		dnsName = d.newClusterDnsName(clusterID)
+	}
+
+	d.logger.Debug("restoring snapshot to new cluster",
+		zap.String("snapshot", manifest.Name),
+		zap.String("id", clusterID))
+
+	leaveNodesAfterReturn := false
+	defer func() {
+		if !leaveNodesAfterReturn {
+			allNodes, _ := d.controller.ListNodes(ctx)
+			for _, node := range allNodes {
+				if node.ClusterID == clusterID {
+					d.controller.RemoveNode(ctx, node.ContainerID)
+				}
+			}
+		}
+	}()
+
+	// couchbase identifies nodes by the address they had when the snapshot
+	// was taken, so we map those addresses to the new nodes.
+	addrs := make(map[string]string)
+	var nodes []*ContainerInfo
+	deployOpts := snapshotDeployOptions(snapshotPath, manifest, clusterID, dnsName, purpose, expiry, dnsServers)
+	for nodeIdx, snapshotNode := range manifest.Nodes {
+		d.logger.Info("restoring node",
+			zap.String("snapshotNode", snapshotNode.NodeID))
+
+		node, err := d.controller.DeployNode(ctx, deployOpts[nodeIdx])
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to deploy restored node")
+		}
+
+		addrs[snapshotNode.IPAddress] = node.IPAddress
+		nodes = append(nodes, node)
+	}
+
+	for _, node := range nodes {
+		err := d.controller.TranslateAddresses(ctx, node.ContainerID, addrs)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to setup node address translation")
+		}
+	}
+
+	// clients need to be told the new addresses of the nodes
+	for _, node := range nodes {
+		nodeCtrl := &clustercontrol.NodeManager{
+			Logger:   d.logger,
+			Endpoint: fmt.Sprintf("http://%s:8091", node.IPAddress),
+		}
+
+		err := nodeCtrl.Controller().SetupAlternateAddresses(ctx, &clustercontrol.SetupAlternateAddressesOptions{
+			Hostname: node.IPAddress,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to setup node alternate address")
+		}
+	}
+
+	d.logger.Info("waiting for restored cluster to become healthy")
+
+	for _, node := range nodes {
+		nodeCtrl := &clustercontrol.NodeManager{
+			Logger:   d.logger,
+			Endpoint: fmt.Sprintf("http://%s:8091", node.IPAddress),
+		}
+
+		for {
+			localInfo, err := nodeCtrl.Controller().GetLocalInfo(ctx)
+			if err == nil && localInfo.Status == "healthy" {
+				break
+			}
+
+			select {
+			case <-time.After(1 * time.Second):
+			case <-ctx.Done():
+				return nil, errors.Wrap(ctx.Err(), "context finished while waiting for restored node")
+			}
+		}
+	}
+
+	if manifest.UsingDinoCerts {
+		err := d.restoreSnapshotCertificates(ctx, clusterID, manifest, nodes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	thisCluster, err := d.getCluster(ctx, clusterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get restored cluster info")
+	}
+
+	if dnsName != "" {
+		// since this is a net-new domain name, no need to wait for dns propagation
+		err := d.updateDnsRecords(ctx, dnsName, thisCluster.Nodes, thisCluster.IsColumnar(), thisCluster.LoadBalancerIPAddress(), true)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to update dns records")
+		}
+	}
+
+	leaveNodesAfterReturn = true
+	return d.clusterInfoFromCluster(thisCluster), nil
+}
+
+// restoreSnapshotCertificates replaces the certificates restored along with
+// the node data, which belong to the cluster the snapshot was taken from, with
+// ones derived from the id of the restored cluster.  The key settings of the
+// source cluster are kept, but not its rotations.
+func (d *Deployer) restoreSnapshotCertificates(
+	ctx context.Context,
+	clusterID string,
+	manifest *SnapshotManifest,
+	nodes []*ContainerInfo,
+) error {
+	d.logger.Info("setting up dinocert certificates", zap.String("cluster", clusterID))
+
+	var dinoCerts DinoCertState
+	if manifest.DinoCerts != nil {
+		dinoCerts.Key = manifest.DinoCerts.Key
+	}
+
+	clusterCa, rootCaPem, err := d.getClusterDinoCert(clusterID, 0)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster dino ca")
+	}
+
+	for _, node := range nodes {
+		chainPem, keyPem, err := makeDinoCertChain(clusterCa, nodeDinoCertOptions(node, &dinoCerts))
+		if err != nil {
+			return errors.Wrap(err, "failed to create server certificate")
+		}
+
+		// the default certificate was already removed from the source cluster,
+		// so unlike a new node, there is nothing else to clean up.
+		err = d.installNodeCertificates(ctx, node, chainPem, keyPem, dinoCerts.Key.Passphrase, [][]byte{rootCaPem})
+		if err != nil {
+			return errors.Wrap(err, "failed to setup node certificates")
+		}
+
+		if dinoCerts != (DinoCertState{}) {
+			err := d.controller.UpdateDinoCertState(ctx, node.ContainerID, &dinoCerts)
+			if err != nil {
+				return errors.Wrap(err, "failed to update node certificate state")
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package dockerdeploy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/stretchr/testify/require"
+)
+
+func testSnapshotManifest(name string) *SnapshotManifest {
+	return &SnapshotManifest{
+		Name:            name,
+		CreatedAt:       time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		SourceClusterID: "4a7f7c58-7a4e-4bd0-9d43-4bd1b1f0c8a1",
+		Persistent:      true,
+		DnsName:         "4a7f7c.cbdino.local",
+		UsingDinoCerts:  true,
+		DinoCerts: &DinoCertState{
+			Rotation:     2,
+			CaGeneration: 1,
+		},
+		Nodes: []*SnapshotNode{
+			{
+				NodeID:    "a1b2c3d4e5f6",
+				Version:   "7.6.2",
+				ImagePath: "couchbase/server:7.6.2",
+				IPAddress: "172.18.0.2",
+				Services:  []clusterdef.Service{clusterdef.KvService, clusterdef.QueryService},
+				Resources: NodeResources{Memory: 2 * 1024 * 1024 * 1024},
+				EnvVars:   map[string]string{"CB_DEBUG": "1"},
+				Archive:   "node-a1b2c3d4e5f6.tar",
+			},
+			{
+				NodeID:    "f6e5d4c3b2a1",
+				Version:   "7.6.2",
+				ImagePath: "couchbase/server:7.6.2",
+				IPAddress: "172.18.0.3",
+				Services:  []clusterdef.Service{clusterdef.KvService},
+				Archive:   "node-f6e5d4c3b2a1.tar",
+			},
+		},
+	}
+}
+
+func TestSnapshotManifestStore(t *testing.T) {
+	rootPath := t.TempDir()
+
+	manifest := testSnapshotManifest("first")
+	snapshotPath := filepath.Join(rootPath, "first")
+	require.NoError(t, os.MkdirAll(snapshotPath, 0755))
+	require.NoError(t, writeSnapshotManifest(snapshotPath, manifest))
+
+	readManifest, err := ReadSnapshotManifest(snapshotPath)
+	require.NoError(t, err)
+	require.Equal(t, manifest, readManifest)
+
+	// directories without a manifest are not listed
+	require.NoError(t, os.MkdirAll(filepath.Join(rootPath, "other"), 0755))
+
+	manifests, err := ListSnapshots(rootPath)
+	require.NoError(t, err)
+	require.Len(t, manifests, 1)
+	require.Equal(t, "first", manifests[0].Name)
+
+	require.Error(t, DeleteSnapshot(filepath.Join(rootPath, "other")))
+	require.NoError(t, DeleteSnapshot(snapshotPath))
+
+	manifests, err = ListSnapshots(rootPath)
+	require.NoError(t, err)
+	require.Empty(t, manifests)
+}
+
+func TestListSnapshotsMissingRoot(t *testing.T) {
+	manifests, err := ListSnapshots(filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	require.Empty(t, manifests)
+}
+
+func TestSnapshotManifestValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		modify  func(m *SnapshotManifest)
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			modify: func(m *SnapshotManifest) {},
+		},
+		{
+			name:    "no cluster id",
+			modify:  func(m *SnapshotManifest) { m.SourceClusterID = "" },
+			wantErr: true,
+		},
+		{
+			name:    "no nodes",
+			modify:  func(m *SnapshotManifest) { m.Nodes = nil },
+			wantErr: true,
+		},
+		{
+			name:    "node without address",
+			modify:  func(m *SnapshotManifest) { m.Nodes[1].IPAddress = "" },
+			wantErr: true,
+		},
+		{
+			name:    "duplicate address",
+			modify:  func(m *SnapshotManifest) { m.Nodes[1].IPAddress = m.Nodes[0].IPAddress },
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifest := testSnapshotManifest("test")
+			tc.modify(manifest)
+
+			err := manifest.validate()
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSnapshotDeployOptions(t *testing.T) {
+	manifest := testSnapshotManifest("test")
+
+	deployOpts := snapshotDeployOptions("/snapshots/test", manifest, "c0ffee00-1111-2222-3333-444455556666", "c0ffee00-20240501.cbdino.local", "testing", time.Hour, []string{"172.18.0.100"})
+	require.Len(t, deployOpts, 2)
+
+	for nodeIdx, opts := range deployOpts {
+		snapshotNode := manifest.Nodes[nodeIdx]
+
+		// nodes are restored as new nodes of a new cluster
+		require.Equal(t, "c0ffee00-1111-2222-3333-444455556666", opts.ClusterID)
+		require.Empty(t, opts.NodeID)
+		require.Empty(t, opts.IPAddress)
+		require.Equal(t, "c0ffee00-20240501.cbdino.local", opts.DnsSuffix)
+
+		require.Equal(t, "testing", opts.Purpose)
+		require.Equal(t, time.Hour, opts.Expiry)
+		require.Equal(t, snapshotNode.ImagePath, opts.Image.ImagePath)
+		require.Equal(t, snapshotNode.Version, opts.ImageServerVersion)
+		require.Equal(t, snapshotNode.Resources, opts.Resources)
+		require.Equal(t, snapshotNode.EnvVars, opts.EnvVars)
+		require.True(t, opts.UseDinoCerts)
+		require.True(t, opts.Persistent)
+		require.Equal(t, []string{"172.18.0.100"}, opts.DnsServers)
+		require.Equal(t, filepath.Join("/snapshots/test", snapshotNode.Archive), opts.DataArchivePath)
+	}
+}
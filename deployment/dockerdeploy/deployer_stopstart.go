@@ -0,0 +1,135 @@
+package dockerdeploy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/couchbaselabs/cbdinocluster/utils/clustercontrol"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+func (d *Deployer) StopCluster(ctx context.Context, clusterID string) error {
+	clusterInfo, err := d.getCluster(ctx, clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster info")
+	}
+
+	// non-persistent nodes are auto-removed by docker when they stop, so
+	// stopping them would destroy the cluster rather than pausing it.
+	for _, node := range clusterInfo.Nodes {
+		if node.IsClusterNode() && node.DataVolume == "" {
+			return errors.New("cannot stop a cluster which was not deployed with persistent storage")
+		}
+	}
+
+	for _, node := range clusterInfo.Nodes {
+		if !node.IsClusterNode() || !node.IsRunning() {
+			continue
+		}
+
+		d.logger.Info("stopping node",
+			zap.String("id", node.NodeID),
+			zap.String("container", node.ContainerID))
+
+		err := d.controller.StopNode(ctx, node.ContainerID)
+		if err != nil {
+			return errors.Wrap(err, "failed to stop node")
+		}
+	}
+
+	return nil
+}
+
+func (d *Deployer) StartCluster(ctx context.Context, clusterID string) error {
+	clusterInfo, err := d.getCluster(ctx, clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster info")
+	}
+
+	oldAddresses := make(map[string]string)
+	for _, node := range clusterInfo.Nodes {
+		if !node.IsClusterNode() || node.IsRunning() {
+			continue
+		}
+
+		d.logger.Info("starting node",
+			zap.String("id", node.NodeID),
+			zap.String("container", node.ContainerID))
+
+		nodeState, err := d.controller.ReadNodeState(ctx, node.ContainerID)
+		if err == nil && nodeState != nil {
+			oldAddresses[node.ContainerID] = nodeState.IPAddress
+		}
+
+		err = d.controller.StartNode(ctx, node.ContainerID)
+		if err != nil {
+			return errors.Wrap(err, "failed to start node")
+		}
+	}
+
+	if len(oldAddresses) == 0 {
+		return nil
+	}
+
+	// refetch the cluster now that the nodes have been assigned addresses
+	clusterInfo, err = d.getCluster(ctx, clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get updated cluster info")
+	}
+
+	addressesChanged := false
+	for _, node := range clusterInfo.Nodes {
+		oldAddress, wasStarted := oldAddresses[node.ContainerID]
+		if !wasStarted {
+			continue
+		}
+
+		if oldAddress != node.IPAddress {
+			d.logger.Warn("node address changed during start",
+				zap.String("id", node.NodeID),
+				zap.String("old", oldAddress),
+				zap.String("new", node.IPAddress))
+			addressesChanged = true
+		}
+
+		d.logger.Info("waiting for node readiness",
+			zap.String("id", node.NodeID),
+			zap.String("address", node.IPAddress))
+
+		nodeCtrl := &clustercontrol.NodeManager{
+			Logger:   d.logger,
+			Endpoint: fmt.Sprintf("http://%s:8091", node.IPAddress),
+		}
+
+		err := nodeCtrl.WaitForOnline(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to wait for node readiness")
+		}
+	}
+
+	if addressesChanged {
+		if clusterInfo.DnsName != "" {
+			err := d.updateDnsRecords(ctx, clusterInfo.DnsName, clusterInfo.Nodes, clusterInfo.IsColumnar(), clusterInfo.LoadBalancerIPAddress(), false)
+			if err != nil {
+				return errors.Wrap(err, "failed to update dns records")
+			}
+		}
+
+		for _, node := range clusterInfo.Nodes {
+			if node.IsPassiveLoadBalancerNode() {
+				err := d.updatePassiveLoadBalancer(ctx, node.ContainerID, clusterInfo.Nodes, clusterInfo.IsColumnar(), clusterInfo.UsingDinoCerts)
+				if err != nil {
+					return errors.Wrap(err, "failed to update passive load balancer")
+				}
+			} else if node.IsActiveLoadBalancerNode() {
+				err := d.updateActiveLoadBalancer(ctx, node.ContainerID, clusterInfo.Nodes, clusterInfo.UsingDinoCerts, clusterInfo.IsColumnar())
+				if err != nil {
+					return errors.Wrap(err, "failed to update active load balancer")
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package dockerdeploy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrphanedVolumes(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	volumes := []*VolumeInfo{
+		{Name: "in-use", ClusterID: "cluster-a", CreatedAt: now.Add(-time.Hour)},
+		{Name: "orphaned", ClusterID: "cluster-b", CreatedAt: now.Add(-time.Hour)},
+		// the container of a node is created after its volume
+		{Name: "deploying", ClusterID: "cluster-c", CreatedAt: now.Add(-time.Minute)},
+		{Name: "unknown-age", ClusterID: "cluster-d"},
+	}
+	nodes := []*ContainerInfo{
+		{ClusterID: "cluster-a"},
+	}
+
+	var names []string
+	for _, volume := range orphanedVolumes(volumes, nodes, now) {
+		names = append(names, volume.Name)
+	}
+	require.Equal(t, []string{"orphaned", "unknown-age"}, names)
+}
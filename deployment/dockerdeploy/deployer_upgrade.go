@@ -0,0 +1,461 @@
+package dockerdeploy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/utils/clustercontrol"
+	"github.com/couchbaselabs/cbdinocluster/utils/versionident"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type UpgradeMode string
+
+const (
+	// UpgradeModeSwap adds a node running the new version for each existing
+	// node and swap-rebalances the old node out of the cluster.
+	UpgradeModeSwap UpgradeMode = "swap"
+
+	// UpgradeModeDelta fails each node over, recreates its container on the new
+	// version using its existing data and then delta-recovers it back into the
+	// cluster.  This requires a persistent cluster.
+	UpgradeModeDelta UpgradeMode = "delta"
+)
+
+type UpgradeStage string
+
+const (
+	UpgradeStageSwapping   UpgradeStage = "swapping"
+	UpgradeStageFailover   UpgradeStage = "failing-over"
+	UpgradeStageRecreating UpgradeStage = "recreating"
+	UpgradeStageRecovering UpgradeStage = "recovering"
+	UpgradeStageCompleted  UpgradeStage = "completed"
+)
+
+type UpgradeProgress struct {
+	NodeIndex int
+	NumNodes  int
+	NodeID    string
+	NewNodeID string
+	Stage     UpgradeStage
+}
+
+type UpgradeClusterOptions struct {
+	// Version is the server version to upgrade to.
+	Version string
+
+	// Image optionally overrides the docker image used for the new nodes.
+	Image string
+
+	// FromVersion limits the upgrade to nodes that were deployed with this
+	// version, by default all nodes not already on Version are upgraded.
+	FromVersion string
+
+	Mode UpgradeMode
+
+	// OnProgress is invoked each time an individual node changes stage.
+	OnProgress func(progress *UpgradeProgress)
+}
+
+func (d *Deployer) UpgradeCluster(ctx context.Context, clusterID string, CurrentImages string, NewImage string) error {
+	fromVersion, _, err := parseUpgradeTarget(ctx, CurrentImages)
+	if err != nil {
+		return errors.Wrap(err, "invalid current image")
+	}
+
+	version, image, err := parseUpgradeTarget(ctx, NewImage)
+	if err != nil {
+		return errors.Wrap(err, "invalid new image")
+	}
+
+	return d.UpgradeClusterWithOptions(ctx, clusterID, &UpgradeClusterOptions{
+		Version:     version,
+		Image:       image,
+		FromVersion: fromVersion,
+		Mode:        UpgradeModeSwap,
+	})
+}
+
+// parseUpgradeTarget accepts either a server version or a docker image, and
+// returns the server version along with the image if one was given.  The
+// version of an image is identified from its tag.
+func parseUpgradeTarget(ctx context.Context, target string) (string, string, error) {
+	if !strings.ContainsAny(target, "/:") {
+		return target, "", nil
+	}
+
+	tagIdx := strings.LastIndex(target, ":")
+	if tagIdx < strings.LastIndex(target, "/") {
+		return "", "", fmt.Errorf("image %s has no tag to identify its version from", target)
+	}
+
+	versionInfo, err := versionident.Identify(ctx, target[tagIdx+1:])
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to identify the version of image %s", target)
+	}
+
+	version := versionInfo.Version
+	if versionInfo.BuildNo != 0 {
+		version = fmt.Sprintf("%s-%d", version, versionInfo.BuildNo)
+	}
+	if versionInfo.CommunityEdition {
+		version = "community-" + version
+	}
+
+	return version, target, nil
+}
+
+func (d *Deployer) UpgradeClusterWithOptions(ctx context.Context, clusterID string, opts *UpgradeClusterOptions) error {
+	if opts.Version == "" {
+		return errors.New("a version to upgrade to must be specified")
+	}
+
+	mode := opts.Mode
+	if mode == "" {
+		mode = UpgradeModeSwap
+	}
+	if mode != UpgradeModeSwap && mode != UpgradeModeDelta {
+		return fmt.Errorf("unsupported upgrade mode `%s`", mode)
+	}
+
+	clusterInfo, err := d.getCluster(ctx, clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster info")
+	}
+
+	if mode == UpgradeModeDelta && !clusterInfo.Persistent {
+		return errors.New("delta recovery upgrades require a persistent cluster")
+	}
+
+	// we capture the list of nodes to upgrade up-front, since swap upgrades
+	// will replace the nodes as we go.
+	var nodeIDsToUpgrade []string
+	for _, node := range clusterInfo.Nodes {
+		if !node.IsClusterNode() {
+			continue
+		}
+		if node.InitialServerVersion == opts.Version && opts.Image == "" {
+			continue
+		}
+		if opts.FromVersion != "" && node.InitialServerVersion != opts.FromVersion {
+			continue
+		}
+
+		nodeIDsToUpgrade = append(nodeIDsToUpgrade, node.NodeID)
+	}
+
+	if len(nodeIDsToUpgrade) == 0 {
+		d.logger.Info("no nodes require upgrading")
+		return nil
+	}
+
+	images, err := d.getImagesForNodeGrps(ctx, []*clusterdef.NodeGroup{
+		{
+			Version: opts.Version,
+			Docker: clusterdef.DockerNodeGroup{
+				Image: opts.Image,
+			},
+		},
+	}, clusterInfo.IsColumnar())
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch upgrade image")
+	}
+	image := images[0]
+
+	for nodeIdx, nodeID := range nodeIDsToUpgrade {
+		progress := &UpgradeProgress{
+			NodeIndex: nodeIdx,
+			NumNodes:  len(nodeIDsToUpgrade),
+			NodeID:    nodeID,
+		}
+		reportStage := func(stage UpgradeStage) {
+			progress.Stage = stage
+
+			d.logger.Info("upgrading node",
+				zap.Int("node", nodeIdx+1),
+				zap.Int("of", len(nodeIDsToUpgrade)),
+				zap.String("id", nodeID),
+				zap.String("stage", string(stage)))
+
+			if opts.OnProgress != nil {
+				opts.OnProgress(progress)
+			}
+		}
+
+		if mode == UpgradeModeSwap {
+			reportStage(UpgradeStageSwapping)
+
+			newNodeID, err := d.swapUpgradeNode(ctx, clusterID, nodeID, opts.Version, opts.Image)
+			if err != nil {
+				return errors.Wrapf(err, "failed to swap upgrade node %s", nodeID)
+			}
+
+			progress.NewNodeID = newNodeID
+		} else {
+			newNodeID, err := d.deltaUpgradeNode(ctx, clusterID, nodeID, image, opts.Version, reportStage)
+			if err != nil {
+				return errors.Wrapf(err, "failed to delta upgrade node %s", nodeID)
+			}
+
+			progress.NewNodeID = newNodeID
+		}
+
+		reportStage(UpgradeStageCompleted)
+	}
+
+	return nil
+}
+
+func (d *Deployer) swapUpgradeNode(
+	ctx context.Context,
+	clusterID string,
+	nodeID string,
+	version string,
+	image string,
+) (string, error) {
+	clusterInfo, err := d.getCluster(ctx, clusterID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get cluster info")
+	}
+
+	clusterInfoEx, err := d.getClusterInfoEx(ctx, clusterInfo)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get extended cluster info")
+	}
+
+	nodeIdx := slices.IndexFunc(clusterInfoEx.NodesEx, func(node *nodeInfoEx) bool {
+		return node.NodeID == nodeID
+	})
+	if nodeIdx < 0 {
+		return "", errors.New("failed to find node to upgrade")
+	}
+	oldNode := clusterInfoEx.NodesEx[nodeIdx]
+
+	resources, err := d.controller.GetNodeResources(ctx, oldNode.ContainerID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get node resources")
+	}
+
+	envVars, err := d.controller.GetNodeEnvVars(ctx, oldNode.ContainerID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get node environment")
+	}
+
+	dockerDef := resources.ToDef()
+	dockerDef.Image = image
+	dockerDef.EnvVars = envVars
+
+	newNodeIDs, err := d.addRemoveNodes(ctx, clusterInfoEx, []*clusterdef.NodeGroup{
+		{
+			Count:    1,
+			Version:  version,
+			Services: oldNode.Services,
+			Docker:   dockerDef,
+		},
+	}, []*nodeInfoEx{oldNode})
+	if err != nil {
+		return "", err
+	}
+
+	if len(newNodeIDs) != 1 {
+		return "", errors.New("unexpected number of node ids returned")
+	}
+
+	return newNodeIDs[0], nil
+}
+
+func (d *Deployer) deltaUpgradeNode(
+	ctx context.Context,
+	clusterID string,
+	nodeID string,
+	image *ImageRef,
+	version string,
+	reportStage func(stage UpgradeStage),
+) (string, error) {
+	clusterInfo, err := d.getCluster(ctx, clusterID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get cluster info")
+	}
+
+	clusterInfoEx, err := d.getClusterInfoEx(ctx, clusterInfo)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get extended cluster info")
+	}
+
+	nodeIdx := slices.IndexFunc(clusterInfoEx.NodesEx, func(node *nodeInfoEx) bool {
+		return node.NodeID == nodeID
+	})
+	if nodeIdx < 0 {
+		return "", errors.New("failed to find node to upgrade")
+	}
+	oldNode := clusterInfoEx.NodesEx[nodeIdx]
+
+	if oldNode.DataVolume == "" {
+		return "", errors.New("node does not have persistent storage")
+	}
+	if oldNode.OTPNode == "" {
+		return "", errors.New("failed to identify node otp")
+	}
+
+	reportStage(UpgradeStageFailover)
+
+	// graceful failover is only possible for data nodes
+	failOverType := deployment.HardFailOver
+	if slices.Contains(oldNode.Services, clusterdef.KvService) {
+		failOverType = deployment.GracefulFailOver
+	}
+
+	err = d.FailOverNode(ctx, clusterID, nodeID, failOverType, false)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to fail over node")
+	}
+
+	reportStage(UpgradeStageRecreating)
+
+	resources, err := d.controller.GetNodeResources(ctx, oldNode.ContainerID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get node resources")
+	}
+
+	envVars, err := d.controller.GetNodeEnvVars(ctx, oldNode.ContainerID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get node environment")
+	}
+
+	dnsServers, err := d.getNodeDnsServers(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	err = d.controller.StopNode(ctx, oldNode.ContainerID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to stop node")
+	}
+
+	err = d.controller.RemoveNodeContainer(ctx, oldNode.ContainerID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to remove node container")
+	}
+
+	// the node must keep its address, since couchbase identifies nodes by it,
+	// so if the address was taken in the meantime we replace the node instead.
+	addressInUse, err := d.controller.IsAddressInUse(ctx, oldNode.IPAddress)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to check node address")
+	}
+	if addressInUse {
+		d.logger.Warn("node address was taken, replacing the node instead of recovering it",
+			zap.String("id", oldNode.NodeID),
+			zap.String("address", oldNode.IPAddress))
+
+		err := d.controller.RemoveVolume(ctx, oldNode.DataVolume)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to remove node data volume")
+		}
+
+		dockerDef := resources.ToDef()
+		dockerDef.Image = image.ImagePath
+		dockerDef.EnvVars = envVars
+
+		clusterInfo, err = d.getCluster(ctx, clusterID)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get cluster info")
+		}
+
+		clusterInfoEx, err = d.getClusterInfoEx(ctx, clusterInfo)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get extended cluster info")
+		}
+
+		// the failed over node is ejected by the rebalance which adds its
+		// replacement, since no recovery type was set for it
+		newNodeIDs, err := d.addRemoveNodes(ctx, clusterInfoEx, []*clusterdef.NodeGroup{
+			{
+				Count:    1,
+				Version:  version,
+				Services: oldNode.Services,
+				Docker:   dockerDef,
+			},
+		}, nil)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to replace node")
+		}
+
+		if len(newNodeIDs) != 1 {
+			return "", errors.New("unexpected number of node ids returned")
+		}
+
+		return newNodeIDs[0], nil
+	}
+
+	recreatedNode, err := d.controller.DeployNode(ctx, &DeployNodeOptions{
+		Purpose:            clusterInfo.Purpose,
+		Expiry:             time.Until(clusterInfo.Expiry),
+		ClusterID:          clusterInfo.ClusterID,
+		Image:              image,
+		ImageServerVersion: version,
+		IsColumnar:         clusterInfo.IsColumnar(),
+		DnsSuffix:          clusterInfo.DnsName,
+		UseDinoCerts:       clusterInfo.UsingDinoCerts,
+		EnvVars:            envVars,
+		Resources:          resources,
+		Persistent:         true,
+		DnsServers:         dnsServers,
+		NodeID:             oldNode.NodeID,
+		DataVolume:         oldNode.DataVolume,
+		IPAddress:          oldNode.IPAddress,
+
+		AddressTranslations: clusterInfo.AddressTranslations,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to recreate node")
+	}
+
+	reportStage(UpgradeStageRecovering)
+
+	recreatedNodeCtrl := &clustercontrol.NodeManager{
+		Logger:   d.logger,
+		Endpoint: fmt.Sprintf("http://%s:8091", recreatedNode.IPAddress),
+	}
+
+	err = recreatedNodeCtrl.WaitForOnline(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to wait for recreated node")
+	}
+
+	nodeCtrl, _, err := d.getNodeManager(ctx, clusterInfo)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get node manager")
+	}
+
+	err = nodeCtrl.Controller().SetRecovery(ctx, &clustercontrol.FailOverRecoveryType{
+		NodeOTPs:     []string{oldNode.OTPNode},
+		RecoveryType: string(deployment.DeltaRecovery),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to set delta recovery")
+	}
+
+	clusterInfo, err = d.getCluster(ctx, clusterID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get recreated cluster info")
+	}
+
+	clusterInfoEx, err = d.getClusterInfoEx(ctx, clusterInfo)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get recreated extended cluster info")
+	}
+
+	err = d.reconcileRebalance(ctx, clusterInfoEx, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to rebalance recovered node")
+	}
+
+	return nodeID, nil
+}
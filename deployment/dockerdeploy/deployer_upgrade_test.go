@@ -0,0 +1,49 @@
+package dockerdeploy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUpgradeTarget(t *testing.T) {
+	testCases := []struct {
+		target  string
+		version string
+		image   string
+	}{
+		{"7.6.2", "7.6.2", ""},
+		{"", "", ""},
+		{"couchbase:7.6.2", "7.6.2", "couchbase:7.6.2"},
+		{"couchbase/server:enterprise-7.6.2", "7.6.2", "couchbase/server:enterprise-7.6.2"},
+		{"couchbase/server:community-7.6.2", "community-7.6.2", "couchbase/server:community-7.6.2"},
+		{"ghcr.io/cb-vanilla/server:8.0.0-1234", "8.0.0-1234", "ghcr.io/cb-vanilla/server:8.0.0-1234"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.target, func(t *testing.T) {
+			version, image, err := parseUpgradeTarget(context.Background(), tc.target)
+			require.NoError(t, err)
+			require.Equal(t, tc.version, version)
+			require.Equal(t, tc.image, image)
+		})
+	}
+
+	_, _, err := parseUpgradeTarget(context.Background(), "localhost:5000/server")
+	require.Error(t, err)
+
+	_, _, err = parseUpgradeTarget(context.Background(), "couchbase/server:latest")
+	require.Error(t, err)
+}
+
+func TestNodeEnvVars(t *testing.T) {
+	envVars := nodeEnvVars(
+		[]string{"PATH=/usr/bin", "CB_FOO=bar", "EMPTY=", "LANG=fr_FR.UTF-8"},
+		[]string{"PATH=/usr/bin", "LANG=C.UTF-8"})
+	require.Equal(t, map[string]string{
+		"CB_FOO": "bar",
+		"EMPTY":  "",
+		"LANG":   "fr_FR.UTF-8",
+	}, envVars)
+}
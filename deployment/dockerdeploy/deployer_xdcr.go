@@ -0,0 +1,112 @@
+package dockerdeploy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/couchbaselabs/cbdinocluster/deployment/commondeploy"
+	"github.com/pkg/errors"
+)
+
+func (d *Deployer) getXdcrHelper(ctx context.Context, clusterID string) (*commondeploy.XdcrHelper, error) {
+	controller, err := d.getController(ctx, clusterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster controller")
+	}
+
+	return &commondeploy.XdcrHelper{
+		Controller: controller.Controller(),
+	}, nil
+}
+
+func (d *Deployer) GetXdcrTarget(ctx context.Context, clusterID string) (*deployment.XdcrTarget, error) {
+	clusterInfo, err := d.getCluster(ctx, clusterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster info")
+	}
+
+	// the nodes of other clusters share our docker network, so they can reach
+	// any node directly and discover the rest of the cluster from it.
+	var hostname string
+	for _, node := range clusterInfo.Nodes {
+		if node.IsClusterNode() {
+			hostname = fmt.Sprintf("%s:8091", node.IPAddress)
+			break
+		}
+	}
+	if hostname == "" {
+		return nil, errors.New("cluster has no nodes")
+	}
+
+	target := &deployment.XdcrTarget{
+		Hostname: hostname,
+		Username: "Administrator",
+		Password: "password",
+	}
+
+	if clusterInfo.UsingDinoCerts {
+		certPem, err := d.GetCertificate(ctx, clusterID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get cluster certificate")
+		}
+
+		target.Certificate = certPem
+	}
+
+	return target, nil
+}
+
+func (d *Deployer) ListXdcrRemotes(ctx context.Context, clusterID string) ([]deployment.XdcrRemoteInfo, error) {
+	helper, err := d.getXdcrHelper(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.ListXdcrRemotes(ctx)
+}
+
+func (d *Deployer) CreateXdcrRemote(ctx context.Context, clusterID string, opts *deployment.CreateXdcrRemoteOptions) error {
+	helper, err := d.getXdcrHelper(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	return helper.CreateXdcrRemote(ctx, opts)
+}
+
+func (d *Deployer) ListXdcrReplications(ctx context.Context, clusterID string) ([]deployment.XdcrReplicationInfo, error) {
+	helper, err := d.getXdcrHelper(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return helper.ListXdcrReplications(ctx)
+}
+
+func (d *Deployer) CreateXdcrReplication(ctx context.Context, clusterID string, opts *deployment.CreateXdcrReplicationOptions) (string, error) {
+	helper, err := d.getXdcrHelper(ctx, clusterID)
+	if err != nil {
+		return "", err
+	}
+
+	return helper.CreateXdcrReplication(ctx, opts)
+}
+
+func (d *Deployer) SetXdcrReplicationPaused(ctx context.Context, clusterID string, replicationID string, paused bool) error {
+	helper, err := d.getXdcrHelper(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	return helper.SetXdcrReplicationPaused(ctx, replicationID, paused)
+}
+
+func (d *Deployer) DeleteXdcrReplication(ctx context.Context, clusterID string, replicationID string) error {
+	helper, err := d.getXdcrHelper(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	return helper.DeleteXdcrReplication(ctx, replicationID)
+}
@@ -0,0 +1,207 @@
+package dockerdeploy
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// haproxyRuntimePort is the port the haproxy runtime api listens on.
+const haproxyRuntimePort = 9999
+
+var haproxyBalanceAlgorithms = []string{
+	"roundrobin", "static-rr", "leastconn", "first", "source", "random",
+}
+
+// ValidateLoadBalancerSettings checks the settings of an active load balancer
+// before it is deployed.
+func ValidateLoadBalancerSettings(settings *clusterdef.LoadBalancerSettings) error {
+	if settings.Balance != "" && !slices.Contains(haproxyBalanceAlgorithms, settings.Balance) {
+		return fmt.Errorf("unsupported balance algorithm `%s`, must be one of %s",
+			settings.Balance, strings.Join(haproxyBalanceAlgorithms, ", "))
+	}
+
+	if settings.ConnectTimeout < 0 || settings.ClientTimeout < 0 || settings.ServerTimeout < 0 {
+		return errors.New("load balancer timeouts must not be negative")
+	}
+	if settings.HealthCheckInterval < 0 {
+		return errors.New("load balancer health check interval must not be negative")
+	}
+	if settings.Retries < 0 || settings.HealthCheckFall < 0 || settings.HealthCheckRise < 0 {
+		return errors.New("load balancer retries and health check counts must not be negative")
+	}
+
+	return nil
+}
+
+func loadBalancerSettingsWithDefaults(settings *clusterdef.LoadBalancerSettings) *clusterdef.LoadBalancerSettings {
+	// this is configured to broadly match AWS Network Load Balancer defaults
+	out := *settings
+	if out.ConnectTimeout == 0 {
+		out.ConnectTimeout = 350 * time.Second
+	}
+	if out.ClientTimeout == 0 {
+		out.ClientTimeout = 350 * time.Second
+	}
+	if out.ServerTimeout == 0 {
+		out.ServerTimeout = 350 * time.Second
+	}
+	if out.Balance == "" {
+		out.Balance = "roundrobin"
+	}
+	if out.HealthCheckInterval == 0 {
+		out.HealthCheckInterval = 30 * time.Second
+	}
+	if out.HealthCheckFall == 0 {
+		out.HealthCheckFall = 2
+	}
+	if out.HealthCheckRise == 0 {
+		out.HealthCheckRise = 5
+	}
+	return &out
+}
+
+// haproxyDuration formats a duration the way haproxy expects it.
+func haproxyDuration(d time.Duration) string {
+	if d%time.Second == 0 {
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+	return fmt.Sprintf("%dms", d/time.Millisecond)
+}
+
+type HaproxyServerStat struct {
+	Backend     string
+	Server      string
+	Address     string
+	Status      string
+	CheckStatus string
+	Weight      int
+	Sessions    int
+}
+
+// parseHaproxyStats parses the output of the `show stat` runtime command,
+// returning only the servers and not the frontend and backend summaries.
+func parseHaproxyStats(output string) ([]*HaproxyServerStat, error) {
+	output = strings.TrimPrefix(strings.TrimSpace(output), "# ")
+
+	records, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse haproxy stats")
+	}
+	if len(records) == 0 {
+		return nil, errors.New("haproxy stats were empty")
+	}
+
+	columns := make(map[string]int)
+	for idx, name := range records[0] {
+		columns[name] = idx
+	}
+
+	for _, name := range []string{"pxname", "svname", "status", "weight", "check_status", "scur"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("haproxy stats are missing the %s column", name)
+		}
+	}
+
+	getField := func(record []string, name string) string {
+		idx, ok := columns[name]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return record[idx]
+	}
+
+	var servers []*HaproxyServerStat
+	for _, record := range records[1:] {
+		svname := getField(record, "svname")
+		if svname == "FRONTEND" || svname == "BACKEND" {
+			continue
+		}
+
+		weight, _ := strconv.Atoi(getField(record, "weight"))
+		sessions, _ := strconv.Atoi(getField(record, "scur"))
+
+		servers = append(servers, &HaproxyServerStat{
+			Backend:     getField(record, "pxname"),
+			Server:      svname,
+			Address:     getField(record, "addr"),
+			Status:      getField(record, "status"),
+			CheckStatus: getField(record, "check_status"),
+			Weight:      weight,
+			Sessions:    sessions,
+		})
+	}
+
+	return servers, nil
+}
+
+// execHaproxyCommand runs a command against the runtime api of the haproxy at
+// address, returning its response.
+func (c *Controller) execHaproxyCommand(ctx context.Context, address string, command string) (string, error) {
+	c.Logger.Debug("executing haproxy command",
+		zap.String("address", address),
+		zap.String("command", command))
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(address, strconv.Itoa(haproxyRuntimePort)))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to connect to haproxy runtime api")
+	}
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(10 * time.Second)
+	}
+	conn.SetDeadline(deadline)
+
+	_, err = conn.Write([]byte(command + "\n"))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to write haproxy command")
+	}
+
+	// haproxy closes the connection once it has responded to the command
+	resp, err := io.ReadAll(conn)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read haproxy response")
+	}
+
+	return string(resp), nil
+}
+
+func (c *Controller) GetHaproxyServers(ctx context.Context, address string) ([]*HaproxyServerStat, error) {
+	resp, err := c.execHaproxyCommand(ctx, address, "show stat")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseHaproxyStats(resp)
+}
+
+// SetHaproxyServer runs a `set server` command, such as `state drain` or
+// `weight 10`, against a server of a backend.
+func (c *Controller) SetHaproxyServer(ctx context.Context, address string, backend, server string, args string) error {
+	resp, err := c.execHaproxyCommand(ctx, address,
+		fmt.Sprintf("set server %s/%s %s", backend, server, args))
+	if err != nil {
+		return err
+	}
+
+	// successful commands have an empty response, anything else is an error
+	resp = strings.TrimSpace(resp)
+	if resp != "" {
+		return fmt.Errorf("haproxy rejected command: %s", resp)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package dockerdeploy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHaproxyStats(t *testing.T) {
+	output := `# pxname,svname,qcur,qmax,scur,smax,slim,stot,bin,bout,dreq,dresp,ereq,econ,eresp,wretr,wredis,status,weight,act,bck,chkfail,chkdown,lastchg,downtime,qlimit,pid,iid,sid,throttle,lbtot,tracked,type,rate,rate_lim,rate_max,check_status,addr,
+frontend8091,FRONTEND,,,0,0,262121,0,0,0,0,0,0,,,,,OPEN,,,,,,,,,1,2,0,,,,0,0,0,0,,,
+backend8091,node-a,0,0,2,3,,5,0,0,,0,,0,0,0,0,UP,1,1,0,0,0,10,0,,1,3,1,,5,,2,0,,1,L7OK,10.0.0.2:8091,
+backend8091,node-b,0,0,0,0,,0,0,0,,0,,0,0,0,0,DRAIN,1,1,0,0,0,10,0,,1,3,2,,0,,2,0,,0,L7OK,10.0.0.3:8091,
+backend8091,BACKEND,0,0,2,3,26213,5,0,0,0,0,,0,0,0,0,UP,2,2,0,,0,10,0,,1,3,0,,5,,1,0,,1,,,
+`
+
+	servers, err := parseHaproxyStats(output)
+	require.NoError(t, err)
+	require.Equal(t, []*HaproxyServerStat{
+		{
+			Backend:     "backend8091",
+			Server:      "node-a",
+			Address:     "10.0.0.2:8091",
+			Status:      "UP",
+			CheckStatus: "L7OK",
+			Weight:      1,
+			Sessions:    2,
+		},
+		{
+			Backend:     "backend8091",
+			Server:      "node-b",
+			Address:     "10.0.0.3:8091",
+			Status:      "DRAIN",
+			CheckStatus: "L7OK",
+			Weight:      1,
+			Sessions:    0,
+		},
+	}, servers)
+
+	_, err = parseHaproxyStats("# pxname,svname\nbackend8091,node-a\n")
+	require.Error(t, err)
+}
+
+func TestHaproxyDuration(t *testing.T) {
+	require.Equal(t, "350s", haproxyDuration(350*time.Second))
+	require.Equal(t, "1500ms", haproxyDuration(1500*time.Millisecond))
+	require.Equal(t, "120s", haproxyDuration(2*time.Minute))
+}
+
+func TestValidateLoadBalancerSettings(t *testing.T) {
+	require.NoError(t, ValidateLoadBalancerSettings(&clusterdef.LoadBalancerSettings{}))
+	require.NoError(t, ValidateLoadBalancerSettings(&clusterdef.LoadBalancerSettings{
+		Balance:       "leastconn",
+		ClientTimeout: 60 * time.Second,
+	}))
+	require.Error(t, ValidateLoadBalancerSettings(&clusterdef.LoadBalancerSettings{
+		Balance: "fastest",
+	}))
+	require.Error(t, ValidateLoadBalancerSettings(&clusterdef.LoadBalancerSettings{
+		ServerTimeout: -time.Second,
+	}))
+}
+
+func TestBuildHaproxyConfig(t *testing.T) {
+	targets := []ProxyTargetNode{
+		{NodeID: "node-a", Address: "10.0.0.2"},
+	}
+
+	defaultConf := buildHaproxyConfig(targets, false, false, &clusterdef.LoadBalancerSettings{})
+	require.Contains(t, defaultConf, "  stats socket ipv4@:9999 level admin\n")
+	require.Contains(t, defaultConf, "  timeout client 350s\n")
+	require.Contains(t, defaultConf, "  balance roundrobin\n")
+	require.Contains(t, defaultConf, "  server node-a 10.0.0.2:8093  check inter 30s fall 2 rise 5\n")
+
+	conf := buildHaproxyConfig(targets, false, false, &clusterdef.LoadBalancerSettings{
+		ClientTimeout:       60 * time.Second,
+		Retries:             3,
+		Balance:             "leastconn",
+		HealthCheckInterval: 500 * time.Millisecond,
+		HealthCheckFall:     1,
+		HealthCheckRise:     1,
+	})
+	require.Contains(t, conf, "  retries 3\n")
+	require.Contains(t, conf, "  timeout client 60s\n")
+	require.Contains(t, conf, "  timeout server 350s\n")
+	require.Contains(t, conf, "  balance leastconn\n")
+	require.Contains(t, conf, "  server node-a 10.0.0.2:8093  check inter 500ms fall 1 rise 1\n")
+
+	// the management port always keeps sticky sessions
+	require.Contains(t, conf, "backend backend8091\n  balance source\n")
+}
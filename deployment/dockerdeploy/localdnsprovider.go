@@ -0,0 +1,311 @@
+package dockerdeploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	localDnsContainerName = "cbdyndns"
+	localDnsImage         = "coredns/coredns:latest"
+
+	// CoreDNS checks the zone file for changes at this interval, so this is
+	// how long it takes for a record update to become visible.
+	localDnsReloadInterval = 2 * time.Second
+
+	// nodes use the dns server as their resolver, so it has the last of the
+	// fixed utility container addresses.
+	localDnsAddressIndex = 0
+)
+
+type LocalDnsProviderOptions struct {
+	Logger      *zap.Logger
+	DockerCli   *client.Client
+	NetworkName string
+	Hostname    string
+}
+
+// LocalDnsProvider serves the dns records of docker clusters from a CoreDNS
+// utility container on the docker network, rather than from a hosted zone.
+type LocalDnsProvider struct {
+	logger    *zap.Logger
+	hostname  string
+	container *utilityContainer
+}
+
+var _ DnsProvider = &LocalDnsProvider{}
+
+func NewLocalDnsProvider(opts *LocalDnsProviderOptions) (*LocalDnsProvider, error) {
+	if opts.Hostname == "" {
+		return nil, errors.New("a dns hostname is required")
+	}
+
+	p := &LocalDnsProvider{
+		logger:   opts.Logger,
+		hostname: strings.TrimSuffix(opts.Hostname, "."),
+	}
+	p.container = newUtilityContainer(&utilityContainerOptions{
+		Logger:       opts.Logger,
+		DockerCli:    opts.DockerCli,
+		NetworkName:  opts.NetworkName,
+		Kind:         "dns",
+		Name:         localDnsContainerName,
+		Image:        localDnsImage,
+		Cmd:          []string{"-conf", "/etc/coredns/Corefile"},
+		AddressIndex: localDnsAddressIndex,
+		InitFiles: func() ([]utilityContainerFile, error) {
+			return p.stateFiles(&localDnsState{})
+		},
+	})
+
+	return p, nil
+}
+
+func (p *LocalDnsProvider) GetHostname() string {
+	return p.hostname
+}
+
+// GetServerAddress returns the address of the dns server, starting it if it
+// is not already running.
+func (p *LocalDnsProvider) GetServerAddress(ctx context.Context) (string, error) {
+	lock, err := p.container.Lock(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer lock.Release()
+
+	_, ipAddress, err := p.container.Ensure(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return ipAddress, nil
+}
+
+// LookupServerAddress returns the address of the dns server, or an empty
+// string if it is not running.  Unlike GetServerAddress, it never starts the
+// server.
+func (p *LocalDnsProvider) LookupServerAddress(ctx context.Context) (string, error) {
+	_, ipAddress, err := p.container.Lookup(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return ipAddress, nil
+}
+
+type localDnsState struct {
+	Serial  uint32
+	Records []DnsRecord
+}
+
+func (p *LocalDnsProvider) readState(ctx context.Context, containerID string) (*localDnsState, error) {
+	state := &localDnsState{}
+	err := p.container.ReadJSON(ctx, containerID, "/etc/coredns/records.json", state)
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func (p *LocalDnsProvider) stateFiles(state *localDnsState) ([]utilityContainerFile, error) {
+	stateBytes, err := json.Marshal(state)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal dns records")
+	}
+
+	zone, err := buildLocalDnsZone(p.hostname, state.Serial, state.Records)
+	if err != nil {
+		return nil, err
+	}
+
+	return []utilityContainerFile{
+		{"etc/coredns/Corefile", []byte(buildLocalDnsCorefile(p.hostname))},
+		{"etc/coredns/zone", []byte(zone)},
+		{"etc/coredns/records.json", stateBytes},
+	}, nil
+}
+
+func (p *LocalDnsProvider) writeState(ctx context.Context, containerID string, state *localDnsState) error {
+	files, err := p.stateFiles(state)
+	if err != nil {
+		return err
+	}
+
+	return p.container.WriteFiles(ctx, containerID, files)
+}
+
+func (p *LocalDnsProvider) updateState(
+	ctx context.Context,
+	noWait bool,
+	fn func(records []DnsRecord) ([]DnsRecord, error),
+) error {
+	lock, err := p.container.Lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	containerID, _, err := p.container.Ensure(ctx)
+	if err != nil {
+		return err
+	}
+
+	state, err := p.readState(ctx, containerID)
+	if err != nil {
+		return err
+	}
+
+	newRecords, err := fn(state.Records)
+	if err != nil {
+		return err
+	}
+
+	// coredns only reloads the zone when its serial changes
+	err = p.writeState(ctx, containerID, &localDnsState{
+		Serial:  state.Serial + 1,
+		Records: newRecords,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !noWait {
+		p.logger.Info("waiting for dns records to be reloaded")
+
+		select {
+		case <-time.After(localDnsReloadInterval):
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "context finished while waiting for dns records to be reloaded")
+		}
+	}
+
+	return nil
+}
+
+func (p *LocalDnsProvider) UpdateRecords(
+	ctx context.Context,
+	records []DnsRecord,
+	noWait bool,
+	noWaitPropagate bool,
+) error {
+	for _, record := range records {
+		if !isNameInZone(record.Name, p.hostname) {
+			return fmt.Errorf("record %s is not within the dns hostname %s", record.Name, p.hostname)
+		}
+	}
+
+	return p.updateState(ctx, noWait, func(curRecords []DnsRecord) ([]DnsRecord, error) {
+		var newRecords []DnsRecord
+		for _, curRecord := range curRecords {
+			replaced := slices.ContainsFunc(records, func(record DnsRecord) bool {
+				return record.Name == curRecord.Name && record.RecordType == curRecord.RecordType
+			})
+			if !replaced {
+				newRecords = append(newRecords, curRecord)
+			}
+		}
+
+		return append(newRecords, records...), nil
+	})
+}
+
+func (p *LocalDnsProvider) RemoveRecords(
+	ctx context.Context,
+	recordNames []string,
+	noWait bool,
+	noWaitPropagate bool,
+) error {
+	return p.updateState(ctx, noWait, func(curRecords []DnsRecord) ([]DnsRecord, error) {
+		var newRecords []DnsRecord
+		for _, curRecord := range curRecords {
+			if !slices.Contains(recordNames, curRecord.Name) {
+				newRecords = append(newRecords, curRecord)
+			}
+		}
+
+		return newRecords, nil
+	})
+}
+
+func isNameInZone(name string, zone string) bool {
+	name = strings.TrimSuffix(name, ".")
+	return name == zone || strings.HasSuffix(name, "."+zone)
+}
+
+func buildLocalDnsCorefile(hostname string) string {
+	return fmt.Sprintf(`%s {
+    file /etc/coredns/zone {
+        reload %s
+    }
+    errors
+}
+. {
+    forward . /etc/resolv.conf
+    errors
+}
+`, hostname, localDnsReloadInterval)
+}
+
+// buildLocalDnsZone generates the zone file for a set of records.  SRV
+// records generated by the deployer target IP addresses, which are not valid
+// SRV targets, so an A record is generated for each of those addresses and
+// the SRV record targets that instead.
+func buildLocalDnsZone(hostname string, serial uint32, records []DnsRecord) (string, error) {
+	origin := hostname + "."
+
+	var lines []string
+	lines = append(lines,
+		"$ORIGIN "+origin,
+		fmt.Sprintf("$TTL %d", DNS_TTL_TIME),
+		fmt.Sprintf("@ IN SOA ns.%s admin.%s %d 60 60 3600 %d", origin, origin, serial, DNS_TTL_TIME),
+		fmt.Sprintf("@ IN NS ns.%s", origin))
+
+	var addrNames []string
+	for _, record := range records {
+		name := strings.TrimSuffix(record.Name, ".") + "."
+
+		for _, addr := range record.Addrs {
+			switch record.RecordType {
+			case "A":
+				lines = append(lines, fmt.Sprintf("%s IN A %s", name, addr))
+			case "CNAME":
+				lines = append(lines, fmt.Sprintf("%s IN CNAME %s", name, strings.TrimSuffix(addr, ".")+"."))
+			case "SRV":
+				fields := strings.Fields(addr)
+				if len(fields) != 4 {
+					return "", fmt.Errorf("invalid srv record value: %s", addr)
+				}
+
+				target := fields[3]
+				if net.ParseIP(target) != nil {
+					addrName := strings.ReplaceAll(target, ".", "-") + ".addr." + origin
+					if !slices.Contains(addrNames, addrName) {
+						addrNames = append(addrNames, addrName)
+						lines = append(lines, fmt.Sprintf("%s IN A %s", addrName, target))
+					}
+					target = addrName
+				} else {
+					target = strings.TrimSuffix(target, ".") + "."
+				}
+
+				lines = append(lines, fmt.Sprintf("%s IN SRV %s %s %s %s",
+					name, fields[0], fields[1], fields[2], target))
+			default:
+				return "", fmt.Errorf("unsupported record type: %s", record.RecordType)
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n", nil
+}
@@ -0,0 +1,57 @@
+package dockerdeploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildLocalDnsZone(t *testing.T) {
+	zone, err := buildLocalDnsZone("dino.test", 3, []DnsRecord{
+		{
+			RecordType: "A",
+			Name:       "abc.dino.test",
+			Addrs:      []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			RecordType: "SRV",
+			Name:       "_couchbase._tcp.srv.abc.dino.test",
+			Addrs:      []string{"0 0 11210 10.0.0.1", "0 0 11210 10.0.0.2"},
+		},
+		{
+			RecordType: "SRV",
+			Name:       "_couchbases._tcp.srv.abc.dino.test",
+			Addrs:      []string{"0 0 11207 10.0.0.1"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `$ORIGIN dino.test.
+$TTL 5
+@ IN SOA ns.dino.test. admin.dino.test. 3 60 60 3600 5
+@ IN NS ns.dino.test.
+abc.dino.test. IN A 10.0.0.1
+abc.dino.test. IN A 10.0.0.2
+10-0-0-1.addr.dino.test. IN A 10.0.0.1
+_couchbase._tcp.srv.abc.dino.test. IN SRV 0 0 11210 10-0-0-1.addr.dino.test.
+10-0-0-2.addr.dino.test. IN A 10.0.0.2
+_couchbase._tcp.srv.abc.dino.test. IN SRV 0 0 11210 10-0-0-2.addr.dino.test.
+_couchbases._tcp.srv.abc.dino.test. IN SRV 0 0 11207 10-0-0-1.addr.dino.test.
+`, zone)
+
+	_, err = buildLocalDnsZone("dino.test", 1, []DnsRecord{
+		{RecordType: "SRV", Name: "x.dino.test", Addrs: []string{"10.0.0.1"}},
+	})
+	require.Error(t, err)
+
+	_, err = buildLocalDnsZone("dino.test", 1, []DnsRecord{
+		{RecordType: "TXT", Name: "x.dino.test", Addrs: []string{"hello"}},
+	})
+	require.Error(t, err)
+}
+
+func TestIsNameInZone(t *testing.T) {
+	require.True(t, isNameInZone("dino.test", "dino.test"))
+	require.True(t, isNameInZone("abc.dino.test.", "dino.test"))
+	require.False(t, isNameInZone("abcdino.test", "dino.test"))
+	require.False(t, isNameInZone("abc.example.com", "dino.test"))
+}
@@ -0,0 +1,279 @@
+package dockerdeploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/couchbaselabs/cbdinocluster/utils/dinooidc"
+	"github.com/docker/docker/client"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	localOidcContainerName = "cbdynoidc"
+	localOidcImage         = "nginx:latest"
+
+	// the issuer is next to the dns server, at the second of the fixed
+	// utility container addresses.
+	localOidcAddressIndex = 1
+)
+
+type LocalOidcIssuerOptions struct {
+	Logger      *zap.Logger
+	DockerCli   *client.Client
+	NetworkName string
+}
+
+// LocalOidcIssuer serves the JWKS and discovery documents of the dino token
+// issuers from an nginx utility container on the docker network, so that
+// clusters can fetch the keys of an issuer as they would from a real identity
+// provider.
+type LocalOidcIssuer struct {
+	logger    *zap.Logger
+	container *utilityContainer
+}
+
+func NewLocalOidcIssuer(opts *LocalOidcIssuerOptions) *LocalOidcIssuer {
+	p := &LocalOidcIssuer{
+		logger: opts.Logger,
+	}
+	p.container = newUtilityContainer(&utilityContainerOptions{
+		Logger:       opts.Logger,
+		DockerCli:    opts.DockerCli,
+		NetworkName:  opts.NetworkName,
+		Kind:         "oidc",
+		Name:         localOidcContainerName,
+		Image:        localOidcImage,
+		AddressIndex: localOidcAddressIndex,
+		InitFiles: func() ([]utilityContainerFile, error) {
+			return []utilityContainerFile{
+				{"etc/nginx/conf.d/default.conf", []byte(localOidcNginxConfig)},
+			}, nil
+		},
+		// the documents of the issuers contain the address of the server,
+		// so they are only written once it has started.
+		OnCreated: func(ctx context.Context, containerID string, ipAddress string) error {
+			return p.writeState(ctx, containerID, ipAddress, &localOidcState{})
+		},
+	})
+	return p
+}
+
+type localOidcState struct {
+	Issuers []*dinooidc.Issuer
+}
+
+// GetServerAddress returns the address of the issuer server, starting it if
+// it is not already running.
+func (p *LocalOidcIssuer) GetServerAddress(ctx context.Context) (string, error) {
+	lock, err := p.container.Lock(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer lock.Release()
+
+	_, ipAddress, err := p.container.Ensure(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return ipAddress, nil
+}
+
+// IssuerUri returns the identifier of an issuer, which is the url its
+// discovery document is served beneath.
+func (p *LocalOidcIssuer) IssuerUri(serverAddress string, issuerName string) string {
+	return fmt.Sprintf("http://%s/%s", serverAddress, issuerName)
+}
+
+// JwksUri returns the url a cluster fetches the keys of an issuer from.
+func (p *LocalOidcIssuer) JwksUri(serverAddress string, issuerName string) string {
+	return p.IssuerUri(serverAddress, issuerName) + "/jwks.json"
+}
+
+// ListIssuers lists the issuers, without starting the server if it is not
+// running.  The default issuer is always included.
+func (p *LocalOidcIssuer) ListIssuers(ctx context.Context) ([]*dinooidc.Issuer, error) {
+	containerID, _, err := p.container.Lookup(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &localOidcState{}
+	if containerID != "" {
+		state, err = p.readState(ctx, containerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return issuersWithDefault(state.Issuers), nil
+}
+
+func (p *LocalOidcIssuer) GetIssuer(ctx context.Context, name string) (*dinooidc.Issuer, error) {
+	issuers, err := p.ListIssuers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := findOidcIssuer(issuers, name)
+	if issuer == nil {
+		return nil, fmt.Errorf("issuer %s does not exist", name)
+	}
+
+	return issuer, nil
+}
+
+func (p *LocalOidcIssuer) AddIssuer(ctx context.Context, name string, audiences []string) (*dinooidc.Issuer, error) {
+	issuer, err := dinooidc.NewIssuer(name, audiences)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.updateIssuers(ctx, func(issuers []*dinooidc.Issuer) ([]*dinooidc.Issuer, error) {
+		if findOidcIssuer(issuers, name) != nil {
+			return nil, fmt.Errorf("issuer %s already exists", name)
+		}
+
+		return append(issuers, issuer), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return issuer, nil
+}
+
+// RotateIssuerKey makes a new signing key active for an issuer.  The previous
+// keys remain in its JWKS unless dropOld is set, so that tokens signed with
+// them remain valid during a key rollover.
+func (p *LocalOidcIssuer) RotateIssuerKey(ctx context.Context, name string, dropOld bool) (*dinooidc.Issuer, error) {
+	var rotatedIssuer *dinooidc.Issuer
+	err := p.updateIssuers(ctx, func(issuers []*dinooidc.Issuer) ([]*dinooidc.Issuer, error) {
+		rotatedIssuer = findOidcIssuer(issuers, name)
+		if rotatedIssuer == nil {
+			return nil, fmt.Errorf("issuer %s does not exist", name)
+		}
+
+		rotatedIssuer.RotateKey(dropOld)
+		return issuers, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rotatedIssuer, nil
+}
+
+func issuersWithDefault(issuers []*dinooidc.Issuer) []*dinooidc.Issuer {
+	if findOidcIssuer(issuers, dinooidc.DefaultIssuerName) != nil {
+		return issuers
+	}
+	return append([]*dinooidc.Issuer{dinooidc.DefaultIssuer()}, issuers...)
+}
+
+func findOidcIssuer(issuers []*dinooidc.Issuer, name string) *dinooidc.Issuer {
+	idx := slices.IndexFunc(issuers, func(issuer *dinooidc.Issuer) bool {
+		return issuer.Name == name
+	})
+	if idx < 0 {
+		return nil
+	}
+	return issuers[idx]
+}
+
+func (p *LocalOidcIssuer) readState(ctx context.Context, containerID string) (*localOidcState, error) {
+	state := &localOidcState{}
+	err := p.container.ReadJSON(ctx, containerID, "/etc/cbdynoidc/issuers.json", state)
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func (p *LocalOidcIssuer) writeState(
+	ctx context.Context,
+	containerID string,
+	serverAddress string,
+	state *localOidcState,
+) error {
+	stateBytes, err := json.Marshal(state)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal oidc issuers")
+	}
+
+	files := []utilityContainerFile{
+		{"etc/cbdynoidc/issuers.json", stateBytes},
+	}
+
+	for _, issuer := range issuersWithDefault(state.Issuers) {
+		jwks, err := dinooidc.BuildJwks(issuer)
+		if err != nil {
+			return errors.Wrapf(err, "failed to build jwks for issuer %s", issuer.Name)
+		}
+
+		jwksBytes, err := json.Marshal(jwks)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal jwks")
+		}
+
+		discovery := dinooidc.BuildDiscovery(
+			p.IssuerUri(serverAddress, issuer.Name),
+			p.JwksUri(serverAddress, issuer.Name))
+		discoveryBytes, err := json.Marshal(discovery)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal discovery document")
+		}
+
+		issuerPath := "usr/share/nginx/html/" + issuer.Name
+		files = append(files,
+			utilityContainerFile{issuerPath + "/jwks.json", jwksBytes},
+			utilityContainerFile{issuerPath + "/.well-known/openid-configuration", discoveryBytes})
+	}
+
+	return p.container.WriteFiles(ctx, containerID, files)
+}
+
+func (p *LocalOidcIssuer) updateIssuers(
+	ctx context.Context,
+	fn func(issuers []*dinooidc.Issuer) ([]*dinooidc.Issuer, error),
+) error {
+	lock, err := p.container.Lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	containerID, serverAddress, err := p.container.Ensure(ctx)
+	if err != nil {
+		return err
+	}
+
+	state, err := p.readState(ctx, containerID)
+	if err != nil {
+		return err
+	}
+
+	newIssuers, err := fn(issuersWithDefault(state.Issuers))
+	if err != nil {
+		return err
+	}
+
+	return p.writeState(ctx, containerID, serverAddress, &localOidcState{
+		Issuers: newIssuers,
+	})
+}
+
+const localOidcNginxConfig = `server {
+    listen 80;
+    root /usr/share/nginx/html;
+    default_type application/json;
+    types {
+        application/json json;
+    }
+}
+`
@@ -0,0 +1,134 @@
+package dockerdeploy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// partitionSpecToRules converts a partition spec into the rules which need
+// to be applied to each node, keyed by node id.  nodeIPs maps the node ids
+// of the cluster to their addresses.
+func partitionSpecToRules(
+	spec *deployment.PartitionSpec,
+	nodeIPs map[string]string,
+) (map[string][]*TrafficPartitionRule, error) {
+	err := spec.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range spec.Groups {
+		for _, nodeID := range group.NodeIDs {
+			if nodeIPs[nodeID] == "" {
+				return nil, fmt.Errorf("failed to find node `%s`", nodeID)
+			}
+		}
+	}
+
+	nodeRules := make(map[string][]*TrafficPartitionRule)
+	getRule := func(nodeID string, peerID string) *TrafficPartitionRule {
+		peerIP := nodeIPs[peerID]
+		for _, rule := range nodeRules[nodeID] {
+			if rule.IPAddress == peerIP {
+				return rule
+			}
+		}
+
+		rule := &TrafficPartitionRule{IPAddress: peerIP}
+		nodeRules[nodeID] = append(nodeRules[nodeID], rule)
+		return rule
+	}
+
+	for _, rule := range spec.Rules {
+		for _, fromNodeID := range spec.GroupNodeIDs(rule.From) {
+			for _, toNodeID := range spec.GroupNodeIDs(rule.To) {
+				fromRule := getRule(fromNodeID, toNodeID)
+				toRule := getRule(toNodeID, fromNodeID)
+
+				fromRule.BlockOutbound = true
+				toRule.BlockInbound = true
+				if rule.Bidirectional {
+					fromRule.BlockInbound = true
+					toRule.BlockOutbound = true
+				}
+			}
+		}
+	}
+
+	for _, rules := range nodeRules {
+		slices.SortFunc(rules, func(a, b *TrafficPartitionRule) int {
+			return strings.Compare(a.IPAddress, b.IPAddress)
+		})
+	}
+
+	return nodeRules, nil
+}
+
+func (d *Deployer) PartitionNodeGroups(ctx context.Context, clusterID string, spec *deployment.PartitionSpec, rejectType string) error {
+	clusterInfo, err := d.getCluster(ctx, clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster info")
+	}
+
+	nodeIPs := make(map[string]string)
+	for _, node := range clusterInfo.Nodes {
+		nodeIPs[node.NodeID] = node.IPAddress
+	}
+
+	nodeRules, err := partitionSpecToRules(spec, nodeIPs)
+	if err != nil {
+		return errors.Wrap(err, "invalid partition")
+	}
+
+	for _, node := range clusterInfo.Nodes {
+		rules := nodeRules[node.NodeID]
+		if len(rules) == 0 {
+			continue
+		}
+
+		d.logger.Debug("partitioning traffic for node",
+			zap.String("nodeID", node.NodeID),
+			zap.String("containerID", node.ContainerID),
+			zap.String("ipAddress", node.IPAddress))
+
+		err := d.controller.SetTrafficControl(ctx, node.ContainerID, TrafficControlAllowAll, rejectType, nil, nil, rules)
+		if err != nil {
+			return errors.Wrapf(err, "failed to partition traffic for node %s", node.NodeID)
+		}
+	}
+
+	return nil
+}
+
+func (d *Deployer) ListNodeTrafficRules(ctx context.Context, clusterID string) ([]deployment.NodeTrafficRules, error) {
+	clusterInfo, err := d.getCluster(ctx, clusterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster info")
+	}
+
+	var out []deployment.NodeTrafficRules
+	for _, node := range clusterInfo.Nodes {
+		if !node.IsClusterNode() {
+			continue
+		}
+
+		rules, err := d.controller.GetTrafficRules(ctx, node.ContainerID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get traffic rules for node %s", node.NodeID)
+		}
+
+		out = append(out, deployment.NodeTrafficRules{
+			NodeID:    node.NodeID,
+			IPAddress: node.IPAddress,
+			Rules:     rules,
+		})
+	}
+
+	return out, nil
+}
@@ -0,0 +1,70 @@
+package dockerdeploy
+
+import (
+	"testing"
+
+	"github.com/couchbaselabs/cbdinocluster/deployment"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionSpecToRules(t *testing.T) {
+	nodeIPs := map[string]string{
+		"a": "10.0.0.2",
+		"b": "10.0.0.3",
+		"c": "10.0.0.4",
+		"d": "10.0.0.5",
+	}
+	groups := []deployment.PartitionGroup{
+		{Name: "ab", NodeIDs: []string{"a", "b"}},
+		{Name: "c", NodeIDs: []string{"c"}},
+	}
+
+	rules, err := partitionSpecToRules(&deployment.PartitionSpec{
+		Groups: groups,
+		Rules:  []deployment.PartitionRule{{From: "ab", To: "c", Bidirectional: true}},
+	}, nodeIPs)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]*TrafficPartitionRule{
+		"a": {{IPAddress: "10.0.0.4", BlockInbound: true, BlockOutbound: true}},
+		"b": {{IPAddress: "10.0.0.4", BlockInbound: true, BlockOutbound: true}},
+		"c": {
+			{IPAddress: "10.0.0.2", BlockInbound: true, BlockOutbound: true},
+			{IPAddress: "10.0.0.3", BlockInbound: true, BlockOutbound: true},
+		},
+	}, rules)
+
+	// c cannot reach a or b, but they can still reach c
+	rules, err = partitionSpecToRules(&deployment.PartitionSpec{
+		Groups: groups,
+		Rules:  []deployment.PartitionRule{{From: "c", To: "ab"}},
+	}, nodeIPs)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]*TrafficPartitionRule{
+		"a": {{IPAddress: "10.0.0.4", BlockInbound: true}},
+		"b": {{IPAddress: "10.0.0.4", BlockInbound: true}},
+		"c": {
+			{IPAddress: "10.0.0.2", BlockOutbound: true},
+			{IPAddress: "10.0.0.3", BlockOutbound: true},
+		},
+	}, rules)
+
+	// opposing one-way rules combine into a full partition
+	rules, err = partitionSpecToRules(&deployment.PartitionSpec{
+		Groups: groups,
+		Rules: []deployment.PartitionRule{
+			{From: "c", To: "ab"},
+			{From: "ab", To: "c"},
+		},
+	}, nodeIPs)
+	require.NoError(t, err)
+	require.Equal(t, &TrafficPartitionRule{IPAddress: "10.0.0.4", BlockInbound: true, BlockOutbound: true}, rules["a"][0])
+
+	_, err = partitionSpecToRules(&deployment.PartitionSpec{
+		Groups: []deployment.PartitionGroup{
+			{Name: "x", NodeIDs: []string{"missing"}},
+			{Name: "c", NodeIDs: []string{"c"}},
+		},
+		Rules: []deployment.PartitionRule{{From: "x", To: "c"}},
+	}, nodeIPs)
+	require.Error(t, err)
+}
@@ -0,0 +1,153 @@
+package dockerdeploy
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type PoolManagerOptions struct {
+	Logger   *zap.Logger
+	Deployer *Deployer
+
+	// Pool is the short definition of the clusters in the pool, such as
+	// simple:7.6.2, and is also the name of the pool.
+	Pool string
+	Size int
+
+	// IdleExpiry defaults to DefaultPoolIdleExpiry.
+	IdleExpiry time.Duration
+}
+
+// PoolManager keeps a pool filled with a number of unclaimed clusters.
+type PoolManager struct {
+	logger     *zap.Logger
+	deployer   *Deployer
+	pool       string
+	size       int
+	idleExpiry time.Duration
+
+	refillCh  chan struct{}
+	fillMutex sync.Mutex
+}
+
+func NewPoolManager(opts *PoolManagerOptions) (*PoolManager, error) {
+	_, err := clusterdef.FromShortString(opts.Pool)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid pool definition")
+	}
+
+	if opts.Size < 0 {
+		return nil, errors.New("pool size must not be negative")
+	}
+
+	idleExpiry := opts.IdleExpiry
+	if idleExpiry == 0 {
+		idleExpiry = DefaultPoolIdleExpiry
+	}
+
+	return &PoolManager{
+		logger:     opts.Logger,
+		deployer:   opts.Deployer,
+		pool:       opts.Pool,
+		size:       opts.Size,
+		idleExpiry: idleExpiry,
+		refillCh:   make(chan struct{}, 1),
+	}, nil
+}
+
+func (m *PoolManager) Pool() string {
+	return m.pool
+}
+
+// Fill allocates clusters until the pool has the configured number of ready
+// clusters which have not been claimed.  Unclaimed clusters which are not
+// ready are removed, and the expiry of the remaining ones is extended.
+func (m *PoolManager) Fill(ctx context.Context) error {
+	m.fillMutex.Lock()
+	defer m.fillMutex.Unlock()
+
+	poolClusters, err := m.deployer.ListPoolClusters(ctx, m.pool)
+	if err != nil {
+		return errors.Wrap(err, "failed to list pool clusters")
+	}
+
+	numReady := 0
+	for _, cluster := range poolClusters {
+		if cluster.Claimed {
+			continue
+		}
+
+		if cluster.State != "ready" {
+			m.logger.Info("removing pool cluster which is not ready",
+				zap.String("pool", m.pool),
+				zap.String("cluster", cluster.ClusterID),
+				zap.String("state", cluster.State))
+
+			err := m.deployer.RemoveCluster(ctx, cluster.ClusterID)
+			if err != nil {
+				return errors.Wrap(err, "failed to remove pool cluster")
+			}
+			continue
+		}
+
+		err := m.deployer.UpdateClusterExpiry(ctx, cluster.ClusterID, time.Now().Add(m.idleExpiry))
+		if err != nil {
+			return errors.Wrap(err, "failed to extend pool cluster expiry")
+		}
+
+		numReady++
+	}
+
+	for ; numReady < m.size; numReady++ {
+		m.logger.Info("adding cluster to pool",
+			zap.String("pool", m.pool),
+			zap.Int("ready", numReady),
+			zap.Int("size", m.size))
+
+		def, err := clusterdef.FromShortString(m.pool)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse pool definition")
+		}
+
+		_, err = m.deployer.AddPoolCluster(ctx, m.pool, def, m.idleExpiry)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Refill asks a running pool manager to fill the pool now, rather than at
+// its next interval.
+func (m *PoolManager) Refill() {
+	select {
+	case m.refillCh <- struct{}{}:
+	default:
+	}
+}
+
+// Run keeps the pool filled until the context is cancelled, checking it at
+// each interval and whenever Refill is called.
+func (m *PoolManager) Run(ctx context.Context, interval time.Duration) {
+	for {
+		err := m.Fill(ctx)
+		if err != nil && ctx.Err() == nil {
+			m.logger.Warn("failed to fill pool",
+				zap.String("pool", m.pool),
+				zap.Error(err))
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-m.refillCh:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package dockerdeploy
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/docker/docker/api/types/container"
+	units "github.com/docker/go-units"
+	"github.com/pkg/errors"
+	"k8s.io/utils/ptr"
+)
+
+// NodeResources represents the resource limits applied to a node container.
+// A zero value for any field indicates that no limit is applied.
+type NodeResources struct {
+	NanoCPUs   int64
+	Memory     int64
+	MemorySwap int64
+	PidsLimit  int64
+}
+
+// ParseNodeResources converts the docker resource limits of a node group
+// into their docker representation, validating them along the way.
+func ParseNodeResources(def clusterdef.DockerNodeGroup) (NodeResources, error) {
+	var res NodeResources
+
+	if def.Cpus < 0 {
+		return NodeResources{}, errors.New("cpus must not be negative")
+	}
+	res.NanoCPUs = int64(def.Cpus * 1e9)
+
+	if def.Memory != "" {
+		memory, err := units.RAMInBytes(def.Memory)
+		if err != nil {
+			return NodeResources{}, errors.Wrap(err, "failed to parse memory limit")
+		}
+		if memory <= 0 {
+			return NodeResources{}, errors.New("memory limit must be positive")
+		}
+		res.Memory = memory
+	}
+
+	if def.MemorySwap != "" {
+		if res.Memory == 0 {
+			return NodeResources{}, errors.New("memory-swap requires memory to be specified")
+		}
+
+		if def.MemorySwap == "-1" {
+			res.MemorySwap = -1
+		} else {
+			memorySwap, err := units.RAMInBytes(def.MemorySwap)
+			if err != nil {
+				return NodeResources{}, errors.Wrap(err, "failed to parse memory-swap limit")
+			}
+			if memorySwap < res.Memory {
+				return NodeResources{}, errors.New("memory-swap must be at least as large as memory")
+			}
+			res.MemorySwap = memorySwap
+		}
+	}
+
+	if def.PidsLimit < 0 {
+		return NodeResources{}, errors.New("pids-limit must not be negative")
+	}
+	res.PidsLimit = def.PidsLimit
+
+	return res, nil
+}
+
+// NodeResourcesFromHostConfig extracts the resource limits which were
+// applied to a container when it was created or last updated.  Docker fills in
+// memory-swap when only memory is specified, so the memory-swap the node was
+// requested with is recorded separately and passed in as memorySwap.
+func NodeResourcesFromHostConfig(hostConfig *container.HostConfig, memorySwap int64) NodeResources {
+	if hostConfig == nil {
+		return NodeResources{}
+	}
+
+	var pidsLimit int64
+	if hostConfig.PidsLimit != nil && *hostConfig.PidsLimit > 0 {
+		pidsLimit = *hostConfig.PidsLimit
+	}
+
+	return NodeResources{
+		NanoCPUs:   hostConfig.NanoCPUs,
+		Memory:     hostConfig.Memory,
+		MemorySwap: memorySwap,
+		PidsLimit:  pidsLimit,
+	}
+}
+
+// ApplyTo writes these limits into a docker resources structure.
+func (r NodeResources) ApplyTo(res *container.Resources) {
+	res.NanoCPUs = r.NanoCPUs
+	res.Memory = r.Memory
+	res.MemorySwap = r.MemorySwap
+	if r.PidsLimit > 0 {
+		res.PidsLimit = ptr.To(r.PidsLimit)
+	}
+}
+
+// CanUpdateTo indicates whether a container with these limits can be moved
+// to the new limits in-place.  Docker does not permit removing a cpu or memory
+// limit from a running container, so those require a new container.
+func (r NodeResources) CanUpdateTo(newRes NodeResources) bool {
+	if r.NanoCPUs != 0 && newRes.NanoCPUs == 0 {
+		return false
+	}
+	if r.Memory != 0 && newRes.Memory == 0 {
+		return false
+	}
+	if r.MemorySwap != 0 && newRes.MemorySwap == 0 {
+		return false
+	}
+	return true
+}
+
+// ToDef converts the limits back into their cluster definition form.
+func (r NodeResources) ToDef() clusterdef.DockerNodeGroup {
+	var def clusterdef.DockerNodeGroup
+
+	def.Cpus = float64(r.NanoCPUs) / 1e9
+
+	if r.Memory > 0 {
+		def.Memory = formatMemorySize(r.Memory)
+	}
+
+	if r.MemorySwap < 0 {
+		def.MemorySwap = "-1"
+	} else if r.MemorySwap > 0 {
+		def.MemorySwap = formatMemorySize(r.MemorySwap)
+	}
+
+	def.PidsLimit = r.PidsLimit
+
+	return def
+}
+
+// formatMemorySize formats a size in bytes using the largest unit which
+// represents it exactly, so that the result parses back to the same value.
+func formatMemorySize(size int64) string {
+	suffixes := []struct {
+		Suffix string
+		Size   int64
+	}{
+		{"t", units.TiB},
+		{"g", units.GiB},
+		{"m", units.MiB},
+		{"k", units.KiB},
+	}
+	for _, suffix := range suffixes {
+		if size%suffix.Size == 0 {
+			return fmt.Sprintf("%d%s", size/suffix.Size, suffix.Suffix)
+		}
+	}
+	return strconv.FormatInt(size, 10)
+}
@@ -0,0 +1,130 @@
+package dockerdeploy
+
+import (
+	"testing"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/docker/docker/api/types/container"
+	units "github.com/docker/go-units"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNodeResources(t *testing.T) {
+	res, err := ParseNodeResources(clusterdef.DockerNodeGroup{
+		Cpus:       1.5,
+		Memory:     "4g",
+		MemorySwap: "6g",
+		PidsLimit:  1024,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, NodeResources{
+		NanoCPUs:   1500000000,
+		Memory:     4 * units.GiB,
+		MemorySwap: 6 * units.GiB,
+		PidsLimit:  1024,
+	}, res)
+}
+
+func TestParseNodeResourcesEmpty(t *testing.T) {
+	res, err := ParseNodeResources(clusterdef.DockerNodeGroup{})
+	require.NoError(t, err)
+	require.Equal(t, NodeResources{}, res)
+}
+
+func TestParseNodeResourcesUnlimitedSwap(t *testing.T) {
+	res, err := ParseNodeResources(clusterdef.DockerNodeGroup{
+		Memory:     "512m",
+		MemorySwap: "-1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(512*units.MiB), res.Memory)
+	require.Equal(t, int64(-1), res.MemorySwap)
+}
+
+func TestParseNodeResourcesInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		def  clusterdef.DockerNodeGroup
+	}{
+		{"negative cpus", clusterdef.DockerNodeGroup{Cpus: -1}},
+		{"bad memory", clusterdef.DockerNodeGroup{Memory: "lots"}},
+		{"swap without memory", clusterdef.DockerNodeGroup{MemorySwap: "1g"}},
+		{"swap below memory", clusterdef.DockerNodeGroup{Memory: "2g", MemorySwap: "1g"}},
+		{"negative pids", clusterdef.DockerNodeGroup{PidsLimit: -5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseNodeResources(tt.def)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestNodeResourcesRoundTrip(t *testing.T) {
+	def := clusterdef.DockerNodeGroup{
+		Cpus:       0.5,
+		Memory:     "1536m",
+		MemorySwap: "-1",
+		PidsLimit:  512,
+	}
+
+	res, err := ParseNodeResources(def)
+	require.NoError(t, err)
+
+	var dockerRes container.Resources
+	res.ApplyTo(&dockerRes)
+
+	got := NodeResourcesFromHostConfig(&container.HostConfig{Resources: dockerRes}, res.MemorySwap)
+	require.Equal(t, res, got)
+	require.Equal(t, def, got.ToDef())
+}
+
+func TestNodeResourcesRoundTripMemoryOnly(t *testing.T) {
+	def := clusterdef.DockerNodeGroup{
+		Memory: "2g",
+	}
+
+	res, err := ParseNodeResources(def)
+	require.NoError(t, err)
+
+	var dockerRes container.Resources
+	res.ApplyTo(&dockerRes)
+
+	// the daemon fills in its default memory-swap of twice the memory
+	dockerRes.MemorySwap = 2 * dockerRes.Memory
+
+	got := NodeResourcesFromHostConfig(&container.HostConfig{Resources: dockerRes}, res.MemorySwap)
+	require.Equal(t, res, got)
+	require.Equal(t, def, got.ToDef())
+	require.True(t, got.CanUpdateTo(res))
+}
+
+func TestNodeResourcesRequestedMemorySwap(t *testing.T) {
+	def := clusterdef.DockerNodeGroup{
+		Memory:     "2g",
+		MemorySwap: "4g",
+	}
+
+	res, err := ParseNodeResources(def)
+	require.NoError(t, err)
+
+	var dockerRes container.Resources
+	res.ApplyTo(&dockerRes)
+
+	// an explicit memory-swap matching the docker default is kept
+	got := NodeResourcesFromHostConfig(&container.HostConfig{Resources: dockerRes}, res.MemorySwap)
+	require.Equal(t, res, got)
+	require.Equal(t, def, got.ToDef())
+}
+
+func TestNodeResourcesCanUpdateTo(t *testing.T) {
+	limited := NodeResources{NanoCPUs: 1e9, Memory: units.GiB}
+
+	require.True(t, NodeResources{}.CanUpdateTo(limited))
+	require.True(t, limited.CanUpdateTo(NodeResources{NanoCPUs: 2e9, Memory: 2 * units.GiB}))
+	require.False(t, limited.CanUpdateTo(NodeResources{Memory: units.GiB}))
+	require.False(t, limited.CanUpdateTo(NodeResources{NanoCPUs: 1e9}))
+	require.True(t, NodeResources{PidsLimit: 100}.CanUpdateTo(NodeResources{}))
+}
@@ -0,0 +1,246 @@
+package dockerdeploy
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	degradeDevice    = "eth0"
+	degradeIfbDevice = "ifb0"
+)
+
+type TrafficDegradeOptions struct {
+	// Scope selects which traffic is degraded, using the same classification
+	// as SetTrafficControl.  TrafficControlAllowAll is not valid here.
+	Scope TrafficControlType
+
+	Ingress bool
+	Egress  bool
+
+	Latency     time.Duration
+	Jitter      time.Duration
+	LossPercent float64
+	Rate        string
+}
+
+var netemRateRegexp = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?([kmgt]i?)?(bit|bps)$`)
+
+// netemArgs builds the arguments to a `tc qdisc ... netem` command for the
+// specified options.
+func netemArgs(opts *TrafficDegradeOptions) ([]string, error) {
+	if opts.Latency < 0 || opts.Jitter < 0 {
+		return nil, errors.New("latency and jitter must not be negative")
+	}
+	if opts.Jitter > 0 && opts.Latency == 0 {
+		return nil, errors.New("jitter requires latency to be specified")
+	}
+	if opts.LossPercent < 0 || opts.LossPercent > 100 {
+		return nil, errors.New("loss must be between 0% and 100%")
+	}
+	if opts.Rate != "" && !netemRateRegexp.MatchString(opts.Rate) {
+		return nil, fmt.Errorf("invalid rate `%s`, expected a value such as 1mbit", opts.Rate)
+	}
+
+	args := []string{"netem"}
+
+	if opts.Latency > 0 {
+		args = append(args, "delay", fmt.Sprintf("%dus", opts.Latency.Microseconds()))
+		if opts.Jitter > 0 {
+			args = append(args, fmt.Sprintf("%dus", opts.Jitter.Microseconds()))
+		}
+	}
+
+	if opts.LossPercent > 0 {
+		args = append(args, "loss", strconv.FormatFloat(opts.LossPercent, 'f', -1, 64)+"%")
+	}
+
+	if opts.Rate != "" {
+		args = append(args, "rate", opts.Rate)
+	}
+
+	if len(args) == 1 {
+		return nil, errors.New("at least one of latency, loss or rate must be specified")
+	}
+
+	return args, nil
+}
+
+// degradeQdiscCmds builds the tc commands which attach netem to the device
+// for the traffic in scope.  A prio qdisc with an extra band is used so that
+// only the traffic we classify into that band is degraded.  matchField is
+// `dst` when shaping egress traffic and `src` when shaping ingress traffic.
+func degradeQdiscCmds(
+	device string,
+	matchField string,
+	scope TrafficControlType,
+	gatewayIP string,
+	ipRange string,
+	netem []string,
+) ([][]string, error) {
+	const normalBand = "1:2"
+	const degradedBand = "1:4"
+
+	cmds := [][]string{
+		{"tc", "qdisc", "add", "dev", device, "root", "handle", "1:", "prio",
+			"bands", "4", "priomap", "1", "2", "2", "2", "1", "2", "0", "0", "1", "1", "1", "1", "1", "1", "1", "1"},
+		append([]string{"tc", "qdisc", "add", "dev", device, "parent", degradedBand, "handle", "40:"}, netem...),
+	}
+
+	addFilter := func(prio int, cidr string, flowID string) {
+		match := []string{"match", "ip", matchField, cidr}
+		if cidr == "" {
+			match = []string{"match", "u32", "0", "0"}
+		}
+
+		cmd := []string{"tc", "filter", "add", "dev", device, "parent", "1:0",
+			"protocol", "ip", "prio", strconv.Itoa(prio), "u32"}
+		cmd = append(cmd, match...)
+		cmd = append(cmd, "flowid", flowID)
+		cmds = append(cmds, cmd)
+	}
+
+	gatewayCidr := gatewayIP + "/32"
+
+	switch scope {
+	case TrafficControlBlockNodes:
+		// the gateway lives inside the node range, but carries client traffic
+		addFilter(1, gatewayCidr, normalBand)
+		addFilter(2, ipRange, degradedBand)
+	case TrafficControlBlockClients:
+		addFilter(1, gatewayCidr, degradedBand)
+		addFilter(2, ipRange, normalBand)
+		addFilter(3, "", degradedBand)
+	case TrafficControlBlockAll:
+		addFilter(1, "", degradedBand)
+	default:
+		return nil, errors.New("invalid traffic degrade scope")
+	}
+
+	return cmds, nil
+}
+
+func (c *Controller) execTc(ctx context.Context, containerID string, cmd []string) error {
+	err := c.execCmd(ctx, containerID, cmd)
+	if err != nil {
+		// if the command fails initially, we attempt to install iproute2 first
+		c.Logger.Debug("failed to execute tc, attempting to install")
+
+		err := c.execCmd(ctx, containerID, []string{"apt-get", "update"})
+		if err != nil {
+			return errors.Wrap(err, "failed to update apt")
+		}
+
+		err = c.execCmd(ctx, containerID, []string{"apt-get", "-y", "install", "iproute2"})
+		if err != nil {
+			return errors.Wrap(err, "failed to install iproute2")
+		}
+
+		err = c.execCmd(ctx, containerID, cmd)
+		if err != nil {
+			return errors.Wrap(err, "failed to execute tc command")
+		}
+	}
+
+	return nil
+}
+
+// ClearTrafficDegrade removes any latency, loss or rate limiting previously
+// applied to a node with SetTrafficDegrade.
+func (c *Controller) ClearTrafficDegrade(ctx context.Context, containerID string) error {
+	logger := c.Logger.With(zap.String("container", containerID))
+
+	// each of these fail if there was nothing to remove, or if tc was never
+	// installed, both of which mean there is nothing to clear.
+	clearCmds := [][]string{
+		{"tc", "qdisc", "del", "dev", degradeDevice, "root"},
+		{"tc", "qdisc", "del", "dev", degradeDevice, "ingress"},
+		{"ip", "link", "del", degradeIfbDevice},
+	}
+	for _, cmd := range clearCmds {
+		err := c.execCmd(ctx, containerID, cmd)
+		if err != nil {
+			logger.Debug("failed to clear traffic degrade, this probably just means it was never set",
+				zap.Strings("cmd", cmd),
+				zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// SetTrafficDegrade uses tc netem to add latency, packet loss or rate limiting
+// to the traffic of a node, replacing any degradation that was already set.
+func (c *Controller) SetTrafficDegrade(ctx context.Context, containerID string, opts *TrafficDegradeOptions) error {
+	logger := c.Logger.With(zap.String("container", containerID))
+	logger.Debug("setting up traffic degrade",
+		zap.String("scope", string(opts.Scope)),
+		zap.Bool("ingress", opts.Ingress),
+		zap.Bool("egress", opts.Egress))
+
+	if !opts.Ingress && !opts.Egress {
+		return errors.New("at least one traffic direction must be degraded")
+	}
+
+	netem, err := netemArgs(opts)
+	if err != nil {
+		return err
+	}
+
+	gatewayIP, ipRange, err := c.getNetworkAddresses(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = c.ClearTrafficDegrade(ctx, containerID)
+	if err != nil {
+		return errors.Wrap(err, "failed to clear existing traffic degrade")
+	}
+
+	var cmds [][]string
+
+	if opts.Egress {
+		egressCmds, err := degradeQdiscCmds(degradeDevice, "dst", opts.Scope, gatewayIP, ipRange, netem)
+		if err != nil {
+			return err
+		}
+
+		cmds = append(cmds, egressCmds...)
+	}
+
+	if opts.Ingress {
+		// netem can only shape outgoing traffic, so incoming traffic is
+		// redirected through an ifb device and shaped as it leaves that.
+		ingressCmds, err := degradeQdiscCmds(degradeIfbDevice, "src", opts.Scope, gatewayIP, ipRange, netem)
+		if err != nil {
+			return err
+		}
+
+		cmds = append(cmds,
+			[]string{"ip", "link", "add", degradeIfbDevice, "type", "ifb"},
+			[]string{"ip", "link", "set", degradeIfbDevice, "up"},
+			[]string{"tc", "qdisc", "add", "dev", degradeDevice, "handle", "ffff:", "ingress"},
+			[]string{"tc", "filter", "add", "dev", degradeDevice, "parent", "ffff:",
+				"protocol", "ip", "u32", "match", "u32", "0", "0",
+				"action", "mirred", "egress", "redirect", "dev", degradeIfbDevice},
+		)
+		cmds = append(cmds, ingressCmds...)
+	}
+
+	for _, cmd := range cmds {
+		err := c.execTc(ctx, containerID, cmd)
+		if err != nil {
+			return errors.Wrap(err, "failed to apply traffic degrade")
+		}
+	}
+
+	logger.Debug("traffic degrade has been set up!")
+
+	return nil
+}
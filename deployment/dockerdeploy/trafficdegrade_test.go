@@ -0,0 +1,84 @@
+package dockerdeploy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetemArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    TrafficDegradeOptions
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "latency",
+			opts: TrafficDegradeOptions{Latency: 200 * time.Millisecond},
+			want: []string{"netem", "delay", "200000us"},
+		},
+		{
+			name: "latency with jitter",
+			opts: TrafficDegradeOptions{Latency: 200 * time.Millisecond, Jitter: 50 * time.Millisecond},
+			want: []string{"netem", "delay", "200000us", "50000us"},
+		},
+		{
+			name: "loss",
+			opts: TrafficDegradeOptions{LossPercent: 2.5},
+			want: []string{"netem", "loss", "2.5%"},
+		},
+		{
+			name: "rate",
+			opts: TrafficDegradeOptions{Rate: "1mbit"},
+			want: []string{"netem", "rate", "1mbit"},
+		},
+		{
+			name: "everything",
+			opts: TrafficDegradeOptions{Latency: time.Second, LossPercent: 5, Rate: "512kbit"},
+			want: []string{"netem", "delay", "1000000us", "loss", "5%", "rate", "512kbit"},
+		},
+		{name: "nothing", opts: TrafficDegradeOptions{}, wantErr: true},
+		{name: "jitter without latency", opts: TrafficDegradeOptions{Jitter: time.Millisecond}, wantErr: true},
+		{name: "loss over 100", opts: TrafficDegradeOptions{LossPercent: 101}, wantErr: true},
+		{name: "invalid rate", opts: TrafficDegradeOptions{Rate: "fast"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := netemArgs(&tt.opts)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDegradeQdiscCmdsScope(t *testing.T) {
+	filterTargets := func(cmds [][]string) [][]string {
+		var out [][]string
+		for _, cmd := range cmds {
+			if cmd[1] != "filter" {
+				continue
+			}
+			// filters end with the matched value followed by `flowid <band>`
+			out = append(out, []string{cmd[len(cmd)-3], cmd[len(cmd)-1]})
+		}
+		return out
+	}
+
+	cmds, err := degradeQdiscCmds("eth0", "dst", TrafficControlBlockNodes, "10.0.0.1", "10.0.0.0/24", []string{"netem"})
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"10.0.0.1/32", "1:2"}, {"10.0.0.0/24", "1:4"}}, filterTargets(cmds))
+
+	cmds, err = degradeQdiscCmds("eth0", "dst", TrafficControlBlockClients, "10.0.0.1", "10.0.0.0/24", []string{"netem"})
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"10.0.0.1/32", "1:4"}, {"10.0.0.0/24", "1:2"}, {"0", "1:4"}}, filterTargets(cmds))
+
+	_, err = degradeQdiscCmds("eth0", "dst", TrafficControlAllowAll, "10.0.0.1", "10.0.0.0/24", []string{"netem"})
+	require.Error(t, err)
+}
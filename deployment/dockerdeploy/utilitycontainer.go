@@ -0,0 +1,398 @@
+package dockerdeploy
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/couchbaselabs/cbdinocluster/utils/filelock"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type utilityContainerFile struct {
+	Name string
+	Data []byte
+}
+
+type utilityContainerOptions struct {
+	Logger      *zap.Logger
+	DockerCli   *client.Client
+	NetworkName string
+
+	// Kind is the type label of the container, and names it in messages.
+	Kind  string
+	Name  string
+	Image string
+	Cmd   []string
+
+	// AddressIndex picks the fixed address of the container, counting back
+	// from the last address nodes can be assigned, so that each utility
+	// container has its own.
+	AddressIndex int
+
+	// InitFiles are written to the container before it first starts.
+	InitFiles func() ([]utilityContainerFile, error)
+
+	// OnCreated is called once the container has been created and started,
+	// for anything which depends on the address of the container.
+	OnCreated func(ctx context.Context, containerID string, ipAddress string) error
+}
+
+// utilityContainer is a container on the docker network which provides a
+// service to the clusters, rather than being part of one.  Its state is kept
+// in files within the container itself, so that it is shared by every
+// invocation of cbdinocluster, and changes to it are serialized between
+// invocations with a lock file.
+type utilityContainer struct {
+	logger      *zap.Logger
+	dockerCli   *client.Client
+	networkName string
+	opts        utilityContainerOptions
+	lockPath    string
+}
+
+func newUtilityContainer(opts *utilityContainerOptions) *utilityContainer {
+	return &utilityContainer{
+		logger:      opts.Logger,
+		dockerCli:   opts.DockerCli,
+		networkName: opts.NetworkName,
+		opts:        *opts,
+		lockPath:    filepath.Join(os.TempDir(), "cbdinocluster-"+opts.Name+"-"+opts.NetworkName+".lock"),
+	}
+}
+
+func (c *utilityContainer) Lock(ctx context.Context) (*filelock.Lock, error) {
+	lock, err := filelock.Acquire(ctx, c.lockPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to lock %s container", c.opts.Kind)
+	}
+
+	return lock, nil
+}
+
+// Lookup returns the id of the container and its address, without creating
+// or starting it.  The id is empty if the container does not exist, and the
+// address is empty if it is not running.
+func (c *utilityContainer) Lookup(ctx context.Context) (string, string, error) {
+	inspect, err := c.dockerCli.ContainerInspect(ctx, c.opts.Name)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return "", "", nil
+		}
+
+		return "", "", errors.Wrapf(err, "failed to inspect %s container", c.opts.Kind)
+	}
+
+	if !inspect.State.Running {
+		return inspect.ID, "", nil
+	}
+
+	endpoint := inspect.NetworkSettings.Networks[c.networkName]
+	if endpoint == nil {
+		return inspect.ID, "", nil
+	}
+
+	return inspect.ID, endpoint.IPAddress, nil
+}
+
+// Ensure returns the id of the container and its address, creating and
+// starting it as needed.
+func (c *utilityContainer) Ensure(ctx context.Context) (string, string, error) {
+	inspect, err := c.dockerCli.ContainerInspect(ctx, c.opts.Name)
+	if err != nil {
+		if !errdefs.IsNotFound(err) {
+			return "", "", errors.Wrapf(err, "failed to inspect %s container", c.opts.Kind)
+		}
+
+		err := c.create(ctx)
+		if err != nil {
+			return "", "", err
+		}
+
+		inspect, err = c.dockerCli.ContainerInspect(ctx, c.opts.Name)
+		if err != nil {
+			return "", "", errors.Wrapf(err, "failed to inspect %s container", c.opts.Kind)
+		}
+
+		endpoint := inspect.NetworkSettings.Networks[c.networkName]
+		if c.opts.OnCreated != nil && endpoint != nil && endpoint.IPAddress != "" {
+			err := c.opts.OnCreated(ctx, inspect.ID, endpoint.IPAddress)
+			if err != nil {
+				return "", "", err
+			}
+		}
+	}
+
+	if !inspect.State.Running {
+		c.logger.Debug("starting utility container",
+			zap.String("kind", c.opts.Kind))
+
+		err := c.dockerCli.ContainerStart(ctx, inspect.ID, container.StartOptions{})
+		if err != nil {
+			return "", "", errors.Wrapf(err, "failed to start %s container", c.opts.Kind)
+		}
+
+		inspect, err = c.dockerCli.ContainerInspect(ctx, inspect.ID)
+		if err != nil {
+			return "", "", errors.Wrapf(err, "failed to inspect %s container", c.opts.Kind)
+		}
+	}
+
+	endpoint := inspect.NetworkSettings.Networks[c.networkName]
+	if endpoint == nil || endpoint.IPAddress == "" {
+		return "", "", fmt.Errorf("%s container has no address on the docker network", c.opts.Kind)
+	}
+
+	return inspect.ID, endpoint.IPAddress, nil
+}
+
+func (c *utilityContainer) create(ctx context.Context) error {
+	c.logger.Info("deploying utility container",
+		zap.String("kind", c.opts.Kind))
+
+	_, err := MultiArchImagePuller{
+		Logger:    c.logger,
+		DockerCli: c.dockerCli,
+		ImagePath: c.opts.Image,
+	}.Pull(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to pull %s image", c.opts.Kind)
+	}
+
+	// nodes are configured with the address of the container, so it is given
+	// a fixed address which survives it being restarted.
+	fixedAddress, err := c.getFixedAddress(ctx)
+	if err != nil {
+		c.logger.Warn("failed to find a fixed address for the utility container, its address may change",
+			zap.String("kind", c.opts.Kind),
+			zap.Error(err))
+	}
+
+	if fixedAddress != "" {
+		err := c.createAndStart(ctx, fixedAddress)
+		if err == nil {
+			return nil
+		}
+
+		// networks without a user-configured subnet do not permit static
+		// addresses, in which case the container receives a dynamic address.
+		c.logger.Warn("failed to start utility container with a fixed address, its address may change",
+			zap.String("kind", c.opts.Kind),
+			zap.String("address", fixedAddress),
+			zap.Error(err))
+
+		err = c.dockerCli.ContainerRemove(ctx, c.opts.Name, container.RemoveOptions{
+			Force: true,
+		})
+		if err != nil && !errdefs.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to remove %s container", c.opts.Kind)
+		}
+	}
+
+	return c.createAndStart(ctx, "")
+}
+
+func (c *utilityContainer) createAndStart(ctx context.Context, ipAddress string) error {
+	var networkingConfig *network.NetworkingConfig
+	if ipAddress != "" {
+		networkingConfig = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				c.networkName: {
+					IPAMConfig: &network.EndpointIPAMConfig{
+						IPv4Address: ipAddress,
+					},
+				},
+			},
+		}
+	}
+
+	createResult, err := c.dockerCli.ContainerCreate(ctx, &container.Config{
+		Image: c.opts.Image,
+		Cmd:   c.opts.Cmd,
+		// this is not part of any cluster, so it has no cluster_id label and
+		// is neither listed nor cleaned up with the cluster nodes.
+		Labels: map[string]string{
+			"com.couchbase.dyncluster.type": c.opts.Kind,
+		},
+	}, &container.HostConfig{
+		NetworkMode: container.NetworkMode(c.networkName),
+		RestartPolicy: container.RestartPolicy{
+			Name: container.RestartPolicyUnlessStopped,
+		},
+	}, networkingConfig, nil, c.opts.Name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s container", c.opts.Kind)
+	}
+
+	// the initial files are written before the container starts, since some
+	// images have no shell with which to write them afterwards.
+	if c.opts.InitFiles != nil {
+		files, err := c.opts.InitFiles()
+		if err != nil {
+			return err
+		}
+
+		err = c.WriteFiles(ctx, createResult.ID, files)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.dockerCli.ContainerStart(ctx, createResult.ID, container.StartOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to start %s container", c.opts.Kind)
+	}
+
+	return nil
+}
+
+func (c *utilityContainer) getFixedAddress(ctx context.Context) (string, error) {
+	netInfo, err := c.dockerCli.NetworkInspect(ctx, c.networkName, network.InspectOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to inspect network")
+	}
+
+	if len(netInfo.IPAM.Config) < 1 {
+		return "", errors.New("network has no ipam config")
+	}
+	ipamConfig := netInfo.IPAM.Config[0]
+
+	ipAddress, err := utilityContainerAddress(ipamConfig.Subnet, ipamConfig.IPRange, c.opts.AddressIndex)
+	if err != nil {
+		return "", err
+	}
+
+	for _, endpoint := range netInfo.Containers {
+		endpointIP, _, _ := strings.Cut(endpoint.IPv4Address, "/")
+		if endpointIP == ipAddress {
+			return "", fmt.Errorf("address %s is already in use", ipAddress)
+		}
+	}
+
+	return ipAddress, nil
+}
+
+// utilityContainerAddress returns the address index places back from the
+// last address of the ip range of a network, or of its subnet if it has no ip
+// range, excluding the broadcast address.  Docker assigns addresses from the
+// start of the range, so these are the least likely to collide with a node.
+func utilityContainerAddress(subnet string, ipRange string, index int) (string, error) {
+	_, subnetNet, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse network subnet")
+	}
+
+	rangeNet := subnetNet
+	if ipRange != "" {
+		_, rangeNet, err = net.ParseCIDR(ipRange)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to parse network ip range")
+		}
+	}
+
+	if subnetNet.IP.To4() == nil || rangeNet.IP.To4() == nil {
+		return "", errors.New("only ipv4 networks are supported")
+	}
+
+	lastAddr := lastIPv4Address(rangeNet)
+	if lastAddr == lastIPv4Address(subnetNet) {
+		lastAddr--
+	}
+
+	firstAddr := binary.BigEndian.Uint32(rangeNet.IP.To4())
+	if index < 0 || uint64(lastAddr) < uint64(firstAddr)+uint64(index)+1 {
+		return "", fmt.Errorf("ip range %s has too few usable addresses", rangeNet)
+	}
+
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, lastAddr-uint32(index))
+	return ip.String(), nil
+}
+
+func lastIPv4Address(ipNet *net.IPNet) uint32 {
+	addr := binary.BigEndian.Uint32(ipNet.IP.To4())
+	mask := binary.BigEndian.Uint32(ipNet.Mask[len(ipNet.Mask)-net.IPv4len:])
+	return addr | ^mask
+}
+
+// ReadJSON reads a json file from the container into v, leaving v untouched
+// if the file does not exist.
+func (c *utilityContainer) ReadJSON(ctx context.Context, containerID string, filePath string, v any) error {
+	resp, _, err := c.dockerCli.CopyFromContainer(ctx, containerID, filePath)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return nil
+		}
+
+		return errors.Wrapf(err, "failed to read %s from %s container", filePath, c.opts.Kind)
+	}
+	defer resp.Close()
+
+	tarRdr := tar.NewReader(resp)
+	_, err = tarRdr.Next()
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s file", filePath)
+	}
+
+	dataBytes, err := io.ReadAll(tarRdr)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s data", filePath)
+	}
+
+	err = json.Unmarshal(dataBytes, v)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse %s data", filePath)
+	}
+
+	return nil
+}
+
+// WriteFiles writes files into the container, creating the directories which
+// contain them.  File names are relative to the root of the container.
+func (c *utilityContainer) WriteFiles(ctx context.Context, containerID string, files []utilityContainerFile) error {
+	tarBuf := bytes.NewBuffer(nil)
+	tarFile := tar.NewWriter(tarBuf)
+
+	var writtenDirs []string
+	for _, file := range files {
+		dirPath := path.Dir(file.Name)
+		if dirPath != "." && !slices.Contains(writtenDirs, dirPath) {
+			writtenDirs = append(writtenDirs, dirPath)
+			tarFile.WriteHeader(&tar.Header{
+				Name:     dirPath + "/",
+				Typeflag: tar.TypeDir,
+				Mode:     0755,
+			})
+		}
+
+		tarFile.WriteHeader(&tar.Header{
+			Name: file.Name,
+			Size: int64(len(file.Data)),
+			Mode: 0644,
+		})
+		tarFile.Write(file.Data)
+	}
+	tarFile.Close()
+
+	err := c.dockerCli.CopyToContainer(ctx, containerID, "/", tarBuf, container.CopyToContainerOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to write files to %s container", c.opts.Kind)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package dockerdeploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUtilityContainerAddress(t *testing.T) {
+	testCases := []struct {
+		name    string
+		subnet  string
+		ipRange string
+		index   int
+		want    string
+		wantErr bool
+	}{
+		{name: "subnet", subnet: "172.18.0.0/16", want: "172.18.255.254"},
+		{name: "second address", subnet: "172.18.0.0/16", index: 1, want: "172.18.255.253"},
+		{name: "range inside subnet", subnet: "192.168.106.0/24", ipRange: "192.168.106.64/26", want: "192.168.106.127"},
+		{name: "range at end of subnet", subnet: "192.168.106.0/24", ipRange: "192.168.106.128/25", want: "192.168.106.254"},
+		{name: "range too small", subnet: "10.0.0.0/24", ipRange: "10.0.0.254/31", wantErr: true},
+		{name: "index outside range", subnet: "10.0.0.0/24", ipRange: "10.0.0.252/30", index: 2, wantErr: true},
+		{name: "ipv6", subnet: "fd00::/64", wantErr: true},
+		{name: "invalid", subnet: "", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			addr, err := utilityContainerAddress(tc.subnet, tc.ipRange, tc.index)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.want, addr)
+		})
+	}
+}
@@ -83,6 +83,13 @@ func dockerPullAndPipe(ctx context.Context, logger *zap.Logger, cli *client.Clie
 }
 
 func dockerExecAndPipe(ctx context.Context, logger *zap.Logger, cli *client.Client, containerID string, cmd []string) error {
+	_, err := dockerExecAndCapture(ctx, logger, cli, containerID, cmd)
+	return err
+}
+
+// dockerExecAndCapture executes a command like dockerExecAndPipe, but also
+// returns the lines of output the command wrote.
+func dockerExecAndCapture(ctx context.Context, logger *zap.Logger, cli *client.Client, containerID string, cmd []string) ([]string, error) {
 	execID, err := cli.ContainerExecCreate(ctx, containerID, container.ExecOptions{
 		AttachStdout: true,
 		AttachStderr: true,
@@ -90,33 +97,35 @@ func dockerExecAndPipe(ctx context.Context, logger *zap.Logger, cli *client.Clie
 		Cmd:          cmd,
 	})
 	if err != nil {
-		return errors.Wrap(err, "failed to create exec")
+		return nil, errors.Wrap(err, "failed to create exec")
 	}
 
 	resp, err := cli.ContainerExecAttach(ctx, execID.ID, container.ExecStartOptions{
 		Tty: true,
 	})
 	if err != nil {
-		return errors.Wrap(err, "failed to start exec")
+		return nil, errors.Wrap(err, "failed to start exec")
 	}
 
+	var lines []string
 	scanner := bufio.NewScanner(resp.Reader)
 	for scanner.Scan() {
 		line := scanner.Text()
 
 		logger.Debug("docker exec output", zap.String("text", line))
+		lines = append(lines, line)
 	}
 
 	res, err := cli.ContainerExecInspect(ctx, execID.ID)
 	if err != nil {
-		return errors.Wrap(err, "failed to inspect exec")
+		return nil, errors.Wrap(err, "failed to inspect exec")
 	}
 
 	if res.ExitCode != 0 {
-		return fmt.Errorf("failed to execute process (exit code: %d)", res.ExitCode)
+		return nil, fmt.Errorf("failed to execute process (exit code: %d)", res.ExitCode)
 	}
 
-	return nil
+	return lines, nil
 }
 
 func isColumnarVersionEA(version string) bool {
@@ -5,3 +5,25 @@ import "errors"
 // ErrBucketAlreadyExists is returned when attempting to create a bucket
 // that already exists on the cluster.
 var ErrBucketAlreadyExists = errors.New("bucket already exists")
+
+// ErrNotSupported is wrapped by the errors deployers return for operations
+// they do not implement.  Use errors.Is to detect it.
+var ErrNotSupported = errors.New("operation not supported")
+
+type notSupportedError struct {
+	msg string
+}
+
+func (e *notSupportedError) Error() string {
+	return e.msg
+}
+
+func (e *notSupportedError) Unwrap() error {
+	return ErrNotSupported
+}
+
+// NewNotSupportedError returns an error with the given message which wraps
+// ErrNotSupported.
+func NewNotSupportedError(msg string) error {
+	return &notSupportedError{msg: msg}
+}
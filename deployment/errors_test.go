@@ -0,0 +1,19 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotSupportedError(t *testing.T) {
+	err := NewNotSupportedError("testdeploy does not support testing")
+	require.EqualError(t, err, "testdeploy does not support testing")
+	require.ErrorIs(t, err, ErrNotSupported)
+
+	wrappedErr := errors.Wrap(err, "failed to test")
+	require.ErrorIs(t, wrappedErr, ErrNotSupported)
+
+	require.NotErrorIs(t, errors.New("some other error"), ErrNotSupported)
+}
@@ -0,0 +1,194 @@
+package deployment
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+)
+
+const (
+	IndexStateOnline   = "online"
+	IndexStateDeferred = "deferred"
+)
+
+// Keyspace identifies the collection an index belongs to.  An empty scope or
+// collection is the default one.
+type Keyspace struct {
+	Bucket     string
+	Scope      string
+	Collection string
+}
+
+func (k Keyspace) ScopeName() string {
+	if k.Scope == "" {
+		return "_default"
+	}
+	return k.Scope
+}
+
+func (k Keyspace) CollectionName() string {
+	if k.Collection == "" {
+		return "_default"
+	}
+	return k.Collection
+}
+
+func (k Keyspace) String() string {
+	return fmt.Sprintf("%s.%s.%s", k.Bucket, k.ScopeName(), k.CollectionName())
+}
+
+// N1QL returns the keyspace escaped for use in a N1QL statement.
+func (k Keyspace) N1QL() string {
+	return fmt.Sprintf("%s.%s.%s",
+		escapeIdentifier(k.Bucket),
+		escapeIdentifier(k.ScopeName()),
+		escapeIdentifier(k.CollectionName()))
+}
+
+func escapeIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+type IndexInfo struct {
+	Name      string
+	Keyspace  Keyspace
+	IsPrimary bool
+	Keys      []string
+	Condition string
+	Partition string
+
+	// State is the state reported by the index service, which is online
+	// once the index is built and deferred until it is built.
+	State string
+}
+
+type CreateIndexOptions struct {
+	Name      string
+	Keyspace  Keyspace
+	IsPrimary bool
+	Keys      []string
+	Where     string
+
+	NumReplicas   int
+	PartitionBy   []string
+	NumPartitions int
+	Deferred      bool
+}
+
+type SearchIndexInfo struct {
+	Name       string
+	SourceName string
+	Ready      bool
+}
+
+type CreateSearchIndexOptions struct {
+	Name       string
+	SourceName string
+
+	// Definition is the index definition of the search service.
+	Definition map[string]interface{}
+}
+
+func NewCreateIndexOptions(bucketName string, index *clusterdef.Index) (*CreateIndexOptions, error) {
+	if index.Name == "" {
+		return nil, fmt.Errorf("indexes must have a name")
+	}
+	if index.Primary && len(index.Keys) > 0 {
+		return nil, fmt.Errorf("primary index %s cannot have keys", index.Name)
+	}
+	if !index.Primary && len(index.Keys) == 0 {
+		return nil, fmt.Errorf("index %s must have keys or be a primary index", index.Name)
+	}
+	if index.NumReplicas < 0 || index.NumPartitions < 0 {
+		return nil, fmt.Errorf("index %s cannot have negative replicas or partitions", index.Name)
+	}
+	if index.NumPartitions > 0 && len(index.PartitionBy) == 0 {
+		return nil, fmt.Errorf("index %s must be partitioned to have partitions", index.Name)
+	}
+
+	return &CreateIndexOptions{
+		Name: index.Name,
+		Keyspace: Keyspace{
+			Bucket:     bucketName,
+			Scope:      index.Scope,
+			Collection: index.Collection,
+		},
+		IsPrimary:     index.Primary,
+		Keys:          index.Keys,
+		Where:         index.Where,
+		NumReplicas:   index.NumReplicas,
+		PartitionBy:   index.PartitionBy,
+		NumPartitions: index.NumPartitions,
+		Deferred:      index.Deferred,
+	}, nil
+}
+
+func NewCreateSearchIndexOptions(bucketName string, index *clusterdef.SearchIndex) (*CreateSearchIndexOptions, error) {
+	if index.Name == "" {
+		return nil, fmt.Errorf("search indexes must have a name")
+	}
+
+	definition := make(map[string]interface{})
+	if index.Definition != "" {
+		err := json.Unmarshal([]byte(index.Definition), &definition)
+		if err != nil {
+			return nil, fmt.Errorf("invalid definition for search index %s: %w", index.Name, err)
+		}
+	}
+
+	return &CreateSearchIndexOptions{
+		Name:       index.Name,
+		SourceName: bucketName,
+		Definition: definition,
+	}, nil
+}
+
+// CreateIndexStatement builds the N1QL statement which creates an index.
+func CreateIndexStatement(opts *CreateIndexOptions) string {
+	var stmt strings.Builder
+	if opts.IsPrimary {
+		fmt.Fprintf(&stmt, "CREATE PRIMARY INDEX %s ON %s",
+			escapeIdentifier(opts.Name), opts.Keyspace.N1QL())
+	} else {
+		fmt.Fprintf(&stmt, "CREATE INDEX %s ON %s(%s)",
+			escapeIdentifier(opts.Name), opts.Keyspace.N1QL(), strings.Join(opts.Keys, ", "))
+	}
+
+	if len(opts.PartitionBy) > 0 {
+		fmt.Fprintf(&stmt, " PARTITION BY HASH(%s)", strings.Join(opts.PartitionBy, ", "))
+	}
+	if opts.Where != "" {
+		fmt.Fprintf(&stmt, " WHERE %s", opts.Where)
+	}
+
+	with := make(map[string]interface{})
+	if opts.NumReplicas > 0 {
+		with["num_replica"] = opts.NumReplicas
+	}
+	if opts.NumPartitions > 0 {
+		with["num_partition"] = opts.NumPartitions
+	}
+	if opts.Deferred {
+		with["defer_build"] = true
+	}
+	if len(with) > 0 {
+		withBytes, _ := json.Marshal(with)
+		fmt.Fprintf(&stmt, " WITH %s", withBytes)
+	}
+
+	return stmt.String()
+}
+
+func DropIndexStatement(keyspace Keyspace, indexName string) string {
+	return fmt.Sprintf("DROP INDEX %s ON %s", escapeIdentifier(indexName), keyspace.N1QL())
+}
+
+func BuildIndexesStatement(keyspace Keyspace, indexNames []string) string {
+	escapedNames := make([]string, len(indexNames))
+	for i, indexName := range indexNames {
+		escapedNames[i] = escapeIdentifier(indexName)
+	}
+	return fmt.Sprintf("BUILD INDEX ON %s(%s)", keyspace.N1QL(), strings.Join(escapedNames, ", "))
+}
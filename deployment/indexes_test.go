@@ -0,0 +1,121 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyspace(t *testing.T) {
+	keyspace := Keyspace{Bucket: "travel-sample"}
+	require.Equal(t, "travel-sample._default._default", keyspace.String())
+	require.Equal(t, "`travel-sample`.`_default`.`_default`", keyspace.N1QL())
+
+	keyspace = Keyspace{Bucket: "b`1", Scope: "inventory", Collection: "hotel"}
+	require.Equal(t, "`b``1`.`inventory`.`hotel`", keyspace.N1QL())
+}
+
+func TestNewCreateIndexOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		index   clusterdef.Index
+		wantErr bool
+	}{
+		{name: "keys", index: clusterdef.Index{Name: "idx", Keys: []string{"city"}}},
+		{name: "primary", index: clusterdef.Index{Name: "idx", Primary: true}},
+		{name: "missing name", index: clusterdef.Index{Keys: []string{"city"}}, wantErr: true},
+		{name: "missing keys", index: clusterdef.Index{Name: "idx"}, wantErr: true},
+		{name: "primary with keys", index: clusterdef.Index{Name: "idx", Primary: true, Keys: []string{"city"}}, wantErr: true},
+		{name: "negative replicas", index: clusterdef.Index{Name: "idx", Primary: true, NumReplicas: -1}, wantErr: true},
+		{name: "partitions without partitioning", index: clusterdef.Index{Name: "idx", Primary: true, NumPartitions: 8}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := NewCreateIndexOptions("travel-sample", &tt.index)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "travel-sample", opts.Keyspace.Bucket)
+		})
+	}
+}
+
+func TestNewCreateSearchIndexOptions(t *testing.T) {
+	opts, err := NewCreateSearchIndexOptions("travel-sample", &clusterdef.SearchIndex{
+		Name:       "hotels",
+		Definition: `{"params": {"doc_config": {"mode": "scope.collection.type_field"}}}`,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "travel-sample", opts.SourceName)
+	require.Contains(t, opts.Definition, "params")
+
+	_, err = NewCreateSearchIndexOptions("travel-sample", &clusterdef.SearchIndex{
+		Name:       "hotels",
+		Definition: `{"params"`,
+	})
+	require.Error(t, err)
+}
+
+func TestCreateIndexStatement(t *testing.T) {
+	tests := []struct {
+		name string
+		opts CreateIndexOptions
+		want string
+	}{
+		{
+			name: "primary",
+			opts: CreateIndexOptions{
+				Name:      "idx_primary",
+				Keyspace:  Keyspace{Bucket: "travel-sample"},
+				IsPrimary: true,
+			},
+			want: "CREATE PRIMARY INDEX `idx_primary` ON `travel-sample`.`_default`.`_default`",
+		},
+		{
+			name: "partial deferred",
+			opts: CreateIndexOptions{
+				Name:     "idx_city",
+				Keyspace: Keyspace{Bucket: "travel-sample", Scope: "inventory", Collection: "hotel"},
+				Keys:     []string{"city", "country"},
+				Where:    "free_parking = true",
+				Deferred: true,
+			},
+			want: "CREATE INDEX `idx_city` ON `travel-sample`.`inventory`.`hotel`(city, country)" +
+				" WHERE free_parking = true WITH {\"defer_build\":true}",
+		},
+		{
+			name: "partitioned with replicas",
+			opts: CreateIndexOptions{
+				Name:          "idx_name",
+				Keyspace:      Keyspace{Bucket: "travel-sample"},
+				Keys:          []string{"name"},
+				NumReplicas:   1,
+				PartitionBy:   []string{"META().id"},
+				NumPartitions: 8,
+			},
+			want: "CREATE INDEX `idx_name` ON `travel-sample`.`_default`.`_default`(name)" +
+				" PARTITION BY HASH(META().id) WITH {\"num_partition\":8,\"num_replica\":1}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, CreateIndexStatement(&tt.opts))
+		})
+	}
+}
+
+func TestDropAndBuildIndexStatements(t *testing.T) {
+	keyspace := Keyspace{Bucket: "travel-sample", Scope: "inventory", Collection: "hotel"}
+
+	require.Equal(t,
+		"DROP INDEX `idx_city` ON `travel-sample`.`inventory`.`hotel`",
+		DropIndexStatement(keyspace, "idx_city"))
+	require.Equal(t,
+		"BUILD INDEX ON `travel-sample`.`inventory`.`hotel`(`idx_city`, `idx_name`)",
+		BuildIndexesStatement(keyspace, []string{"idx_city", "idx_name"}))
+}
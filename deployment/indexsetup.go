@@ -0,0 +1,223 @@
+package deployment
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// DefaultIndexWaitTimeout is how long to wait for indexes to come online
+// unless told otherwise.
+const DefaultIndexWaitTimeout = 10 * time.Minute
+
+// DefinedIndexes are the indexes of the buckets of a cluster definition.
+type DefinedIndexes struct {
+	Indexes       []*CreateIndexOptions
+	SearchIndexes []*CreateSearchIndexOptions
+}
+
+func NewDefinedIndexes(buckets map[string]clusterdef.Bucket) (*DefinedIndexes, error) {
+	bucketNames := slices.Sorted(maps.Keys(buckets))
+
+	defined := &DefinedIndexes{}
+	for _, bucketName := range bucketNames {
+		bucketDef := buckets[bucketName]
+
+		for _, indexDef := range bucketDef.Indexes {
+			opts, err := NewCreateIndexOptions(bucketName, &indexDef)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid index for bucket %s", bucketName)
+			}
+			defined.Indexes = append(defined.Indexes, opts)
+		}
+
+		for _, indexDef := range bucketDef.SearchIndexes {
+			opts, err := NewCreateSearchIndexOptions(bucketName, &indexDef)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid search index for bucket %s", bucketName)
+			}
+			defined.SearchIndexes = append(defined.SearchIndexes, opts)
+		}
+	}
+
+	return defined, nil
+}
+
+func (i *DefinedIndexes) IsEmpty() bool {
+	return len(i.Indexes) == 0 && len(i.SearchIndexes) == 0
+}
+
+func (i *DefinedIndexes) hasIndex(index IndexInfo) bool {
+	return slices.ContainsFunc(i.Indexes, func(opts *CreateIndexOptions) bool {
+		return index.Name == opts.Name && index.Keyspace.String() == opts.Keyspace.String()
+	})
+}
+
+func (i *DefinedIndexes) hasSearchIndex(index SearchIndexInfo) bool {
+	return slices.ContainsFunc(i.SearchIndexes, func(opts *CreateSearchIndexOptions) bool {
+		return index.Name == opts.Name
+	})
+}
+
+// Create creates the defined indexes which do not already exist on the
+// cluster.
+func (i *DefinedIndexes) Create(
+	ctx context.Context,
+	logger *zap.Logger,
+	deployer Deployer,
+	clusterID string,
+) error {
+	if len(i.Indexes) > 0 {
+		indexes, err := deployer.ListIndexes(ctx, clusterID)
+		if err != nil {
+			return errors.Wrap(err, "failed to list indexes")
+		}
+
+		for _, opts := range i.Indexes {
+			if slices.ContainsFunc(indexes, func(index IndexInfo) bool {
+				return index.Name == opts.Name && index.Keyspace.String() == opts.Keyspace.String()
+			}) {
+				continue
+			}
+
+			err := deployer.CreateIndex(ctx, clusterID, opts)
+			if err != nil {
+				return errors.Wrapf(err, "failed to create index %s on %s", opts.Name, opts.Keyspace)
+			}
+			logger.Info("index created",
+				zap.String("index", opts.Name),
+				zap.String("keyspace", opts.Keyspace.String()))
+		}
+	}
+
+	if len(i.SearchIndexes) > 0 {
+		searchIndexes, err := deployer.ListSearchIndexes(ctx, clusterID)
+		if err != nil {
+			return errors.Wrap(err, "failed to list search indexes")
+		}
+
+		for _, opts := range i.SearchIndexes {
+			if slices.ContainsFunc(searchIndexes, func(index SearchIndexInfo) bool {
+				return index.Name == opts.Name
+			}) {
+				continue
+			}
+
+			err := deployer.CreateSearchIndex(ctx, clusterID, opts)
+			if err != nil {
+				return errors.Wrapf(err, "failed to create search index %s", opts.Name)
+			}
+			logger.Info("search index created",
+				zap.String("index", opts.Name),
+				zap.String("bucket", opts.SourceName))
+		}
+	}
+
+	return nil
+}
+
+// CreateAndWait creates the defined indexes and waits for them to come
+// online, which is how indexes are set up when a definition is applied.  Other
+// indexes of the cluster which are still being built are not waited for.
+func (i *DefinedIndexes) CreateAndWait(
+	ctx context.Context,
+	logger *zap.Logger,
+	deployer Deployer,
+	clusterID string,
+) error {
+	if i.IsEmpty() {
+		return nil
+	}
+
+	err := i.Create(ctx, logger, deployer, clusterID)
+	if err != nil {
+		return err
+	}
+
+	return waitForIndexes(ctx, logger, deployer, clusterID, DefaultIndexWaitTimeout, i)
+}
+
+// pendingIndexes returns the names of the indexes which are still being
+// built, limited to the defined indexes unless defined is nil.  Deferred
+// indexes are not pending, since they are only built once they are
+// explicitly built.
+func pendingIndexes(indexes []IndexInfo, searchIndexes []SearchIndexInfo, defined *DefinedIndexes) []string {
+	var pending []string
+	for _, index := range indexes {
+		if defined != nil && !defined.hasIndex(index) {
+			continue
+		}
+		if index.State != IndexStateOnline && index.State != IndexStateDeferred {
+			pending = append(pending, fmt.Sprintf("%s on %s (%s)", index.Name, index.Keyspace, index.State))
+		}
+	}
+	for _, index := range searchIndexes {
+		if defined != nil && !defined.hasSearchIndex(index) {
+			continue
+		}
+		if !index.Ready {
+			pending = append(pending, fmt.Sprintf("search index %s", index.Name))
+		}
+	}
+	return pending
+}
+
+// WaitForIndexes waits until none of the indexes of a cluster are being
+// built.  Search indexes are skipped on deployers which do not support them,
+// and clusters without the search service have none.
+func WaitForIndexes(
+	ctx context.Context,
+	logger *zap.Logger,
+	deployer Deployer,
+	clusterID string,
+	timeout time.Duration,
+) error {
+	return waitForIndexes(ctx, logger, deployer, clusterID, timeout, nil)
+}
+
+func waitForIndexes(
+	ctx context.Context,
+	logger *zap.Logger,
+	deployer Deployer,
+	clusterID string,
+	timeout time.Duration,
+	defined *DefinedIndexes,
+) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		indexes, err := deployer.ListIndexes(ctx, clusterID)
+		if err != nil {
+			return errors.Wrap(err, "failed to list indexes")
+		}
+
+		searchIndexes, err := deployer.ListSearchIndexes(ctx, clusterID)
+		if err != nil && !errors.Is(err, ErrNotSupported) {
+			return errors.Wrap(err, "failed to list search indexes")
+		}
+
+		pending := pendingIndexes(indexes, searchIndexes, defined)
+		if len(pending) == 0 {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for indexes: %s", strings.Join(pending, ", "))
+		}
+
+		logger.Info("waiting for indexes to come online", zap.Strings("pending", pending))
+
+		select {
+		case <-time.After(2 * time.Second):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
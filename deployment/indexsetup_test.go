@@ -0,0 +1,79 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDefinedIndexes(t *testing.T) {
+	indexes, err := NewDefinedIndexes(map[string]clusterdef.Bucket{
+		"b": {
+			Indexes: []clusterdef.Index{{Name: "idx_b", Keys: []string{"name"}}},
+		},
+		"a": {
+			Indexes:       []clusterdef.Index{{Name: "idx_a", Primary: true}},
+			SearchIndexes: []clusterdef.SearchIndex{{Name: "fts_a"}},
+		},
+		"c": {},
+	})
+	require.NoError(t, err)
+	require.False(t, indexes.IsEmpty())
+	require.Len(t, indexes.Indexes, 2)
+	require.Equal(t, "idx_a", indexes.Indexes[0].Name)
+	require.Equal(t, "idx_b", indexes.Indexes[1].Name)
+	require.Len(t, indexes.SearchIndexes, 1)
+	require.Equal(t, "a", indexes.SearchIndexes[0].SourceName)
+
+	_, err = NewDefinedIndexes(map[string]clusterdef.Bucket{
+		"a": {Indexes: []clusterdef.Index{{Name: "idx_a"}}},
+	})
+	require.Error(t, err)
+
+	indexes, err = NewDefinedIndexes(nil)
+	require.NoError(t, err)
+	require.True(t, indexes.IsEmpty())
+}
+
+func TestPendingIndexes(t *testing.T) {
+	keyspace := Keyspace{Bucket: "travel-sample"}
+
+	require.Empty(t, pendingIndexes([]IndexInfo{
+		{Name: "idx_a", Keyspace: keyspace, State: IndexStateOnline},
+		{Name: "idx_b", Keyspace: keyspace, State: IndexStateDeferred},
+	}, []SearchIndexInfo{
+		{Name: "fts_a", Ready: true},
+	}, nil))
+
+	require.Equal(t, []string{
+		"idx_c on travel-sample._default._default (building)",
+		"search index fts_b",
+	}, pendingIndexes([]IndexInfo{
+		{Name: "idx_a", Keyspace: keyspace, State: IndexStateOnline},
+		{Name: "idx_c", Keyspace: keyspace, State: "building"},
+	}, []SearchIndexInfo{
+		{Name: "fts_b", Ready: false},
+	}, nil))
+
+	// only the defined indexes are waited for when creating them
+	defined := &DefinedIndexes{
+		Indexes: []*CreateIndexOptions{
+			{Name: "idx_d", Keyspace: keyspace},
+		},
+		SearchIndexes: []*CreateSearchIndexOptions{
+			{Name: "fts_d"},
+		},
+	}
+	require.Equal(t, []string{
+		"idx_d on travel-sample._default._default (building)",
+		"search index fts_d",
+	}, pendingIndexes([]IndexInfo{
+		{Name: "idx_c", Keyspace: keyspace, State: "building"},
+		{Name: "idx_d", Keyspace: keyspace, State: "building"},
+		{Name: "idx_d", Keyspace: Keyspace{Bucket: "beer-sample"}, State: "building"},
+	}, []SearchIndexInfo{
+		{Name: "fts_b", Ready: false},
+		{Name: "fts_d", Ready: false},
+	}, defined))
+}
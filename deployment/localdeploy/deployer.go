@@ -5,6 +5,7 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/couchbase/gocbcorex"
 	"github.com/couchbaselabs/cbdinocluster/clusterdef"
 	"github.com/couchbaselabs/cbdinocluster/deployment"
 	"github.com/couchbaselabs/cbdinocluster/utils/versionident"
@@ -67,7 +68,7 @@ func (d *Deployer) NewCluster(ctx context.Context, def *clusterdef.Cluster) (dep
 		return nil, errors.New("local deployment only supports a single node")
 	}
 	if def.Columnar {
-		return nil, errors.New("columnar is not supported for local deploy")
+		return nil, deployment.NewNotSupportedError("columnar is not supported for local deploy")
 	}
 
 	nodeGrp := def.NodeGroups[0]
@@ -91,23 +92,23 @@ func (d *Deployer) NewCluster(ctx context.Context, def *clusterdef.Cluster) (dep
 }
 
 func (d *Deployer) GetDefinition(ctx context.Context, clusterID string) (*clusterdef.Cluster, error) {
-	return nil, errors.New("localdeploy does not support fetching the cluster definition")
+	return nil, deployment.NewNotSupportedError("localdeploy does not support fetching the cluster definition")
 }
 
 func (d *Deployer) UpdateClusterExpiry(ctx context.Context, clusterID string, newExpiryTime time.Time) error {
-	return errors.New("localdeploy does not support updating expiry")
+	return deployment.NewNotSupportedError("localdeploy does not support updating expiry")
 }
 
 func (d *Deployer) ModifyCluster(ctx context.Context, clusterID string, def *clusterdef.Cluster) error {
-	return errors.New("localdeploy does not support cluster modification")
+	return deployment.NewNotSupportedError("localdeploy does not support cluster modification")
 }
 
 func (d *Deployer) AddNode(ctx context.Context, clusterID string) (string, error) {
-	return "", errors.New("localdeploy does not support cluster node addition")
+	return "", deployment.NewNotSupportedError("localdeploy does not support cluster node addition")
 }
 
 func (d *Deployer) RemoveNode(ctx context.Context, clusterID string, nodeID string) error {
-	return errors.New("localdeploy does not support cluster node removal")
+	return deployment.NewNotSupportedError("localdeploy does not support cluster node removal")
 }
 
 func (d *Deployer) RemoveCluster(ctx context.Context, clusterID string) error {
@@ -146,140 +147,242 @@ func (d *Deployer) GetConnectInfo(ctx context.Context, clusterID string) (*deplo
 }
 
 func (d *Deployer) ListUsers(ctx context.Context, clusterID string) ([]deployment.UserInfo, error) {
-	return nil, errors.New("localdeploy does not support user modification")
+	return nil, deployment.NewNotSupportedError("localdeploy does not support user modification")
 }
 
 func (d *Deployer) CreateUser(ctx context.Context, clusterID string, opts *deployment.CreateUserOptions) error {
-	return errors.New("localdeploy does not support user management")
+	return deployment.NewNotSupportedError("localdeploy does not support user management")
 }
 
 func (d *Deployer) DeleteUser(ctx context.Context, clusterID string, username string) error {
-	return errors.New("localdeploy does not support user management")
+	return deployment.NewNotSupportedError("localdeploy does not support user management")
+}
+
+func (d *Deployer) CreateGroup(ctx context.Context, clusterID string, opts *deployment.CreateGroupOptions) error {
+	return deployment.NewNotSupportedError("localdeploy does not support user groups")
 }
 
 func (d *Deployer) ListBuckets(ctx context.Context, clusterID string) ([]deployment.BucketInfo, error) {
-	return nil, errors.New("localdeploy does not support bucket management")
+	return nil, deployment.NewNotSupportedError("localdeploy does not support bucket management")
 }
 
 func (d *Deployer) CreateBucket(ctx context.Context, clusterID string, opts *deployment.CreateBucketOptions) error {
-	return errors.New("localdeploy does not support user management")
+	return deployment.NewNotSupportedError("localdeploy does not support user management")
 }
 
 func (d *Deployer) DeleteBucket(ctx context.Context, clusterID string, bucketName string) error {
-	return errors.New("localdeploy does not support user management")
+	return deployment.NewNotSupportedError("localdeploy does not support user management")
+}
+
+func (d *Deployer) GetBucket(ctx context.Context, clusterID string, bucketName string) (*deployment.BucketDetails, error) {
+	return nil, deployment.NewNotSupportedError("localdeploy does not support bucket management")
+}
+
+func (d *Deployer) UpdateBucket(ctx context.Context, clusterID string, bucketName string, opts *deployment.UpdateBucketOptions) error {
+	return deployment.NewNotSupportedError("localdeploy does not support bucket management")
+}
+
+func (d *Deployer) FlushBucket(ctx context.Context, clusterID string, bucketName string) error {
+	return deployment.NewNotSupportedError("localdeploy does not support bucket management")
 }
 
 func (d *Deployer) GetCertificate(ctx context.Context, clusterID string) (string, error) {
-	return "", errors.New("localdeploy does not support getting the CA certificate")
+	return "", deployment.NewNotSupportedError("localdeploy does not support getting the CA certificate")
 }
 
 func (d *Deployer) GetGatewayCertificate(ctx context.Context, clusterID string) (string, error) {
-	return "", errors.New("localdeploy does not support getting gateway certificates")
+	return "", deployment.NewNotSupportedError("localdeploy does not support getting gateway certificates")
 }
 
 func (d *Deployer) GetMetrics(ctx context.Context, clusterID string) (string, error) {
-	return "", errors.New("localdeploy does not support getting metrics")
+	return "", deployment.NewNotSupportedError("localdeploy does not support getting metrics")
 }
 
 func (d *Deployer) ExecuteQuery(ctx context.Context, clusterID string, query string, opts *deployment.ExecuteQueryOptions) (string, error) {
-	return "", errors.New("localdeploy does not support executing queries")
+	return "", deployment.NewNotSupportedError("localdeploy does not support executing queries")
 }
 
 func (d *Deployer) ListCollections(ctx context.Context, clusterID string, bucketName string) ([]deployment.ScopeInfo, error) {
-	return nil, errors.New("localdeploy does not support getting collections")
+	return nil, deployment.NewNotSupportedError("localdeploy does not support getting collections")
 }
 
 func (d *Deployer) CreateScope(ctx context.Context, clusterID string, bucketName, scopeName string) error {
-	return errors.New("localdeploy does not support creating scopes")
+	return deployment.NewNotSupportedError("localdeploy does not support creating scopes")
 }
 
 func (d *Deployer) CreateCollection(ctx context.Context, clusterID string, bucketName, scopeName, collectionName string) error {
-	return errors.New("localdeploy does not support creating collections")
+	return deployment.NewNotSupportedError("localdeploy does not support creating collections")
 }
 
 func (d *Deployer) DeleteScope(ctx context.Context, clusterID string, bucketName, scopeName string) error {
-	return errors.New("localdeploy does not support deleting scopes")
+	return deployment.NewNotSupportedError("localdeploy does not support deleting scopes")
 }
 
 func (d *Deployer) DeleteCollection(ctx context.Context, clusterID string, bucketName, scopeName, collectionName string) error {
-	return errors.New("localdeploy does not support deleting collections")
+	return deployment.NewNotSupportedError("localdeploy does not support deleting collections")
 }
 
 func (d *Deployer) BlockNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string, trafficType deployment.BlockNodeTrafficType, rejectType string) error {
-	return errors.New("localdeploy does not support traffic control")
+	return deployment.NewNotSupportedError("localdeploy does not support traffic control")
 }
 
 func (d *Deployer) AllowNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string) error {
-	return errors.New("localdeploy does not support traffic control")
+	return deployment.NewNotSupportedError("localdeploy does not support traffic control")
 }
 
 func (d *Deployer) PartitionNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string, rejectType string) error {
-	return errors.New("localdeploy does not support traffic control")
+	return deployment.NewNotSupportedError("localdeploy does not support traffic control")
+}
+
+func (d *Deployer) DegradeNodeTraffic(ctx context.Context, clusterID string, nodeIDs []string, opts *deployment.DegradeNodeTrafficOptions) error {
+	return deployment.NewNotSupportedError("localdeploy does not support traffic control")
+}
+
+func (d *Deployer) PartitionNodeGroups(ctx context.Context, clusterID string, spec *deployment.PartitionSpec, rejectType string) error {
+	return deployment.NewNotSupportedError("localdeploy does not support traffic control")
+}
+
+func (d *Deployer) ListNodeTrafficRules(ctx context.Context, clusterID string) ([]deployment.NodeTrafficRules, error) {
+	return nil, deployment.NewNotSupportedError("localdeploy does not support traffic control")
 }
 
 func (d *Deployer) CollectLogs(ctx context.Context, clusterID string, destPath string) ([]string, error) {
-	return nil, errors.New("localdeploy does not support log collection")
+	return nil, deployment.NewNotSupportedError("localdeploy does not support log collection")
 }
 
 func (d *Deployer) ListImages(ctx context.Context) ([]deployment.Image, error) {
-	return nil, errors.New("localdeploy does not support image listing")
+	return nil, deployment.NewNotSupportedError("localdeploy does not support image listing")
 }
 
 func (d *Deployer) SearchImages(ctx context.Context, version string) ([]deployment.Image, error) {
-	return nil, errors.New("localdeploy does not support image search")
+	return nil, deployment.NewNotSupportedError("localdeploy does not support image search")
 }
 
 func (d *Deployer) PauseNode(ctx context.Context, clusterID string, nodeIDs []string) error {
-	return errors.New("localdeploy does not support node pausing")
+	return deployment.NewNotSupportedError("localdeploy does not support node pausing")
 }
 
 func (d *Deployer) UnpauseNode(ctx context.Context, clusterID string, nodeIDs []string) error {
-	return errors.New("localdeploy does not support node pausing")
+	return deployment.NewNotSupportedError("localdeploy does not support node pausing")
 }
 
 func (d *Deployer) LoadSampleBucket(ctx context.Context, clusterID string, bucketName string) error {
-	return errors.New("localdeploy does not support loading sample buckets")
+	return deployment.NewNotSupportedError("localdeploy does not support loading sample buckets")
 }
 
 func (d *Deployer) RedeployCluster(ctx context.Context, clusterID string) error {
-	return errors.New("localdeploy does not support redeploy cluster")
+	return deployment.NewNotSupportedError("localdeploy does not support redeploy cluster")
 }
 
 func (d *Deployer) CreateCapellaLink(ctx context.Context, columnarID, linkName, clusterId, directID string) error {
-	return errors.New("localdeploy does not support create capella link")
+	return deployment.NewNotSupportedError("localdeploy does not support create capella link")
 }
 
 func (d *Deployer) CreateS3Link(ctx context.Context, columnarID, linkName, region, endpoint, accessKey, secretKey string) error {
-	return errors.New("localdeploy does not support create S3 link")
+	return deployment.NewNotSupportedError("localdeploy does not support create S3 link")
 }
 
 func (d *Deployer) DropLink(ctx context.Context, columnarID, linkName string) error {
-	return errors.New("localdeploy does not support drop link")
+	return deployment.NewNotSupportedError("localdeploy does not support drop link")
 }
 
 func (d *Deployer) UpgradeCluster(ctx context.Context, clusterID string, CurrentImages string, NewImage string) error {
-	return errors.New("localdeploy does not support upgrade cluster command")
+	return deployment.NewNotSupportedError("localdeploy does not support upgrade cluster command")
 }
 
 func (d *Deployer) EnableDataApi(ctx context.Context, clusterID string) error {
-	return errors.New("localdeploy does not support enabling data api")
+	return deployment.NewNotSupportedError("localdeploy does not support enabling data api")
 }
 
 func (d *Deployer) FailOverNode(ctx context.Context, clusterID string, nodeID string, failOverType deployment.FailOverType, allowUnsafe bool) error {
-	return errors.New("localdeploy does not support failing over a node")
+	return deployment.NewNotSupportedError("localdeploy does not support failing over a node")
 }
 func (d *Deployer) SetNodeRecovery(ctx context.Context, clusterID string, nodeID string, recoverType deployment.RecoveryType) error {
-	return errors.New("localdeploy does not support failover recovery")
+	return deployment.NewNotSupportedError("localdeploy does not support failover recovery")
 }
 
 func (d *Deployer) RebalanceCluster(ctx context.Context, clusterID string, nodesToEject []string) error {
-	return errors.New("localdeploy does not support rebalance cluster")
+	return deployment.NewNotSupportedError("localdeploy does not support rebalance cluster")
 }
 
 func (d *Deployer) KillCouchbase(ctx context.Context, clusterID string, nodes []string) error {
-	return errors.New("localdeploy does not support killing couchbase process")
+	return deployment.NewNotSupportedError("localdeploy does not support killing couchbase process")
 }
 
 func (d *Deployer) SetAutoFailover(ctx context.Context, clusterID string, enabled bool, timeout int) error {
-	return errors.New("localdeploy does not support setting auto-failover")
+	return deployment.NewNotSupportedError("localdeploy does not support setting auto-failover")
+}
+
+func (d *Deployer) StopCluster(ctx context.Context, clusterID string) error {
+	return deployment.NewNotSupportedError("localdeploy does not support stopping clusters")
+}
+
+func (d *Deployer) StartCluster(ctx context.Context, clusterID string) error {
+	return deployment.NewNotSupportedError("localdeploy does not support starting clusters")
+}
+
+func (d *Deployer) GetXdcrTarget(ctx context.Context, clusterID string) (*deployment.XdcrTarget, error) {
+	return nil, deployment.NewNotSupportedError("localdeploy does not support xdcr")
+}
+
+func (d *Deployer) ListXdcrRemotes(ctx context.Context, clusterID string) ([]deployment.XdcrRemoteInfo, error) {
+	return nil, deployment.NewNotSupportedError("localdeploy does not support xdcr")
+}
+
+func (d *Deployer) CreateXdcrRemote(ctx context.Context, clusterID string, opts *deployment.CreateXdcrRemoteOptions) error {
+	return deployment.NewNotSupportedError("localdeploy does not support xdcr")
+}
+
+func (d *Deployer) ListXdcrReplications(ctx context.Context, clusterID string) ([]deployment.XdcrReplicationInfo, error) {
+	return nil, deployment.NewNotSupportedError("localdeploy does not support xdcr")
+}
+
+func (d *Deployer) CreateXdcrReplication(ctx context.Context, clusterID string, opts *deployment.CreateXdcrReplicationOptions) (string, error) {
+	return "", deployment.NewNotSupportedError("localdeploy does not support xdcr")
+}
+
+func (d *Deployer) SetXdcrReplicationPaused(ctx context.Context, clusterID string, replicationID string, paused bool) error {
+	return deployment.NewNotSupportedError("localdeploy does not support xdcr")
+}
+
+func (d *Deployer) DeleteXdcrReplication(ctx context.Context, clusterID string, replicationID string) error {
+	return deployment.NewNotSupportedError("localdeploy does not support xdcr")
+}
+
+func (d *Deployer) ListIndexes(ctx context.Context, clusterID string) ([]deployment.IndexInfo, error) {
+	return nil, deployment.NewNotSupportedError("localdeploy does not support indexes")
+}
+
+func (d *Deployer) CreateIndex(ctx context.Context, clusterID string, opts *deployment.CreateIndexOptions) error {
+	return deployment.NewNotSupportedError("localdeploy does not support indexes")
+}
+
+func (d *Deployer) DropIndex(ctx context.Context, clusterID string, keyspace deployment.Keyspace, indexName string) error {
+	return deployment.NewNotSupportedError("localdeploy does not support indexes")
+}
+
+func (d *Deployer) BuildIndexes(ctx context.Context, clusterID string, keyspace deployment.Keyspace, indexNames []string) error {
+	return deployment.NewNotSupportedError("localdeploy does not support indexes")
+}
+
+func (d *Deployer) ListSearchIndexes(ctx context.Context, clusterID string) ([]deployment.SearchIndexInfo, error) {
+	return nil, deployment.NewNotSupportedError("localdeploy does not support search indexes")
+}
+
+func (d *Deployer) CreateSearchIndex(ctx context.Context, clusterID string, opts *deployment.CreateSearchIndexOptions) error {
+	return deployment.NewNotSupportedError("localdeploy does not support search indexes")
+}
+
+func (d *Deployer) DropSearchIndex(ctx context.Context, clusterID string, indexName string) error {
+	return deployment.NewNotSupportedError("localdeploy does not support search indexes")
+}
+
+func (d *Deployer) OpenBucketAgent(ctx context.Context, clusterID string, bucketName string, opts *deployment.OpenBucketAgentOptions) (*gocbcorex.Agent, error) {
+	return nil, deployment.NewNotSupportedError("localdeploy does not support document operations")
+}
+
+// Capabilities is empty, since localdeploy only supports allocating and
+// removing its single local node, which every deployer supports.
+func (d *Deployer) Capabilities(ctx context.Context) ([]deployment.Capability, error) {
+	return []deployment.Capability{}, nil
 }
@@ -0,0 +1,88 @@
+package deployment
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+type PartitionGroup struct {
+	Name    string
+	NodeIDs []string
+}
+
+type PartitionRule struct {
+	// From and To are the names of the groups the rule applies to.  Traffic
+	// initiated by the nodes of From towards the nodes of To is blocked.
+	From string
+	To   string
+
+	// Bidirectional blocks all traffic between the groups, in both directions.
+	Bidirectional bool
+}
+
+// PartitionSpec describes a network partition between named groups of nodes.
+// Nodes which are not part of any group are unaffected.
+type PartitionSpec struct {
+	Groups []PartitionGroup
+	Rules  []PartitionRule
+}
+
+func (s *PartitionSpec) Validate() error {
+	groupNames := make(map[string]bool)
+	nodeGroups := make(map[string]string)
+	for _, group := range s.Groups {
+		if group.Name == "" {
+			return errors.New("partition groups must be named")
+		}
+		if groupNames[group.Name] {
+			return fmt.Errorf("duplicate partition group `%s`", group.Name)
+		}
+		groupNames[group.Name] = true
+
+		if len(group.NodeIDs) == 0 {
+			return fmt.Errorf("partition group `%s` has no nodes", group.Name)
+		}
+
+		for _, nodeID := range group.NodeIDs {
+			if otherGroup, ok := nodeGroups[nodeID]; ok {
+				return fmt.Errorf("node `%s` is in both partition groups `%s` and `%s`", nodeID, otherGroup, group.Name)
+			}
+			nodeGroups[nodeID] = group.Name
+		}
+	}
+
+	if len(s.Rules) == 0 {
+		return errors.New("partition has no rules")
+	}
+
+	for _, rule := range s.Rules {
+		if !groupNames[rule.From] {
+			return fmt.Errorf("unknown partition group `%s`", rule.From)
+		}
+		if !groupNames[rule.To] {
+			return fmt.Errorf("unknown partition group `%s`", rule.To)
+		}
+		if rule.From == rule.To {
+			return fmt.Errorf("cannot partition group `%s` from itself", rule.From)
+		}
+	}
+
+	return nil
+}
+
+// GroupNodeIDs returns the node ids of a group by name.
+func (s *PartitionSpec) GroupNodeIDs(name string) []string {
+	for _, group := range s.Groups {
+		if group.Name == name {
+			return group.NodeIDs
+		}
+	}
+	return nil
+}
+
+type NodeTrafficRules struct {
+	NodeID    string
+	IPAddress string
+	Rules     []string
+}
@@ -0,0 +1,77 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionSpecValidate(t *testing.T) {
+	groups := []PartitionGroup{
+		{Name: "a", NodeIDs: []string{"n1", "n2"}},
+		{Name: "b", NodeIDs: []string{"n3"}},
+	}
+
+	tests := []struct {
+		name    string
+		spec    PartitionSpec
+		wantErr bool
+	}{
+		{
+			name: "bidirectional",
+			spec: PartitionSpec{Groups: groups, Rules: []PartitionRule{{From: "a", To: "b", Bidirectional: true}}},
+		},
+		{
+			name: "one-way",
+			spec: PartitionSpec{Groups: groups, Rules: []PartitionRule{{From: "b", To: "a"}}},
+		},
+		{
+			name:    "no rules",
+			spec:    PartitionSpec{Groups: groups},
+			wantErr: true,
+		},
+		{
+			name:    "unknown group",
+			spec:    PartitionSpec{Groups: groups, Rules: []PartitionRule{{From: "a", To: "c"}}},
+			wantErr: true,
+		},
+		{
+			name:    "self partition",
+			spec:    PartitionSpec{Groups: groups, Rules: []PartitionRule{{From: "a", To: "a"}}},
+			wantErr: true,
+		},
+		{
+			name: "node in two groups",
+			spec: PartitionSpec{
+				Groups: []PartitionGroup{
+					{Name: "a", NodeIDs: []string{"n1"}},
+					{Name: "b", NodeIDs: []string{"n1"}},
+				},
+				Rules: []PartitionRule{{From: "a", To: "b"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate group",
+			spec: PartitionSpec{
+				Groups: []PartitionGroup{
+					{Name: "a", NodeIDs: []string{"n1"}},
+					{Name: "a", NodeIDs: []string{"n2"}},
+				},
+				Rules: []PartitionRule{{From: "a", To: "a"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.spec.Validate()
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package deployment
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Role is a Couchbase RBAC role, optionally restricted to a bucket, scope or
+// collection.  A Bucket of "*" applies the role to every bucket.
+type Role struct {
+	Name       string
+	Bucket     string
+	Scope      string
+	Collection string
+}
+
+var roleRegexp = regexp.MustCompile(`^([a-z0-9_]+)(?:\[([^\]]*)\])?$`)
+
+// ParseRole parses a role in the form used by the server, such as
+// `security_admin`, `query_manage_index[*]` or
+// `data_reader[travel-sample:inventory.airline]`.
+func ParseRole(s string) (Role, error) {
+	matches := roleRegexp.FindStringSubmatch(strings.TrimSpace(s))
+	if matches == nil {
+		return Role{}, fmt.Errorf("invalid role %q, expected name[bucket:scope.collection]", s)
+	}
+
+	role := Role{Name: matches[1]}
+	if matches[2] == "" {
+		if strings.Contains(s, "[") {
+			return Role{}, fmt.Errorf("invalid role %q, the resource must not be empty", s)
+		}
+		return role, nil
+	}
+
+	bucket, keyspace, hasKeyspace := strings.Cut(matches[2], ":")
+	role.Bucket = bucket
+	if hasKeyspace {
+		scope, collection, hasCollection := strings.Cut(keyspace, ".")
+		if scope == "" || (hasCollection && collection == "") {
+			return Role{}, fmt.Errorf("invalid role %q, the scope and collection must not be empty", s)
+		}
+		role.Scope = scope
+		role.Collection = collection
+	}
+
+	if role.Bucket == "" {
+		return Role{}, fmt.Errorf("invalid role %q, the bucket must not be empty", s)
+	}
+
+	return role, nil
+}
+
+func ParseRoles(strs []string) ([]Role, error) {
+	var roles []Role
+	for _, str := range strs {
+		role, err := ParseRole(str)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+func (r Role) String() string {
+	if r.Bucket == "" {
+		return r.Name
+	}
+
+	// the server reports the scope and collection of a bucket-wide role as
+	// wildcards, which are equivalent to leaving them out.
+	resource := r.Bucket
+	if r.Scope != "" && r.Scope != "*" {
+		resource += ":" + r.Scope
+		if r.Collection != "" && r.Collection != "*" {
+			resource += "." + r.Collection
+		}
+	}
+
+	return r.Name + "[" + resource + "]"
+}
+
+// legacyReadRoles and legacyWriteRoles are the roles granted to users created
+// with CanRead and CanWrite, rather than with explicit roles.
+var (
+	legacyReadRoles = []string{
+		"ro_admin",
+		"analytics_reader",
+		"data_reader[*]",
+		"views_reader[*]",
+		"query_select[*]",
+		"fts_searcher[*]",
+	}
+	legacyWriteRoles = []string{
+		"admin",
+	}
+)
+
+// UsesLegacyRoles returns whether a user is granted the roles matching CanRead
+// and CanWrite.  Users with explicit roles, groups or in the external domain
+// are not, since their access is defined by those instead.
+func (o *CreateUserOptions) UsesLegacyRoles() bool {
+	return len(o.Roles) == 0 && len(o.Groups) == 0 && !o.External
+}
+
+// EffectiveRoles returns the roles a user should be created with, which are
+// the explicit roles, or the roles matching CanRead and CanWrite when the user
+// uses the legacy roles.
+func (o *CreateUserOptions) EffectiveRoles() []string {
+	if !o.UsesLegacyRoles() {
+		return o.Roles
+	}
+	if o.CanWrite {
+		return legacyWriteRoles
+	}
+	if o.CanRead {
+		return legacyReadRoles
+	}
+	return nil
+}
+
+// RolesCanRead and RolesCanWrite derive the legacy read and write flags of a
+// user from its roles.
+func RolesCanRead(roles []string) bool {
+	for _, str := range roles {
+		role, err := ParseRole(str)
+		if err != nil {
+			continue
+		}
+		switch role.Name {
+		case "admin", "data_reader":
+			return true
+		}
+	}
+	return false
+}
+
+func RolesCanWrite(roles []string) bool {
+	for _, str := range roles {
+		role, err := ParseRole(str)
+		if err != nil {
+			continue
+		}
+		switch role.Name {
+		case "admin", "data_writer":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Role
+		wantErr bool
+	}{
+		{name: "cluster role", input: "security_admin", want: Role{Name: "security_admin"}},
+		{name: "all buckets", input: "query_manage_index[*]", want: Role{Name: "query_manage_index", Bucket: "*"}},
+		{name: "bucket", input: "data_reader[travel-sample]", want: Role{Name: "data_reader", Bucket: "travel-sample"}},
+		{
+			name:  "scope",
+			input: "data_reader[travel-sample:inventory]",
+			want:  Role{Name: "data_reader", Bucket: "travel-sample", Scope: "inventory"},
+		},
+		{
+			name:  "collection",
+			input: "data_reader[travel-sample:inventory.airline]",
+			want:  Role{Name: "data_reader", Bucket: "travel-sample", Scope: "inventory", Collection: "airline"},
+		},
+		{name: "surrounding whitespace is trimmed", input: " admin ", want: Role{Name: "admin"}},
+		{name: "empty", input: "", wantErr: true},
+		{name: "empty resource", input: "data_reader[]", wantErr: true},
+		{name: "unterminated resource", input: "data_reader[default", wantErr: true},
+		{name: "empty bucket", input: "data_reader[:inventory]", wantErr: true},
+		{name: "empty collection", input: "data_reader[default:inventory.]", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRole(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRoleString(t *testing.T) {
+	for _, roleStr := range []string{
+		"security_admin",
+		"query_manage_index[*]",
+		"data_reader[travel-sample]",
+		"data_reader[travel-sample:inventory]",
+		"data_reader[travel-sample:inventory.airline]",
+	} {
+		role, err := ParseRole(roleStr)
+		require.NoError(t, err)
+		require.Equal(t, roleStr, role.String())
+	}
+
+	require.Equal(t, "data_reader[*]",
+		Role{Name: "data_reader", Bucket: "*", Scope: "*", Collection: "*"}.String())
+}
+
+func TestCreateUserOptionsEffectiveRoles(t *testing.T) {
+	require.Nil(t, (&CreateUserOptions{}).EffectiveRoles())
+	require.Equal(t, []string{"admin"}, (&CreateUserOptions{CanRead: true, CanWrite: true}).EffectiveRoles())
+	require.Contains(t, (&CreateUserOptions{CanRead: true}).EffectiveRoles(), "data_reader[*]")
+	require.Equal(t, []string{"security_admin"},
+		(&CreateUserOptions{CanWrite: true, Roles: []string{"security_admin"}}).EffectiveRoles())
+
+	// users with groups or in the external domain are not granted the legacy
+	// roles, which would otherwise make them admins by default
+	require.Nil(t, (&CreateUserOptions{CanRead: true, CanWrite: true, Groups: []string{"devs"}}).EffectiveRoles())
+	require.Nil(t, (&CreateUserOptions{CanRead: true, CanWrite: true, External: true}).EffectiveRoles())
+	require.Equal(t, []string{"security_admin"},
+		(&CreateUserOptions{CanWrite: true, External: true, Roles: []string{"security_admin"}}).EffectiveRoles())
+}
@@ -0,0 +1,55 @@
+package deployment
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/couchbaselabs/cbdinocluster/clusterdef"
+)
+
+// NewCreateUserOptions validates a user of a cluster definition, returning
+// the options to create it with.
+func NewCreateUserOptions(user *clusterdef.User) (*CreateUserOptions, error) {
+	if user.Username == "" {
+		return nil, errors.New("users must have a username")
+	}
+	if user.External && user.Password != "" {
+		return nil, fmt.Errorf("external user `%s` cannot have a password", user.Username)
+	}
+	if !user.External && user.Password == "" {
+		return nil, fmt.Errorf("user `%s` must have a password", user.Username)
+	}
+
+	_, err := ParseRoles(user.Roles)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user `%s`: %w", user.Username, err)
+	}
+
+	return &CreateUserOptions{
+		Username: user.Username,
+		Password: user.Password,
+		Roles:    user.Roles,
+		Groups:   user.Groups,
+		External: user.External,
+	}, nil
+}
+
+// NewCreateGroupOptions validates a group of a cluster definition, returning
+// the options to create it with.
+func NewCreateGroupOptions(group *clusterdef.Group) (*CreateGroupOptions, error) {
+	if group.Name == "" {
+		return nil, errors.New("groups must have a name")
+	}
+
+	_, err := ParseRoles(group.Roles)
+	if err != nil {
+		return nil, fmt.Errorf("invalid group `%s`: %w", group.Name, err)
+	}
+
+	return &CreateGroupOptions{
+		Name:         group.Name,
+		Description:  group.Description,
+		Roles:        group.Roles,
+		LdapGroupRef: group.LdapGroupRef,
+	}, nil
+}
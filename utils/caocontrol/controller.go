@@ -394,6 +394,51 @@ func (c *Controller) ListNamespaces(ctx context.Context) (*corev1.NamespaceList,
 	return namespaces, nil
 }
 
+func (c *Controller) GetNamespace(ctx context.Context, namespace string) (*corev1.Namespace, error) {
+	kubes, err := kubernetes.NewForConfig(c.restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create kubernetes client")
+	}
+
+	ns, err := kubes.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get namespace")
+	}
+
+	return ns, nil
+}
+
+// UpdateNamespaceLabels sets labels on a namespace, leaving its other labels
+// unchanged.
+func (c *Controller) UpdateNamespaceLabels(ctx context.Context, namespace string, labels map[string]string) error {
+	kubes, err := kubernetes.NewForConfig(c.restConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to create kubernetes client")
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		ns, err := kubes.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		if ns.Labels == nil {
+			ns.Labels = make(map[string]string)
+		}
+		for key, value := range labels {
+			ns.Labels[key] = value
+		}
+
+		_, err = kubes.CoreV1().Namespaces().Update(ctx, ns, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to update namespace labels")
+	}
+
+	return nil
+}
+
 func (c *Controller) CreateNamespace(ctx context.Context, namespace string, labels map[string]string) error {
 	c.logger.Info("creating namespace", zap.String("namespace", namespace))
 
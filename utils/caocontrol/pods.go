@@ -0,0 +1,147 @@
+package caocontrol
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+func (c *Controller) ListPods(ctx context.Context, namespace string, labelSelector string) ([]corev1.Pod, error) {
+	kubes, err := kubernetes.NewForConfig(c.restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create kubernetes client")
+	}
+
+	pods, err := kubes.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list pods")
+	}
+
+	return pods.Items, nil
+}
+
+// ExecInPod runs a command in a container of a pod, returning its output.  A
+// command which exits with a non-zero status returns an error which includes
+// its stderr.
+func (c *Controller) ExecInPod(
+	ctx context.Context,
+	namespace string,
+	podName string,
+	containerName string,
+	cmd []string,
+) (string, error) {
+	c.logger.Debug("executing command in pod",
+		zap.String("namespace", namespace),
+		zap.String("pod", podName),
+		zap.Strings("cmd", cmd))
+
+	kubes, err := kubernetes.NewForConfig(c.restConfig)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create kubernetes client")
+	}
+
+	req := kubes.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: containerName,
+			Command:   cmd,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(c.restConfig, "POST", req.URL())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create pod executor")
+	}
+
+	var stdout, stderr bytes.Buffer
+	err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to execute command in pod: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+func (c *Controller) ListNetworkPolicies(ctx context.Context, namespace string, labelSelector string) ([]networkingv1.NetworkPolicy, error) {
+	kubes, err := kubernetes.NewForConfig(c.restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create kubernetes client")
+	}
+
+	policies, err := kubes.NetworkingV1().NetworkPolicies(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list network policies")
+	}
+
+	return policies.Items, nil
+}
+
+// ApplyNetworkPolicy creates a network policy, replacing any existing policy
+// with the same name.
+func (c *Controller) ApplyNetworkPolicy(ctx context.Context, namespace string, policy *networkingv1.NetworkPolicy) error {
+	kubes, err := kubernetes.NewForConfig(c.restConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to create kubernetes client")
+	}
+
+	policies := kubes.NetworkingV1().NetworkPolicies(namespace)
+
+	existing, err := policies.Get(ctx, policy.Name, metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return errors.Wrap(err, "failed to get network policy")
+		}
+
+		_, err = policies.Create(ctx, policy, metav1.CreateOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to create network policy")
+		}
+
+		return nil
+	}
+
+	policy.ResourceVersion = existing.ResourceVersion
+	_, err = policies.Update(ctx, policy, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to update network policy")
+	}
+
+	return nil
+}
+
+// DeleteNetworkPolicy deletes a network policy, succeeding if it does not
+// exist.
+func (c *Controller) DeleteNetworkPolicy(ctx context.Context, namespace string, name string) error {
+	kubes, err := kubernetes.NewForConfig(c.restConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to create kubernetes client")
+	}
+
+	err = kubes.NetworkingV1().NetworkPolicies(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to delete network policy")
+	}
+
+	return nil
+}
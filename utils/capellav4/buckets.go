@@ -29,6 +29,16 @@ type BucketInfo struct {
 	FlushEnabled             bool   `json:"flushEnabled"`
 	TimeToLiveInSeconds      int    `json:"timeToLiveInSeconds"`
 	EvictionPolicy           string `json:"evictionPolicy"`
+
+	// Stats is only returned when getting a single bucket.
+	Stats *BucketStats `json:"stats,omitempty"`
+}
+
+type BucketStats struct {
+	ItemCount       uint64 `json:"itemCount"`
+	OpsPerSecond    int    `json:"opsPerSecond"`
+	DiskUsedInMib   uint64 `json:"diskUsedInMib"`
+	MemoryUsedInMib uint64 `json:"memoryUsedInMib"`
 }
 
 type listBucketsResponse struct {
@@ -55,6 +65,7 @@ type CreateBucketRequest struct {
 	Replicas                 int    `json:"replicas,omitempty"`
 	FlushEnabled             bool   `json:"flushEnabled,omitempty"`
 	TimeToLiveInSeconds      int    `json:"timeToLiveInSeconds,omitempty"`
+	EvictionPolicy           string `json:"evictionPolicy,omitempty"`
 }
 
 type CreateBucketResponse struct {
@@ -74,6 +85,41 @@ func (c *Client) CreateBucket(
 	return resp, nil
 }
 
+func (c *Client) GetBucket(ctx context.Context, orgID, projectID, clusterID, bucketID string) (*BucketInfo, error) {
+	resp := &BucketInfo{}
+	path := fmt.Sprintf("/v4/organizations/%s/projects/%s/clusters/%s/buckets/%s",
+		orgID, projectID, clusterID, url.PathEscape(bucketID))
+	if err := c.doRead(ctx, http.MethodGet, path, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// The v4 API replaces every mutable setting, so all of them must be sent.
+type UpdateBucketRequest struct {
+	MemoryAllocationInMb int    `json:"memoryAllocationInMb"`
+	DurabilityLevel      string `json:"durabilityLevel"`
+	Replicas             int    `json:"replicas"`
+	FlushEnabled         bool   `json:"flushEnabled"`
+	TimeToLiveInSeconds  int    `json:"timeToLiveInSeconds"`
+}
+
+func (c *Client) UpdateBucket(
+	ctx context.Context,
+	orgID, projectID, clusterID, bucketID string,
+	req *UpdateBucketRequest,
+) error {
+	path := fmt.Sprintf("/v4/organizations/%s/projects/%s/clusters/%s/buckets/%s",
+		orgID, projectID, clusterID, url.PathEscape(bucketID))
+	return c.doWrite(ctx, http.MethodPut, path, req, nil)
+}
+
+func (c *Client) FlushBucket(ctx context.Context, orgID, projectID, clusterID, bucketID string) error {
+	path := fmt.Sprintf("/v4/organizations/%s/projects/%s/clusters/%s/buckets/%s/flush",
+		orgID, projectID, clusterID, url.PathEscape(bucketID))
+	return c.doWrite(ctx, http.MethodPut, path, nil, nil)
+}
+
 func (c *Client) DeleteBucket(ctx context.Context, orgID, projectID, clusterID, bucketID string) error {
 	path := fmt.Sprintf("/v4/organizations/%s/projects/%s/clusters/%s/buckets/%s",
 		orgID, projectID, clusterID, url.PathEscape(bucketID))
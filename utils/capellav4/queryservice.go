@@ -0,0 +1,78 @@
+package capellav4
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	IndexBuildStatusReady   = "Ready"
+	IndexBuildStatusCreated = "Created"
+)
+
+// Index DDL statements of every kind are executed through the same
+// endpoint of the v4 API.
+type ManageIndexRequest struct {
+	Definition string `json:"definition"`
+}
+
+func (c *Client) ManageIndex(
+	ctx context.Context,
+	orgID, projectID, clusterID string,
+	req *ManageIndexRequest,
+) error {
+	path := fmt.Sprintf("/v4/organizations/%s/projects/%s/clusters/%s/queryService/indexes",
+		orgID, projectID, clusterID)
+	return c.doWrite(ctx, http.MethodPost, path, req, nil)
+}
+
+type IndexDefinition struct {
+	IndexName  string `json:"indexName"`
+	Definition string `json:"definition"`
+}
+
+type listIndexDefinitionsResponse struct {
+	Definitions []*IndexDefinition `json:"definitions"`
+}
+
+func keyspaceQuery(bucket, scope, collection string) string {
+	query := url.Values{}
+	query.Set("bucket", bucket)
+	query.Set("scope", scope)
+	query.Set("collection", collection)
+	return query.Encode()
+}
+
+func (c *Client) ListIndexDefinitions(
+	ctx context.Context,
+	orgID, projectID, clusterID string,
+	bucket, scope, collection string,
+) ([]*IndexDefinition, error) {
+	resp := &listIndexDefinitionsResponse{}
+	path := fmt.Sprintf("/v4/organizations/%s/projects/%s/clusters/%s/queryService/indexes?%s",
+		orgID, projectID, clusterID, keyspaceQuery(bucket, scope, collection))
+	if err := c.doRead(ctx, http.MethodGet, path, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp.Definitions, nil
+}
+
+type IndexBuildStatus struct {
+	Status string `json:"status"`
+}
+
+func (c *Client) GetIndexBuildStatus(
+	ctx context.Context,
+	orgID, projectID, clusterID string,
+	bucket, scope, collection, indexName string,
+) (*IndexBuildStatus, error) {
+	resp := &IndexBuildStatus{}
+	path := fmt.Sprintf("/v4/organizations/%s/projects/%s/clusters/%s/queryService/indexBuildStatus/%s?%s",
+		orgID, projectID, clusterID, url.PathEscape(indexName), keyspaceQuery(bucket, scope, collection))
+	if err := c.doRead(ctx, http.MethodGet, path, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
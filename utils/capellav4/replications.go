@@ -0,0 +1,86 @@
+package capellav4
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	ReplicationDirectionOneWay = "one-way"
+
+	ReplicationStatusRunning = "running"
+	ReplicationStatusPaused  = "paused"
+)
+
+type ReplicationTarget struct {
+	Type    string `json:"type"`
+	Cluster string `json:"cluster"`
+	Bucket  string `json:"bucket"`
+}
+
+type ReplicationFilter struct {
+	Expression string `json:"expression,omitempty"`
+}
+
+type ReplicationInfo struct {
+	ID           string             `json:"id"`
+	SourceBucket string             `json:"sourceBucket"`
+	Target       ReplicationTarget  `json:"target"`
+	Direction    string             `json:"direction"`
+	Status       string             `json:"status"`
+	Filter       *ReplicationFilter `json:"filter"`
+}
+
+type listReplicationsResponse struct {
+	Data []*ReplicationInfo `json:"data"`
+}
+
+func (c *Client) ListReplications(ctx context.Context, orgID, projectID, clusterID string) ([]*ReplicationInfo, error) {
+	resp := &listReplicationsResponse{}
+	path := fmt.Sprintf("/v4/organizations/%s/projects/%s/clusters/%s/replications", orgID, projectID, clusterID)
+	if err := c.doRead(ctx, http.MethodGet, path, nil, resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// Buckets are identified by the base64 form of their name.
+type CreateReplicationRequest struct {
+	SourceBucket string             `json:"sourceBucket"`
+	Target       ReplicationTarget  `json:"target"`
+	Direction    string             `json:"direction"`
+	Filter       *ReplicationFilter `json:"filter,omitempty"`
+}
+
+type CreateReplicationResponse struct {
+	ID string `json:"id"`
+}
+
+func (c *Client) CreateReplication(
+	ctx context.Context,
+	orgID, projectID, clusterID string,
+	req *CreateReplicationRequest,
+) (*CreateReplicationResponse, error) {
+	resp := &CreateReplicationResponse{}
+	path := fmt.Sprintf("/v4/organizations/%s/projects/%s/clusters/%s/replications", orgID, projectID, clusterID)
+	if err := c.doWrite(ctx, http.MethodPost, path, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type UpdateReplicationStatusRequest struct {
+	Status string `json:"status"`
+}
+
+func (c *Client) UpdateReplicationStatus(
+	ctx context.Context,
+	orgID, projectID, clusterID, replicationID string,
+	req *UpdateReplicationStatusRequest,
+) error {
+	path := fmt.Sprintf("/v4/organizations/%s/projects/%s/clusters/%s/replications/%s",
+		orgID, projectID, clusterID, url.PathEscape(replicationID))
+	return c.doWrite(ctx, http.MethodPut, path, req, nil)
+}
@@ -26,15 +26,30 @@ func (e *non200StatusCodeError) Error() string {
 	return fmt.Sprintf("non-200 status code encountered: %d %s", e.StatusCode, e.Message)
 }
 
+// IsNotFoundError returns whether a request failed with a 404, which is also
+// how requests proxied to a service which no node runs fail.
+func IsNotFoundError(err error) bool {
+	var non200Err *non200StatusCodeError
+	return errors.As(err, &non200Err) && non200Err.StatusCode == http.StatusNotFound
+}
+
 type Controller struct {
 	Logger   *zap.Logger
 	Endpoint string
+
+	// Username and Password default to the credentials of dino clusters.
+	Username string
+	Password string
 }
 
 func (c *Controller) doReq(ctx context.Context, req *http.Request, out interface{}) error {
 	client := &http.Client{}
 
-	req.SetBasicAuth("Administrator", "password")
+	username, password := c.Username, c.Password
+	if username == "" {
+		username, password = "Administrator", "password"
+	}
+	req.SetBasicAuth(username, password)
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -668,7 +683,7 @@ type ListUsersResponse_User struct {
 	ID     string                        `json:"id"`
 	Domain string                        `json:"domain"`
 	Roles  []ListUsersResponse_User_Role `json:"roles"`
-	// groups
+	Groups []string                      `json:"groups"`
 	// external_groups
 	Name               string `json:"name"`
 	Uuid               string `json:"uuid"`
@@ -700,16 +715,36 @@ func (c *Controller) ListUsers(ctx context.Context, req *ListUsersRequest) (*Lis
 	return resp, nil
 }
 
+// ListAllUsers lists every user of the cluster, which unlike ListUsers is not
+// limited to a single page.
+func (c *Controller) ListAllUsers(ctx context.Context) ([]ListUsersResponse_User, error) {
+	var resp []ListUsersResponse_User
+
+	err := c.doGet(ctx, "/settings/rbac/users", &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
 type CreateUserRequest struct {
+	// Domain defaults to local, external users are created without a password.
+	Domain   string   `url:"-"`
 	Roles    []string `url:"roles,comma"`
 	Name     string   `url:"name"`
 	Groups   []string `url:"groups,comma"`
-	Password string   `url:"password"`
+	Password string   `url:"password,omitempty"`
 }
 
 func (c *Controller) CreateUser(ctx context.Context, username string, req *CreateUserRequest) error {
+	domain := req.Domain
+	if domain == "" {
+		domain = "local"
+	}
+
 	form, _ := query.Values(req)
-	path := fmt.Sprintf("/settings/rbac/users/local/%s", username)
+	path := fmt.Sprintf("/settings/rbac/users/%s/%s", domain, url.PathEscape(username))
 	err := c.doFormPut(ctx, path, form, true, nil)
 	if err != nil {
 		return err
@@ -719,7 +754,11 @@ func (c *Controller) CreateUser(ctx context.Context, username string, req *Creat
 }
 
 func (c *Controller) DeleteUser(ctx context.Context, username string) error {
-	path := fmt.Sprintf("/settings/rbac/users/local/%s", username)
+	return c.DeleteUserInDomain(ctx, "local", username)
+}
+
+func (c *Controller) DeleteUserInDomain(ctx context.Context, domain string, username string) error {
+	path := fmt.Sprintf("/settings/rbac/users/%s/%s", domain, url.PathEscape(username))
 	err := c.doDelete(ctx, path, nil)
 	if err != nil {
 		return err
@@ -728,6 +767,23 @@ func (c *Controller) DeleteUser(ctx context.Context, username string) error {
 	return nil
 }
 
+type CreateGroupRequest struct {
+	Roles        []string `url:"roles,comma"`
+	Description  string   `url:"description"`
+	LdapGroupRef string   `url:"ldap_group_ref,omitempty"`
+}
+
+func (c *Controller) CreateGroup(ctx context.Context, groupName string, req *CreateGroupRequest) error {
+	form, _ := query.Values(req)
+	path := fmt.Sprintf("/settings/rbac/groups/%s", url.PathEscape(groupName))
+	err := c.doFormPut(ctx, path, form, true, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 type ListBucketsResponse_Bucket struct {
 	Name string `json:"name"`
 }
@@ -782,6 +838,104 @@ func (c *Controller) DeleteBucket(ctx context.Context, bucketName string) error
 	return nil
 }
 
+type GetBucketResponse_Quota struct {
+	RawRAM uint64 `json:"rawRAM"`
+}
+
+type GetBucketResponse_Controllers struct {
+	Flush string `json:"flush"`
+}
+
+type GetBucketResponse_BasicStats struct {
+	ItemCount uint64 `json:"itemCount"`
+	MemUsed   uint64 `json:"memUsed"`
+	DiskUsed  uint64 `json:"diskUsed"`
+}
+
+type GetBucketResponse struct {
+	Name                    string                        `json:"name"`
+	BucketType              string                        `json:"bucketType"`
+	StorageBackend          string                        `json:"storageBackend"`
+	EvictionPolicy          string                        `json:"evictionPolicy"`
+	DurabilityMinLevel      string                        `json:"durabilityMinLevel"`
+	MaxTTL                  int                           `json:"maxTTL"`
+	CompressionMode         string                        `json:"compressionMode"`
+	ConflictResolutionType  string                        `json:"conflictResolutionType"`
+	ReplicaNumber           int                           `json:"replicaNumber"`
+	NumVBuckets             int                           `json:"numVBuckets"`
+	HistoryRetentionBytes   uint64                        `json:"historyRetentionBytes"`
+	HistoryRetentionSeconds int                           `json:"historyRetentionSeconds"`
+	Quota                   GetBucketResponse_Quota       `json:"quota"`
+	Controllers             GetBucketResponse_Controllers `json:"controllers"`
+	BasicStats              GetBucketResponse_BasicStats  `json:"basicStats"`
+}
+
+func (c *Controller) GetBucket(ctx context.Context, bucketName string) (*GetBucketResponse, error) {
+	var resp GetBucketResponse
+
+	path := fmt.Sprintf("/pools/default/buckets/%s", url.PathEscape(bucketName))
+	err := c.doGet(ctx, path, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// GetBucketResidentRatio returns the most recent percentage of the active
+// items of a bucket which are resident in memory.
+func (c *Controller) GetBucketResidentRatio(ctx context.Context, bucketName string) (float64, error) {
+	var resp struct {
+		Op struct {
+			Samples struct {
+				ResidentRatio []float64 `json:"vb_active_resident_items_ratio"`
+			} `json:"samples"`
+		} `json:"op"`
+	}
+
+	path := fmt.Sprintf("/pools/default/buckets/%s/stats", url.PathEscape(bucketName))
+	err := c.doGet(ctx, path, &resp)
+	if err != nil {
+		return 0, err
+	}
+
+	samples := resp.Op.Samples.ResidentRatio
+	if len(samples) == 0 {
+		return 0, errors.New("no resident ratio samples were returned")
+	}
+
+	return samples[len(samples)-1], nil
+}
+
+// UpdateBucketRequest only sends the settings which are set.
+type UpdateBucketRequest struct {
+	RamQuotaMB         *int   `url:"ramQuotaMB,omitempty"`
+	ReplicaNumber      *int   `url:"replicaNumber,omitempty"`
+	MaxTTL             *int   `url:"maxTTL,omitempty"`
+	DurabilityMinLevel string `url:"durabilityMinLevel,omitempty"`
+}
+
+func (c *Controller) UpdateBucket(ctx context.Context, bucketName string, req *UpdateBucketRequest) error {
+	form, _ := query.Values(req)
+	path := fmt.Sprintf("/pools/default/buckets/%s", url.PathEscape(bucketName))
+	err := c.doFormPost(ctx, path, form, true, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Controller) FlushBucket(ctx context.Context, bucketName string) error {
+	path := fmt.Sprintf("/pools/default/buckets/%s/controller/doFlush", url.PathEscape(bucketName))
+	err := c.doFormPost(ctx, path, url.Values{}, false, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (c *Controller) LoadSampleBucket(ctx context.Context, bucketName string) error {
 	samples := []string{
 		bucketName,
@@ -861,9 +1015,20 @@ func (c *Controller) LoadTrustedCAs(ctx context.Context, opts *LoadTrustedCAsOpt
 }
 
 type ReloadCertificateOptions struct {
+	// PrivateKeyPassphrase is needed when the uploaded key is encrypted.
+	PrivateKeyPassphrase string
 }
 
 func (c *Controller) ReloadCertificate(ctx context.Context, opts *ReloadCertificateOptions) error {
+	if opts.PrivateKeyPassphrase != "" {
+		return c.doJsonPost(ctx, "/node/controller/reloadCertificate", map[string]any{
+			"privateKeyPassphrase": map[string]any{
+				"type":     "plain",
+				"password": opts.PrivateKeyPassphrase,
+			},
+		}, true, nil)
+	}
+
 	return c.doFormPost(ctx, "/node/controller/reloadCertificate", nil, true, nil)
 }
 
@@ -896,6 +1061,17 @@ func (c *Controller) GracefulFailOver(ctx context.Context, nodeOTPs []string) er
 	return c.doFormPost(ctx, "/controller/startGracefulFailover", form, true, nil)
 }
 
+type SetupAlternateAddressesOptions struct {
+	Hostname string
+}
+
+func (c *Controller) SetupAlternateAddresses(ctx context.Context, opts *SetupAlternateAddressesOptions) error {
+	form := make(url.Values)
+	form.Add("hostname", opts.Hostname)
+
+	return c.doFormPut(ctx, "/node/controller/setupAlternateAddresses/external", form, true, nil)
+}
+
 type FailOverRecoveryType struct {
 	NodeOTPs     []string
 	RecoveryType string
@@ -938,6 +1114,8 @@ type SetupJwtOptions_Issuer struct {
 	SubClaim             string   `json:"subClaim"`
 	RolesClaim           string   `json:"rolesClaim"`
 	RolesMaps            []string `json:"rolesMaps,omitempty"`
+	GroupsClaim          string   `json:"groupsClaim,omitempty"`
+	GroupsMaps           []string `json:"groupsMaps,omitempty"`
 	PublicKeySource      string   `json:"publicKeySource"`
 	PublicKey            string   `json:"publicKey,omitempty"`
 	JwksUri              string   `json:"jwksUri,omitempty"`
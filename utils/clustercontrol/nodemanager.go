@@ -13,12 +13,16 @@ import (
 type NodeManager struct {
 	Logger   *zap.Logger
 	Endpoint string
+	Username string
+	Password string
 }
 
 func (m *NodeManager) Controller() *Controller {
 	return &Controller{
 		Logger:   m.Logger,
 		Endpoint: m.Endpoint,
+		Username: m.Username,
+		Password: m.Password,
 	}
 }
 
@@ -302,6 +306,8 @@ func (m *NodeManager) checkClusterIsBalanced(
 		nodeCtrl := &NodeManager{
 			Logger:   m.Logger,
 			Endpoint: endpoint,
+			Username: m.Username,
+			Password: m.Password,
 		}
 
 		localInfo, err := nodeCtrl.Controller().GetLocalInfo(ctx)
@@ -373,6 +379,8 @@ func (m *NodeManager) RebalanceWithRetry(
 		nodeCtrl := &NodeManager{
 			Logger:   m.Logger,
 			Endpoint: endpoint,
+			Username: m.Username,
+			Password: m.Password,
 		}
 
 		localInfo, err := nodeCtrl.Controller().GetLocalInfo(ctx)
@@ -413,6 +421,8 @@ func (m *NodeManager) RebalanceWithRetry(
 	ctrlNodeMgr := &NodeManager{
 		Logger:   m.Logger,
 		Endpoint: ctrlEndpoint,
+		Username: m.Username,
+		Password: m.Password,
 	}
 
 	m.Logger.Info("initiating rebalance")
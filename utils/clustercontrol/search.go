@@ -0,0 +1,71 @@
+package clustercontrol
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// The search service is reached through the service proxy of ns_server, so
+// that any node of the cluster can be used regardless of where it runs.
+
+type SearchIndexDef struct {
+	Name       string `json:"name"`
+	SourceName string `json:"sourceName"`
+}
+
+func (c *Controller) ListSearchIndexes(ctx context.Context) ([]SearchIndexDef, error) {
+	type listIndexesJson struct {
+		IndexDefs *struct {
+			IndexDefs map[string]SearchIndexDef `json:"indexDefs"`
+		} `json:"indexDefs"`
+	}
+
+	var resp listIndexesJson
+	err := c.doGet(ctx, "/_p/fts/api/index", &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	// the index definitions are null until the first index is created
+	if resp.IndexDefs == nil {
+		return nil, nil
+	}
+
+	indexes := make([]SearchIndexDef, 0, len(resp.IndexDefs.IndexDefs))
+	for _, index := range resp.IndexDefs.IndexDefs {
+		indexes = append(indexes, index)
+	}
+
+	return indexes, nil
+}
+
+func (c *Controller) CreateSearchIndex(ctx context.Context, indexName string, def map[string]interface{}) error {
+	path := fmt.Sprintf("/_p/fts/api/index/%s", url.PathEscape(indexName))
+	return c.doJsonReq(ctx, http.MethodPut, path, def, false, nil)
+}
+
+func (c *Controller) DeleteSearchIndex(ctx context.Context, indexName string) error {
+	path := fmt.Sprintf("/_p/fts/api/index/%s", url.PathEscape(indexName))
+	return c.doDelete(ctx, path, nil)
+}
+
+// GetSearchIndexCount fails until all the partitions of the index are
+// available to serve requests.
+func (c *Controller) GetSearchIndexCount(ctx context.Context, indexName string) (uint64, error) {
+	type countJson struct {
+		Count uint64 `json:"count"`
+	}
+
+	var resp countJson
+	path := fmt.Sprintf("/_p/fts/api/index/%s/count", url.PathEscape(indexName))
+	err := c.doRetriableReq(ctx, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, c.Endpoint+path, nil)
+	}, 0, &resp)
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.Count, nil
+}
@@ -0,0 +1,161 @@
+package clustercontrol
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-querystring/query"
+)
+
+type RemoteCluster struct {
+	Name             string `json:"name"`
+	Uuid             string `json:"uuid"`
+	Hostname         string `json:"hostname"`
+	Username         string `json:"username"`
+	DemandEncryption bool   `json:"demandEncryption"`
+	EncryptionType   string `json:"encryptionType"`
+	Deleted          bool   `json:"deleted"`
+}
+
+func (c *Controller) ListRemoteClusters(ctx context.Context) ([]RemoteCluster, error) {
+	var resp []RemoteCluster
+	err := c.doGet(ctx, "/pools/default/remoteClusters", &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	// deleted references are kept around until their replications are gone
+	remotes := make([]RemoteCluster, 0, len(resp))
+	for _, remote := range resp {
+		if !remote.Deleted {
+			remotes = append(remotes, remote)
+		}
+	}
+
+	return remotes, nil
+}
+
+type CreateRemoteClusterRequest struct {
+	Name     string `url:"name"`
+	Hostname string `url:"hostname"`
+	Username string `url:"username"`
+	Password string `url:"password"`
+
+	// Full encryption requires the certificate of the remote cluster.
+	DemandEncryption bool   `url:"demandEncryption,int,omitempty"`
+	EncryptionType   string `url:"encryptionType,omitempty"`
+	Certificate      string `url:"certificate,omitempty"`
+}
+
+func (c *Controller) CreateRemoteCluster(ctx context.Context, req *CreateRemoteClusterRequest) error {
+	form, _ := query.Values(req)
+	return c.doFormPost(ctx, "/pools/default/remoteClusters", form, false, nil)
+}
+
+func (c *Controller) DeleteRemoteCluster(ctx context.Context, name string) error {
+	path := fmt.Sprintf("/pools/default/remoteClusters/%s", url.PathEscape(name))
+	return c.doDelete(ctx, path, nil)
+}
+
+type Replication struct {
+	ID               string
+	SourceBucket     string
+	RemoteUuid       string
+	TargetBucket     string
+	Status           string
+	FilterExpression string
+}
+
+func (c *Controller) ListReplications(ctx context.Context) ([]Replication, error) {
+	type xdcrTaskJson struct {
+		Type             string `json:"type"`
+		ID               string `json:"id"`
+		Source           string `json:"source"`
+		Target           string `json:"target"`
+		Status           string `json:"status"`
+		FilterExpression string `json:"filterExpression"`
+	}
+
+	var resp []xdcrTaskJson
+	err := c.doGet(ctx, "/pools/default/tasks", &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var replications []Replication
+	for _, task := range resp {
+		if task.Type != "xdcr" {
+			continue
+		}
+
+		// targets are of the form /remoteClusters/<uuid>/buckets/<bucket>
+		targetParts := strings.Split(strings.TrimPrefix(task.Target, "/"), "/")
+		if len(targetParts) != 4 || targetParts[0] != "remoteClusters" || targetParts[2] != "buckets" {
+			return nil, fmt.Errorf("unexpected replication target '%s'", task.Target)
+		}
+
+		replications = append(replications, Replication{
+			ID:               task.ID,
+			SourceBucket:     task.Source,
+			RemoteUuid:       targetParts[1],
+			TargetBucket:     targetParts[3],
+			Status:           task.Status,
+			FilterExpression: task.FilterExpression,
+		})
+	}
+
+	return replications, nil
+}
+
+type CreateReplicationRequest struct {
+	FromBucket       string `url:"fromBucket"`
+	ToCluster        string `url:"toCluster"`
+	ToBucket         string `url:"toBucket"`
+	ReplicationType  string `url:"replicationType"`
+	FilterExpression string `url:"filterExpression,omitempty"`
+}
+
+type CreateReplicationResponse struct {
+	ID string `json:"id"`
+}
+
+func (c *Controller) CreateReplication(ctx context.Context, req *CreateReplicationRequest) (*CreateReplicationResponse, error) {
+	if req.ReplicationType == "" {
+		req.ReplicationType = "continuous"
+	}
+
+	form, _ := query.Values(req)
+
+	var resp CreateReplicationResponse
+	err := c.doFormPost(ctx, "/controller/createReplication", form, false, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (c *Controller) SetReplicationPaused(ctx context.Context, replicationID string, paused bool) error {
+	form := url.Values{}
+	form.Add("pauseRequested", fmt.Sprintf("%t", paused))
+
+	path := fmt.Sprintf("/settings/replications/%s", url.PathEscape(replicationID))
+	err := c.doFormPost(ctx, path, form, true, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Controller) DeleteReplication(ctx context.Context, replicationID string) error {
+	path := fmt.Sprintf("/controller/cancelXDCR/%s", url.PathEscape(replicationID))
+	err := c.doDelete(ctx, path, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
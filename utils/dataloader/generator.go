@@ -0,0 +1,168 @@
+package dataloader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"text/template"
+)
+
+const DefaultTemplate = `{"key": "{{.Key}}", "index": {{.Index}}, "value": {{.RandInt 1000000}}}`
+
+const DefaultKeyPattern = "doc-%d"
+
+const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// TemplateData is what document templates are executed against.
+type TemplateData struct {
+	Key   string
+	Index int
+
+	rnd *rand.Rand
+}
+
+func (d TemplateData) RandInt(n int) int {
+	return d.rnd.Intn(n)
+}
+
+func (d TemplateData) RandString(n int) string {
+	return randString(d.rnd, n)
+}
+
+func (d TemplateData) Pick(values ...string) string {
+	return values[d.rnd.Intn(len(values))]
+}
+
+func randString(rnd *rand.Rand, n int) string {
+	buf := make([]byte, n)
+	for i := range buf {
+		buf[i] = letters[rnd.Intn(len(letters))]
+	}
+	return string(buf)
+}
+
+type GeneratorOptions struct {
+	// KeyPattern is a fmt pattern which formats the index of a document
+	// into its key.
+	KeyPattern string
+
+	// Template is a text/template of a JSON object, see TemplateData.
+	Template string
+
+	// Documents smaller than MinSize are padded to a size uniformly
+	// distributed between MinSize and MaxSize.
+	MinSize int
+	MaxSize int
+}
+
+// Generator generates the keys and documents of a data set.
+type Generator struct {
+	keyPattern string
+	template   *template.Template
+	minSize    int
+	maxSize    int
+}
+
+func NewGenerator(opts *GeneratorOptions) (*Generator, error) {
+	keyPattern := opts.KeyPattern
+	if keyPattern == "" {
+		keyPattern = DefaultKeyPattern
+	}
+
+	firstKey := fmt.Sprintf(keyPattern, 0)
+	if strings.Contains(firstKey, "%!") || firstKey == fmt.Sprintf(keyPattern, 1) {
+		return nil, fmt.Errorf("key pattern '%s' must format the document index", keyPattern)
+	}
+
+	templateStr := opts.Template
+	if templateStr == "" {
+		templateStr = DefaultTemplate
+	}
+
+	tmpl, err := template.New("document").Option("missingkey=error").Parse(templateStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	if opts.MinSize < 0 || opts.MinSize > opts.MaxSize {
+		return nil, fmt.Errorf("invalid document size range %d-%d", opts.MinSize, opts.MaxSize)
+	}
+
+	gen := &Generator{
+		keyPattern: keyPattern,
+		template:   tmpl,
+		minSize:    opts.MinSize,
+		maxSize:    opts.MaxSize,
+	}
+
+	// templates are only checked once, rather than for every document
+	_, doc, err := gen.Generate(rand.New(rand.NewSource(0)), 0)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(doc, &obj); err != nil {
+		return nil, fmt.Errorf("template must generate a JSON object: %w", err)
+	}
+
+	return gen, nil
+}
+
+func (g *Generator) Key(index int) string {
+	return fmt.Sprintf(g.keyPattern, index)
+}
+
+// Generate returns the key and the document at an index.
+func (g *Generator) Generate(rnd *rand.Rand, index int) (string, []byte, error) {
+	key := g.Key(index)
+
+	var buf bytes.Buffer
+	err := g.template.Execute(&buf, TemplateData{
+		Key:   key,
+		Index: index,
+		rnd:   rnd,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	doc := bytes.TrimSpace(buf.Bytes())
+
+	targetSize := g.minSize
+	if g.maxSize > g.minSize {
+		targetSize += rnd.Intn(g.maxSize - g.minSize + 1)
+	}
+
+	return key, padDocument(rnd, doc, targetSize), nil
+}
+
+// padDocument adds a padding field to the end of a JSON object to bring it
+// up to the target size.  Documents which are already large enough are
+// left alone.
+func padDocument(rnd *rand.Rand, doc []byte, targetSize int) []byte {
+	if len(doc) < 2 || doc[len(doc)-1] != '}' {
+		return doc
+	}
+
+	body := bytes.TrimSpace(doc[:len(doc)-1])
+	isEmpty := len(body) == 1
+
+	prefix := `,"padding":"`
+	if isEmpty {
+		prefix = `"padding":"`
+	}
+
+	padLen := targetSize - len(body) - len(prefix) - len(`"}`)
+	if padLen <= 0 {
+		return doc
+	}
+
+	padded := make([]byte, 0, targetSize)
+	padded = append(padded, body...)
+	padded = append(padded, prefix...)
+	padded = append(padded, randString(rnd, padLen)...)
+	padded = append(padded, `"}`...)
+	return padded
+}
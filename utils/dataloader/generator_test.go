@@ -0,0 +1,68 @@
+package dataloader
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator(t *testing.T) {
+	gen, err := NewGenerator(&GeneratorOptions{
+		KeyPattern: "user::%06d",
+		Template:   `{"id": "{{.Key}}", "n": {{.Index}}, "city": "{{.Pick "paris" "london"}}"}`,
+		MinSize:    256,
+		MaxSize:    1024,
+	})
+	require.NoError(t, err)
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		key, doc, err := gen.Generate(rnd, i)
+		require.NoError(t, err)
+		require.Equal(t, gen.Key(i), key)
+		require.GreaterOrEqual(t, len(doc), 256)
+		require.LessOrEqual(t, len(doc), 1024)
+
+		var obj map[string]interface{}
+		require.NoError(t, json.Unmarshal(doc, &obj))
+		require.Equal(t, key, obj["id"])
+		require.Contains(t, []string{"paris", "london"}, obj["city"])
+	}
+
+	require.Equal(t, "user::000042", gen.Key(42))
+}
+
+func TestGeneratorDefaults(t *testing.T) {
+	gen, err := NewGenerator(&GeneratorOptions{MinSize: 100, MaxSize: 100})
+	require.NoError(t, err)
+
+	key, doc, err := gen.Generate(rand.New(rand.NewSource(1)), 7)
+	require.NoError(t, err)
+	require.Equal(t, "doc-7", key)
+	require.Len(t, doc, 100)
+	require.True(t, json.Valid(doc))
+}
+
+func TestGeneratorInvalid(t *testing.T) {
+	_, err := NewGenerator(&GeneratorOptions{KeyPattern: "static-key"})
+	require.Error(t, err)
+
+	_, err = NewGenerator(&GeneratorOptions{Template: `["not", "an", "object"]`})
+	require.Error(t, err)
+
+	_, err = NewGenerator(&GeneratorOptions{Template: `{"missing": {{.Missing}}}`})
+	require.Error(t, err)
+
+	_, err = NewGenerator(&GeneratorOptions{MinSize: 10, MaxSize: 5})
+	require.Error(t, err)
+}
+
+func TestPadDocument(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	require.Len(t, padDocument(rnd, []byte(`{}`), 64), 64)
+	require.True(t, json.Valid(padDocument(rnd, []byte(`{}`), 64)))
+	require.Equal(t, `{"a":1}`, string(padDocument(rnd, []byte(`{"a":1}`), 4)))
+}
@@ -0,0 +1,222 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/couchbase/gocbcorex/memdx"
+	"github.com/couchbaselabs/cbdinocluster/utils/workerpool"
+)
+
+type Operation string
+
+const (
+	OperationUpsert Operation = "upsert"
+	OperationDelete Operation = "delete"
+	OperationTouch  Operation = "touch"
+)
+
+// Store is where documents are loaded to.
+type Store interface {
+	Upsert(ctx context.Context, key string, value []byte, expiry time.Duration) error
+	Delete(ctx context.Context, key string) error
+	Touch(ctx context.Context, key string, expiry time.Duration) error
+}
+
+type Options struct {
+	Operation Operation
+	Generator *Generator
+
+	// The documents operated on are those from StartIndex up to
+	// StartIndex+Count.
+	StartIndex int
+	Count      int
+
+	Workers int
+
+	// When Duration is set, the documents are cycled through until it has
+	// passed, rather than operated on once.
+	Duration time.Duration
+
+	// Expiry is the expiry set by upserts and touches.
+	Expiry time.Duration
+
+	ReportInterval time.Duration
+	OnProgress     func(progress *Progress)
+}
+
+type Progress struct {
+	Elapsed   time.Duration
+	Succeeded int64
+	Failed    int64
+
+	// OpsPerSec is the throughput since the last report.
+	OpsPerSec float64
+}
+
+type Result struct {
+	Elapsed   time.Duration
+	Succeeded int64
+	Failed    int64
+
+	// Errors counts the failed operations by ErrorClass, and FirstErrors
+	// holds the first error of each class.
+	Errors      map[string]int64
+	FirstErrors map[string]string
+}
+
+func (r *Result) OpsPerSec() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Succeeded+r.Failed) / r.Elapsed.Seconds()
+}
+
+// ErrorClass groups errors into the classes reported by a Result.
+func ErrorClass(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, memdx.ErrDocNotFound):
+		return "doc-not-found"
+	case errors.Is(err, memdx.ErrDocExists):
+		return "doc-exists"
+	case errors.Is(err, memdx.ErrDocLocked):
+		return "doc-locked"
+	case errors.Is(err, memdx.ErrCasMismatch):
+		return "cas-mismatch"
+	case errors.Is(err, memdx.ErrTmpFail):
+		return "tmp-fail"
+	case errors.Is(err, memdx.ErrSyncWriteAmbiguous):
+		return "sync-write-ambiguous"
+	case errors.Is(err, memdx.ErrDurabilityImpossible):
+		return "durability-impossible"
+	}
+	return "other"
+}
+
+type runStats struct {
+	succeeded atomic.Int64
+	failed    atomic.Int64
+
+	lock        sync.Mutex
+	errors      map[string]int64
+	firstErrors map[string]string
+}
+
+func (s *runStats) recordError(err error) {
+	s.failed.Add(1)
+
+	errClass := ErrorClass(err)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.errors[errClass]++
+	if _, ok := s.firstErrors[errClass]; !ok {
+		s.firstErrors[errClass] = err.Error()
+	}
+}
+
+// Run executes an operation against the documents of a data set, spread
+// across a number of workers.  Failed operations are counted rather than
+// stopping the run.
+func Run(ctx context.Context, store Store, opts *Options) (*Result, error) {
+	if opts.Count <= 0 {
+		return nil, fmt.Errorf("count must be positive")
+	}
+	if opts.Generator == nil {
+		return nil, fmt.Errorf("a generator is required")
+	}
+
+	stats := &runStats{
+		errors:      make(map[string]int64),
+		firstErrors: make(map[string]string),
+	}
+
+	var nextOp atomic.Int64
+	nextIndex := func() (int, bool) {
+		opNum := nextOp.Add(1) - 1
+		if opts.Duration == 0 && opNum >= int64(opts.Count) {
+			return 0, false
+		}
+		return opts.StartIndex + int(opNum%int64(opts.Count)), true
+	}
+
+	startTime := time.Now()
+	lastTime := startTime
+	var lastOps int64
+
+	poolOpts := &workerpool.Options{
+		Workers:  opts.Workers,
+		Duration: opts.Duration,
+	}
+	if opts.ReportInterval > 0 && opts.OnProgress != nil {
+		poolOpts.Interval = opts.ReportInterval
+		poolOpts.OnInterval = func(now time.Time) {
+			succeeded, failed := stats.succeeded.Load(), stats.failed.Load()
+			opsPerSec := float64(succeeded+failed-lastOps) / now.Sub(lastTime).Seconds()
+			lastTime, lastOps = now, succeeded+failed
+
+			opts.OnProgress(&Progress{
+				Elapsed:   now.Sub(startTime),
+				Succeeded: succeeded,
+				Failed:    failed,
+				OpsPerSec: opsPerSec,
+			})
+		}
+	}
+
+	err := workerpool.Run(ctx, poolOpts, func(runCtx context.Context, rnd *rand.Rand) (bool, error) {
+		index, ok := nextIndex()
+		if !ok {
+			return false, nil
+		}
+
+		var err error
+		switch opts.Operation {
+		case OperationUpsert:
+			key, doc, genErr := opts.Generator.Generate(rnd, index)
+			if genErr != nil {
+				return false, genErr
+			}
+			err = store.Upsert(runCtx, key, doc, opts.Expiry)
+		case OperationDelete:
+			err = store.Delete(runCtx, opts.Generator.Key(index))
+		case OperationTouch:
+			err = store.Touch(runCtx, opts.Generator.Key(index), opts.Expiry)
+		default:
+			return false, fmt.Errorf("unsupported operation '%s'", opts.Operation)
+		}
+
+		if err != nil {
+			// operations cut short by the end of the run are not failures
+			if runCtx.Err() != nil {
+				return false, nil
+			}
+			stats.recordError(err)
+			return true, nil
+		}
+
+		stats.succeeded.Add(1)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return &Result{
+		Elapsed:     time.Since(startTime),
+		Succeeded:   stats.succeeded.Load(),
+		Failed:      stats.failed.Load(),
+		Errors:      stats.errors,
+		FirstErrors: stats.firstErrors,
+	}, nil
+}
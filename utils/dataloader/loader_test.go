@@ -0,0 +1,143 @@
+package dataloader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/couchbase/gocbcorex/memdx"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	lock    sync.Mutex
+	docs    map[string][]byte
+	expiry  map[string]time.Duration
+	failKey string
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		docs:   make(map[string][]byte),
+		expiry: make(map[string]time.Duration),
+	}
+}
+
+func (s *fakeStore) Upsert(ctx context.Context, key string, value []byte, expiry time.Duration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if key == s.failKey {
+		return fmt.Errorf("upsert failed: %w", memdx.ErrTmpFail)
+	}
+	s.docs[key] = value
+	s.expiry[key] = expiry
+	return nil
+}
+
+func (s *fakeStore) Delete(ctx context.Context, key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.docs[key]; !ok {
+		return memdx.ErrDocNotFound
+	}
+	delete(s.docs, key)
+	return nil
+}
+
+func (s *fakeStore) Touch(ctx context.Context, key string, expiry time.Duration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.docs[key]; !ok {
+		return memdx.ErrDocNotFound
+	}
+	s.expiry[key] = expiry
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeStore()
+	store.failKey = "doc-15"
+
+	gen, err := NewGenerator(&GeneratorOptions{})
+	require.NoError(t, err)
+
+	result, err := Run(ctx, store, &Options{
+		Operation:  OperationUpsert,
+		Generator:  gen,
+		StartIndex: 10,
+		Count:      100,
+		Workers:    8,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(99), result.Succeeded)
+	require.Equal(t, int64(1), result.Failed)
+	require.Equal(t, map[string]int64{"tmp-fail": 1}, result.Errors)
+	require.Contains(t, result.FirstErrors["tmp-fail"], "upsert failed")
+	require.Len(t, store.docs, 99)
+	require.Contains(t, store.docs, "doc-109")
+	require.NotContains(t, store.docs, "doc-9")
+
+	result, err = Run(ctx, store, &Options{
+		Operation: OperationTouch,
+		Generator: gen,
+		Count:     20,
+		Workers:   4,
+		Expiry:    time.Hour,
+	})
+	require.NoError(t, err)
+	// only doc-10 to doc-19 were loaded, less the one which failed
+	require.Equal(t, int64(9), result.Succeeded)
+	require.Equal(t, map[string]int64{"doc-not-found": 11}, result.Errors)
+	require.Equal(t, time.Hour, store.expiry["doc-10"])
+
+	result, err = Run(ctx, store, &Options{
+		Operation:  OperationDelete,
+		Generator:  gen,
+		StartIndex: 10,
+		Count:      100,
+		Workers:    4,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(99), result.Succeeded)
+	require.Empty(t, store.docs)
+}
+
+func TestRunDuration(t *testing.T) {
+	store := newFakeStore()
+
+	gen, err := NewGenerator(&GeneratorOptions{})
+	require.NoError(t, err)
+
+	var progressLock sync.Mutex
+	var reports int
+	result, err := Run(context.Background(), store, &Options{
+		Operation:      OperationUpsert,
+		Generator:      gen,
+		Count:          10,
+		Workers:        2,
+		Duration:       200 * time.Millisecond,
+		ReportInterval: 50 * time.Millisecond,
+		OnProgress: func(progress *Progress) {
+			progressLock.Lock()
+			reports++
+			progressLock.Unlock()
+		},
+	})
+	require.NoError(t, err)
+
+	// the documents are cycled through until the duration has passed
+	require.Greater(t, result.Succeeded, int64(10))
+	require.Zero(t, result.Failed)
+	require.Len(t, store.docs, 10)
+	require.GreaterOrEqual(t, result.Elapsed, 200*time.Millisecond)
+	require.NotZero(t, reports)
+}
+
+func TestErrorClass(t *testing.T) {
+	require.Equal(t, "timeout", ErrorClass(fmt.Errorf("op: %w", context.DeadlineExceeded)))
+	require.Equal(t, "doc-not-found", ErrorClass(memdx.ErrDocNotFound))
+	require.Equal(t, "other", ErrorClass(fmt.Errorf("boom")))
+}
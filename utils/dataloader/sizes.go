@@ -0,0 +1,80 @@
+package dataloader
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int
+}{
+	{"KIB", 1024},
+	{"MIB", 1024 * 1024},
+	{"KB", 1024},
+	{"MB", 1024 * 1024},
+	{"K", 1024},
+	{"M", 1024 * 1024},
+	{"B", 1},
+}
+
+// ParseSize parses a document size such as 512, 512B, 1KB or 2MiB.  The
+// units are powers of 1024, whether or not they are written as such.
+func ParseSize(s string) (int, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+
+	multiplier := 1
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	size, err := strconv.ParseFloat(value, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size '%s'", s)
+	}
+
+	return int(size * float64(multiplier)), nil
+}
+
+// ParseSizeRange parses either a single size, or a range of sizes such as
+// 512B-4KB which document sizes are uniformly distributed over.
+func ParseSizeRange(s string) (int, int, error) {
+	minStr, maxStr, isRange := strings.Cut(s, "-")
+	if !isRange {
+		size, err := ParseSize(s)
+		return size, size, err
+	}
+
+	minSize, err := ParseSize(minStr)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	maxSize, err := ParseSize(maxStr)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if minSize > maxSize {
+		return 0, 0, fmt.Errorf("invalid size range '%s'", s)
+	}
+
+	return minSize, maxSize, nil
+}
+
+// ParseCount parses a document count, which may use scientific notation
+// such as 1e6.
+func ParseCount(s string) (int, error) {
+	count, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || count <= 0 || count != math.Trunc(count) || count > math.MaxInt32 {
+		return 0, fmt.Errorf("invalid count '%s'", s)
+	}
+
+	return int(count), nil
+}
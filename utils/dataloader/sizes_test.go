@@ -0,0 +1,67 @@
+package dataloader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{input: "512", want: 512},
+		{input: "512B", want: 512},
+		{input: "1KB", want: 1024},
+		{input: "1kib", want: 1024},
+		{input: "1.5K", want: 1536},
+		{input: "2MB", want: 2 * 1024 * 1024},
+		{input: "", wantErr: true},
+		{input: "-1KB", wantErr: true},
+		{input: "lots", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSize(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseSizeRange(t *testing.T) {
+	minSize, maxSize, err := ParseSizeRange("1KB")
+	require.NoError(t, err)
+	require.Equal(t, 1024, minSize)
+	require.Equal(t, 1024, maxSize)
+
+	minSize, maxSize, err = ParseSizeRange("512B-4KB")
+	require.NoError(t, err)
+	require.Equal(t, 512, minSize)
+	require.Equal(t, 4096, maxSize)
+
+	_, _, err = ParseSizeRange("4KB-512B")
+	require.Error(t, err)
+}
+
+func TestParseCount(t *testing.T) {
+	count, err := ParseCount("1e6")
+	require.NoError(t, err)
+	require.Equal(t, 1000000, count)
+
+	count, err = ParseCount("250")
+	require.NoError(t, err)
+	require.Equal(t, 250, count)
+
+	for _, input := range []string{"0", "-5", "1.5", "1e20", "many"} {
+		_, err := ParseCount(input)
+		require.Error(t, err, input)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"fmt"
 	"math/big"
 	"net"
 	"time"
@@ -148,43 +149,103 @@ func (d *CertAuthority) GetRS256PublicKeyPem() ([]byte, error) {
 	return publicKeyPem.Bytes(), nil
 }
 
+// MakeJwtSigningKey makes an RSA-2048 key for signing tokens.  The key is
+// derived from both the seed and this authority, so that it is as specific to
+// the machine as the root authority is.
+func (d *CertAuthority) MakeJwtSigningKey(seed string) (*rsa.PrivateKey, error) {
+	rnd := newSeededRand(fmt.Sprintf("%s-%x", seed, d.SubjectKeyId))
+	return rsa.GenerateKey(&certRandReader{rnd}, 2048)
+}
+
 func (d *CertAuthority) MakeIntermediaryCA(
 	seed string,
 ) (*CertAuthority, error) {
 	return makeDinoCertAuthority(seed, d)
 }
 
+// DefaultNotBefore and DefaultNotAfter are the validity period of certificates
+// which are not given a specific one.
+var (
+	DefaultNotBefore = time.Date(2025, 01, 01, 00, 00, 00, 00, time.UTC)
+	DefaultNotAfter  = time.Date(2035, 01, 01, 00, 00, 00, 00, time.UTC)
+)
+
 func (d *CertAuthority) MakeServerCertificate(
 	seed string,
 	ipAddresses []net.IP,
 	dnsNames []string,
 ) ([]byte, []byte, error) {
+	return d.MakeServerCertificateWithOptions(seed, &ServerCertificateOptions{
+		IPAddresses: ipAddresses,
+		DNSNames:    dnsNames,
+	})
+}
+
+type ServerCertificateOptions struct {
+	IPAddresses []net.IP
+	DNSNames    []string
+
+	// NotBefore and NotAfter allow expired or not yet valid certificates to
+	// be produced.  Zero times use the default validity period.
+	NotBefore time.Time
+	NotAfter  time.Time
+
+	Key KeySpec
+}
+
+func (d *CertAuthority) MakeServerCertificateWithOptions(
+	seed string,
+	opts *ServerCertificateOptions,
+) ([]byte, []byte, error) {
+	err := opts.Key.Validate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	notBefore := opts.NotBefore
+	if notBefore.IsZero() {
+		notBefore = DefaultNotBefore
+	}
+	notAfter := opts.NotAfter
+	if notAfter.IsZero() {
+		notAfter = DefaultNotAfter
+	}
+
 	rnd := newSeededRand(seed)
 
-	privKey, err := rsa.GenerateKey(&certRandReader{rnd}, 4096)
+	privKey, err := opts.Key.generateKey(rnd)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	subjectKeyId := makeSubjectKeyId(privKey)
+	subjectKeyId, err := makeSubjectKeyIdForKey(privKey)
+	if err != nil {
+		return nil, nil, err
+	}
 	authorityKeyId := d.SubjectKeyId
 
+	// key encipherment only applies to rsa key exchange
+	keyUsage := x509.KeyUsageDigitalSignature
+	if _, ok := privKey.(*rsa.PrivateKey); ok {
+		keyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
 	cert := &x509.Certificate{
 		SerialNumber: big.NewInt(2),
 		Subject: pkix.Name{
 			CommonName: "dinocert-" + seed,
 		},
-		IPAddresses:    ipAddresses,
-		DNSNames:       dnsNames,
-		NotBefore:      time.Date(2025, 01, 01, 00, 00, 00, 00, time.UTC),
-		NotAfter:       time.Date(2035, 01, 01, 00, 00, 00, 00, time.UTC),
+		IPAddresses:    opts.IPAddresses,
+		DNSNames:       opts.DNSNames,
+		NotBefore:      notBefore,
+		NotAfter:       notAfter,
 		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
-		KeyUsage:       x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		KeyUsage:       keyUsage,
 		SubjectKeyId:   subjectKeyId,
 		AuthorityKeyId: authorityKeyId,
 	}
 
-	certBytes, err := x509.CreateCertificate(nil, cert, d.Cert, &privKey.PublicKey, d.PrivKey)
+	certBytes, err := x509.CreateCertificate(nil, cert, d.Cert, privKey.Public(), d.PrivKey)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -195,27 +256,42 @@ func (d *CertAuthority) MakeServerCertificate(
 		Bytes: certBytes,
 	})
 
-	privKeyPem := new(bytes.Buffer)
-	pem.Encode(privKeyPem, &pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(privKey),
-	})
+	privKeyPem, err := opts.Key.encodeKeyPem(privKey, rnd)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	return certPem.Bytes(), privKeyPem.Bytes(), nil
+	return certPem.Bytes(), privKeyPem, nil
 }
 
 func (d *CertAuthority) MakeClientCertificate(
 	username string,
 	expiresIn time.Duration,
 ) ([]byte, []byte, error) {
+	return d.MakeClientCertificateWithKey(username, expiresIn, KeySpec{})
+}
+
+func (d *CertAuthority) MakeClientCertificateWithKey(
+	username string,
+	expiresIn time.Duration,
+	key KeySpec,
+) ([]byte, []byte, error) {
+	err := key.Validate()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	rnd := newSeededRand(username)
 
-	privKey, err := rsa.GenerateKey(&certRandReader{rnd}, 4096)
+	privKey, err := key.generateKey(rnd)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	subjectKeyId := makeSubjectKeyId(privKey)
+	subjectKeyId, err := makeSubjectKeyIdForKey(privKey)
+	if err != nil {
+		return nil, nil, err
+	}
 	authorityKeyId := d.SubjectKeyId
 
 	var notAfter time.Time
@@ -240,7 +316,7 @@ func (d *CertAuthority) MakeClientCertificate(
 		AuthorityKeyId: authorityKeyId,
 	}
 
-	certBytes, err := x509.CreateCertificate(nil, cert, d.Cert, &privKey.PublicKey, d.PrivKey)
+	certBytes, err := x509.CreateCertificate(nil, cert, d.Cert, privKey.Public(), d.PrivKey)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -251,11 +327,10 @@ func (d *CertAuthority) MakeClientCertificate(
 		Bytes: certBytes,
 	})
 
-	privKeyPem := new(bytes.Buffer)
-	pem.Encode(privKeyPem, &pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(privKey),
-	})
+	privKeyPem, err := key.encodeKeyPem(privKey, rnd)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	return certPem.Bytes(), privKeyPem.Bytes(), nil
+	return certPem.Bytes(), privKeyPem, nil
 }
@@ -1,7 +1,11 @@
 package dinocerts_test
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"testing"
+	"time"
 
 	"github.com/couchbaselabs/cbdinocluster/utils/dinocerts"
 	"github.com/stretchr/testify/require"
@@ -45,3 +49,87 @@ func TestSameSeedEqualCerts(t *testing.T) {
 	require.Equal(t, cert1, cert2)
 	require.Equal(t, key1, key2)
 }
+
+func TestServerCertificateValidity(t *testing.T) {
+	ca, err := dinocerts.NewDinoCertAuthority("seed")
+	require.NoError(t, err)
+
+	notBefore := time.Date(2020, 01, 01, 00, 00, 00, 00, time.UTC)
+	notAfter := time.Date(2021, 01, 01, 00, 00, 00, 00, time.UTC)
+
+	certPem, _, err := ca.MakeServerCertificateWithOptions("server", &dinocerts.ServerCertificateOptions{
+		NotBefore: notBefore,
+		NotAfter:  notAfter,
+	})
+	require.NoError(t, err)
+
+	block, _ := pem.Decode(certPem)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	require.Equal(t, notBefore, cert.NotBefore)
+	require.Equal(t, notAfter, cert.NotAfter)
+
+	defaultPem, _, err := ca.MakeServerCertificate("server", nil, nil)
+	require.NoError(t, err)
+
+	block, _ = pem.Decode(defaultPem)
+	cert, err = x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	require.Equal(t, dinocerts.DefaultNotAfter, cert.NotAfter)
+}
+
+func TestClientCertificateKeySpecs(t *testing.T) {
+	ca, err := dinocerts.NewDinoCertAuthority("seed")
+	require.NoError(t, err)
+
+	caCert, err := x509.ParseCertificate(ca.CertBytes)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		key     dinocerts.KeySpec
+		pemType string
+	}{
+		{"default", dinocerts.KeySpec{}, "RSA PRIVATE KEY"},
+		{"rsa-2048 pkcs8", dinocerts.KeySpec{Type: dinocerts.KeyTypeRSA2048, Format: dinocerts.KeyFormatPKCS8}, "PRIVATE KEY"},
+		{"ecdsa-p256", dinocerts.KeySpec{Type: dinocerts.KeyTypeECDSAP256}, "EC PRIVATE KEY"},
+		{"ecdsa-p384 pkcs8", dinocerts.KeySpec{Type: dinocerts.KeyTypeECDSAP384, Format: dinocerts.KeyFormatPKCS8}, "PRIVATE KEY"},
+		{"ed25519", dinocerts.KeySpec{Type: dinocerts.KeyTypeEd25519}, "PRIVATE KEY"},
+		{"encrypted", dinocerts.KeySpec{Type: dinocerts.KeyTypeECDSAP256, Passphrase: "secret"}, "ENCRYPTED PRIVATE KEY"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cert1, key1, err := ca.MakeClientCertificateWithKey("user", 0, tc.key)
+			require.NoError(t, err)
+
+			cert2, key2, err := ca.MakeClientCertificateWithKey("user", 0, tc.key)
+			require.NoError(t, err)
+
+			require.Equal(t, cert1, cert2)
+			require.Equal(t, key1, key2)
+
+			block, _ := pem.Decode(key1)
+			require.Equal(t, tc.pemType, block.Type)
+
+			certBlock, _ := pem.Decode(cert1)
+			cert, err := x509.ParseCertificate(certBlock.Bytes)
+			require.NoError(t, err)
+
+			if block.Type != "ENCRYPTED PRIVATE KEY" {
+				_, err := tls.X509KeyPair(cert1, key1)
+				require.NoError(t, err)
+			}
+			require.NoError(t, cert.CheckSignatureFrom(caCert))
+		})
+	}
+}
+
+func TestInvalidKeySpecs(t *testing.T) {
+	require.Error(t, dinocerts.KeySpec{Type: "dsa"}.Validate())
+	require.Error(t, dinocerts.KeySpec{Format: "der"}.Validate())
+	require.Error(t, dinocerts.KeySpec{Type: dinocerts.KeyTypeECDSAP256, Format: dinocerts.KeyFormatPKCS1}.Validate())
+	require.Error(t, dinocerts.KeySpec{Type: dinocerts.KeyTypeEd25519, Format: dinocerts.KeyFormatSEC1}.Validate())
+	require.Error(t, dinocerts.KeySpec{Format: dinocerts.KeyFormatPKCS1, Passphrase: "secret"}.Validate())
+	require.NoError(t, dinocerts.KeySpec{Passphrase: "secret"}.Validate())
+}
@@ -0,0 +1,345 @@
+package dinocerts
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/pbkdf2"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+type KeyType string
+
+const (
+	KeyTypeRSA2048   KeyType = "rsa-2048"
+	KeyTypeRSA4096   KeyType = "rsa-4096"
+	KeyTypeECDSAP256 KeyType = "ecdsa-p256"
+	KeyTypeECDSAP384 KeyType = "ecdsa-p384"
+	KeyTypeEd25519   KeyType = "ed25519"
+)
+
+var KeyTypes = []KeyType{
+	KeyTypeRSA2048, KeyTypeRSA4096, KeyTypeECDSAP256, KeyTypeECDSAP384, KeyTypeEd25519,
+}
+
+type KeyFormat string
+
+const (
+	// KeyFormatPKCS1 is only valid for RSA keys.
+	KeyFormatPKCS1 KeyFormat = "pkcs1"
+	// KeyFormatSEC1 is only valid for ECDSA keys.
+	KeyFormatSEC1  KeyFormat = "sec1"
+	KeyFormatPKCS8 KeyFormat = "pkcs8"
+)
+
+var KeyFormats = []KeyFormat{
+	KeyFormatPKCS1, KeyFormatSEC1, KeyFormatPKCS8,
+}
+
+// KeySpec describes the private key of a generated certificate.  The zero
+// value is an RSA-4096 key in PKCS#1, which is what all certificates used
+// before key specs were introduced.
+type KeySpec struct {
+	Type   KeyType
+	Format KeyFormat
+
+	// Passphrase encrypts the key as an encrypted PKCS#8 key, and so can only
+	// be used with the PKCS#8 format.
+	Passphrase string
+}
+
+func (s KeySpec) withDefaults() KeySpec {
+	if s.Type == "" {
+		s.Type = KeyTypeRSA4096
+	}
+
+	if s.Format == "" {
+		if s.Passphrase != "" {
+			s.Format = KeyFormatPKCS8
+		} else {
+			switch s.Type {
+			case KeyTypeRSA2048, KeyTypeRSA4096:
+				s.Format = KeyFormatPKCS1
+			case KeyTypeECDSAP256, KeyTypeECDSAP384:
+				s.Format = KeyFormatSEC1
+			default:
+				s.Format = KeyFormatPKCS8
+			}
+		}
+	}
+
+	return s
+}
+
+func (s KeySpec) Validate() error {
+	s = s.withDefaults()
+
+	if !slices.Contains(KeyTypes, s.Type) {
+		return fmt.Errorf("unsupported key type `%s`, must be one of %s", s.Type, joinKeyOptions(KeyTypes))
+	}
+	if !slices.Contains(KeyFormats, s.Format) {
+		return fmt.Errorf("unsupported key format `%s`, must be one of %s", s.Format, joinKeyOptions(KeyFormats))
+	}
+
+	isRsa := s.Type == KeyTypeRSA2048 || s.Type == KeyTypeRSA4096
+	isEcdsa := s.Type == KeyTypeECDSAP256 || s.Type == KeyTypeECDSAP384
+	if s.Format == KeyFormatPKCS1 && !isRsa {
+		return fmt.Errorf("key format pkcs1 can only be used with rsa keys")
+	}
+	if s.Format == KeyFormatSEC1 && !isEcdsa {
+		return fmt.Errorf("key format sec1 can only be used with ecdsa keys")
+	}
+	if s.Passphrase != "" && s.Format != KeyFormatPKCS8 {
+		return fmt.Errorf("encrypted keys must use the pkcs8 key format")
+	}
+
+	return nil
+}
+
+func joinKeyOptions[T ~string](options []T) string {
+	var strs []string
+	for _, option := range options {
+		strs = append(strs, string(option))
+	}
+	return strings.Join(strs, ", ")
+}
+
+// generateKey deterministically generates a key from the random source.
+func (s KeySpec) generateKey(rnd io.Reader) (crypto.Signer, error) {
+	switch s.withDefaults().Type {
+	case KeyTypeRSA2048:
+		return rsa.GenerateKey(&certRandReader{rnd}, 2048)
+	case KeyTypeRSA4096:
+		return rsa.GenerateKey(&certRandReader{rnd}, 4096)
+	case KeyTypeECDSAP256:
+		return generateEcdsaKey(rnd, ecdh.P256(), elliptic.P256())
+	case KeyTypeECDSAP384:
+		return generateEcdsaKey(rnd, ecdh.P384(), elliptic.P384())
+	case KeyTypeEd25519:
+		seed := make([]byte, ed25519.SeedSize)
+		_, err := io.ReadFull(rnd, seed)
+		if err != nil {
+			return nil, err
+		}
+		return ed25519.NewKeyFromSeed(seed), nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type `%s`", s.Type)
+}
+
+// generateEcdsaKey derives the private scalar directly from the random source,
+// since ecdsa.GenerateKey deliberately does not produce the same key for the
+// same random source.
+func generateEcdsaKey(rnd io.Reader, ecdhCurve ecdh.Curve, curve elliptic.Curve) (*ecdsa.PrivateKey, error) {
+	scalar := make([]byte, (curve.Params().BitSize+7)/8)
+	for {
+		_, err := io.ReadFull(rnd, scalar)
+		if err != nil {
+			return nil, err
+		}
+
+		// scalars outside of the curve order are rejected, so we just try
+		// again with the next bytes from the source.
+		ecdhKey, err := ecdhCurve.NewPrivateKey(scalar)
+		if err != nil {
+			continue
+		}
+
+		pubBytes := ecdhKey.PublicKey().Bytes()
+		coordLen := (len(pubBytes) - 1) / 2
+
+		return &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: curve,
+				X:     new(big.Int).SetBytes(pubBytes[1 : 1+coordLen]),
+				Y:     new(big.Int).SetBytes(pubBytes[1+coordLen:]),
+			},
+			D: new(big.Int).SetBytes(scalar),
+		}, nil
+	}
+}
+
+func makeSubjectKeyIdForKey(privKey crypto.Signer) ([]byte, error) {
+	if rsaKey, ok := privKey.(*rsa.PrivateKey); ok {
+		return makeSubjectKeyId(rsaKey), nil
+	}
+
+	keyBytes, err := x509.MarshalPKIXPublicKey(privKey.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	keyHash := sha1.Sum(keyBytes)
+	return keyHash[:], nil
+}
+
+// encodeKeyPem encodes a private key in the format of the spec, encrypting it
+// with the passphrase if there is one.  The random source is used for the
+// encryption salt and iv so that encrypted keys are deterministic too.
+func (s KeySpec) encodeKeyPem(privKey crypto.Signer, rnd io.Reader) ([]byte, error) {
+	s = s.withDefaults()
+
+	var block *pem.Block
+	switch s.Format {
+	case KeyFormatPKCS1:
+		rsaKey, ok := privKey.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("key format pkcs1 can only be used with rsa keys")
+		}
+		block = &pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(rsaKey),
+		}
+	case KeyFormatSEC1:
+		ecKey, ok := privKey.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("key format sec1 can only be used with ecdsa keys")
+		}
+		keyBytes, err := x509.MarshalECPrivateKey(ecKey)
+		if err != nil {
+			return nil, err
+		}
+		block = &pem.Block{
+			Type:  "EC PRIVATE KEY",
+			Bytes: keyBytes,
+		}
+	case KeyFormatPKCS8:
+		keyBytes, err := x509.MarshalPKCS8PrivateKey(privKey)
+		if err != nil {
+			return nil, err
+		}
+
+		if s.Passphrase != "" {
+			encBytes, err := encryptPKCS8(keyBytes, s.Passphrase, rnd)
+			if err != nil {
+				return nil, err
+			}
+			block = &pem.Block{
+				Type:  "ENCRYPTED PRIVATE KEY",
+				Bytes: encBytes,
+			}
+		} else {
+			block = &pem.Block{
+				Type:  "PRIVATE KEY",
+				Bytes: keyBytes,
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported key format `%s`", s.Format)
+	}
+
+	keyPem := new(bytes.Buffer)
+	pem.Encode(keyPem, block)
+	return keyPem.Bytes(), nil
+}
+
+var (
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHmacWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+const pkcs8EncryptionIterations = 10000
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	PRF            pkix.AlgorithmIdentifier
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type encryptedPrivateKeyInfo struct {
+	EncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedData       []byte
+}
+
+// encryptPKCS8 encrypts a PKCS#8 key using PBES2 with PBKDF2-HMAC-SHA256 and
+// AES-256-CBC, which is what `openssl pkcs8 -topk8` produces by default.
+func encryptPKCS8(keyBytes []byte, passphrase string, rnd io.Reader) ([]byte, error) {
+	salt := make([]byte, 16)
+	_, err := io.ReadFull(rnd, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	_, err = io.ReadFull(rnd, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	encKey, err := pbkdf2.Key(sha256.New, passphrase, salt, pkcs8EncryptionIterations, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+
+	padLen := aes.BlockSize - len(keyBytes)%aes.BlockSize
+	plaintext := append(slices.Clone(keyBytes), bytes.Repeat([]byte{byte(padLen)}, padLen)...)
+	ciphertext := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, plaintext)
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:           salt,
+		IterationCount: pkcs8EncryptionIterations,
+		PRF: pkix.AlgorithmIdentifier{
+			Algorithm:  oidHmacWithSHA256,
+			Parameters: asn1.NullRawValue,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ivParams, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+
+	encParams, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPBKDF2,
+			Parameters: asn1.RawValue{FullBytes: kdfParams},
+		},
+		EncryptionScheme: pkix.AlgorithmIdentifier{
+			Algorithm:  oidAES256CBC,
+			Parameters: asn1.RawValue{FullBytes: ivParams},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		EncryptionAlgorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPBES2,
+			Parameters: asn1.RawValue{FullBytes: encParams},
+		},
+		EncryptedData: ciphertext,
+	})
+}
@@ -0,0 +1,229 @@
+package dinooidc
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/utils/dinocerts"
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+)
+
+// DefaultIssuerName is the issuer which exists even when no others have been
+// added, and whose first key is the key used before issuers were introduced.
+const DefaultIssuerName = "dino"
+
+// Issuer is an OIDC-style token issuer.  The signing keys of an issuer are
+// derived from the dino root authority, so only the key generations need to
+// be recorded in order to sign tokens or publish its JWKS.
+type Issuer struct {
+	Name      string   `json:"name"`
+	Audiences []string `json:"audiences"`
+
+	// KeyGenerations are the keys published in the JWKS of the issuer, and
+	// ActiveKeyGeneration is the one used to sign new tokens.
+	KeyGenerations      []int `json:"keyGenerations"`
+	ActiveKeyGeneration int   `json:"activeKeyGeneration"`
+}
+
+var issuerNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+func ValidateIssuerName(name string) error {
+	if !issuerNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid issuer name `%s`, must only contain letters, numbers, dashes and underscores", name)
+	}
+	return nil
+}
+
+func NewIssuer(name string, audiences []string) (*Issuer, error) {
+	err := ValidateIssuerName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(audiences) == 0 {
+		audiences = []string{"client"}
+	}
+
+	return &Issuer{
+		Name:                name,
+		Audiences:           audiences,
+		KeyGenerations:      []int{0},
+		ActiveKeyGeneration: 0,
+	}, nil
+}
+
+func DefaultIssuer() *Issuer {
+	issuer, _ := NewIssuer(DefaultIssuerName, nil)
+	return issuer
+}
+
+// RotateKey makes a new key active, keeping the previous keys published
+// unless dropOld is set.
+func (i *Issuer) RotateKey(dropOld bool) {
+	newGeneration := slices.Max(i.KeyGenerations) + 1
+	if dropOld {
+		i.KeyGenerations = nil
+	}
+	i.KeyGenerations = append(i.KeyGenerations, newGeneration)
+	i.ActiveKeyGeneration = newGeneration
+}
+
+func KeyID(issuerName string, generation int) string {
+	return fmt.Sprintf("%s-%d", issuerName, generation)
+}
+
+func SigningKey(issuerName string, generation int) (*rsa.PrivateKey, error) {
+	rootCa, err := dinocerts.GetRootCertAuthority()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get dino certificate authority")
+	}
+
+	// the first key of the default issuer is the one which clusters were
+	// configured with before issuers existed.
+	if issuerName == DefaultIssuerName && generation == 0 {
+		_, privKey, err := rootCa.GetRS256SigningKeys()
+		return privKey, err
+	}
+
+	return rootCa.MakeJwtSigningKey(fmt.Sprintf("jwt-%s-%d", issuerName, generation))
+}
+
+type Jwks struct {
+	Keys []Jwk `json:"keys"`
+}
+
+type Jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func BuildJwks(issuer *Issuer) (*Jwks, error) {
+	jwks := &Jwks{Keys: []Jwk{}}
+	for _, generation := range issuer.KeyGenerations {
+		privKey, err := SigningKey(issuer.Name, generation)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get signing key")
+		}
+
+		jwks.Keys = append(jwks.Keys, Jwk{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: "RS256",
+			Kid: KeyID(issuer.Name, generation),
+			N:   base64.RawURLEncoding.EncodeToString(privKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privKey.E)).Bytes()),
+		})
+	}
+
+	return jwks, nil
+}
+
+type Discovery struct {
+	Issuer                           string   `json:"issuer"`
+	JwksUri                          string   `json:"jwks_uri"`
+	IdTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+	SubjectTypesSupported            []string `json:"subject_types_supported"`
+	ResponseTypesSupported           []string `json:"response_types_supported"`
+}
+
+// BuildDiscovery builds the openid-configuration document of an issuer, whose
+// identifier is the url the document is served beneath.
+func BuildDiscovery(issuerUri string, jwksUri string) *Discovery {
+	return &Discovery{
+		Issuer:                           issuerUri,
+		JwksUri:                          jwksUri,
+		IdTokenSigningAlgValuesSupported: []string{"RS256"},
+		SubjectTypesSupported:            []string{"public"},
+		ResponseTypesSupported:           []string{"id_token"},
+	}
+}
+
+type TokenOptions struct {
+	Subject string
+	Roles   []string
+	Groups  []string
+
+	// Audiences defaults to the audiences of the issuer.
+	Audiences []string
+
+	// ExpiresIn of zero leaves out the expiry claim.
+	ExpiresIn time.Duration
+
+	// NotBeforeSkew and IssuedAtSkew offset the nbf and iat claims from now.
+	NotBeforeSkew time.Duration
+	IssuedAtSkew  time.Duration
+
+	// KeyGeneration signs with a specific key of the issuer rather than its
+	// active key, when set.
+	KeyGeneration *int
+
+	// Claims are added to the token, overriding any standard claims.
+	Claims map[string]any
+
+	Now time.Time
+}
+
+func IssueToken(issuer *Issuer, opts *TokenOptions) (string, error) {
+	generation := issuer.ActiveKeyGeneration
+	if opts.KeyGeneration != nil {
+		generation = *opts.KeyGeneration
+		if !slices.Contains(issuer.KeyGenerations, generation) {
+			return "", fmt.Errorf("issuer %s has no key generation %d", issuer.Name, generation)
+		}
+	}
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	audiences := opts.Audiences
+	if len(audiences) == 0 {
+		audiences = issuer.Audiences
+	}
+
+	claims := jwt.MapClaims{
+		"iss": issuer.Name,
+		"sub": opts.Subject,
+		"aud": audiences,
+		"iat": now.Add(opts.IssuedAtSkew).Unix(),
+		"nbf": now.Add(opts.NotBeforeSkew).Unix(),
+	}
+	if opts.ExpiresIn != 0 {
+		claims["exp"] = now.Add(opts.ExpiresIn).Unix()
+	}
+	if opts.Roles != nil {
+		claims["roles"] = opts.Roles
+	}
+	if opts.Groups != nil {
+		claims["groups"] = opts.Groups
+	}
+	for name, value := range opts.Claims {
+		claims[name] = value
+	}
+
+	privKey, err := SigningKey(issuer.Name, generation)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get signing key")
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = KeyID(issuer.Name, generation)
+
+	signedToken, err := token.SignedString(privKey)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to sign token")
+	}
+
+	return signedToken, nil
+}
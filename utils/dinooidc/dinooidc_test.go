@@ -0,0 +1,80 @@
+package dinooidc_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/utils/dinooidc"
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateKey(t *testing.T) {
+	issuer, err := dinooidc.NewIssuer("test", nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"client"}, issuer.Audiences)
+
+	issuer.RotateKey(false)
+	require.Equal(t, []int{0, 1}, issuer.KeyGenerations)
+	require.Equal(t, 1, issuer.ActiveKeyGeneration)
+
+	issuer.RotateKey(true)
+	require.Equal(t, []int{2}, issuer.KeyGenerations)
+	require.Equal(t, 2, issuer.ActiveKeyGeneration)
+}
+
+func TestInvalidIssuerName(t *testing.T) {
+	_, err := dinooidc.NewIssuer("../etc", nil)
+	require.Error(t, err)
+}
+
+func TestIssueToken(t *testing.T) {
+	issuer, err := dinooidc.NewIssuer("test", []string{"aud1"})
+	require.NoError(t, err)
+	issuer.RotateKey(false)
+
+	now := time.Unix(1700000000, 0)
+	tokenStr, err := dinooidc.IssueToken(issuer, &dinooidc.TokenOptions{
+		Subject:       "user",
+		Roles:         []string{"admin"},
+		Groups:        []string{"devs"},
+		ExpiresIn:     time.Hour,
+		NotBeforeSkew: 10 * time.Minute,
+		IssuedAtSkew:  -time.Minute,
+		Claims:        map[string]any{"tenant": "acme"},
+		Now:           now,
+	})
+	require.NoError(t, err)
+
+	jwks, err := dinooidc.BuildJwks(issuer)
+	require.NoError(t, err)
+	require.Len(t, jwks.Keys, 2)
+
+	claims := jwt.MapClaims{}
+	token, err := jwt.NewParser(jwt.WithoutClaimsValidation()).ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (any, error) {
+		key, err := dinooidc.SigningKey(issuer.Name, issuer.ActiveKeyGeneration)
+		if err != nil {
+			return nil, err
+		}
+		return &key.PublicKey, nil
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "test-1", token.Header["kid"])
+	require.Equal(t, jwks.Keys[1].Kid, token.Header["kid"])
+	require.Equal(t, "test", claims["iss"])
+	require.Equal(t, []any{"aud1"}, claims["aud"])
+	require.Equal(t, []any{"devs"}, claims["groups"])
+	require.Equal(t, "acme", claims["tenant"])
+	require.Equal(t, float64(now.Add(time.Hour).Unix()), claims["exp"])
+	require.Equal(t, float64(now.Add(10*time.Minute).Unix()), claims["nbf"])
+	require.Equal(t, float64(now.Add(-time.Minute).Unix()), claims["iat"])
+
+	oldGeneration := 0
+	_, err = dinooidc.IssueToken(issuer, &dinooidc.TokenOptions{KeyGeneration: &oldGeneration})
+	require.NoError(t, err)
+
+	missingGeneration := 5
+	_, err = dinooidc.IssueToken(issuer, &dinooidc.TokenOptions{KeyGeneration: &missingGeneration})
+	require.Error(t, err)
+}
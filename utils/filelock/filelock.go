@@ -0,0 +1,63 @@
+package filelock
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// pollInterval is how often a held lock is retried.
+const pollInterval = 50 * time.Millisecond
+
+// Lock is an exclusive advisory lock on a file, which serializes work between
+// processes as well as within one.  The lock is released by the operating
+// system if the process exits without releasing it.
+type Lock struct {
+	file *os.File
+}
+
+// Acquire waits until the lock on the file at path is acquired, or the context
+// is done.  The file is created if it does not exist.
+func Acquire(ctx context.Context, path string) (*Lock, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create lock directory")
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open lock file")
+	}
+
+	for {
+		locked, err := tryLock(file)
+		if err != nil {
+			file.Close()
+			return nil, errors.Wrap(err, "failed to lock file")
+		}
+		if locked {
+			return &Lock{file: file}, nil
+		}
+
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			file.Close()
+			return nil, errors.Wrap(ctx.Err(), "context finished while waiting for lock")
+		}
+	}
+}
+
+// Release releases the lock.
+func (l *Lock) Release() error {
+	err := unlock(l.file)
+	if err != nil {
+		l.file.Close()
+		return errors.Wrap(err, "failed to unlock file")
+	}
+
+	return l.file.Close()
+}
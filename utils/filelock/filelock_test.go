@@ -0,0 +1,30 @@
+package filelock
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockIsExclusive(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "test.lock")
+
+	lock, err := Acquire(context.Background(), lockPath)
+	require.NoError(t, err)
+
+	// flock locks are held per open file, so a second acquire within the
+	// same process waits just like another process would.
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err = Acquire(ctx, lockPath)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, lock.Release())
+
+	lock, err = Acquire(context.Background(), lockPath)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+}
@@ -0,0 +1,113 @@
+package workerpool
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+type Options struct {
+	Workers int
+
+	// When Duration is zero, the workers run until they have nothing left to
+	// do or the context is done.
+	Duration time.Duration
+
+	// OpsPerSec limits the rate at which operations are started, unless it is
+	// zero.
+	OpsPerSec int
+
+	// OnInterval is called every Interval while the workers run, if both are
+	// set.  It is always called from the goroutine which called Run.
+	Interval   time.Duration
+	OnInterval func(now time.Time)
+}
+
+// WorkFunc runs one operation of a worker, using the random source of that
+// worker.  It returns false once the worker has nothing left to do, and any
+// error it returns stops every worker.
+type WorkFunc func(ctx context.Context, rnd *rand.Rand) (bool, error)
+
+// Run calls work repeatedly from a number of concurrent workers, until each
+// has nothing left to do, the duration has passed or the context is done.
+// The first error returned by work is returned once every worker stops.
+func Run(ctx context.Context, opts *Options, work WorkFunc) error {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	var limiter *rate.Limiter
+	if opts.OpsPerSec > 0 {
+		limiter = rate.NewLimiter(rate.Limit(opts.OpsPerSec), workers)
+	}
+
+	var runCtx context.Context
+	var cancel context.CancelFunc
+	if opts.Duration > 0 {
+		runCtx, cancel = context.WithTimeout(ctx, opts.Duration)
+	} else {
+		runCtx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	startTime := time.Now()
+
+	var workErr error
+	var workErrOnce sync.Once
+
+	var wg sync.WaitGroup
+	for workerIdx := 0; workerIdx < workers; workerIdx++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(seed))
+
+			for runCtx.Err() == nil {
+				if limiter != nil {
+					if limiter.Wait(runCtx) != nil {
+						return
+					}
+				}
+
+				more, err := work(runCtx, rnd)
+				if err != nil {
+					workErrOnce.Do(func() { workErr = err })
+					cancel()
+					return
+				}
+				if !more {
+					return
+				}
+			}
+		}(startTime.UnixNano() + int64(workerIdx))
+	}
+
+	doneCh := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(doneCh)
+	}()
+
+	if opts.Interval > 0 && opts.OnInterval != nil {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+
+	ReportLoop:
+		for {
+			select {
+			case <-doneCh:
+				break ReportLoop
+			case now := <-ticker.C:
+				opts.OnInterval(now)
+			}
+		}
+	} else {
+		<-doneCh
+	}
+
+	return workErr
+}
@@ -0,0 +1,57 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunUntilDone(t *testing.T) {
+	var remaining atomic.Int64
+	remaining.Store(100)
+
+	var ops atomic.Int64
+	err := Run(context.Background(), &Options{Workers: 4}, func(ctx context.Context, rnd *rand.Rand) (bool, error) {
+		if remaining.Add(-1) < 0 {
+			return false, nil
+		}
+		ops.Add(1)
+		return true, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), ops.Load())
+}
+
+func TestRunDuration(t *testing.T) {
+	var intervals int
+	var ops atomic.Int64
+	err := Run(context.Background(), &Options{
+		Workers:    2,
+		Duration:   200 * time.Millisecond,
+		OpsPerSec:  100,
+		Interval:   50 * time.Millisecond,
+		OnInterval: func(now time.Time) { intervals++ },
+	}, func(ctx context.Context, rnd *rand.Rand) (bool, error) {
+		ops.Add(1)
+		return true, nil
+	})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, intervals, 2)
+
+	// the rate limit allows a burst of one op per worker
+	require.Greater(t, ops.Load(), int64(5))
+	require.LessOrEqual(t, ops.Load(), int64(25))
+}
+
+func TestRunError(t *testing.T) {
+	testErr := errors.New("test error")
+	err := Run(context.Background(), &Options{Workers: 4}, func(ctx context.Context, rnd *rand.Rand) (bool, error) {
+		return true, testErr
+	})
+	require.ErrorIs(t, err, testErr)
+}
@@ -0,0 +1,111 @@
+package workload
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/maps"
+)
+
+type Op string
+
+const (
+	OpGet       Op = "get"
+	OpUpsert    Op = "upsert"
+	OpLookupIn  Op = "lookup-in"
+	OpMutateIn  Op = "mutate-in"
+	OpQuery     Op = "query"
+	OpRangeScan Op = "range-scan"
+	OpTxn       Op = "txn"
+)
+
+var allOps = []Op{OpGet, OpUpsert, OpLookupIn, OpMutateIn, OpQuery, OpRangeScan, OpTxn}
+
+// Mix is the relative weights of the operations of a workload.
+type Mix map[Op]int
+
+// Profiles are the named mixes of operations.
+var Profiles = map[string]Mix{
+	"kv-mixed": {OpGet: 60, OpUpsert: 25, OpLookupIn: 10, OpMutateIn: 5},
+	"query":    {OpQuery: 70, OpRangeScan: 20, OpGet: 10},
+	"txn":      {OpTxn: 80, OpGet: 20},
+}
+
+func ProfileNames() []string {
+	names := maps.Keys(Profiles)
+	sort.Strings(names)
+	return names
+}
+
+// ParseMix parses a mix of operations such as get=60,upsert=40.
+func ParseMix(s string) (Mix, error) {
+	mix := make(Mix)
+	for _, part := range strings.Split(s, ",") {
+		opStr, weightStr, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid mix entry '%s', expected op=weight", part)
+		}
+
+		op := Op(strings.TrimSpace(opStr))
+		if !isKnownOp(op) {
+			return nil, fmt.Errorf("unknown operation '%s'", op)
+		}
+
+		weight, err := strconv.Atoi(strings.TrimSpace(weightStr))
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight '%s' for operation '%s'", weightStr, op)
+		}
+
+		mix[op] = weight
+	}
+
+	return mix, nil
+}
+
+func isKnownOp(op Op) bool {
+	for _, knownOp := range allOps {
+		if op == knownOp {
+			return true
+		}
+	}
+	return false
+}
+
+// opPicker picks operations at random in proportion to their weights.
+type opPicker struct {
+	ops        []Op
+	cumulative []int
+	total      int
+}
+
+func newOpPicker(mix Mix) (*opPicker, error) {
+	picker := &opPicker{}
+
+	// ops are sorted so that a seed always picks the same operations
+	ops := maps.Keys(mix)
+	sort.Slice(ops, func(i, j int) bool { return ops[i] < ops[j] })
+
+	for _, op := range ops {
+		if mix[op] <= 0 {
+			continue
+		}
+		picker.total += mix[op]
+		picker.ops = append(picker.ops, op)
+		picker.cumulative = append(picker.cumulative, picker.total)
+	}
+
+	if picker.total == 0 {
+		return nil, fmt.Errorf("the mix must contain at least one operation")
+	}
+
+	return picker, nil
+}
+
+func (p *opPicker) pick(rnd *rand.Rand) Op {
+	n := rnd.Intn(p.total)
+	idx := sort.SearchInts(p.cumulative, n+1)
+	return p.ops[idx]
+}
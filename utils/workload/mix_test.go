@@ -0,0 +1,63 @@
+package workload
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMix(t *testing.T) {
+	testCases := []struct {
+		input string
+		mix   Mix
+		isErr bool
+	}{
+		{"get=60,upsert=40", Mix{OpGet: 60, OpUpsert: 40}, false},
+		{" query = 1 , range-scan=0", Mix{OpQuery: 1, OpRangeScan: 0}, false},
+		{"txn=80,get=20", Mix{OpTxn: 80, OpGet: 20}, false},
+		{"get", nil, true},
+		{"range-query=10", nil, true},
+		{"get=-1", nil, true},
+		{"get=lots", nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			mix, err := ParseMix(tc.input)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.mix, mix)
+		})
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	require.Equal(t, []string{"kv-mixed", "query", "txn"}, ProfileNames())
+
+	for name, mix := range Profiles {
+		_, err := newOpPicker(mix)
+		require.NoError(t, err, name)
+	}
+}
+
+func TestOpPicker(t *testing.T) {
+	_, err := newOpPicker(Mix{OpGet: 0})
+	require.Error(t, err)
+
+	picker, err := newOpPicker(Mix{OpGet: 3, OpUpsert: 1, OpQuery: 0})
+	require.NoError(t, err)
+
+	counts := make(map[Op]int)
+	rnd := rand.New(rand.NewSource(0))
+	for i := 0; i < 10000; i++ {
+		counts[picker.pick(rnd)]++
+	}
+
+	require.NotContains(t, counts, OpQuery)
+	require.InDelta(t, 7500, counts[OpGet], 300)
+	require.InDelta(t, 2500, counts[OpUpsert], 300)
+}
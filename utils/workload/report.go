@@ -0,0 +1,158 @@
+package workload
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatText       Format = "text"
+	FormatJSONLines  Format = "jsonl"
+	FormatPrometheus Format = "prometheus"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case FormatText, FormatJSONLines, FormatPrometheus:
+		return Format(s), nil
+	}
+	return "", fmt.Errorf("unknown format '%s', expected text, jsonl or prometheus", s)
+}
+
+// WriteInterval writes the statistics of an interval in a format.  Every
+// interval written in the prometheus format is a complete exposition whose
+// samples are timestamped with the start of the interval.
+func WriteInterval(w io.Writer, format Format, stats *IntervalStats) error {
+	switch format {
+	case FormatText:
+		return writeText(w, stats)
+	case FormatJSONLines:
+		return writeJSONLine(w, stats)
+	case FormatPrometheus:
+		return writePrometheus(w, stats)
+	}
+	return fmt.Errorf("unknown format '%s'", format)
+}
+
+func sortedOps(stats *IntervalStats) []Op {
+	ops := make([]Op, 0, len(stats.Ops))
+	for op := range stats.Ops {
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i] < ops[j] })
+	return ops
+}
+
+func sortedErrorClasses(errors map[string]int64) []string {
+	errClasses := make([]string, 0, len(errors))
+	for errClass := range errors {
+		errClasses = append(errClasses, errClass)
+	}
+	sort.Strings(errClasses)
+	return errClasses
+}
+
+func writeText(w io.Writer, stats *IntervalStats) error {
+	timeStr := stats.Time.UTC().Format(time.RFC3339Nano)
+
+	if len(stats.Ops) == 0 {
+		_, err := fmt.Fprintf(w, "%s  no operations completed\n", timeStr)
+		return err
+	}
+
+	for _, op := range sortedOps(stats) {
+		opStats := stats.Ops[op]
+
+		var errStrs []string
+		for _, errClass := range sortedErrorClasses(opStats.Errors) {
+			errStrs = append(errStrs, fmt.Sprintf("%s:%d", errClass, opStats.Errors[errClass]))
+		}
+
+		_, err := fmt.Fprintf(w, "%s  %-10s ops=%d failed=%d timeouts=%d p50=%.2fms p90=%.2fms p99=%.2fms max=%.2fms",
+			timeStr, op, opStats.Count, opStats.Failed, opStats.Timeouts,
+			opStats.P50Ms, opStats.P90Ms, opStats.P99Ms, opStats.MaxMs)
+		if err != nil {
+			return err
+		}
+
+		if len(errStrs) > 0 {
+			_, err = fmt.Fprintf(w, " errors=%s", strings.Join(errStrs, ","))
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = fmt.Fprintf(w, "\n")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeJSONLine(w io.Writer, stats *IntervalStats) error {
+	statsBytes, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", statsBytes)
+	return err
+}
+
+const prometheusPrefix = "cbdinocluster_workload_"
+
+func writePrometheus(w io.Writer, stats *IntervalStats) error {
+	ts := stats.Time.UnixMilli()
+	ops := sortedOps(stats)
+
+	var b strings.Builder
+
+	b.WriteString("# HELP " + prometheusPrefix + "ops Operations completed during the interval.\n")
+	b.WriteString("# TYPE " + prometheusPrefix + "ops gauge\n")
+	for _, op := range ops {
+		fmt.Fprintf(&b, "%sops{op=%q} %d %d\n", prometheusPrefix, op, stats.Ops[op].Count, ts)
+	}
+
+	b.WriteString("# HELP " + prometheusPrefix + "timeouts Operations which timed out during the interval.\n")
+	b.WriteString("# TYPE " + prometheusPrefix + "timeouts gauge\n")
+	for _, op := range ops {
+		fmt.Fprintf(&b, "%stimeouts{op=%q} %d %d\n", prometheusPrefix, op, stats.Ops[op].Timeouts, ts)
+	}
+
+	b.WriteString("# HELP " + prometheusPrefix + "errors Operations which failed during the interval.\n")
+	b.WriteString("# TYPE " + prometheusPrefix + "errors gauge\n")
+	for _, op := range ops {
+		errors := stats.Ops[op].Errors
+		for _, errClass := range sortedErrorClasses(errors) {
+			fmt.Fprintf(&b, "%serrors{op=%q,error=%q} %d %d\n", prometheusPrefix, op, errClass, errors[errClass], ts)
+		}
+	}
+
+	b.WriteString("# HELP " + prometheusPrefix + "latency_seconds Latency percentiles of the operations of the interval.\n")
+	b.WriteString("# TYPE " + prometheusPrefix + "latency_seconds gauge\n")
+	for _, op := range ops {
+		opStats := stats.Ops[op]
+		for _, q := range []struct {
+			quantile string
+			valueMs  float64
+		}{
+			{"0.5", opStats.P50Ms},
+			{"0.9", opStats.P90Ms},
+			{"0.99", opStats.P99Ms},
+			{"1", opStats.MaxMs},
+		} {
+			fmt.Fprintf(&b, "%slatency_seconds{op=%q,quantile=%q} %g %d\n", prometheusPrefix, op, q.quantile, q.valueMs/1000, ts)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,82 @@
+package workload
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testIntervalStats() *IntervalStats {
+	return &IntervalStats{
+		Time:      time.Unix(1700000001, 0).UTC(),
+		ElapsedMs: 1000,
+		Ops: map[Op]*OpStats{
+			OpUpsert: {Count: 10, P50Ms: 1, P90Ms: 2, P99Ms: 3, MaxMs: 4},
+			OpGet: {
+				Count:    20,
+				Failed:   2,
+				Timeouts: 1,
+				Errors:   map[string]int64{"timeout": 1, "doc-not-found": 1},
+				P50Ms:    0.5,
+				P90Ms:    1.25,
+				P99Ms:    2500,
+				MaxMs:    2500,
+			},
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("jsonl")
+	require.NoError(t, err)
+	require.Equal(t, FormatJSONLines, format)
+
+	_, err = ParseFormat("csv")
+	require.Error(t, err)
+}
+
+func TestWriteIntervalText(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteInterval(&buf, FormatText, testIntervalStats())
+	require.NoError(t, err)
+	require.Equal(t,
+		"2023-11-14T22:13:21Z  get        ops=20 failed=2 timeouts=1 p50=0.50ms p90=1.25ms p99=2500.00ms max=2500.00ms errors=doc-not-found:1,timeout:1\n"+
+			"2023-11-14T22:13:21Z  upsert     ops=10 failed=0 timeouts=0 p50=1.00ms p90=2.00ms p99=3.00ms max=4.00ms\n",
+		buf.String())
+
+	buf.Reset()
+	err = WriteInterval(&buf, FormatText, &IntervalStats{Time: time.Unix(1700000001, 0)})
+	require.NoError(t, err)
+	require.Equal(t, "2023-11-14T22:13:21Z  no operations completed\n", buf.String())
+}
+
+func TestWriteIntervalJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteInterval(&buf, FormatJSONLines, &IntervalStats{
+		Time:      time.Unix(1700000001, 0).UTC(),
+		ElapsedMs: 1000,
+		Ops: map[Op]*OpStats{
+			OpGet: {Count: 1, P50Ms: 1, P90Ms: 1, P99Ms: 1, MaxMs: 1},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t,
+		`{"time":"2023-11-14T22:13:21Z","elapsed_ms":1000,"ops":{"get":{"count":1,"failed":0,"timeouts":0,"p50_ms":1,"p90_ms":1,"p99_ms":1,"max_ms":1}}}`+"\n",
+		buf.String())
+}
+
+func TestWriteIntervalPrometheus(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteInterval(&buf, FormatPrometheus, testIntervalStats())
+	require.NoError(t, err)
+
+	out := buf.String()
+	require.Contains(t, out, "# TYPE cbdinocluster_workload_ops gauge\n")
+	require.Contains(t, out, `cbdinocluster_workload_ops{op="get"} 20 1700000001000`+"\n")
+	require.Contains(t, out, `cbdinocluster_workload_timeouts{op="upsert"} 0 1700000001000`+"\n")
+	require.Contains(t, out, `cbdinocluster_workload_errors{op="get",error="doc-not-found"} 1 1700000001000`+"\n")
+	require.Contains(t, out, `cbdinocluster_workload_latency_seconds{op="get",quantile="0.99"} 2.5 1700000001000`+"\n")
+	require.Contains(t, out, `cbdinocluster_workload_latency_seconds{op="upsert",quantile="0.5"} 0.001 1700000001000`+"\n")
+}
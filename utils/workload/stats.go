@@ -0,0 +1,141 @@
+package workload
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/utils/dataloader"
+)
+
+// IntervalStats are the statistics of the operations which completed during
+// one reporting interval.  Time is the start of the interval, which lines up
+// with the times of a chaos timeline.
+type IntervalStats struct {
+	Time      time.Time       `json:"time"`
+	ElapsedMs int64           `json:"elapsed_ms"`
+	Ops       map[Op]*OpStats `json:"ops"`
+}
+
+type OpStats struct {
+	Count    int64 `json:"count"`
+	Failed   int64 `json:"failed"`
+	Timeouts int64 `json:"timeouts"`
+
+	// Errors counts the failed operations by dataloader.ErrorClass.
+	Errors map[string]int64 `json:"errors,omitempty"`
+
+	// latencies include failed operations
+	P50Ms float64 `json:"p50_ms"`
+	P90Ms float64 `json:"p90_ms"`
+	P99Ms float64 `json:"p99_ms"`
+	MaxMs float64 `json:"max_ms"`
+}
+
+type opSamples struct {
+	latencies []time.Duration
+	errors    map[string]int64
+}
+
+// recorder collects the results of operations until they are flushed into
+// the statistics of an interval.
+type recorder struct {
+	lock sync.Mutex
+	ops  map[Op]*opSamples
+
+	count  int64
+	failed int64
+	errors map[string]int64
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		ops:    make(map[Op]*opSamples),
+		errors: make(map[string]int64),
+	}
+}
+
+func (r *recorder) record(op Op, latency time.Duration, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	samples := r.ops[op]
+	if samples == nil {
+		samples = &opSamples{errors: make(map[string]int64)}
+		r.ops[op] = samples
+	}
+
+	samples.latencies = append(samples.latencies, latency)
+	r.count++
+
+	if err != nil {
+		errClass := dataloader.ErrorClass(err)
+		samples.errors[errClass]++
+		r.errors[errClass]++
+		r.failed++
+	}
+}
+
+func (r *recorder) isEmpty() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.ops) == 0
+}
+
+// flush returns the statistics of the operations recorded since the last
+// flush, and starts a new interval.
+func (r *recorder) flush(startTime, intervalTime time.Time) *IntervalStats {
+	r.lock.Lock()
+	ops := r.ops
+	r.ops = make(map[Op]*opSamples)
+	r.lock.Unlock()
+
+	stats := &IntervalStats{
+		Time:      intervalTime,
+		ElapsedMs: intervalTime.Sub(startTime).Milliseconds(),
+		Ops:       make(map[Op]*OpStats),
+	}
+
+	for op, samples := range ops {
+		sort.Slice(samples.latencies, func(i, j int) bool {
+			return samples.latencies[i] < samples.latencies[j]
+		})
+
+		opStats := &OpStats{
+			Count:    int64(len(samples.latencies)),
+			Timeouts: samples.errors["timeout"],
+			P50Ms:    durationMs(percentile(samples.latencies, 0.50)),
+			P90Ms:    durationMs(percentile(samples.latencies, 0.90)),
+			P99Ms:    durationMs(percentile(samples.latencies, 0.99)),
+			MaxMs:    durationMs(percentile(samples.latencies, 1)),
+		}
+		for _, count := range samples.errors {
+			opStats.Failed += count
+		}
+		if len(samples.errors) > 0 {
+			opStats.Errors = samples.errors
+		}
+
+		stats.Ops[op] = opStats
+	}
+
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
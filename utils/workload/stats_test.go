@@ -0,0 +1,57 @@
+package workload
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/couchbase/gocbcorex/memdx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	require.Equal(t, time.Duration(0), percentile(nil, 0.5))
+	require.Equal(t, 50*time.Millisecond, percentile(latencies, 0.5))
+	require.Equal(t, 99*time.Millisecond, percentile(latencies, 0.99))
+	require.Equal(t, 100*time.Millisecond, percentile(latencies, 1))
+	require.Equal(t, 1*time.Millisecond, percentile(latencies, 0))
+}
+
+func TestRecorderFlush(t *testing.T) {
+	startTime := time.Unix(1700000000, 0)
+
+	rec := newRecorder()
+	require.True(t, rec.isEmpty())
+
+	rec.record(OpGet, 3*time.Millisecond, nil)
+	rec.record(OpGet, 1*time.Millisecond, nil)
+	rec.record(OpGet, 2500*time.Millisecond, context.DeadlineExceeded)
+	rec.record(OpUpsert, 2*time.Millisecond, fmt.Errorf("upsert failed: %w", memdx.ErrTmpFail))
+
+	stats := rec.flush(startTime, startTime.Add(2*time.Second))
+	require.Equal(t, startTime.Add(2*time.Second), stats.Time)
+	require.Equal(t, int64(2000), stats.ElapsedMs)
+	require.Equal(t, &OpStats{
+		Count:    3,
+		Failed:   1,
+		Timeouts: 1,
+		Errors:   map[string]int64{"timeout": 1},
+		P50Ms:    3,
+		P90Ms:    2500,
+		P99Ms:    2500,
+		MaxMs:    2500,
+	}, stats.Ops[OpGet])
+	require.Equal(t, map[string]int64{"tmp-fail": 1}, stats.Ops[OpUpsert].Errors)
+
+	// flushing starts a new interval, but keeps the totals
+	require.True(t, rec.isEmpty())
+	require.Empty(t, rec.flush(startTime, startTime.Add(3*time.Second)).Ops)
+	require.Equal(t, int64(4), rec.count)
+	require.Equal(t, int64(2), rec.failed)
+}
@@ -0,0 +1,213 @@
+package workload
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/couchbaselabs/cbdinocluster/utils/dataloader"
+	"github.com/couchbaselabs/cbdinocluster/utils/workerpool"
+)
+
+// workloadPath is the path of the documents which sub-document operations
+// read and write.
+const workloadPath = "workload"
+
+// rangeScanLimit is how many keys a range scan reads.
+const rangeScanLimit = 10
+
+// txnDocs is how many documents a transaction updates.
+const txnDocs = 2
+
+// Store is what a workload runs against.
+type Store interface {
+	Get(ctx context.Context, key string) error
+	Upsert(ctx context.Context, key string, value []byte, expiry time.Duration) error
+	LookupIn(ctx context.Context, key string, path string) error
+	MutateIn(ctx context.Context, key string, path string, value []byte) error
+	Query(ctx context.Context, statement string) error
+	RangeScan(ctx context.Context, fromKey string, limit int) error
+	Transaction(ctx context.Context, keys []string, path string, value []byte) error
+}
+
+type Options struct {
+	Mix Mix
+
+	// Generator generates the keys operated on and the documents which are
+	// upserted.  Keys are picked at random from the first Count keys.
+	Generator *dataloader.Generator
+	Count     int
+
+	// Expiry is the expiry set by upserts.
+	Expiry time.Duration
+
+	// Keyspace is the escaped N1QL keyspace of the documents, which queries
+	// read from.
+	Keyspace string
+
+	// OpsPerSec limits the rate of operations, unless it is zero.
+	OpsPerSec int
+	Workers   int
+
+	// When Duration is zero, the workload runs until its context is done.
+	Duration time.Duration
+
+	// OpTimeout is the timeout of each operation.
+	OpTimeout time.Duration
+
+	Interval   time.Duration
+	OnInterval func(stats *IntervalStats)
+}
+
+type Summary struct {
+	Elapsed time.Duration
+	Count   int64
+	Failed  int64
+	Errors  map[string]int64
+}
+
+// Run runs a workload until its duration has passed or its context is done,
+// reporting the statistics of each interval as it goes.  Failed operations
+// are counted rather than stopping the workload.
+func Run(ctx context.Context, store Store, opts *Options) (*Summary, error) {
+	if opts.Count <= 0 {
+		return nil, fmt.Errorf("count must be positive")
+	}
+	if opts.Generator == nil {
+		return nil, fmt.Errorf("a generator is required")
+	}
+	if opts.OpTimeout <= 0 {
+		return nil, fmt.Errorf("an operation timeout is required")
+	}
+
+	picker, err := newOpPicker(opts.Mix)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	runner := &runner{
+		store: store,
+		opts:  opts,
+	}
+	rec := newRecorder()
+
+	startTime := time.Now()
+	intervalTime := startTime
+
+	err = workerpool.Run(ctx, &workerpool.Options{
+		Workers:   opts.Workers,
+		Duration:  opts.Duration,
+		OpsPerSec: opts.OpsPerSec,
+		Interval:  interval,
+		OnInterval: func(now time.Time) {
+			stats := rec.flush(startTime, intervalTime)
+			intervalTime = now
+			if opts.OnInterval != nil {
+				opts.OnInterval(stats)
+			}
+		},
+	}, func(runCtx context.Context, rnd *rand.Rand) (bool, error) {
+		op := picker.pick(rnd)
+
+		opCtx, opCancel := context.WithTimeout(runCtx, opts.OpTimeout)
+		opStart := time.Now()
+		err := runner.runOp(opCtx, rnd, op)
+		latency := time.Since(opStart)
+		opCancel()
+
+		if err != nil {
+			// operations cut short by the end of the run are not failures
+			if runCtx.Err() != nil {
+				return false, nil
+			}
+
+			if _, ok := err.(*fatalError); ok {
+				return false, err
+			}
+		}
+
+		rec.record(op, latency, err)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the final interval is usually partial, so is only reported when
+	// something happened during it
+	if !rec.isEmpty() && opts.OnInterval != nil {
+		opts.OnInterval(rec.flush(startTime, intervalTime))
+	}
+
+	return &Summary{
+		Elapsed: time.Since(startTime),
+		Count:   rec.count,
+		Failed:  rec.failed,
+		Errors:  rec.errors,
+	}, nil
+}
+
+// fatalError is an error which stops a workload, rather than being counted
+// as a failed operation.
+type fatalError struct {
+	err error
+}
+
+func (e *fatalError) Error() string { return e.err.Error() }
+func (e *fatalError) Unwrap() error { return e.err }
+
+type runner struct {
+	store Store
+	opts  *Options
+}
+
+func (r *runner) randomKey(rnd *rand.Rand) string {
+	return r.opts.Generator.Key(rnd.Intn(r.opts.Count))
+}
+
+func (r *runner) runOp(ctx context.Context, rnd *rand.Rand, op Op) error {
+	switch op {
+	case OpGet:
+		return r.store.Get(ctx, r.randomKey(rnd))
+	case OpUpsert:
+		key, doc, err := r.opts.Generator.Generate(rnd, rnd.Intn(r.opts.Count))
+		if err != nil {
+			return &fatalError{err}
+		}
+		return r.store.Upsert(ctx, key, doc, r.opts.Expiry)
+	case OpLookupIn:
+		return r.store.LookupIn(ctx, r.randomKey(rnd), workloadPath)
+	case OpMutateIn:
+		return r.store.MutateIn(ctx, r.randomKey(rnd), workloadPath, workloadValue())
+	case OpQuery:
+		return r.store.Query(ctx, fmt.Sprintf("SELECT META(d).id, d.* FROM %s AS d USE KEYS %s",
+			r.opts.Keyspace, quoteString(r.randomKey(rnd))))
+	case OpRangeScan:
+		return r.store.RangeScan(ctx, r.randomKey(rnd), rangeScanLimit)
+	case OpTxn:
+		keys := make([]string, txnDocs)
+		for i := range keys {
+			keys[i] = r.randomKey(rnd)
+		}
+		return r.store.Transaction(ctx, keys, workloadPath, workloadValue())
+	}
+	return &fatalError{fmt.Errorf("unsupported operation '%s'", op)}
+}
+
+func workloadValue() []byte {
+	return []byte(fmt.Sprintf(`{"updated_ms":%d}`, time.Now().UnixMilli()))
+}
+
+// quoteString quotes a string as a N1QL string literal, which uses the same
+// escaping as JSON.
+func quoteString(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
@@ -0,0 +1,147 @@
+package workload
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/couchbase/gocbcorex/memdx"
+	"github.com/couchbaselabs/cbdinocluster/utils/dataloader"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	lock       sync.Mutex
+	docs       map[string][]byte
+	statements []string
+	scans      []string
+	txns       [][]string
+}
+
+func (s *fakeStore) exists(key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.docs[key]; !ok {
+		return memdx.ErrDocNotFound
+	}
+	return nil
+}
+
+func (s *fakeStore) Get(ctx context.Context, key string) error {
+	return s.exists(key)
+}
+
+func (s *fakeStore) Upsert(ctx context.Context, key string, value []byte, expiry time.Duration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.docs[key] = value
+	return nil
+}
+
+func (s *fakeStore) LookupIn(ctx context.Context, key string, path string) error {
+	return s.exists(key)
+}
+
+func (s *fakeStore) MutateIn(ctx context.Context, key string, path string, value []byte) error {
+	return s.exists(key)
+}
+
+func (s *fakeStore) Query(ctx context.Context, statement string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.statements = append(s.statements, statement)
+	return nil
+}
+
+func (s *fakeStore) RangeScan(ctx context.Context, fromKey string, limit int) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.scans = append(s.scans, fromKey)
+	return nil
+}
+
+func (s *fakeStore) Transaction(ctx context.Context, keys []string, path string, value []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.txns = append(s.txns, keys)
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	generator, err := dataloader.NewGenerator(&dataloader.GeneratorOptions{})
+	require.NoError(t, err)
+
+	store := &fakeStore{docs: make(map[string][]byte)}
+
+	var intervals []*IntervalStats
+	summary, err := Run(context.Background(), store, &Options{
+		Mix:       Mix{OpGet: 1, OpUpsert: 1, OpQuery: 1, OpRangeScan: 1, OpTxn: 1},
+		Generator: generator,
+		Count:     10,
+		Keyspace:  "`default`.`_default`.`_default`",
+		OpsPerSec: 500,
+		Workers:   4,
+		Duration:  300 * time.Millisecond,
+		OpTimeout: time.Second,
+		Interval:  100 * time.Millisecond,
+		OnInterval: func(stats *IntervalStats) {
+			intervals = append(intervals, stats)
+		},
+	})
+	require.NoError(t, err)
+
+	// the rate limit allows a burst of one op per worker
+	require.Greater(t, summary.Count, int64(50))
+	require.LessOrEqual(t, summary.Count, int64(160))
+
+	// gets of documents which were not upserted yet fail
+	for errClass := range summary.Errors {
+		require.Equal(t, "doc-not-found", errClass)
+	}
+
+	require.GreaterOrEqual(t, len(intervals), 2)
+	var intervalCount int64
+	for _, stats := range intervals {
+		for _, opStats := range stats.Ops {
+			intervalCount += opStats.Count
+		}
+	}
+	require.Equal(t, summary.Count, intervalCount)
+
+	require.NotEmpty(t, store.statements)
+	for _, statement := range store.statements {
+		require.Contains(t, statement, "USE KEYS \"doc-")
+	}
+
+	require.NotEmpty(t, store.scans)
+	for _, fromKey := range store.scans {
+		require.True(t, strings.HasPrefix(fromKey, "doc-"), fromKey)
+	}
+
+	require.NotEmpty(t, store.txns)
+	for _, keys := range store.txns {
+		require.Len(t, keys, txnDocs)
+	}
+}
+
+func TestRunInvalid(t *testing.T) {
+	generator, err := dataloader.NewGenerator(&dataloader.GeneratorOptions{})
+	require.NoError(t, err)
+
+	_, err = Run(context.Background(), &fakeStore{}, &Options{
+		Mix:       Mix{},
+		Generator: generator,
+		Count:     10,
+		OpTimeout: time.Second,
+	})
+	require.Error(t, err)
+
+	_, err = Run(context.Background(), &fakeStore{}, &Options{
+		Mix:       Mix{OpGet: 1},
+		Generator: generator,
+		Count:     10,
+	})
+	require.Error(t, err)
+}